@@ -1,11 +1,13 @@
 package main
 
 import (
+	"github.com/miekg/dns"
 	"github.com/yaklang/nuclei/v2/internal/testutils"
 )
 
 var dnsTestCases = map[string]testutils.TestCase{
-	"dns/basic.yaml": &dnsBasic{},
+	"dns/basic.yaml":         &dnsBasic{},
+	"dns/zone-transfer.yaml": &dnsZoneTransfer{},
 }
 
 type dnsBasic struct{}
@@ -26,3 +28,42 @@ func (h *dnsBasic) Execute(filePath string) error {
 	}
 	return nil
 }
+
+type dnsZoneTransfer struct{}
+
+// Executes executes a test case and returns an error if occurred
+func (h *dnsZoneTransfer) Execute(filePath string) error {
+	// The template transfers the zones from this address
+	server := testutils.NewDNSServer("127.0.0.1:15353", func(w dns.ResponseWriter, req *dns.Msg) {
+		resp := new(dns.Msg)
+		resp.SetReply(req)
+
+		zone := req.Question[0].Name
+		if zone == "example.test." {
+			soa, _ := dns.NewRR(zone + " 3600 IN SOA ns1." + zone + " admin." + zone + " 1 7200 3600 1209600 3600")
+			a, _ := dns.NewRR("internal." + zone + " 3600 IN A 10.0.0.1")
+			resp.Answer = []dns.RR{soa, a, soa}
+		} else {
+			resp.Rcode = dns.RcodeRefused
+		}
+		_ = w.WriteMsg(resp)
+		_ = w.Close()
+	})
+	defer server.Close()
+
+	results, err := testutils.RunNucleiAndGetResults(filePath, "example.test", debug)
+	if err != nil {
+		return err
+	}
+	if len(results) != 1 {
+		return errIncorrectResultsCount(results)
+	}
+	results, err = testutils.RunNucleiAndGetResults(filePath, "refused.test", debug)
+	if err != nil {
+		return err
+	}
+	if len(results) != 0 {
+		return errIncorrectResultsCount(results)
+	}
+	return nil
+}
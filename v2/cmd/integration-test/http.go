@@ -1,36 +1,51 @@
 package main
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/http/httputil"
+	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/yaklang/nuclei/v2/internal/testutils"
 )
 
 var httpTestcases = map[string]testutils.TestCase{
-	"http/get-headers.yaml":           &httpGetHeaders{},
-	"http/get-query-string.yaml":      &httpGetQueryString{},
-	"http/get-redirects.yaml":         &httpGetRedirects{},
-	"http/get.yaml":                   &httpGet{},
-	"http/post-body.yaml":             &httpPostBody{},
-	"http/post-json-body.yaml":        &httpPostJSONBody{},
-	"http/post-multipart-body.yaml":   &httpPostMultipartBody{},
-	"http/raw-cookie-reuse.yaml":      &httpRawCookieReuse{},
-	"http/raw-dynamic-extractor.yaml": &httpRawDynamicExtractor{},
-	"http/raw-get-query.yaml":         &httpRawGetQuery{},
-	"http/raw-get.yaml":               &httpRawGet{},
-	"http/raw-payload.yaml":           &httpRawPayload{},
-	"http/raw-post-body.yaml":         &httpRawPostBody{},
-	"http/raw-unsafe-request.yaml":    &httpRawUnsafeRequest{},
-	"http/request-condition.yaml":     &httpRequestCondition{},
-	"http/request-condition-new.yaml": &httpRequestCondition{},
+	"http/get-headers.yaml":                 &httpGetHeaders{},
+	"http/get-custom-header.yaml":           &httpCustomHeader{},
+	"http/get-query-string.yaml":            &httpGetQueryString{},
+	"http/get-redirects.yaml":               &httpGetRedirects{},
+	"http/get.yaml":                         &httpGet{},
+	"http/post-body.yaml":                   &httpPostBody{},
+	"http/post-json-body.yaml":              &httpPostJSONBody{},
+	"http/post-multipart-body.yaml":         &httpPostMultipartBody{},
+	"http/raw-cookie-reuse.yaml":            &httpRawCookieReuse{},
+	"http/raw-cookie-isolation.yaml":        &httpRawCookieIsolation{},
+	"http/raw-custom-header.yaml":           &httpCustomHeader{},
+	"http/raw-dynamic-extractor.yaml":       &httpRawDynamicExtractor{},
+	"http/raw-dynamic-extractor-multi.yaml": &httpRawDynamicExtractorMulti{},
+	"http/raw-get-query.yaml":               &httpRawGetQuery{},
+	"http/raw-kval-extractor.yaml":          &httpRawKvalExtractor{},
+	"http/raw-named-group-extractor.yaml":   &httpRawNamedGroupExtractor{},
+	"http/raw-get.yaml":                     &httpRawGet{},
+	"http/raw-baseline.yaml":                &httpRawBaseline{},
+	"http/raw-payload.yaml":                 &httpRawPayload{},
+	"http/raw-post-body.yaml":               &httpRawPostBody{},
+	"http/raw-unsafe-request.yaml":          &httpRawUnsafeRequest{},
+	"http/raw-unsafe-verbatim.yaml":         &httpRawUnsafeVerbatim{},
+	"http/raw-gzip-response.yaml":           &httpRawGzipResponse{},
+	"http/request-condition.yaml":           &httpRequestCondition{},
+	"http/request-condition-new.yaml":       &httpRequestCondition{},
 }
 
 func httpDebugRequestDump(r *http.Request) {
@@ -65,6 +80,30 @@ func (h *httpGetHeaders) Execute(filePath string) error {
 	return nil
 }
 
+type httpCustomHeader struct{}
+
+// Executes executes a test case and returns an error if occurred
+func (h *httpCustomHeader) Execute(filePath string) error {
+	router := httprouter.New()
+	router.GET("/", httprouter.Handle(func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		httpDebugRequestDump(r)
+		if r.Header.Get("X-Bug-Bounty") == "researcher-"+r.Host {
+			fmt.Fprintf(w, "This is test custom header matcher text")
+		}
+	}))
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	results, err := testutils.RunNucleiAndGetResults(filePath, ts.URL, debug, "-H", "X-Bug-Bounty: researcher-{{Hostname}}")
+	if err != nil {
+		return err
+	}
+	if len(results) != 1 {
+		return errIncorrectResultsCount(results)
+	}
+	return nil
+}
+
 type httpGetQueryString struct{}
 
 // Executes executes a test case and returns an error if occurred
@@ -291,6 +330,147 @@ func (h *httpRawDynamicExtractor) Execute(filePath string) error {
 	return nil
 }
 
+type httpRawKvalExtractor struct{}
+
+// Executes executes a test case and returns an error if occurred
+func (h *httpRawKvalExtractor) Execute(filePath string) error {
+	router := httprouter.New()
+	var routerErr error
+
+	router.GET("/", httprouter.Handle(func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		httpDebugRequestDump(r)
+		http.SetCookie(w, &http.Cookie{Name: "csrf-token", Value: "nuclei-csrf"})
+		w.Header().Set("X-Request-Id", "nuclei-request")
+		fmt.Fprintf(w, "login form")
+	}))
+	router.POST("/", httprouter.Handle(func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		httpDebugRequestDump(r)
+		if err := r.ParseForm(); err != nil {
+			routerErr = err
+			return
+		}
+		if r.Form.Get("csrf") == "nuclei-csrf" && r.Form.Get("request") == "nuclei-request" {
+			fmt.Fprintf(w, "Test is test-kval-extractor-raw matcher text")
+		}
+	}))
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	results, err := testutils.RunNucleiAndGetResults(filePath, ts.URL, debug)
+	if err != nil {
+		return err
+	}
+	if routerErr != nil {
+		return routerErr
+	}
+	if len(results) != 1 {
+		return errIncorrectResultsCount(results)
+	}
+	return nil
+}
+
+type httpRawNamedGroupExtractor struct{}
+
+// Executes executes a test case and returns an error if occurred
+func (h *httpRawNamedGroupExtractor) Execute(filePath string) error {
+	router := httprouter.New()
+	var routerErr error
+
+	router.GET("/", httprouter.Handle(func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		httpDebugRequestDump(r)
+		fmt.Fprintf(w, `<form><input type="hidden" name="csrf" value="nuclei4csrf"></form>`)
+	}))
+	router.POST("/", httprouter.Handle(func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		httpDebugRequestDump(r)
+		if err := r.ParseForm(); err != nil {
+			routerErr = err
+			return
+		}
+		if r.Form.Get("csrf") == "nuclei4csrf" {
+			fmt.Fprintf(w, "Test is test-named-group-extractor-raw matcher text")
+		}
+	}))
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	results, err := testutils.RunNucleiAndGetResults(filePath, ts.URL, debug)
+	if err != nil {
+		return err
+	}
+	if routerErr != nil {
+		return routerErr
+	}
+	if len(results) != 1 {
+		return errIncorrectResultsCount(results)
+	}
+	return nil
+}
+
+type httpRawBaseline struct{}
+
+// Executes executes a test case and returns an error if occurred
+func (h *httpRawBaseline) Execute(filePath string) error {
+	router := httprouter.New()
+	router.GET("/", httprouter.Handle(func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		httpDebugRequestDump(r)
+		// every response of the slow host takes at least a second
+		sleep, _ := strconv.Atoi(r.URL.Query().Get("sleep"))
+		time.Sleep(time.Second + time.Duration(sleep)*time.Second)
+		fmt.Fprintf(w, "This is test baseline matcher text")
+	}))
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	results, err := testutils.RunNucleiAndGetResults(filePath, ts.URL, debug)
+	if err != nil {
+		return err
+	}
+	if len(results) != 1 {
+		return errIncorrectResultsCount(results)
+	}
+	return nil
+}
+
+type httpRawDynamicExtractorMulti struct{}
+
+// Executes executes a test case and returns an error if occurred
+func (h *httpRawDynamicExtractorMulti) Execute(filePath string) error {
+	router := httprouter.New()
+	var routerErr error
+
+	router.POST("/", httprouter.Handle(func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		httpDebugRequestDump(r)
+		if err := r.ParseForm(); err != nil {
+			routerErr = err
+			return
+		}
+		if strings.EqualFold(r.Form.Get("testing"), "parameter") {
+			fmt.Fprintf(w, "Token: 'nuclei' Token: 'projectdiscovery' Token: 'yaklang'")
+		}
+	}))
+	router.GET("/", httprouter.Handle(func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		httpDebugRequestDump(r)
+		switch strings.ToLower(r.URL.Query().Get("username")) {
+		case "nuclei", "projectdiscovery", "yaklang":
+			fmt.Fprintf(w, "Test is test-dynamic-extractor-raw matcher text")
+		}
+	}))
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	results, err := testutils.RunNucleiAndGetResults(filePath, ts.URL, debug)
+	if err != nil {
+		return err
+	}
+	if routerErr != nil {
+		return routerErr
+	}
+	if len(results) != 3 {
+		return errIncorrectResultsCount(results)
+	}
+	return nil
+}
+
 type httpRawGetQuery struct{}
 
 // Executes executes a test case and returns an error if occurred
@@ -466,6 +646,62 @@ func (h *httpRawCookieReuse) Execute(filePath string) error {
 	return nil
 }
 
+type httpRawCookieIsolation struct{}
+
+// Executes executes a test case and returns an error if occurred
+func (h *httpRawCookieIsolation) Execute(filePath string) error {
+	setter := httprouter.New()
+	setter.POST("/", httprouter.Handle(func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		httpDebugRequestDump(r)
+		http.SetCookie(w, &http.Cookie{Name: "nuclei", Value: "test"})
+	}))
+	setter.GET("/", httprouter.Handle(func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		httpDebugRequestDump(r)
+		if cookie, err := r.Cookie("nuclei"); err == nil && cookie.Value == "test" {
+			fmt.Fprintf(w, "Test is test-cookie-isolation matcher text")
+		}
+	}))
+	var leaked bool
+	other := httprouter.New()
+	other.POST("/", httprouter.Handle(func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		httpDebugRequestDump(r)
+		// let the setter store its cookie before the second request
+		time.Sleep(500 * time.Millisecond)
+	}))
+	other.GET("/", httprouter.Handle(func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		httpDebugRequestDump(r)
+		if _, err := r.Cookie("nuclei"); err == nil {
+			leaked = true
+		}
+	}))
+	setterServer := httptest.NewServer(setter)
+	defer setterServer.Close()
+	otherServer := httptest.NewServer(other)
+	defer otherServer.Close()
+
+	list, err := ioutil.TempFile("", "nuclei-cookie-isolation-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(list.Name())
+	if _, err := list.WriteString(otherServer.URL + "\n"); err != nil {
+		return err
+	}
+	list.Close()
+
+	results, err := testutils.RunNucleiAndGetResults(filePath, setterServer.URL, debug, "-l", list.Name())
+	if err != nil {
+		return err
+	}
+	if leaked {
+		return errors.New("cookie of a target was sent to another target")
+	}
+	if len(results) != 1 {
+		return errIncorrectResultsCount(results)
+	}
+	return nil
+}
+
 type httpRawUnsafeRequest struct{}
 
 // Executes executes a test case and returns an error if occurred
@@ -491,6 +727,78 @@ func (h *httpRawUnsafeRequest) Execute(filePath string) error {
 	return nil
 }
 
+type httpRawUnsafeVerbatim struct{}
+
+// Executes executes a test case and returns an error if occurred
+func (h *httpRawUnsafeVerbatim) Execute(filePath string) error {
+	var routerErr error
+
+	ts := testutils.NewTCPServer(func(conn net.Conn) {
+		defer conn.Close()
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		data := make([]byte, 0, 512)
+		buffer := make([]byte, 512)
+		for !bytes.HasSuffix(data, []byte("body-without-length\r\n")) {
+			n, err := conn.Read(buffer)
+			if err != nil {
+				break
+			}
+			data = append(data, buffer[:n]...)
+		}
+		expected := "POST /verbatim HTTP/1.1\r\n  X-Leading: space\r\nX-Target: " + conn.LocalAddr().String() + "\r\nX-Dup: one\r\nX-Dup: two\r\nX-Spaced : value\r\nConnection: close\r\n\r\nbody-without-length\r\n"
+		if string(data) != expected {
+			routerErr = fmt.Errorf("unexpected request bytes %q", data)
+			return
+		}
+		_, _ = conn.Write([]byte("HTTP/1.1 200 OK\r\nConnection: close\r\nContent-Length: 37\r\nContent-Type: text/plain; charset=utf-8\r\n\r\nThis is test raw-unsafe-verbatim test"))
+	})
+	defer ts.Close()
+
+	results, err := testutils.RunNucleiAndGetResults(filePath, "http://"+ts.URL, debug)
+	if err != nil {
+		return err
+	}
+	if routerErr != nil {
+		return routerErr
+	}
+	if len(results) != 1 {
+		return errIncorrectResultsCount(results)
+	}
+	return nil
+}
+
+type httpRawGzipResponse struct{}
+
+// Executes executes a test case and returns an error if occurred
+func (h *httpRawGzipResponse) Execute(filePath string) error {
+	var routerErr error
+
+	buffer := &bytes.Buffer{}
+	writer := gzip.NewWriter(buffer)
+	_, _ = writer.Write([]byte("This is test raw-gzip-matcher test"))
+	writer.Close()
+	body := buffer.Bytes()
+
+	ts := testutils.NewTCPServer(func(conn net.Conn) {
+		defer conn.Close()
+		_, _ = conn.Write([]byte(fmt.Sprintf("HTTP/1.1 200 OK\r\nConnection: close\r\nContent-Encoding: gzip\r\nContent-Length: %d\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n", len(body))))
+		_, _ = conn.Write(body)
+	})
+	defer ts.Close()
+
+	results, err := testutils.RunNucleiAndGetResults(filePath, "http://"+ts.URL, debug)
+	if err != nil {
+		return err
+	}
+	if routerErr != nil {
+		return routerErr
+	}
+	if len(results) != 1 {
+		return errIncorrectResultsCount(results)
+	}
+	return nil
+}
+
 type httpRequestCondition struct{}
 
 // Executes executes a test case and returns an error if occurred
@@ -1,15 +1,21 @@
 package main
 
 import (
+	"bufio"
+	"crypto/tls"
+	"io/ioutil"
 	"net"
+	"os"
 
 	"github.com/yaklang/nuclei/v2/internal/testutils"
+	"github.com/yaklang/nuclei/v2/internal/testutils/certificates"
 )
 
 var networkTestcases = map[string]testutils.TestCase{
 	"network/basic.yaml":      &networkBasic{},
 	"network/hex.yaml":        &networkBasic{},
 	"network/multi-step.yaml": &networkMultiStep{},
+	"network/starttls.yaml":   &networkStartTLS{},
 }
 
 type networkBasic struct{}
@@ -87,3 +93,59 @@ func (h *networkMultiStep) Execute(filePath string) error {
 	}
 	return nil
 }
+
+type networkStartTLS struct{}
+
+// Executes executes a test case and returns an error if occurred
+func (h *networkStartTLS) Execute(filePath string) error {
+	var routerErr error
+
+	dir, err := ioutil.TempDir("", "nuclei-starttls-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	generated, err := certificates.Generate(dir)
+	if err != nil {
+		return err
+	}
+
+	ts := testutils.NewTCPServer(func(conn net.Conn) {
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		_, _ = conn.Write([]byte("220 test ESMTP\r\n"))
+		if _, err := reader.ReadString('\n'); err != nil {
+			routerErr = err
+			return
+		}
+		_, _ = conn.Write([]byte("250-test\r\n250 STARTTLS\r\n"))
+		if line, err := reader.ReadString('\n'); err != nil || line != "STARTTLS\r\n" {
+			routerErr = err
+			return
+		}
+		_, _ = conn.Write([]byte("220 ready to start tls\r\n"))
+
+		tlsConn := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{generated.Server}})
+		if err := tlsConn.Handshake(); err != nil {
+			routerErr = err
+			return
+		}
+		if line, _ := bufio.NewReader(tlsConn).ReadString('\n'); line == "EHLO nuclei\r\n" {
+			_, _ = tlsConn.Write([]byte("250 secure\r\n"))
+		}
+	})
+	defer ts.Close()
+
+	results, err := testutils.RunNucleiAndGetResults(filePath, ts.URL, debug)
+	if err != nil {
+		return err
+	}
+	if routerErr != nil {
+		return routerErr
+	}
+	if len(results) != 1 {
+		return errIncorrectResultsCount(results)
+	}
+	return nil
+}
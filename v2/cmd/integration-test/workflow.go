@@ -4,16 +4,18 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/yaklang/nuclei/v2/internal/testutils"
 )
 
 var workflowTestcases = map[string]testutils.TestCase{
-	"workflow/basic.yaml":               &workflowBasic{},
-	"workflow/condition-matched.yaml":   &workflowConditionMatched{},
-	"workflow/condition-unmatched.yaml": &workflowConditionUnmatch{},
-	"workflow/matcher-name.yaml":        &workflowMatcherName{},
+	"workflow/basic.yaml":                 &workflowBasic{},
+	"workflow/condition-matched.yaml":     &workflowConditionMatched{},
+	"workflow/condition-unmatched.yaml":   &workflowConditionUnmatch{},
+	"workflow/matcher-name.yaml":          &workflowMatcherName{},
+	"workflow/matcher-name-branches.yaml": &workflowMatcherNameBranches{},
 }
 
 type workflowBasic struct{}
@@ -103,3 +105,25 @@ func (h *workflowMatcherName) Execute(filePath string) error {
 	}
 	return nil
 }
+
+type workflowMatcherNameBranches struct{}
+
+// Executes executes a test case and returns an error if occurred
+func (h *workflowMatcherNameBranches) Execute(filePath string) error {
+	router := httprouter.New()
+	router.GET("/", httprouter.Handle(func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		httpDebugRequestDump(r)
+		fmt.Fprintf(w, "This is test matcher text")
+	}))
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	results, err := testutils.RunNucleiWorkflowAndGetResults(filePath, ts.URL, debug)
+	if err != nil {
+		return err
+	}
+	if len(results) != 1 || !strings.Contains(results[0], "[basic-get]") {
+		return errIncorrectResultsCount(results)
+	}
+	return nil
+}
@@ -11,8 +11,10 @@ import (
 )
 
 var (
-	cfgFile string
-	options = &types.Options{}
+	cfgFile                    string
+	randomAgent                bool
+	restrictLocalNetworkAccess bool
+	options                    = &types.Options{}
 )
 
 func main() {
@@ -26,6 +28,9 @@ func main() {
 	}
 	nucleiRunner.RunEnumeration()
 	nucleiRunner.Close()
+	if nucleiRunner.FailureThresholdReached() {
+		os.Exit(runner.FailureExitCode)
+	}
 }
 
 func readConfig() {
@@ -44,48 +49,86 @@ based on templates offering massive extensibility and ease of use.`)
 	set.StringSliceVarP(&options.ExcludedTemplates, "exclude", "et", []string{}, "Templates to exclude, supports single and multiple templates using directory.")
 	set.StringSliceVarP(&options.Severity, "severity", "impact", []string{}, "Templates to run based on severity, supports single and multiple severity.")
 	set.StringVarP(&options.Targets, "list", "l", "", "List of URLs to run templates on")
+	set.StringVar(&options.Shard, "shard", "", "Scan only the targets assigned to a shard (eg. 3/10)")
 	set.StringVarP(&options.Output, "output", "o", "", "File to write output to (optional)")
+	set.IntVar(&options.OutputSyncInterval, "output-sync-interval", 0, "Seconds between syncs of the output file to disk (0 syncs only on exit)")
+	set.IntVar(&options.MaxResultsPerTemplate, "max-results-per-template", 0, "Maximum results written per template, further matches are only counted (0 for no limit)")
+	set.StringVar(&options.Manifest, "manifest", "", "File to write the scan manifest describing the scan for reproducibility")
+	set.StringVar(&options.RepairOutput, "repair-output", "", "Repair a json output file left incomplete by an unclean exit")
 	set.StringVar(&options.ProxyURL, "proxy-url", "", "URL of the proxy server")
 	set.StringVar(&options.ProxySocksURL, "proxy-socks-url", "", "URL of the proxy socks server")
+	set.StringVar(&options.ProxyFile, "proxy-file", "", "File containing http and socks5 proxies to rotate the requests through")
+	set.StringVar(&options.ClientCertFile, "client-cert", "", "Client certificate file (PEM) for mutual tls authentication")
+	set.StringVar(&options.ClientKeyFile, "client-key", "", "Private key file (PEM) of the client certificate")
+	set.StringVar(&options.ClientCAFile, "client-ca", "", "CA file (PEM) to verify servers with when using a client certificate")
 	set.BoolVar(&options.Silent, "silent", false, "Show only results in output")
 	set.BoolVar(&options.Version, "version", false, "Show version of nuclei")
 	set.BoolVarP(&options.Verbose, "verbose", "v", false, "Show verbose output")
 	set.BoolVarP(&options.NoColor, "no-color", "nc", false, "Disable colors in output")
 	set.IntVar(&options.Timeout, "timeout", 5, "Time to wait in seconds before timeout")
+	set.IntVar(&options.TimeoutDNS, "timeout-dns", 0, "Time to wait in seconds for dns requests (default min(timeout, 2))")
+	set.IntVar(&options.TimeoutHTTP, "timeout-http", 0, "Time to wait in seconds for http requests (default timeout)")
+	set.IntVar(&options.TimeoutHeadless, "timeout-headless", 0, "Time to wait in seconds for headless browser requests (default max(timeout, 30))")
+	set.IntVar(&options.TimeoutNetwork, "timeout-network", 0, "Time to wait in seconds for network requests (default timeout)")
 	set.IntVar(&options.Retries, "retries", 1, "Number of times to retry a failed request")
-	set.StringSliceVarP(&options.CustomHeaders, "header", "H", []string{}, "Custom Header.")
+	set.IntVar(&options.ResponseReadSize, "response-size-read", 0, "Maximum size of http response bodies to read in bytes (0 for no limit)")
+	set.StringSliceVarP(&options.CustomHeaders, "header", "H", []string{}, "Custom header added to all http requests (header: value) or file with a header per line")
+	set.StringSliceVar(&options.Vars, "var", []string{}, "Custom variable usable in all templates (key=value)")
+	set.StringVarP(&options.CustomUserAgent, "user-agent", "ua", "", "Fixed user agent for http requests, supports {{Hostname}} and helper expressions")
+	set.BoolVar(&randomAgent, "random-agent", true, "Send a random user agent with http requests not setting one")
 	set.BoolVar(&options.Debug, "debug", false, "Debugging request and responses")
 	set.BoolVar(&options.DebugRequests, "debug-req", false, "Debugging request")
 	set.BoolVar(&options.DebugResponse, "debug-resp", false, "Debugging response")
 	set.BoolVarP(&options.UpdateTemplates, "update-templates", "ut", false, "Download / updates nuclei community templates")
 	set.StringVar(&options.TraceLogFile, "trace-log", "", "File to write sent requests trace log")
 	set.StringVarP(&options.TemplatesDirectory, "update-directory", "ud", templatesDirectory, "Directory storing nuclei-templates")
+	set.BoolVar(&options.Sandbox, "sandbox", false, "Restrict payload files loaded by templates to the templates directory or their own directory")
 	set.BoolVar(&options.JSON, "json", false, "Write json output to files")
 	set.BoolVarP(&options.JSONRequests, "include-rr", "irr", false, "Write requests/responses for matches in JSON output")
+	set.IntVar(&options.JSONRequestsMaxSize, "include-rr-size", 1048576, "Maximum size in bytes of the requests/responses written in JSON output (0 for no limit)")
+	set.StringVar(&options.FailOnSeverity, "fail-on-severity", "", "Exit with code 2 when results of at least this severity are found (eg. high)")
+	set.BoolVar(&options.FailOnFindings, "fail-on-findings", false, "Exit with code 2 when any result is found")
+	set.BoolVar(&options.MatcherStatus, "matcher-status", false, "Write an event with matcher-status false for the templates not matching an input")
+	set.BoolVar(&options.StoreResponse, "store-resp", false, "Store requests/responses of matches to files")
+	set.StringVar(&options.ExtractedFilesDir, "extracted-files-dir", "", "Directory to write the values of to-file extractors to (disabled if not set)")
+	set.StringVar(&options.StoreResponseDir, "store-resp-dir", "output", "Directory to store requests/responses of matches in")
 	set.BoolVar(&options.EnableProgressBar, "stats", false, "Display stats of the running scan")
 	set.BoolVar(&options.TemplateList, "tl", false, "List available templates")
 	set.IntVarP(&options.RateLimit, "rate-limit", "rl", 150, "Maximum requests to send per second")
 	set.BoolVarP(&options.StopAtFirstMatch, "stop-at-first-path", "spm", false, "Stop processing http requests at first match (this may break template/workflow logic)")
 	set.IntVarP(&options.BulkSize, "bulk-size", "bs", 25, "Maximum Number of hosts analyzed in parallel per template")
+	set.IntVar(&options.FileConcurrency, "file-concurrency", 0, "Maximum Number of files analyzed in parallel per file template (default bulk-size)")
 	set.IntVarP(&options.TemplateThreads, "concurrency", "c", 10, "Maximum Number of templates executed in parallel")
 	set.BoolVar(&options.Project, "project", false, "Use a project folder to avoid sending same request multiple times")
 	set.StringVar(&options.ProjectPath, "project-path", "", "Use a user defined project folder, temporary folder is used if not specified but enabled")
 	set.BoolVarP(&options.NoMeta, "no-meta", "nm", false, "Don't display metadata for the matches")
 	set.BoolVarP(&options.TemplatesVersion, "templates-version", "tv", false, "Shows the installed nuclei-templates version")
 	set.BoolVar(&options.OfflineHTTP, "passive", false, "Enable Passive HTTP response processing mode")
+	set.StringVar(&options.TargetDir, "target-dir", "", "Directory of saved http responses to process in passive mode")
 	set.StringVarP(&options.ReportingConfig, "report-config", "rc", "", "Nuclei Reporting Module configuration file")
 	set.StringVarP(&options.ReportingDB, "report-db", "rdb", "", "Local Nuclei Reporting Database (Always use this to persistent report data)")
+	set.StringVar(&options.ReportingDBKey, "report-db-key", "", "Template of the keys identifying duplicate results (eg. {{template_id}}:{{host}})")
+	set.StringVar(&options.ReportingDBTTL, "report-db-ttl", "", "Duration after which results not seen again are reported again (eg. 720h)")
+	set.StringVarP(&options.AuthConfig, "auth-config", "ac", "", "Scan-level authentication configuration file (jwt)")
 	set.StringSliceVar(&options.Tags, "tags", []string{}, "Tags to execute templates for")
 	set.StringSliceVarP(&options.ExcludeTags, "exclude-tags", "etags", []string{}, "Exclude templates with the provided tags")
 	set.StringVarP(&options.ResolversFile, "resolvers", "r", "", "File containing resolver list for nuclei")
 	set.BoolVar(&options.Headless, "headless", false, "Enable headless browser based templates support")
 	set.BoolVar(&options.ShowBrowser, "show-browser", false, "Show the browser on the screen")
+	set.BoolVar(&options.NoScreenshots, "no-screenshots", false, "Disable the screenshot actions of headless templates")
+	set.StringVar(&options.ScreenshotDir, "screenshot-dir", "", "Directory to write headless screenshots to (default store-resp-dir)")
 	set.IntVarP(&options.StatsInterval, "stats-interval", "si", 5, "Number of seconds between each stats line")
 	set.BoolVar(&options.SystemResolvers, "system-resolvers", false, "Use system dns resolving as error fallback")
 	set.IntVar(&options.PageTimeout, "page-timeout", 20, "Seconds to wait for each page in headless")
+	set.IntVar(&options.NavigationTimeout, "navigation-timeout", 0, "Seconds to wait for each navigation in headless (default page-timeout)")
+	set.IntVar(&options.HeadlessConcurrency, "headless-concurrency", 10, "Maximum Number of headless pages open in parallel, reused across templates")
+	set.IntVar(&options.HeadlessPageUses, "headless-page-uses", 50, "Number of template executions a headless page is reused for before being recycled")
+	set.BoolVar(&options.AllowLocalFileAccess, "allow-local-file-access", false, "Allow headless templates to access local files with file:// URLs and to upload files outside their folder")
+	set.BoolVar(&restrictLocalNetworkAccess, "restrict-local-network-access", true, "Deny headless templates access to private and metadata IPs other than the target")
 	set.BoolVarP(&options.NewTemplates, "new-templates", "nt", false, "Only run newly added templates")
 	set.StringVarP(&options.DiskExportDirectory, "markdown-export", "me", "", "Directory to export results in markdown format")
 	set.StringVarP(&options.SarifExport, "sarif-export", "se", "", "File to export results in sarif format")
+	set.StringVar(&options.CSVExport, "csv-export", "", "File to export results in csv format")
 	set.BoolVar(&options.NoInteractsh, "no-interactsh", false, "Do not use interactsh server for blind interaction polling")
 	set.StringVar(&options.InteractshURL, "interactsh-url", "https://interact.sh", "Self Hosted Interactsh Server URL")
 	set.IntVar(&options.InteractionsCacheSize, "interactions-cache-size", 5000, "Number of requests to keep in interactions cache")
@@ -99,4 +142,6 @@ based on templates offering massive extensibility and ease of use.`)
 			gologger.Fatal().Msgf("Could not read config: %s\n", err)
 		}
 	}
+	options.NoRandomAgent = !randomAgent
+	options.AllowLocalNetworkAccess = !restrictLocalNetworkAccess
 }
@@ -1,6 +1,9 @@
 package runner
 
-import "github.com/projectdiscovery/gologger"
+import (
+	"github.com/projectdiscovery/gologger"
+	"github.com/yaklang/nuclei/v2/pkg/types"
+)
 
 const banner = `
                        __     _
@@ -11,7 +14,7 @@ const banner = `
 `
 
 // Version is the current version of nuclei
-const Version = `2.3.8`
+const Version = types.Version
 
 // showBanner is used to show the banner to the user
 func showBanner() {
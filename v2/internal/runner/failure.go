@@ -0,0 +1,68 @@
+package runner
+
+import (
+	"strings"
+
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/yaklang/nuclei/v2/pkg/types"
+	"go.uber.org/atomic"
+)
+
+// FailureExitCode is the exit code of nuclei when the results reach the
+// failure threshold of -fail-on-severity or -fail-on-findings.
+const FailureExitCode = 2
+
+// severityRanks are the ranks of the severities, the unknown ones being 0
+var severityRanks = map[string]int32{
+	"info":     1,
+	"low":      2,
+	"medium":   3,
+	"high":     4,
+	"critical": 5,
+}
+
+// severityWriter is an output writer recording whether results were
+// written and the highest severity among them.
+type severityWriter struct {
+	output.Writer
+	found   *atomic.Bool
+	highest *atomic.Int32
+}
+
+// newSeverityWriter creates a new severity recording writer
+func newSeverityWriter(writer output.Writer) *severityWriter {
+	return &severityWriter{Writer: writer, found: atomic.NewBool(false), highest: atomic.NewInt32(0)}
+}
+
+// Write records the severity of a result and writes it
+func (w *severityWriter) Write(event *output.ResultEvent) error {
+	// The events of the templates that did not match are not results
+	if event.MatcherStatus == nil || *event.MatcherStatus {
+		w.found.Store(true)
+		rank := severityRanks[strings.ToLower(types.ToString(event.Info["severity"]))]
+		for {
+			highest := w.highest.Load()
+			if rank <= highest || w.highest.CAS(highest, rank) {
+				break
+			}
+		}
+	}
+	return w.Writer.Write(event)
+}
+
+// reached returns true if the written results reach the failure threshold
+func (w *severityWriter) reached(options *types.Options) bool {
+	if options.FailOnFindings && w.found.Load() {
+		return true
+	}
+	if options.FailOnSeverity == "" {
+		return false
+	}
+	return w.found.Load() && w.highest.Load() >= severityRanks[strings.ToLower(options.FailOnSeverity)]
+}
+
+// FailureThresholdReached returns true if the results of the scan reached
+// the failure threshold, to exit with FailureExitCode.
+func (r *Runner) FailureThresholdReached() bool {
+	return r.severities != nil && r.severities.reached(r.options)
+}
@@ -0,0 +1,36 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaklang/nuclei/v2/internal/testutils"
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/yaklang/nuclei/v2/pkg/types"
+)
+
+func TestSeverityWriterThreshold(t *testing.T) {
+	mock := testutils.NewMockOutputWriter()
+	var written int
+	mock.WriteCallback = func(o *output.ResultEvent) {
+		written++
+	}
+	writer := newSeverityWriter(mock)
+
+	require.False(t, writer.reached(&types.Options{FailOnFindings: true}), "could reach threshold without results")
+
+	failed := false
+	require.Nil(t, writer.Write(&output.ResultEvent{Info: map[string]interface{}{"severity": "critical"}, MatcherStatus: &failed}), "could not write failure event")
+	require.False(t, writer.reached(&types.Options{FailOnFindings: true}), "could count failure event as result")
+
+	require.Nil(t, writer.Write(&output.ResultEvent{Info: map[string]interface{}{"severity": "medium"}}), "could not write result")
+	require.Equal(t, 2, written, "could not write events to the output")
+	require.True(t, writer.reached(&types.Options{FailOnFindings: true}), "could not reach findings threshold")
+	require.True(t, writer.reached(&types.Options{FailOnSeverity: "low"}), "could not reach lower severity threshold")
+	require.True(t, writer.reached(&types.Options{FailOnSeverity: "Medium"}), "could not reach same severity threshold")
+	require.False(t, writer.reached(&types.Options{FailOnSeverity: "high"}), "could reach higher severity threshold")
+	require.False(t, writer.reached(&types.Options{}), "could reach threshold without options")
+
+	require.Nil(t, writer.Write(&output.ResultEvent{Info: map[string]interface{}{"severity": "high"}}), "could not write result")
+	require.True(t, writer.reached(&types.Options{FailOnSeverity: "high"}), "could not reach updated severity threshold")
+}
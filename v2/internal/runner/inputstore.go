@@ -0,0 +1,126 @@
+package runner
+
+import (
+	"encoding/binary"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/hmap/store/hybrid"
+)
+
+const (
+	// inputIndexPrefix is the prefix of the keys mapping positions to inputs.
+	// The null byte keeps them from colliding with normalized inputs.
+	inputIndexPrefix = "\x00index:"
+	// inputCountKey is the key storing the number of inputs in the store
+	inputCountKey = "\x00count"
+)
+
+// InputStore stores the scan inputs on disk in insertion order allowing
+// stable iteration and positional access to the inputs.
+type InputStore struct {
+	hm    *hybrid.HybridMap
+	count int64
+}
+
+// NewInputStore creates a new input store. If path is empty, a temporary
+// store is created which is removed on close, otherwise the store at path
+// is opened and kept on close.
+func NewInputStore(path string) (*InputStore, error) {
+	options := hybrid.DefaultDiskOptions
+	if path != "" {
+		options.Path = path
+		options.Cleanup = false
+	}
+	hm, err := hybrid.New(options)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create input store")
+	}
+	store := &InputStore{hm: hm}
+	if value, ok := hm.Get(inputCountKey); ok {
+		count, err := strconv.ParseInt(string(value), 10, 64)
+		if err != nil {
+			hm.Close()
+			return nil, errors.Wrap(err, "could not read input store count")
+		}
+		store.count = count
+	}
+	return store, nil
+}
+
+// Add adds an input to the end of the store returning false if the input
+// was already present.
+func (s *InputStore) Add(input string) (bool, error) {
+	if s.Has(input) {
+		return false, nil
+	}
+	position := positionBytes(s.count)
+	if err := s.hm.Set(inputIndexPrefix+string(position), []byte(input)); err != nil {
+		return false, errors.Wrap(err, "could not write input index")
+	}
+	if err := s.hm.Set(input, position); err != nil {
+		return false, errors.Wrap(err, "could not write input")
+	}
+	s.count++
+	if err := s.hm.Set(inputCountKey, []byte(strconv.FormatInt(s.count, 10))); err != nil {
+		return false, errors.Wrap(err, "could not write input count")
+	}
+	return true, nil
+}
+
+// Has returns true if the input is present in the store
+func (s *InputStore) Has(input string) bool {
+	_, ok := s.hm.Get(input)
+	return ok
+}
+
+// Count returns the number of inputs in the store
+func (s *InputStore) Count() int64 {
+	return s.count
+}
+
+// At returns the input at a position in the store
+func (s *InputStore) At(i int64) (string, bool) {
+	if i < 0 || i >= s.count {
+		return "", false
+	}
+	value, ok := s.hm.Get(inputIndexPrefix + string(positionBytes(i)))
+	if !ok {
+		return "", false
+	}
+	return string(value), true
+}
+
+// Range calls f for inputs in positions [from, to) in insertion order.
+// The position passed to f can be used as a cursor to resume iteration.
+// Iteration stops at the first error returned by f.
+func (s *InputStore) Range(from, to int64, f func(i int64, input string) error) error {
+	if from < 0 {
+		from = 0
+	}
+	if to > s.count {
+		to = s.count
+	}
+	for i := from; i < to; i++ {
+		input, ok := s.At(i)
+		if !ok {
+			return errors.Errorf("could not read input at position %d", i)
+		}
+		if err := f(i, input); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the input store
+func (s *InputStore) Close() error {
+	return s.hm.Close()
+}
+
+// positionBytes encodes a position so index keys sort in insertion order
+func positionBytes(i int64) []byte {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, uint64(i))
+	return data
+}
@@ -0,0 +1,89 @@
+package runner
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInputStoreOrdering(t *testing.T) {
+	path, err := ioutil.TempDir("", "nuclei-inputs-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(path)
+
+	store, err := NewInputStore(path)
+	require.Nil(t, err, "could not create input store")
+
+	var inputs []string
+	for i := 0; i < 1000; i++ {
+		// insert in an order different from the sorted key order
+		input := fmt.Sprintf("http://host-%d.example.com", (i*7919)%1000)
+		added, err := store.Add(input)
+		require.Nil(t, err, "could not add input")
+		require.True(t, added, "could not add new input")
+		inputs = append(inputs, input)
+	}
+	added, err := store.Add(inputs[10])
+	require.Nil(t, err, "could not add duplicate input")
+	require.False(t, added, "duplicate input was added")
+	require.Nil(t, store.Close(), "could not close input store")
+
+	store, err = NewInputStore(path)
+	require.Nil(t, err, "could not reopen input store")
+	defer store.Close()
+
+	require.Equal(t, int64(len(inputs)), store.Count(), "could not get input count after reopen")
+	var got []string
+	err = store.Range(0, store.Count(), func(_ int64, input string) error {
+		got = append(got, input)
+		return nil
+	})
+	require.Nil(t, err, "could not range over inputs")
+	require.Equal(t, inputs, got, "could not get inputs in insertion order after reopen")
+
+	input, ok := store.At(500)
+	require.True(t, ok, "could not get input at position")
+	require.Equal(t, inputs[500], input, "could not get correct input at position")
+	_, ok = store.At(store.Count())
+	require.False(t, ok, "got input at out of range position")
+}
+
+func TestInputStoreCursor(t *testing.T) {
+	store, err := NewInputStore("")
+	require.Nil(t, err, "could not create input store")
+	defer store.Close()
+
+	for i := 0; i < 100; i++ {
+		_, err := store.Add(fmt.Sprintf("host-%d", i))
+		require.Nil(t, err, "could not add input")
+	}
+
+	// iterate until interrupted, recording the cursor to resume from
+	errStop := errors.New("stop")
+	var cursor int64
+	var seen []string
+	err = store.Range(0, store.Count(), func(i int64, input string) error {
+		if i == 40 {
+			return errStop
+		}
+		seen = append(seen, input)
+		cursor = i + 1
+		return nil
+	})
+	require.Equal(t, errStop, err, "could not stop iteration")
+	require.Equal(t, int64(40), cursor, "could not get correct cursor")
+
+	err = store.Range(cursor, store.Count(), func(_ int64, input string) error {
+		seen = append(seen, input)
+		return nil
+	})
+	require.Nil(t, err, "could not resume iteration")
+	require.Len(t, seen, 100, "could not iterate all inputs")
+	for i, input := range seen {
+		require.Equal(t, fmt.Sprintf("host-%d", i), input, "could not resume at correct position")
+	}
+}
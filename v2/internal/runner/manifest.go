@@ -0,0 +1,243 @@
+package runner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/xid"
+	"github.com/yaklang/nuclei/v2/pkg/templates"
+	"github.com/yaklang/nuclei/v2/pkg/types"
+)
+
+// maskedValue replaces the sensitive values in the manifest
+const maskedValue = "********"
+
+// sensitiveNameRegex matches option and header names with sensitive values
+var sensitiveNameRegex = regexp.MustCompile(`(?i)(auth|cookie|secret|token|passw|api[-_]?key|session)`)
+
+// scanManifest describes what was run in a scan allowing it to be reproduced
+type scanManifest struct {
+	ScanID             string                 `json:"scan-id"`
+	EngineVersion      string                 `json:"engine-version"`
+	StartedAt          time.Time              `json:"started-at"`
+	FinishedAt         *time.Time             `json:"finished-at,omitempty"`
+	Complete           bool                   `json:"complete"`
+	TemplatesDirectory string                 `json:"templates-directory,omitempty"`
+	TemplatesVersion   string                 `json:"templates-version,omitempty"`
+	TemplatesHash      string                 `json:"templates-hash,omitempty"`
+	Templates          []manifestTemplate     `json:"templates"`
+	Options            map[string]interface{} `json:"options"`
+	Targets            manifestTargets        `json:"targets"`
+	Summary            *manifestSummary       `json:"summary,omitempty"`
+
+	path       string
+	targetHash hash.Hash
+}
+
+type manifestTemplate struct {
+	ID       string `json:"id"`
+	Path     string `json:"path"`
+	SHA256   string `json:"sha256"`
+	Workflow bool   `json:"workflow,omitempty"`
+}
+
+type manifestTargets struct {
+	Count int64          `json:"count"`
+	Hash  string         `json:"hash"`
+	Files []manifestFile `json:"files,omitempty"`
+}
+
+type manifestFile struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+}
+
+type manifestSummary struct {
+	Duration     string `json:"duration"`
+	Templates    int    `json:"templates"`
+	Requests     int64  `json:"requests"`
+	Matches      int64  `json:"matches"`
+	Suppressed   int64  `json:"suppressed,omitempty"`
+	ResultsFound bool   `json:"results-found"`
+}
+
+// newScanManifest creates a new scan manifest written to path
+func newScanManifest(path string, options *types.Options) (*scanManifest, error) {
+	maskedOptions, err := maskOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	return &scanManifest{
+		ScanID:             xid.New().String(),
+		EngineVersion:      types.Version,
+		StartedAt:          time.Now(),
+		TemplatesDirectory: options.TemplatesDirectory,
+		Options:            maskedOptions,
+		path:               path,
+		targetHash:         sha256.New(),
+	}, nil
+}
+
+// addTarget adds a scanned target to the streaming target hash
+func (m *scanManifest) addTarget(target string) {
+	m.Targets.Count++
+	_, _ = io.WriteString(m.targetHash, target)
+	_, _ = io.WriteString(m.targetHash, "\n")
+}
+
+// addInputFile adds the hash of an input file to the manifest
+func (m *scanManifest) addInputFile(path string) error {
+	sum, err := hashFile(path)
+	if err != nil {
+		return errors.Wrap(err, "could not hash input file")
+	}
+	m.Targets.Files = append(m.Targets.Files, manifestFile{Path: path, SHA256: sum})
+	return nil
+}
+
+// setTemplates records the resolved templates and workflows of the scan
+func (m *scanManifest) setTemplates(version string, parsed, workflows map[string]*templates.Template) error {
+	m.TemplatesVersion = version
+	m.Templates = make([]manifestTemplate, 0, len(parsed)+len(workflows))
+	for _, template := range parsed {
+		if err := m.addTemplate(template, false); err != nil {
+			return err
+		}
+	}
+	for _, template := range workflows {
+		if err := m.addTemplate(template, true); err != nil {
+			return err
+		}
+	}
+	sort.Slice(m.Templates, func(i, j int) bool {
+		return m.Templates[i].Path < m.Templates[j].Path
+	})
+
+	hasher := sha256.New()
+	for _, template := range m.Templates {
+		_, _ = io.WriteString(hasher, template.ID+" "+template.SHA256+"\n")
+	}
+	m.TemplatesHash = hex.EncodeToString(hasher.Sum(nil))
+	return nil
+}
+
+func (m *scanManifest) addTemplate(template *templates.Template, workflow bool) error {
+	sum, err := hashFile(template.Path)
+	if err != nil {
+		return errors.Wrapf(err, "could not hash template %s", template.ID)
+	}
+	m.Templates = append(m.Templates, manifestTemplate{ID: template.ID, Path: template.Path, SHA256: sum, Workflow: workflow})
+	return nil
+}
+
+// finalize marks the manifest complete with the summary of the scan
+func (m *scanManifest) finalize(summary *manifestSummary) {
+	finished := time.Now()
+	summary.Duration = finished.Sub(m.StartedAt).Round(time.Millisecond).String()
+	m.FinishedAt = &finished
+	m.Complete = true
+	m.Summary = summary
+}
+
+// write writes the manifest to disk replacing any previous version
+func (m *scanManifest) write() error {
+	m.Targets.Hash = hex.EncodeToString(m.targetHash.Sum(nil))
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "could not marshal manifest")
+	}
+	temp := m.path + ".tmp"
+	if err := ioutil.WriteFile(temp, data, 0644); err != nil {
+		return errors.Wrap(err, "could not write manifest")
+	}
+	if err := os.Rename(temp, m.path); err != nil {
+		return errors.Wrap(err, "could not write manifest")
+	}
+	return nil
+}
+
+// maskOptions returns the options as a map with sensitive values masked
+func maskOptions(options *types.Options) (map[string]interface{}, error) {
+	data, err := json.Marshal(options)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal options")
+	}
+	masked := make(map[string]interface{})
+	if err := json.Unmarshal(data, &masked); err != nil {
+		return nil, errors.Wrap(err, "could not unmarshal options")
+	}
+	for key, value := range masked {
+		switch v := value.(type) {
+		case string:
+			if v != "" && sensitiveNameRegex.MatchString(key) {
+				masked[key] = maskedValue
+			} else {
+				masked[key] = maskURLPassword(v)
+			}
+		case []interface{}:
+			if key == "CustomHeaders" {
+				masked[key] = maskHeaders(v)
+			}
+		}
+	}
+	return masked, nil
+}
+
+// maskHeaders masks the values of sensitive headers in header: value pairs
+func maskHeaders(headers []interface{}) []interface{} {
+	masked := make([]interface{}, 0, len(headers))
+	for _, header := range headers {
+		value, ok := header.(string)
+		if !ok {
+			continue
+		}
+		if parts := strings.SplitN(value, ":", 2); len(parts) == 2 && sensitiveNameRegex.MatchString(parts[0]) {
+			value = parts[0] + ": " + maskedValue
+		}
+		masked = append(masked, value)
+	}
+	return masked
+}
+
+// maskURLPassword masks the password of a url value if any
+func maskURLPassword(value string) string {
+	if !strings.Contains(value, "@") {
+		return value
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.User == nil {
+		return value
+	}
+	if _, ok := parsed.User.Password(); !ok {
+		return value
+	}
+	parsed.User = url.UserPassword(parsed.User.Username(), maskedValue)
+	return parsed.String()
+}
+
+// hashFile returns the hex encoded sha256 of a file streaming its contents
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
@@ -0,0 +1,60 @@
+package runner
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaklang/nuclei/v2/pkg/types"
+)
+
+func TestLoadCustomHeaders(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nuclei-custom-headers-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "headers.txt")
+	err = ioutil.WriteFile(file, []byte("X-Program: nuclei\n\nX-Researcher: {{Hostname}}\n"), 0644)
+	require.Nil(t, err, "could not write headers file")
+
+	options := &types.Options{CustomHeaders: []string{"User-Agent: test", file}}
+	err = loadCustomHeaders(options)
+	require.Nil(t, err, "could not load custom headers")
+	require.Equal(t, []string{"User-Agent: test", "X-Program: nuclei", "X-Researcher: {{Hostname}}"}, []string(options.CustomHeaders), "could not get custom headers")
+
+	// Paths with a colon like C:\headers.txt are files if they exist
+	colonFile := filepath.Join(dir, "C:headers.txt")
+	err = ioutil.WriteFile(colonFile, []byte("X-Drive: c\n"), 0644)
+	require.Nil(t, err, "could not write headers file")
+	options = &types.Options{CustomHeaders: []string{colonFile}}
+	err = loadCustomHeaders(options)
+	require.Nil(t, err, "could not load custom headers file with colon")
+	require.Equal(t, []string{"X-Drive: c"}, []string(options.CustomHeaders), "could not get custom headers of file with colon")
+
+	err = ioutil.WriteFile(file, []byte("invalid\n"), 0644)
+	require.Nil(t, err, "could not write headers file")
+	err = loadCustomHeaders(&types.Options{CustomHeaders: []string{file}})
+	require.NotNil(t, err, "loaded invalid custom header")
+}
+
+func TestValidateProxies(t *testing.T) {
+	options := &types.Options{TemplateList: true, InternalProxyList: []string{"http://127.0.0.1:8080", "socks5://127.0.0.1:1080"}}
+	err := validateOptions(options)
+	require.Nil(t, err, "could not validate proxies")
+
+	options.InternalProxyList = append(options.InternalProxyList, "127.0.0.1:3128")
+	err = validateOptions(options)
+	require.NotNil(t, err, "could validate invalid proxy")
+}
+
+func TestValidateReportingDB(t *testing.T) {
+	options := &types.Options{TemplateList: true, ReportingDBTTL: "720h"}
+	err := validateOptions(options)
+	require.NotNil(t, err, "could validate report db ttl without reporting")
+
+	options.ReportingConfig = "reporting.yaml"
+	err = validateOptions(options)
+	require.Nil(t, err, "could not validate report db ttl with reporting config")
+}
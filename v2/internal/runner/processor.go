@@ -11,9 +11,11 @@ import (
 func (r *Runner) processTemplateWithList(template *templates.Template) bool {
 	results := &atomic.Bool{}
 	wg := sizedwaitgroup.New(r.options.BulkSize)
-	r.hostMap.Scan(func(k, _ []byte) error {
-		URL := string(k)
-
+	// nolint:errcheck // ignoring error
+	r.inputs.Range(0, r.inputs.Count(), func(_ int64, URL string) error {
+		if err := r.ctx.Err(); err != nil {
+			return err
+		}
 		wg.Add()
 		go func(URL string) {
 			defer wg.Done()
@@ -30,13 +32,33 @@ func (r *Runner) processTemplateWithList(template *templates.Template) bool {
 	return results.Load()
 }
 
+// processSelfContainedTemplate executes a self-contained template once
+func (r *Runner) processSelfContainedTemplate(template *templates.Template) bool {
+	match, err := template.Executer.Execute("")
+	if err != nil {
+		gologger.Warning().Msgf("[%s] Could not execute step: %s\n", r.colorizer.BrightBlue(template.ID), err)
+	}
+	return match
+}
+
+// inputCount returns the number of inputs a template is executed for
+func (r *Runner) inputCount(template *templates.Template) int64 {
+	if template.SelfContained {
+		return 1
+	}
+	return r.inputs.Count()
+}
+
 // processTemplateWithList process a template on the URL list
 func (r *Runner) processWorkflowWithList(template *templates.Template) bool {
 	results := &atomic.Bool{}
 	wg := sizedwaitgroup.New(r.options.BulkSize)
 
-	r.hostMap.Scan(func(k, _ []byte) error {
-		URL := string(k)
+	// nolint:errcheck // ignoring error
+	r.inputs.Range(0, r.inputs.Count(), func(_ int64, URL string) error {
+		if err := r.ctx.Err(); err != nil {
+			return err
+		}
 		wg.Add()
 		go func(URL string) {
 			defer wg.Done()
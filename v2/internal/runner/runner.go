@@ -2,17 +2,19 @@ package runner
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"path"
-	"strings"
+	"strconv"
 	"time"
 
 	"github.com/logrusorgru/aurora"
 	"github.com/projectdiscovery/gologger"
-	"github.com/projectdiscovery/hmap/store/hybrid"
 	"github.com/yaklang/nuclei/v2/internal/colorizer"
 	"github.com/yaklang/nuclei/v2/pkg/catalog"
+	"github.com/yaklang/nuclei/v2/pkg/operators/extractors"
 	"github.com/yaklang/nuclei/v2/pkg/output"
 	"github.com/yaklang/nuclei/v2/pkg/progress"
 	"github.com/yaklang/nuclei/v2/pkg/projectfile"
@@ -20,8 +22,12 @@ import (
 	"github.com/yaklang/nuclei/v2/pkg/protocols/common/clusterer"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/common/interactsh"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/common/protocolinit"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/fingerprint"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/resultcap"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/session"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/headless/engine"
 	"github.com/yaklang/nuclei/v2/pkg/reporting"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/exporters/csv"
 	"github.com/yaklang/nuclei/v2/pkg/reporting/exporters/disk"
 	"github.com/yaklang/nuclei/v2/pkg/reporting/exporters/sarif"
 	"github.com/yaklang/nuclei/v2/pkg/templates"
@@ -35,10 +41,10 @@ import (
 
 // Runner is a client for running the enumeration process.
 type Runner struct {
-	hostMap         *hybrid.HybridMap
+	inputs          *InputStore
 	output          output.Writer
+	severities      *severityWriter
 	interactsh      *interactsh.Client
-	inputCount      int64
 	templatesConfig *nucleiConfig
 	options         *types.Options
 	projectFile     *projectfile.ProjectFile
@@ -49,6 +55,13 @@ type Runner struct {
 	severityColors  *colorizer.Colorizer
 	browser         *engine.Browser
 	ratelimiter     ratelimit.Limiter
+	session         *session.Manager
+	resultCap       *resultcap.Limiter
+	fingerprinter   *fingerprint.Fingerprinter
+	manifest        *scanManifest
+	shard           *shard
+	ctx             context.Context
+	cancel          context.CancelFunc
 }
 
 // New creates a new client for running enumeration process.
@@ -56,6 +69,7 @@ func New(options *types.Options) (*Runner, error) {
 	runner := &Runner{
 		options: options,
 	}
+	runner.ctx, runner.cancel = context.WithCancel(context.Background())
 	if options.Headless {
 		browser, err := engine.New(options)
 		if err != nil {
@@ -103,7 +117,21 @@ func New(options *types.Options) (*Runner, error) {
 			reportingOptions.SarifExporter = &sarif.Options{File: options.SarifExport}
 		}
 	}
+	if options.CSVExport != "" {
+		if reportingOptions != nil {
+			reportingOptions.CSVExporter = &csv.Options{File: options.CSVExport}
+		} else {
+			reportingOptions = &reporting.Options{}
+			reportingOptions.CSVExporter = &csv.Options{File: options.CSVExport}
+		}
+	}
 	if reportingOptions != nil {
+		if options.ReportingDBKey != "" {
+			reportingOptions.DedupeKey = options.ReportingDBKey
+		}
+		if options.ReportingDBTTL != "" {
+			reportingOptions.DedupeTTL, _ = time.ParseDuration(options.ReportingDBTTL)
+		}
 		if client, err := reporting.New(reportingOptions, options.ReportingDB); err != nil {
 			gologger.Fatal().Msgf("Could not create issue reporting client: %s\n", err)
 		} else {
@@ -121,40 +149,70 @@ func New(options *types.Options) (*Runner, error) {
 		os.Exit(0)
 	}
 
-	if (len(options.Templates) == 0 || !options.NewTemplates || (options.Targets == "" && !options.Stdin && options.Target == "")) && options.UpdateTemplates {
+	if (len(options.Templates) == 0 || !options.NewTemplates || (options.Targets == "" && !options.Stdin && options.Target == "" && options.TargetDir == "")) && options.UpdateTemplates {
 		os.Exit(0)
 	}
-	if hm, err := hybrid.New(hybrid.DefaultDiskOptions); err != nil {
+	if inputs, err := NewInputStore(""); err != nil {
 		gologger.Fatal().Msgf("Could not create temporary input file: %s\n", err)
 	} else {
-		runner.hostMap = hm
+		runner.inputs = inputs
+	}
+
+	if options.Manifest != "" {
+		manifest, err := newScanManifest(options.Manifest, options)
+		if err != nil {
+			gologger.Fatal().Msgf("Could not create scan manifest: %s\n", err)
+		}
+		runner.manifest = manifest
+	}
+
+	if options.Shard != "" {
+		parsed, err := parseShard(options.Shard)
+		if err != nil {
+			gologger.Fatal().Msgf("Could not parse shard: %s\n", err)
+		}
+		runner.shard = parsed
 	}
 
-	runner.inputCount = 0
 	dupeCount := 0
+	shardSkipped := 0
+
+	addTarget := func(url string) {
+		url = normalizeTarget(url)
+		if url == "" {
+			return
+		}
+		if runner.inputs.Has(url) {
+			dupeCount++
+			return
+		}
+		if runner.shard != nil && !runner.shard.Contains(url) {
+			shardSkipped++
+			return
+		}
+		// nolint:errcheck // ignoring error
+		runner.inputs.Add(url)
+		if runner.manifest != nil {
+			runner.manifest.addTarget(url)
+		}
+	}
 
 	// Handle single target
 	if options.Target != "" {
-		runner.inputCount++
+		addTarget(options.Target)
+	}
+
+	// Handle saved responses directory, kept as is since paths are case sensitive
+	if options.TargetDir != "" {
 		// nolint:errcheck // ignoring error
-		runner.hostMap.Set(options.Target, nil)
+		runner.inputs.Add(options.TargetDir)
 	}
 
 	// Handle stdin
 	if options.Stdin {
 		scanner := bufio.NewScanner(os.Stdin)
 		for scanner.Scan() {
-			url := strings.TrimSpace(scanner.Text())
-			if url == "" {
-				continue
-			}
-			if _, ok := runner.hostMap.Get(url); ok {
-				dupeCount++
-				continue
-			}
-			runner.inputCount++
-			// nolint:errcheck // ignoring error
-			runner.hostMap.Set(url, nil)
+			addTarget(scanner.Text())
 		}
 	}
 
@@ -166,31 +224,34 @@ func New(options *types.Options) (*Runner, error) {
 		}
 		scanner := bufio.NewScanner(input)
 		for scanner.Scan() {
-			url := strings.TrimSpace(scanner.Text())
-			if url == "" {
-				continue
-			}
-			if _, ok := runner.hostMap.Get(url); ok {
-				dupeCount++
-				continue
-			}
-			runner.inputCount++
-			// nolint:errcheck // ignoring error
-			runner.hostMap.Set(url, nil)
+			addTarget(scanner.Text())
 		}
 		input.Close()
+
+		if runner.manifest != nil {
+			if err := runner.manifest.addInputFile(options.Targets); err != nil {
+				gologger.Fatal().Msgf("Could not add targets file to manifest: %s\n", err)
+			}
+		}
 	}
 
 	if dupeCount > 0 {
 		gologger.Info().Msgf("Supplied input was automatically deduplicated (%d removed).", dupeCount)
 	}
+	if runner.shard != nil {
+		gologger.Info().Msgf("Running shard %s with %d targets (%d assigned to other shards).", runner.shard, runner.inputs.Count(), shardSkipped)
+	}
 
 	// Create the output file if asked
-	outputWriter, err := output.NewStandardWriter(!options.NoColor, options.NoMeta, options.JSON, options.Output, options.TraceLogFile)
+	outputWriter, err := output.NewStandardWriter(!options.NoColor, options.NoMeta, options.JSON, options.Output, options.TraceLogFile, time.Duration(options.OutputSyncInterval)*time.Second)
 	if err != nil {
 		gologger.Fatal().Msgf("Could not create output file '%s': %s\n", options.Output, err)
 	}
-	runner.output = outputWriter
+	runner.severities = newSeverityWriter(outputWriter)
+	if err := extractors.SetOutputDirectory(options.ExtractedFilesDir); err != nil {
+		gologger.Fatal().Msgf("Could not setup extractor files: %s\n", err)
+	}
+	runner.output = runner.severities
 
 	// Creates the progress tracking object
 	var progressErr error
@@ -208,6 +269,19 @@ func New(options *types.Options) (*Runner, error) {
 		}
 	}
 
+	runner.resultCap = resultcap.New(options.MaxResultsPerTemplate)
+
+	fingerprintRules := fingerprint.DefaultRules()
+	fingerprintsFile := path.Join(options.TemplatesDirectory, "helpers", "fingerprints", "waf-cdn.yaml")
+	if _, err := os.Stat(fingerprintsFile); err == nil {
+		if fingerprintRules, err = fingerprint.ReadRules(fingerprintsFile); err != nil {
+			gologger.Fatal().Msgf("Could not read fingerprints: %s\n", err)
+		}
+	}
+	if runner.fingerprinter, err = fingerprint.New(fingerprintRules); err != nil {
+		gologger.Fatal().Msgf("Could not compile fingerprints: %s\n", err)
+	}
+
 	if !options.NoInteractsh {
 		interactshClient, err := interactsh.New(&interactsh.Options{
 			ServerURL:      options.InteractshURL,
@@ -218,6 +292,7 @@ func New(options *types.Options) (*Runner, error) {
 			Output:         runner.output,
 			IssuesClient:   runner.issuesClient,
 			Progress:       runner.progress,
+			ResultCap:      runner.resultCap,
 		})
 		if err != nil {
 			gologger.Error().Msgf("Could not create interactsh client: %s", err)
@@ -226,6 +301,18 @@ func New(options *types.Options) (*Runner, error) {
 		}
 	}
 
+	if options.AuthConfig != "" {
+		sessionOptions, err := session.ReadOptions(options.AuthConfig)
+		if err != nil {
+			gologger.Fatal().Msgf("Could not read auth config: %s\n", err)
+		}
+		manager, err := session.New(sessionOptions, options.Timeout)
+		if err != nil {
+			gologger.Fatal().Msgf("Could not create authentication session: %s\n", err)
+		}
+		runner.session = manager
+	}
+
 	if options.RateLimit > 0 {
 		runner.ratelimiter = ratelimit.New(options.RateLimit)
 	} else {
@@ -239,11 +326,39 @@ func (r *Runner) Close() {
 	if r.output != nil {
 		r.output.Close()
 	}
-	r.hostMap.Close()
+	r.inputs.Close()
 	if r.projectFile != nil {
 		r.projectFile.Close()
 	}
 	protocolinit.Close()
+	extractors.CloseFiles()
+	r.cancel()
+}
+
+// handleInterrupt cancels the scan on the first interrupt and exits on
+// the second one. The returned function stops handling interrupts.
+func (r *Runner) handleInterrupt() func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-signals:
+		case <-done:
+			return
+		}
+		gologger.Warning().Msgf("Interrupted, stopping the scan (press Ctrl-C again to exit)\n")
+		r.cancel()
+		select {
+		case <-signals:
+			os.Exit(1)
+		case <-done:
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
 }
 
 // RunEnumeration sets up the input layer for giving input nuclei.
@@ -298,7 +413,7 @@ func (r *Runner) RunEnumeration() {
 		if len(template.Workflows) > 0 {
 			continue
 		}
-		unclusteredRequests += int64(template.TotalRequests) * r.inputCount
+		unclusteredRequests += int64(template.TotalRequests) * r.inputCount(template)
 	}
 
 	originalTemplatesCount := len(availableTemplates)
@@ -307,15 +422,19 @@ func (r *Runner) RunEnumeration() {
 	for _, cluster := range clusters {
 		if len(cluster) > 1 && !r.options.OfflineHTTP {
 			executerOpts := protocols.ExecuterOptions{
-				Output:       r.output,
-				Options:      r.options,
-				Progress:     r.progress,
-				Catalog:      r.catalog,
-				RateLimiter:  r.ratelimiter,
-				IssuesClient: r.issuesClient,
-				Browser:      r.browser,
-				ProjectFile:  r.projectFile,
-				Interactsh:   r.interactsh,
+				Output:        r.output,
+				Options:       r.options,
+				Progress:      r.progress,
+				Catalog:       r.catalog,
+				RateLimiter:   r.ratelimiter,
+				IssuesClient:  r.issuesClient,
+				Browser:       r.browser,
+				ProjectFile:   r.projectFile,
+				Interactsh:    r.interactsh,
+				Session:       r.session,
+				ResultCap:     r.resultCap,
+				Fingerprinter: r.fingerprinter,
+				Context:       r.ctx,
 			}
 			clusterID := fmt.Sprintf("cluster-%s", xid.New().String())
 
@@ -339,7 +458,7 @@ func (r *Runner) RunEnumeration() {
 		if len(t.Workflows) > 0 {
 			continue
 		}
-		totalRequests += int64(t.TotalRequests) * r.inputCount
+		totalRequests += int64(t.TotalRequests) * r.inputCount(t)
 	}
 	if totalRequests < unclusteredRequests {
 		gologger.Info().Msgf("Reduced %d requests to %d (%d templates clustered)", unclusteredRequests, totalRequests, clusterCount)
@@ -351,6 +470,19 @@ func (r *Runner) RunEnumeration() {
 		gologger.Fatal().Msgf("Error, no templates were found.\n")
 	}
 
+	if r.manifest != nil {
+		var templatesVersion string
+		if r.templatesConfig != nil {
+			templatesVersion = r.templatesConfig.CurrentVersion
+		}
+		if err := r.manifest.setTemplates(templatesVersion, availableTemplates, availableWorkflows); err != nil {
+			gologger.Fatal().Msgf("Could not add templates to manifest: %s\n", err)
+		}
+		if err := r.manifest.write(); err != nil {
+			gologger.Fatal().Msgf("Could not write scan manifest: %s\n", err)
+		}
+	}
+
 	gologger.Info().Msgf("Using %s rules (%s templates, %s workflows)",
 		r.colorizer.Bold(templateCount).String(),
 		r.colorizer.Bold(templateCount-workflowCount).String(),
@@ -360,21 +492,31 @@ func (r *Runner) RunEnumeration() {
 	wgtemplates := sizedwaitgroup.New(r.options.TemplateThreads)
 
 	// tracks global progress and captures stdout/stderr until p.Wait finishes
-	r.progress.Init(r.inputCount, templateCount, totalRequests)
+	r.progress.Init(r.inputs.Count(), templateCount, totalRequests)
+	if r.shard != nil {
+		r.progress.SetShard(r.shard.String())
+	}
 
+	stopInterrupt := r.handleInterrupt()
 	for _, t := range finalTemplates {
+		if r.ctx.Err() != nil {
+			break
+		}
 		wgtemplates.Add()
 		go func(template *templates.Template) {
 			defer wgtemplates.Done()
 
 			if len(template.Workflows) > 0 {
 				results.CAS(false, r.processWorkflowWithList(template))
+			} else if template.SelfContained {
+				results.CAS(false, r.processSelfContainedTemplate(template))
 			} else {
 				results.CAS(false, r.processTemplateWithList(template))
 			}
 		}(t)
 	}
 	wgtemplates.Wait()
+	stopInterrupt()
 
 	if r.interactsh != nil {
 		matched := r.interactsh.Close()
@@ -383,6 +525,10 @@ func (r *Runner) RunEnumeration() {
 		}
 	}
 	r.progress.Stop()
+	if r.options.EnableProgressBar || r.options.Verbose {
+		r.logSlowestHosts()
+	}
+	r.logSuppressedResults()
 
 	if r.issuesClient != nil {
 		r.issuesClient.Close()
@@ -390,11 +536,56 @@ func (r *Runner) RunEnumeration() {
 	if !results.Load() {
 		gologger.Info().Msgf("No results found. Better luck next time!")
 	}
+	if r.manifest != nil {
+		matched, suppressed := r.resultCap.Totals()
+		r.manifest.finalize(&manifestSummary{
+			Templates:    templateCount,
+			Requests:     totalRequests,
+			Matches:      matched,
+			Suppressed:   suppressed,
+			ResultsFound: results.Load(),
+		})
+		if err := r.manifest.write(); err != nil {
+			gologger.Error().Msgf("Could not write scan manifest: %s\n", err)
+		}
+	}
 	if r.browser != nil {
 		r.browser.Close()
 	}
 }
 
+// slowestHostsCount is the number of hosts shown in slowest hosts report
+const slowestHostsCount = 10
+
+// logSlowestHosts logs the hosts with the highest p95 request latency
+func (r *Runner) logSlowestHosts() {
+	hosts := r.progress.SlowestHosts(slowestHostsCount)
+	if len(hosts) == 0 {
+		return
+	}
+	gologger.Info().Msgf("Slowest hosts (p95 latency):")
+	for _, host := range hosts {
+		gologger.Info().Msgf("\t%s: %s (%d requests)", host.Host, host.P95.Round(time.Millisecond), host.Requests)
+	}
+}
+
+// logSuppressedResults logs the templates which had results suppressed
+// due to the per-template results cap.
+func (r *Runner) logSuppressedResults() {
+	for _, stats := range r.resultCap.Suppressed() {
+		gologger.Info().Msgf("Template %s: %s matches (%s suppressed)", stats.TemplateID, formatCount(stats.Matched), formatCount(stats.Suppressed))
+	}
+}
+
+// formatCount formats a count with thousands separators
+func formatCount(count int64) string {
+	value := strconv.FormatInt(count, 10)
+	for i := len(value) - 3; i > 0; i -= 3 {
+		value = value[:i] + "," + value[i:]
+	}
+	return value
+}
+
 // readNewTemplatesFile reads newly added templates from directory if it exists
 func (r *Runner) readNewTemplatesFile() ([]string, error) {
 	additionsFile := path.Join(r.templatesConfig.TemplatesDirectory, ".new-additions")
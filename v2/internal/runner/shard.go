@@ -0,0 +1,98 @@
+package runner
+
+import (
+	"hash/fnv"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// shard is a slice of the input targets assigned to a single nuclei instance
+type shard struct {
+	index int // 1-based index of the shard
+	count int // total number of shards
+}
+
+// parseShard parses a shard specification in the index/count format.
+func parseShard(value string) (*shard, error) {
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) != 2 {
+		return nil, errors.Errorf("invalid shard %s (should be index/count, eg. 3/10)", value)
+	}
+	index, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse shard index")
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse shard count")
+	}
+	if count < 1 || index < 1 || index > count {
+		return nil, errors.Errorf("invalid shard %s (index should be between 1 and count)", value)
+	}
+	return &shard{index: index, count: count}, nil
+}
+
+// String returns the shard in index/count format
+func (s *shard) String() string {
+	return strconv.Itoa(s.index) + "/" + strconv.Itoa(s.count)
+}
+
+// Contains returns true if a normalized target is assigned to the shard.
+func (s *shard) Contains(target string) bool {
+	hasher := fnv.New64a()
+	_, _ = hasher.Write([]byte(target))
+	return jumpHash(hasher.Sum64(), s.count) == s.index-1
+}
+
+// jumpHash is the jump consistent hash algorithm by Lamping and Veach
+// which maps a key to one of buckets while moving the minimal number
+// of keys when the number of buckets changes.
+func jumpHash(key uint64, buckets int) int {
+	var b, j int64 = -1, 0
+	for j < int64(buckets) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
+
+// normalizeTarget normalizes a target so that equivalent spellings of
+// the same URL deduplicate to a single value. Only the scheme and host of
+// URLs are normalized, the rest of the target is kept as is.
+func normalizeTarget(target string) string {
+	target = strings.TrimSpace(target)
+	index := strings.Index(target, "://")
+	if index == -1 {
+		return target
+	}
+	parsed, err := url.Parse(target)
+	if err != nil || parsed.Host == "" {
+		return target
+	}
+	scheme := strings.ToLower(target[:index])
+	authority := target[index+3:]
+	suffix := ""
+	if end := strings.IndexAny(authority, "/?#"); end != -1 {
+		authority, suffix = authority[:end], authority[end:]
+	}
+	userinfo := ""
+	if at := strings.LastIndex(authority, "@"); at != -1 {
+		userinfo = authority[:at+1]
+	}
+	host := strings.ToLower(parsed.Hostname())
+	port := parsed.Port()
+	if (scheme == "http" && port == "80") || (scheme == "https" && port == "443") {
+		port = ""
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" {
+		host = host + ":" + port
+	}
+	return scheme + "://" + userinfo + host + suffix
+}
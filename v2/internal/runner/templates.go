@@ -1,8 +1,10 @@
 package runner
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/karrick/godirwalk"
@@ -24,9 +26,17 @@ func (r *Runner) getParsedTemplatesFor(templatePaths, severities []string, workf
 	}
 
 	parsedTemplates = make(map[string]*templates.Template)
+	unmetRequirements := make(map[string][]string)
 	for _, match := range templatePaths {
 		t, err := r.parseTemplateFile(match)
 		if err != nil {
+			var requirementsErr *templates.RequirementsError
+			if errors.As(err, &requirementsErr) {
+				for _, reason := range requirementsErr.Reasons {
+					unmetRequirements[reason] = append(unmetRequirements[reason], match)
+				}
+				continue
+			}
 			gologger.Warning().Msgf("Could not parse file '%s': %s\n", match, err)
 			continue
 		}
@@ -50,21 +60,47 @@ func (r *Runner) getParsedTemplatesFor(templatePaths, severities []string, workf
 			gologger.Warning().Msgf("Excluding template %s due to severity filter (%s not in [%s])", t.ID, sev, severities)
 		}
 	}
+	logUnmetRequirements(unmetRequirements)
 	return parsedTemplates, workflowCount
 }
 
+// logUnmetRequirements logs a consolidated report of the templates
+// skipped due to unmet requirements grouped by the reason.
+func logUnmetRequirements(unmet map[string][]string) {
+	if len(unmet) == 0 {
+		return
+	}
+	reasons := make([]string, 0, len(unmet))
+	for reason := range unmet {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+
+	for _, reason := range reasons {
+		paths := unmet[reason]
+		gologger.Warning().Msgf("Skipped %d templates due to unmet requirement: %s\n", len(paths), reason)
+		for _, path := range paths {
+			gologger.Verbose().Msgf("\t%s\n", path)
+		}
+	}
+}
+
 // parseTemplateFile returns the parsed template file
 func (r *Runner) parseTemplateFile(file string) (*templates.Template, error) {
 	executerOpts := protocols.ExecuterOptions{
-		Output:       r.output,
-		Options:      r.options,
-		Progress:     r.progress,
-		Catalog:      r.catalog,
-		IssuesClient: r.issuesClient,
-		RateLimiter:  r.ratelimiter,
-		Interactsh:   r.interactsh,
-		ProjectFile:  r.projectFile,
-		Browser:      r.browser,
+		Output:        r.output,
+		Options:       r.options,
+		Progress:      r.progress,
+		Catalog:       r.catalog,
+		IssuesClient:  r.issuesClient,
+		RateLimiter:   r.ratelimiter,
+		Interactsh:    r.interactsh,
+		ProjectFile:   r.projectFile,
+		Browser:       r.browser,
+		Session:       r.session,
+		ResultCap:     r.resultCap,
+		Fingerprinter: r.fingerprinter,
+		Context:       r.ctx,
 	}
 	template, err := templates.Parse(file, executerOpts)
 	if err != nil {
@@ -5,6 +5,8 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+
+	"github.com/miekg/dns"
 )
 
 // RunNucleiAndGetResults returns a list of results for a template
@@ -94,3 +96,37 @@ func NewTCPServer(handler func(conn net.Conn)) *TCPServer {
 func (s *TCPServer) Close() {
 	s.listener.Close()
 }
+
+// DNSServer is a dns server answering over udp and tcp
+type DNSServer struct {
+	Address string
+	udp     *dns.Server
+	tcp     *dns.Server
+}
+
+// NewDNSServer creates a new DNS server from a handler listening on an
+// address, a random port is used for the 0 port.
+func NewDNSServer(address string, handler dns.HandlerFunc) *DNSServer {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		panic(err)
+	}
+	conn, err := net.ListenPacket("udp", listener.Addr().String())
+	if err != nil {
+		panic(err)
+	}
+	server := &DNSServer{
+		Address: listener.Addr().String(),
+		udp:     &dns.Server{PacketConn: conn, Handler: handler},
+		tcp:     &dns.Server{Listener: listener, Handler: handler},
+	}
+	go func() { _ = server.udp.ActivateAndServe() }()
+	go func() { _ = server.tcp.ActivateAndServe() }()
+	return server
+}
+
+// Close closes the DNS server
+func (s *DNSServer) Close() {
+	_ = s.udp.Shutdown()
+	_ = s.tcp.Shutdown()
+}
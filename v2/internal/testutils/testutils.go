@@ -61,6 +61,8 @@ var DefaultOptions = &types.Options{
 type MockOutputWriter struct {
 	aurora          aurora.Aurora
 	RequestCallback func(templateID, url, requestType string, err error)
+	ProxyCallback   func(templateID, url, proxy string)
+	DataCallback    func(templateID, url string, sent, received []byte)
 	WriteCallback   func(o *output.ResultEvent)
 }
 
@@ -92,6 +94,22 @@ func (m *MockOutputWriter) Request(templateID, url, requestType string, err erro
 	}
 }
 
+// RequestWithProxy writes a log of a request sent through a proxy to the trace log
+func (m *MockOutputWriter) RequestWithProxy(templateID, url, requestType, proxy string, err error) {
+	m.Request(templateID, url, requestType, err)
+	if m.ProxyCallback != nil {
+		m.ProxyCallback(templateID, url, proxy)
+	}
+}
+
+// RequestWithData writes a log of a request with the bytes sent and received to the trace log
+func (m *MockOutputWriter) RequestWithData(templateID, url, requestType string, sent, received []byte, err error) {
+	m.Request(templateID, url, requestType, err)
+	if m.DataCallback != nil {
+		m.DataCallback(templateID, url, sent, received)
+	}
+}
+
 // TemplateInfo contains info for a mock executed template.
 type TemplateInfo struct {
 	ID   string
@@ -2,17 +2,20 @@ package dsl
 
 import (
 	"bytes"
+	"crypto/hmac"
 	"crypto/md5"
 	"crypto/sha1"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"html"
 	"math"
 	"math/rand"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -96,6 +99,9 @@ func HelperFunctions() map[string]govaluate.ExpressionFunction {
 	// python encodes to base64 with lines of 76 bytes terminated by new line "\n"
 	functions["base64_py"] = func(args ...interface{}) (interface{}, error) {
 		sEnc := base64.StdEncoding.EncodeToString([]byte(types.ToString(args[0])))
+		if sEnc == "" {
+			return "", nil
+		}
 		return insertInto(sEnc, 76, '\n'), nil
 	}
 
@@ -155,10 +161,45 @@ func HelperFunctions() map[string]govaluate.ExpressionFunction {
 		return hex.EncodeToString(h.Sum(nil)), nil
 	}
 
+	functions["hmac_sha256"] = func(args ...interface{}) (interface{}, error) {
+		h := hmac.New(sha256.New, []byte(types.ToString(args[0])))
+		_, err := h.Write([]byte(types.ToString(args[1])))
+
+		if err != nil {
+			return nil, err
+		}
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+
 	functions["mmh3"] = func(args ...interface{}) (interface{}, error) {
 		return fmt.Sprintf("%d", int32(murmur3.Sum32WithSeed([]byte(types.ToString(args[0])), 0))), nil
 	}
 
+	// jwt_decode returns the decoded header or payload of a jwt, or the
+	// encoded signature.
+	functions["jwt_decode"] = func(args ...interface{}) (interface{}, error) {
+		parts := strings.Split(types.ToString(args[1]), ".")
+		if len(parts) != 3 {
+			return nil, errors.New("invalid jwt token")
+		}
+		switch part := types.ToString(args[0]); part {
+		case "header", "payload":
+			index := 0
+			if part == "payload" {
+				index = 1
+			}
+			decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[index], "="))
+			if err != nil {
+				return nil, fmt.Errorf("could not decode jwt %s: %s", part, err)
+			}
+			return string(decoded), nil
+		case "signature":
+			return parts[2], nil
+		default:
+			return nil, fmt.Errorf("invalid jwt part %s", part)
+		}
+	}
+
 	// search
 	functions["contains"] = func(args ...interface{}) (interface{}, error) {
 		return strings.Contains(types.ToString(args[0]), types.ToString(args[1])), nil
@@ -192,7 +233,7 @@ func HelperFunctions() map[string]govaluate.ExpressionFunction {
 		base := letters + numbers
 
 		if len(args) >= 1 {
-			l = args[0].(int)
+			l = toInt(args[0])
 		}
 		if len(args) >= withCutSetArgsSize {
 			bad = types.ToString(args[1])
@@ -210,7 +251,7 @@ func HelperFunctions() map[string]govaluate.ExpressionFunction {
 		chars := letters + numbers
 
 		if len(args) >= 1 {
-			l = args[0].(int)
+			l = toInt(args[0])
 		}
 		if len(args) >= withCutSetArgsSize {
 			bad = types.ToString(args[1])
@@ -225,7 +266,7 @@ func HelperFunctions() map[string]govaluate.ExpressionFunction {
 		chars := letters
 
 		if len(args) >= 1 {
-			l = args[0].(int)
+			l = toInt(args[0])
 		}
 		if len(args) >= withCutSetArgsSize {
 			bad = types.ToString(args[1])
@@ -240,7 +281,7 @@ func HelperFunctions() map[string]govaluate.ExpressionFunction {
 		chars := numbers
 
 		if len(args) >= 1 {
-			l = args[0].(int)
+			l = toInt(args[0])
 		}
 		if len(args) >= withCutSetArgsSize {
 			bad = types.ToString(args[1])
@@ -254,10 +295,13 @@ func HelperFunctions() map[string]govaluate.ExpressionFunction {
 		max := math.MaxInt32
 
 		if len(args) >= 1 {
-			min = args[0].(int)
+			min = toInt(args[0])
 		}
 		if len(args) >= withMaxRandArgsSize {
-			max = args[1].(int)
+			max = toInt(args[1])
+		}
+		if max <= min {
+			return min, nil
 		}
 		return rand.Intn(max-min) + min, nil
 	}
@@ -268,9 +312,39 @@ func HelperFunctions() map[string]govaluate.ExpressionFunction {
 		time.Sleep(time.Duration(seconds) * time.Second)
 		return true, nil
 	}
+
+	// Certificate Functions
+	functions["cert_expired"] = func(args ...interface{}) (interface{}, error) {
+		notAfter, err := parseTime(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return time.Now().After(notAfter), nil
+	}
 	return functions
 }
 
+// parseTime parses a RFC3339 date or an unix timestamp in seconds
+func parseTime(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case float64:
+		return time.Unix(int64(v), 0), nil
+	case int64:
+		return time.Unix(v, 0), nil
+	case int:
+		return time.Unix(int64(v), 0), nil
+	}
+	str := types.ToString(value)
+	if seconds, err := strconv.ParseInt(str, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	parsed, err := time.Parse(time.RFC3339, str)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not parse time %s", str)
+	}
+	return parsed, nil
+}
+
 func reverseString(s string) string {
 	runes := []rune(s)
 	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
@@ -286,6 +360,25 @@ func trimAll(s, cutset string) string {
 	return s
 }
 
+// RandomAlphanumeric returns a random alphanumeric string of length n
+func RandomAlphanumeric(n int) string {
+	return randSeq(letters+numbers, n)
+}
+
+// toInt converts a numeric helper argument to an int, expression
+// numbers being evaluated as float64.
+func toInt(value interface{}) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	default:
+		i, _ := strconv.Atoi(types.ToString(v))
+		return i
+	}
+}
+
 func randSeq(base string, n int) string {
 	b := make([]rune, n)
 	for i := range b {
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+
+	"github.com/antchfx/xpath"
 )
 
 // CompileExtractors performs the initial setup operation on a extractor
@@ -15,6 +17,12 @@ func (e *Extractor) CompileExtractors() error {
 		return fmt.Errorf("unknown extractor type specified: %s", e.Type)
 	}
 
+	if e.ToFile != "" {
+		if err := validateToFile(e.ToFile); err != nil {
+			return err
+		}
+	}
+
 	// Compile the regexes
 	for _, regex := range e.Regex {
 		compiled, err := regexp.Compile(regex)
@@ -24,6 +32,20 @@ func (e *Extractor) CompileExtractors() error {
 		e.regexCompiled = append(e.regexCompiled, compiled)
 	}
 
+	// Compile the xpath queries
+	for _, query := range e.XPath {
+		compiled, err := xpath.Compile(query)
+		if err != nil {
+			return fmt.Errorf("could not compile xpath: %s", query)
+		}
+		e.xpathCompiled = append(e.xpathCompiled, compiled)
+	}
+
+	// Convert the jq style paths to gjson ones
+	for _, path := range e.JSON {
+		e.jsonPaths = append(e.jsonPaths, toGJSONPath(path))
+	}
+
 	for i, kval := range e.KVal {
 		e.KVal[i] = strings.ToLower(kval)
 	}
@@ -32,5 +54,34 @@ func (e *Extractor) CompileExtractors() error {
 	if e.Part == "" {
 		e.Part = "body"
 	}
+
+	// Setup the extractors of the named groups
+	for _, compiled := range e.regexCompiled {
+		for group, name := range compiled.SubexpNames() {
+			if name == "" {
+				continue
+			}
+			e.namedGroups = append(e.namedGroups, &Extractor{
+				Name:          name,
+				Type:          e.Type,
+				extractorType: RegexExtractor,
+				RegexGroup:    group,
+				regexCompiled: []*regexp.Regexp{compiled},
+				Part:          e.Part,
+				Internal:      true,
+			})
+		}
+	}
 	return nil
 }
+
+// jqIndexRegex matches the array indexes of a jq style path
+var jqIndexRegex = regexp.MustCompile(`\[([0-9]+)\]`)
+
+// toGJSONPath converts a jq style path like .data.items[].token or
+// .items[0] to its gjson equivalent, gjson paths are kept as is.
+func toGJSONPath(path string) string {
+	path = strings.TrimPrefix(path, ".")
+	path = strings.ReplaceAll(path, "[]", ".#")
+	return jqIndexRegex.ReplaceAllString(path, ".$1")
+}
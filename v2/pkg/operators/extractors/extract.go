@@ -1,6 +1,11 @@
 package extractors
 
 import (
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xmlquery"
+	"github.com/tidwall/gjson"
 	"github.com/yaklang/nuclei/v2/pkg/types"
 )
 
@@ -33,7 +38,9 @@ func (e *Extractor) ExtractKval(data map[string]interface{}) map[string]struct{}
 	for _, k := range e.KVal {
 		item, ok := data[k]
 		if !ok {
-			continue
+			if item, ok = lookupKval(data, k); !ok {
+				continue
+			}
 		}
 		itemString := types.ToString(item)
 		if _, ok := results[itemString]; !ok {
@@ -42,3 +49,112 @@ func (e *Extractor) ExtractKval(data map[string]interface{}) map[string]struct{}
 	}
 	return results
 }
+
+// lookupKval looks up a key in a data map ignoring its case and the
+// differences between dashes and underscores of header and cookie names.
+func lookupKval(data map[string]interface{}, key string) (interface{}, bool) {
+	key = normalizeKval(key)
+	for k, v := range data {
+		if normalizeKval(k) == key {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func normalizeKval(key string) string {
+	return strings.ToLower(strings.ReplaceAll(key, "-", "_"))
+}
+
+// ExtractXPath extracts the text or the attribute of the nodes selected
+// by the xpath queries from a html corpus, or a xml one if xml is true.
+func (e *Extractor) ExtractXPath(corpus string, xml bool) map[string]struct{} {
+	if xml || e.XML {
+		return e.extractXMLPath(corpus)
+	}
+	results := make(map[string]struct{})
+
+	document, err := htmlquery.Parse(strings.NewReader(corpus))
+	if err != nil {
+		return results
+	}
+	for _, expr := range e.xpathCompiled {
+		for _, node := range htmlquery.QuerySelectorAll(document, expr) {
+			var value string
+			if e.Attribute != "" {
+				value = htmlquery.SelectAttr(node, e.Attribute)
+			} else {
+				value = htmlquery.InnerText(node)
+			}
+			if value == "" {
+				continue
+			}
+			if _, ok := results[value]; !ok {
+				results[value] = struct{}{}
+			}
+		}
+	}
+	return results
+}
+
+// extractXMLPath extracts the nodes selected by the xpath queries from a xml corpus
+func (e *Extractor) extractXMLPath(corpus string) map[string]struct{} {
+	results := make(map[string]struct{})
+
+	document, err := xmlquery.Parse(strings.NewReader(corpus))
+	if err != nil {
+		return results
+	}
+	for _, expr := range e.xpathCompiled {
+		for _, node := range xmlquery.QuerySelectorAll(document, expr) {
+			var value string
+			if e.Attribute != "" {
+				value = node.SelectAttr(e.Attribute)
+			} else {
+				value = node.InnerText()
+			}
+			if value == "" {
+				continue
+			}
+			if _, ok := results[value]; !ok {
+				results[value] = struct{}{}
+			}
+		}
+	}
+	return results
+}
+
+// ExtractJSON extracts the values of the paths from a json corpus. Arrays
+// are flattened into their values, and objects are returned as json.
+func (e *Extractor) ExtractJSON(corpus string) map[string]struct{} {
+	results := make(map[string]struct{})
+
+	if !gjson.Valid(corpus) {
+		return results
+	}
+	for _, path := range e.jsonPaths {
+		addJSONResults(results, gjson.Get(corpus, path))
+	}
+	return results
+}
+
+// addJSONResults adds a json value to the results flattening the arrays
+func addJSONResults(results map[string]struct{}, value gjson.Result) {
+	switch {
+	case !value.Exists() || value.Type == gjson.Null:
+		return
+	case value.IsArray():
+		value.ForEach(func(_, item gjson.Result) bool {
+			addJSONResults(results, item)
+			return true
+		})
+		return
+	}
+	result := value.String()
+	if value.IsObject() {
+		result = value.Raw
+	}
+	if _, ok := results[result]; !ok {
+		results[result] = struct{}{}
+	}
+}
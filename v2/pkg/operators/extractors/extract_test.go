@@ -0,0 +1,125 @@
+package extractors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const exampleHTML = `<html><head><title>Test Page</title></head>
+<body>
+<a href="/login">Login</a>
+<a href="/admin">Admin</a>
+<a href="/login">Login again</a>
+<p class="version">v1.2.3</p>
+<p>unclosed
+</body>`
+
+func TestExtractXPath(t *testing.T) {
+	t.Run("attribute", func(t *testing.T) {
+		extractor := &Extractor{Type: "xpath", XPath: []string{"//a"}, Attribute: "href"}
+		err := extractor.CompileExtractors()
+		require.Nil(t, err, "could not compile xpath extractor")
+
+		data := extractor.ExtractXPath(exampleHTML, false)
+		require.Equal(t, map[string]struct{}{"/login": {}, "/admin": {}}, data, "could not extract href attributes")
+	})
+
+	t.Run("text", func(t *testing.T) {
+		extractor := &Extractor{Type: "xpath", XPath: []string{"//title", "//p[@class='version']/text()"}}
+		err := extractor.CompileExtractors()
+		require.Nil(t, err, "could not compile xpath extractor")
+
+		data := extractor.ExtractXPath(exampleHTML, false)
+		require.Equal(t, map[string]struct{}{"Test Page": {}, "v1.2.3": {}}, data, "could not extract text nodes")
+	})
+
+	t.Run("xml", func(t *testing.T) {
+		sitemap := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+<url><loc>http://example.com/</loc></url>
+<url><loc>http://example.com/about</loc></url>
+</urlset>`
+		extractor := &Extractor{Type: "xpath", XPath: []string{"//url/loc"}}
+		err := extractor.CompileExtractors()
+		require.Nil(t, err, "could not compile xpath extractor")
+
+		data := extractor.ExtractXPath(sitemap, true)
+		require.Equal(t, map[string]struct{}{"http://example.com/": {}, "http://example.com/about": {}}, data, "could not extract xml nodes")
+
+		extractor.XML = true
+		data = extractor.ExtractXPath(sitemap, false)
+		require.Len(t, data, 2, "could not extract xml nodes with xml flag")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		extractor := &Extractor{Type: "xpath", XPath: []string{"//a["}}
+		err := extractor.CompileExtractors()
+		require.NotNil(t, err, "could compile invalid xpath")
+	})
+}
+
+func TestIsXMLContentType(t *testing.T) {
+	require.True(t, IsXMLContentType("application/soap+xml; charset=utf-8"), "could not detect xml content type")
+	require.True(t, IsXMLContentType("text/xml"), "could not detect xml content type")
+	require.False(t, IsXMLContentType("application/xhtml+xml"), "could detect xhtml as xml")
+	require.False(t, IsXMLContentType("text/html"), "could detect html as xml")
+}
+
+func TestExtractJSON(t *testing.T) {
+	corpus := `{"data":{"items":[{"token":"a1","id":1},{"token":"b2","id":2,"admin":true}],"matrix":[[1,2],[3]],"owner":{"name":"test"}}}`
+
+	tests := []struct {
+		name     string
+		paths    []string
+		expected map[string]struct{}
+	}{
+		{"gjson", []string{"data.items.#.token"}, map[string]struct{}{"a1": {}, "b2": {}}},
+		{"jq", []string{".data.items[].token"}, map[string]struct{}{"a1": {}, "b2": {}}},
+		{"index", []string{".data.items[1].id"}, map[string]struct{}{"2": {}}},
+		{"bool", []string{"data.items.#.admin"}, map[string]struct{}{"true": {}}},
+		{"nested", []string{"data.matrix"}, map[string]struct{}{"1": {}, "2": {}, "3": {}}},
+		{"object", []string{"data.owner"}, map[string]struct{}{`{"name":"test"}`: {}}},
+		{"missing", []string{"data.missing"}, map[string]struct{}{}},
+	}
+	for _, test := range tests {
+		extractor := &Extractor{Type: "json", JSON: test.paths}
+		err := extractor.CompileExtractors()
+		require.Nil(t, err, "could not compile json extractor")
+
+		data := extractor.ExtractJSON(corpus)
+		require.Equal(t, test.expected, data, "could not extract correct %s json data", test.name)
+	}
+
+	extractor := &Extractor{Type: "json", JSON: []string{"data"}}
+	err := extractor.CompileExtractors()
+	require.Nil(t, err, "could not compile json extractor")
+	require.Empty(t, extractor.ExtractJSON(`{"data": `), "could extract malformed json")
+}
+
+func TestExtractKval(t *testing.T) {
+	extractor := &Extractor{Type: "kval", KVal: []string{"Content-Security-Policy", "csrf_token", "X_Request_ID", "missing"}}
+	err := extractor.CompileExtractors()
+	require.Nil(t, err, "could not compile kval extractor")
+
+	data := extractor.ExtractKval(map[string]interface{}{
+		"content_security_policy": "default-src 'self'",
+		"csrf-token":              "abc123",
+		"x_request_id":            1337,
+	})
+	require.Equal(t, map[string]struct{}{"default-src 'self'": {}, "abc123": {}, "1337": {}}, data, "could not extract normalized kval data")
+}
+
+func TestExtractRegexGroups(t *testing.T) {
+	extractor := &Extractor{Type: "regex", RegexGroup: 1, Regex: []string{`token=([a-z]+)&id=(?P<id>[0-9]+)`}}
+	err := extractor.CompileExtractors()
+	require.Nil(t, err, "could not compile regex extractor")
+
+	corpus := "token=abc&id=1 token=def&id=2"
+	require.Equal(t, map[string]struct{}{"abc": {}, "def": {}}, extractor.ExtractRegex(corpus), "could not extract regex group")
+
+	groups := extractor.NamedGroups()
+	require.Len(t, groups, 1, "could not get named groups")
+	require.Equal(t, "id", groups[0].Name, "could not get named group name")
+	require.Equal(t, map[string]struct{}{"1": {}, "2": {}}, groups[0].ExtractRegex(corpus), "could not extract named group")
+}
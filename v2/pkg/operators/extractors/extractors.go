@@ -1,6 +1,11 @@
 package extractors
 
-import "regexp"
+import (
+	"regexp"
+	"strings"
+
+	"github.com/antchfx/xpath"
+)
 
 // Extractor is used to extract part of response using a regex.
 type Extractor struct {
@@ -17,16 +22,38 @@ type Extractor struct {
 	RegexGroup int `yaml:"group"`
 	// regexCompiled is the compiled variant
 	regexCompiled []*regexp.Regexp
+	// namedGroups extract the named groups of the regexes
+	namedGroups []*Extractor
 
 	// KVal are the kval to be present in the response headers/cookies
 	KVal []string `yaml:"kval,omitempty"`
 
+	// XPath are the xpath queries selecting the nodes to extract
+	XPath []string `yaml:"xpath,omitempty"`
+	// Attribute is an optional attribute of the nodes to extract instead
+	// of their text.
+	Attribute string `yaml:"attribute,omitempty"`
+	// XML parses the part as xml instead of html. It is detected from
+	// the content type of http responses.
+	XML bool `yaml:"xml,omitempty"`
+	// xpathCompiled is the compiled variant
+	xpathCompiled []*xpath.Expr
+
+	// JSON are the gjson or jq style paths to extract from json responses
+	JSON []string `yaml:"json,omitempty"`
+	// jsonPaths are the gjson variants of the paths
+	jsonPaths []string
+
 	// Part is the part of the request to match
 	//
 	// By default, matching is performed in request body.
 	Part string `yaml:"part,omitempty"`
 	// Internal defines if this is used internally
 	Internal bool `yaml:"internal,omitempty"`
+	// ToFile is an optional file the extracted values are appended to,
+	// one value per line and each value only once during the run. It is
+	// relative to the extracted files directory, and ignored if not set.
+	ToFile string `yaml:"to-file,omitempty"`
 }
 
 // ExtractorType is the type of the extractor specified
@@ -37,15 +64,34 @@ const (
 	RegexExtractor ExtractorType = iota + 1
 	// KValExtractor extracts responses with key:value
 	KValExtractor
+	// XPathExtractor extracts responses with xpath queries
+	XPathExtractor
+	// JSONExtractor extracts responses with gjson paths
+	JSONExtractor
 )
 
 // ExtractorTypes is an table for conversion of extractor type from string.
 var ExtractorTypes = map[string]ExtractorType{
 	"regex": RegexExtractor,
 	"kval":  KValExtractor,
+	"xpath": XPathExtractor,
+	"json":  JSONExtractor,
 }
 
 // GetType returns the type of the matcher
 func (e *Extractor) GetType() ExtractorType {
 	return e.extractorType
 }
+
+// NamedGroups returns the extractors of the named groups of the regexes,
+// each one named after its group.
+func (e *Extractor) NamedGroups() []*Extractor {
+	return e.namedGroups
+}
+
+// IsXMLContentType returns true if a content type is a xml one, html
+// documents like xhtml are still parsed with the html parser.
+func IsXMLContentType(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	return strings.Contains(contentType, "xml") && !strings.Contains(contentType, "html")
+}
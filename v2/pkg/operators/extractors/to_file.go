@@ -0,0 +1,104 @@
+package extractors
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// valuesFile is a file extracted values are appended to
+type valuesFile struct {
+	mutex sync.Mutex
+	file  *os.File
+	seen  map[string]struct{}
+}
+
+var (
+	valuesFilesMutex sync.Mutex
+	valuesFiles      = make(map[string]*valuesFile)
+	outputDirectory  string
+)
+
+// SetOutputDirectory sets the directory the to-file paths of the
+// extractors are resolved under, creating it. The values are not
+// written to files if no directory is set.
+func SetOutputDirectory(directory string) error {
+	if directory != "" {
+		if err := os.MkdirAll(directory, 0755); err != nil {
+			return fmt.Errorf("could not create extractor files directory %s: %s", directory, err)
+		}
+	}
+	valuesFilesMutex.Lock()
+	outputDirectory = directory
+	valuesFilesMutex.Unlock()
+	return nil
+}
+
+// validateToFile checks that a to-file path stays inside the output directory
+func validateToFile(path string) error {
+	if filepath.IsAbs(path) || filepath.VolumeName(path) != "" || strings.HasPrefix(path, "/") || strings.HasPrefix(path, "\\") {
+		return fmt.Errorf("absolute to-file path not allowed: %s", path)
+	}
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if part == ".." {
+			return fmt.Errorf("to-file path outside the output directory not allowed: %s", path)
+		}
+	}
+	return nil
+}
+
+// SaveToFile appends the values to the to-file of the extractor, skipping
+// the ones already written to it during the run. The path is relative to
+// the output directory, absolute paths and paths containing .. are rejected.
+// Nothing is written if no output directory is set.
+func (e *Extractor) SaveToFile(values []string) error {
+	if e.ToFile == "" || len(values) == 0 {
+		return nil
+	}
+	if err := validateToFile(e.ToFile); err != nil {
+		return err
+	}
+	valuesFilesMutex.Lock()
+	if outputDirectory == "" {
+		valuesFilesMutex.Unlock()
+		return nil
+	}
+	output, ok := valuesFiles[e.ToFile]
+	if !ok {
+		file, err := os.OpenFile(filepath.Join(outputDirectory, e.ToFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			valuesFilesMutex.Unlock()
+			return fmt.Errorf("could not open extractor file %s: %s", e.ToFile, err)
+		}
+		output = &valuesFile{file: file, seen: make(map[string]struct{})}
+		valuesFiles[e.ToFile] = output
+	}
+	valuesFilesMutex.Unlock()
+
+	output.mutex.Lock()
+	defer output.mutex.Unlock()
+
+	for _, value := range values {
+		if _, ok := output.seen[value]; ok {
+			continue
+		}
+		if _, err := output.file.WriteString(value + "\n"); err != nil {
+			return fmt.Errorf("could not write to extractor file %s: %s", e.ToFile, err)
+		}
+		output.seen[value] = struct{}{}
+	}
+	return nil
+}
+
+// CloseFiles closes the files the extracted values were written to.
+func CloseFiles() {
+	valuesFilesMutex.Lock()
+	defer valuesFilesMutex.Unlock()
+
+	for path, output := range valuesFiles {
+		output.file.Close()
+		delete(valuesFiles, path)
+	}
+}
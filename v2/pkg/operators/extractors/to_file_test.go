@@ -0,0 +1,55 @@
+package extractors
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveToFile(t *testing.T) {
+	directory, err := ioutil.TempDir("", "extractors-test-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(directory)
+	defer CloseFiles()
+
+	require.Nil(t, SetOutputDirectory(filepath.Join(directory, "extracted")), "could not set output directory")
+	defer SetOutputDirectory("")
+
+	first := &Extractor{Type: "regex", ToFile: "values.txt"}
+	second := &Extractor{Type: "kval", ToFile: "values.txt"}
+
+	require.Nil(t, first.SaveToFile([]string{"a", "b"}), "could not save values")
+	require.Nil(t, second.SaveToFile([]string{"b", "c"}), "could not save values")
+	require.Nil(t, first.SaveToFile([]string{"a"}), "could not save values")
+
+	data, err := ioutil.ReadFile(filepath.Join(directory, "extracted", "values.txt"))
+	require.Nil(t, err, "could not read values file")
+	require.Equal(t, "a\nb\nc\n", string(data), "could not get deduplicated values")
+}
+
+func TestSaveToFileOutsideDirectory(t *testing.T) {
+	for _, path := range []string{"/tmp/values.txt", "../values.txt", "values/../../values.txt"} {
+		extractor := &Extractor{Type: "regex", Regex: []string{"a"}, ToFile: path}
+		require.NotNil(t, extractor.SaveToFile([]string{"a"}), "could save values to %s", path)
+		require.NotNil(t, extractor.CompileExtractors(), "could compile extractor with %s", path)
+	}
+}
+
+func TestSaveToFileDisabled(t *testing.T) {
+	directory, err := ioutil.TempDir("", "extractors-test-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(directory)
+
+	current, err := os.Getwd()
+	require.Nil(t, err, "could not get working directory")
+	require.Nil(t, os.Chdir(directory), "could not change working directory")
+	defer os.Chdir(current)
+
+	extractor := &Extractor{Type: "regex", ToFile: ".bashrc"}
+	require.Nil(t, extractor.SaveToFile([]string{"a"}), "could not skip values")
+	_, err = os.Stat(filepath.Join(directory, ".bashrc"))
+	require.True(t, os.IsNotExist(err), "could write values without output directory")
+}
@@ -41,6 +41,15 @@ func (m *Matcher) CompileMatchers() error {
 		m.regexCompiled = append(m.regexCompiled, compiled)
 	}
 
+	// Decode the binary values
+	for _, value := range m.Binary {
+		decoded, err := hex.DecodeString(value)
+		if err != nil || len(decoded) == 0 {
+			return fmt.Errorf("could not decode binary: %s", value)
+		}
+		m.binaryDecoded = append(m.binaryDecoded, string(decoded))
+	}
+
 	// Compile the dsl expressions
 	for _, expr := range m.DSL {
 		compiled, err := govaluate.NewEvaluableExpressionWithFunctions(expr, dsl.HelperFunctions())
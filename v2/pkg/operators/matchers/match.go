@@ -1,7 +1,6 @@
 package matchers
 
 import (
-	"encoding/hex"
 	"strings"
 )
 
@@ -37,8 +36,10 @@ func (m *Matcher) MatchSize(length int) bool {
 	return false
 }
 
-// MatchWords matches a word check against a corpus.
-func (m *Matcher) MatchWords(corpus string) bool {
+// MatchWords matches a word check against a corpus and returns the
+// matched words.
+func (m *Matcher) MatchWords(corpus string) (bool, []string) {
+	var matchedWords []string
 	// Iterate over all the words accepted as valid
 	for i, word := range m.Words {
 		// Continue if the word doesn't match
@@ -46,7 +47,7 @@ func (m *Matcher) MatchWords(corpus string) bool {
 			// If we are in an AND request and a match failed,
 			// return false as the AND condition fails on any single mismatch.
 			if m.condition == ANDCondition {
-				return false
+				return false, nil
 			}
 			// Continue with the flow since its an OR Condition.
 			continue
@@ -54,27 +55,31 @@ func (m *Matcher) MatchWords(corpus string) bool {
 
 		// If the condition was an OR, return on the first match.
 		if m.condition == ORCondition {
-			return true
+			return true, []string{word}
 		}
+		matchedWords = append(matchedWords, word)
 
 		// If we are at the end of the words, return with true
 		if len(m.Words)-1 == i {
-			return true
+			return true, matchedWords
 		}
 	}
-	return false
+	return false, nil
 }
 
-// MatchRegex matches a regex check against a corpus
-func (m *Matcher) MatchRegex(corpus string) bool {
+// MatchRegex matches a regex check against a corpus and returns the
+// matched text.
+func (m *Matcher) MatchRegex(corpus string) (bool, []string) {
+	var matchedRegexes []string
 	// Iterate over all the regexes accepted as valid
 	for i, regex := range m.regexCompiled {
 		// Continue if the regex doesn't match
-		if !regex.MatchString(corpus) {
+		currentMatches := regex.FindAllString(corpus, -1)
+		if len(currentMatches) == 0 {
 			// If we are in an AND request and a match failed,
 			// return false as the AND condition fails on any single mismatch.
 			if m.condition == ANDCondition {
-				return false
+				return false, nil
 			}
 			// Continue with the flow since its an OR Condition.
 			continue
@@ -82,28 +87,31 @@ func (m *Matcher) MatchRegex(corpus string) bool {
 
 		// If the condition was an OR, return on the first match.
 		if m.condition == ORCondition {
-			return true
+			return true, currentMatches
 		}
+		matchedRegexes = append(matchedRegexes, currentMatches...)
 
 		// If we are at the end of the regex, return with true
 		if len(m.regexCompiled)-1 == i {
-			return true
+			return true, matchedRegexes
 		}
 	}
-	return false
+	return false, nil
 }
 
-// MatchBinary matches a binary check against a corpus
-func (m *Matcher) MatchBinary(corpus string) bool {
-	// Iterate over all the words accepted as valid
-	for i, binary := range m.Binary {
-		// Continue if the word doesn't match
-		hexa, _ := hex.DecodeString(binary)
-		if !strings.Contains(corpus, string(hexa)) {
+// MatchBinary matches a binary check against a corpus and returns the
+// matched hex values.
+func (m *Matcher) MatchBinary(corpus string) (bool, []string) {
+	var matchedBinary []string
+	// Iterate over all the decoded binary values accepted as valid.
+	// The corpus is compared byte by byte, invalid utf-8 included.
+	for i, decoded := range m.binaryDecoded {
+		// Continue if the bytes don't match
+		if !strings.Contains(corpus, decoded) {
 			// If we are in an AND request and a match failed,
 			// return false as the AND condition fails on any single mismatch.
 			if m.condition == ANDCondition {
-				return false
+				return false, nil
 			}
 			// Continue with the flow since its an OR Condition.
 			continue
@@ -111,15 +119,16 @@ func (m *Matcher) MatchBinary(corpus string) bool {
 
 		// If the condition was an OR, return on the first match.
 		if m.condition == ORCondition {
-			return true
+			return true, []string{m.Binary[i]}
 		}
+		matchedBinary = append(matchedBinary, m.Binary[i])
 
-		// If we are at the end of the words, return with true
-		if len(m.Binary)-1 == i {
-			return true
+		// If we are at the end of the binary values, return with true
+		if len(m.binaryDecoded)-1 == i {
+			return true, matchedBinary
 		}
 	}
-	return false
+	return false, nil
 }
 
 // MatchDSL matches on a generic map result
@@ -128,6 +137,11 @@ func (m *Matcher) MatchDSL(data map[string]interface{}) bool {
 	for i, expression := range m.dslCompiled {
 		result, err := expression.Evaluate(data)
 		if err != nil {
+			// Expressions using missing values, like the ones of a failed
+			// request, never match. Result inverts negative matchers later.
+			if m.Negative {
+				return true
+			}
 			continue
 		}
 
@@ -9,23 +9,23 @@ import (
 func TestANDCondition(t *testing.T) {
 	m := &Matcher{condition: ANDCondition, Words: []string{"a", "b"}}
 
-	matched := m.MatchWords("a b")
+	matched, _ := m.MatchWords("a b")
 	require.True(t, matched, "Could not match valid AND condition")
 
-	matched = m.MatchWords("b")
+	matched, _ = m.MatchWords("b")
 	require.False(t, matched, "Could match invalid AND condition")
 }
 
 func TestORCondition(t *testing.T) {
 	m := &Matcher{condition: ORCondition, Words: []string{"a", "b"}}
 
-	matched := m.MatchWords("a b")
+	matched, _ := m.MatchWords("a b")
 	require.True(t, matched, "Could not match valid OR condition")
 
-	matched = m.MatchWords("b")
+	matched, _ = m.MatchWords("b")
 	require.True(t, matched, "Could not match valid OR condition")
 
-	matched = m.MatchWords("c")
+	matched, _ = m.MatchWords("c")
 	require.False(t, matched, "Could match invalid OR condition")
 }
 
@@ -34,6 +34,74 @@ func TestHexEncoding(t *testing.T) {
 	err := m.CompileMatchers()
 	require.Nil(t, err, "could not compile matcher")
 
-	matched := m.MatchWords("PING")
+	matched, _ := m.MatchWords("PING")
 	require.True(t, matched, "Could not match valid Hex condition")
 }
+
+func TestDSLHelperFunctions(t *testing.T) {
+	m := &Matcher{Type: "dsl", Condition: "and", DSL: []string{"sha256(body) == '9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08'", "contains(jwt_decode('payload', token), 'admin')"}}
+	err := m.CompileMatchers()
+	require.Nil(t, err, "could not compile matcher")
+
+	data := map[string]interface{}{"body": "test", "token": "eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiJhZG1pbiJ9.c2ln"}
+	require.True(t, m.MatchDSL(data), "could not match dsl helper functions")
+
+	data["body"] = "other"
+	require.False(t, m.MatchDSL(data), "could match invalid dsl helper functions")
+}
+
+func TestNegativeMatcher(t *testing.T) {
+	t.Run("word", func(t *testing.T) {
+		m := &Matcher{Type: "word", Negative: true, Words: []string{"login"}}
+		err := m.CompileMatchers()
+		require.Nil(t, err, "could not compile matcher")
+
+		matched, snippets := m.ResultWithMatchedSnippet(m.MatchWords("welcome admin"))
+		require.True(t, matched, "could not match valid negative word matcher")
+		require.Empty(t, snippets, "could get snippets for negative word matcher")
+
+		matched, _ = m.ResultWithMatchedSnippet(m.MatchWords("please login"))
+		require.False(t, matched, "could match invalid negative word matcher")
+	})
+
+	t.Run("dsl", func(t *testing.T) {
+		m := &Matcher{Type: "dsl", Negative: true, DSL: []string{"status_code == 200"}}
+		err := m.CompileMatchers()
+		require.Nil(t, err, "could not compile matcher")
+
+		require.True(t, m.Result(m.MatchDSL(map[string]interface{}{"status_code": 404})), "could not match valid negative dsl matcher")
+		require.False(t, m.Result(m.MatchDSL(map[string]interface{}{"status_code": 200})), "could match invalid negative dsl matcher")
+		require.False(t, m.Result(m.MatchDSL(map[string]interface{}{})), "could match negative dsl matcher without values")
+	})
+}
+
+func TestMatchedSnippets(t *testing.T) {
+	m := &Matcher{Type: "regex", Condition: "and", Regex: []string{"v[0-9]+", "admin"}}
+	err := m.CompileMatchers()
+	require.Nil(t, err, "could not compile matcher")
+
+	matched, snippets := m.MatchRegex("admin panel v1 v2")
+	require.True(t, matched, "could not match valid regex and condition")
+	require.Equal(t, []string{"v1", "v2", "admin"}, snippets, "could not get matched regex snippets")
+
+	m = &Matcher{Type: "binary", Condition: "and", Binary: []string{"504b0304", "6d696d65"}}
+	err = m.CompileMatchers()
+	require.Nil(t, err, "could not compile matcher")
+
+	matched, snippets = m.MatchBinary("PK\x03\x04mimetype")
+	require.True(t, matched, "could not match valid binary and condition")
+	require.Equal(t, []string{"504b0304", "6d696d65"}, snippets, "could not get matched binary snippets")
+
+	matched, snippets = m.MatchBinary("PK\x03\x04")
+	require.False(t, matched, "could match invalid binary and condition")
+	require.Nil(t, snippets, "could get snippets for invalid binary and condition")
+}
+
+func TestCompileBinaryMatcher(t *testing.T) {
+	for _, value := range []string{"4d5a900", "4d5z", ""} {
+		m := &Matcher{Type: "binary", Binary: []string{"4d5a", value}}
+		err := m.CompileMatchers()
+		require.NotNil(t, err, "could compile invalid binary %s", value)
+		require.Contains(t, err.Error(), "binary: "+value, "could not get invalid binary in error")
+	}
+}
@@ -19,7 +19,9 @@ type Matcher struct {
 	Part string `yaml:"part,omitempty"`
 
 	// Negative specifies if the match should be reversed
-	// It will only match if the condition is not true.
+	// It will only match if the condition is not true. Parts missing
+	// from the response and dsl expressions which can't be evaluated
+	// never match.
 	Negative bool `yaml:"negative,omitempty"`
 
 	// Name is matcher Name
@@ -32,7 +34,7 @@ type Matcher struct {
 	Words []string `yaml:"words,omitempty"`
 	// Regex are the regex pattern required to be present in the response
 	Regex []string `yaml:"regex,omitempty"`
-	// Binary are the binary characters required to be present in the response
+	// Binary are the hex encoded bytes required to be present in the response
 	Binary []string `yaml:"binary,omitempty"`
 	// DSL are the dsl queries
 	DSL []string `yaml:"dsl,omitempty"`
@@ -43,6 +45,7 @@ type Matcher struct {
 	condition     ConditionType
 	matcherType   MatcherType
 	regexCompiled []*regexp.Regexp
+	binaryDecoded []string
 	dslCompiled   []*govaluate.EvaluableExpression
 }
 
@@ -82,6 +85,9 @@ const (
 	ANDCondition ConditionType = iota + 1
 	// ORCondition matches responses with AND condition in arguments.
 	ORCondition
+	// NOTCondition matches responses when none of the matchers match.
+	// It is only supported between the matchers of operators.
+	NOTCondition
 )
 
 // ConditionTypes is an table for conversion of condition type from string.
@@ -98,7 +104,25 @@ func (m *Matcher) Result(data bool) bool {
 	return data
 }
 
+// ResultWithMatchedSnippet returns the result of a match with its matched
+// snippets, negative matchers have no snippets.
+func (m *Matcher) ResultWithMatchedSnippet(data bool, matchedSnippet []string) (bool, []string) {
+	if m.Negative {
+		return !data, nil
+	}
+	return data, matchedSnippet
+}
+
 // GetType returns the type of the matcher
 func (m *Matcher) GetType() MatcherType {
 	return m.matcherType
 }
+
+// DSLVariables returns the variables used by the compiled dsl expressions
+func (m *Matcher) DSLVariables() []string {
+	var variables []string
+	for _, expression := range m.dslCompiled {
+		variables = append(variables, expression.Vars()...)
+	}
+	return variables
+}
@@ -1,7 +1,10 @@
 package operators
 
 import (
+	"sort"
+
 	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
 	"github.com/yaklang/nuclei/v2/pkg/operators/extractors"
 	"github.com/yaklang/nuclei/v2/pkg/operators/matchers"
 )
@@ -15,18 +18,29 @@ type Operators struct {
 	// and extract parts of the response.
 	Extractors []*extractors.Extractor `yaml:"extractors,omitempty"`
 	// MatchersCondition is the condition of the matchers
-	// whether to use AND or OR. Default is OR.
+	// whether to use AND, OR or NOT. Default is OR.
 	MatchersCondition string `yaml:"matchers-condition,omitempty"`
+	// StopAtFirstMatch stops evaluating the matchers of an OR condition
+	// after the first one matching. Http requests also stop sending the
+	// next requests for an input after the first match.
+	StopAtFirstMatch bool `yaml:"stop-at-first-match,omitempty"`
 	// cached variables that may be used along with request.
 	matchersCondition matchers.ConditionType
 }
 
 // Compile compiles the operators as well as their corresponding matchers and extractors
 func (r *Operators) Compile() error {
-	if r.MatchersCondition != "" {
-		r.matchersCondition = matchers.ConditionTypes[r.MatchersCondition]
-	} else {
+	switch r.MatchersCondition {
+	case "":
 		r.matchersCondition = matchers.ORCondition
+	case "not":
+		r.matchersCondition = matchers.NOTCondition
+	default:
+		condition, ok := matchers.ConditionTypes[r.MatchersCondition]
+		if !ok {
+			return errors.Errorf("unknown matchers condition specified: %s", r.MatchersCondition)
+		}
+		r.matchersCondition = condition
 	}
 
 	for _, matcher := range r.Matchers {
@@ -55,12 +69,23 @@ type Result struct {
 	Extracted bool
 	// Matches is a map of matcher names that we matched
 	Matches map[string]struct{}
+	// AndMatches contains the names of the matchers of an AND condition.
+	// They matched together, so they are reported in a single result.
+	AndMatches []string
 	// Extracts contains all the data extracted from inputs
 	Extracts map[string][]string
 	// OutputExtracts is the list of extracts to be displayed on screen.
 	OutputExtracts []string
+	// MatchedSnippets is the list of words, regex matches and binary
+	// values matched by the matchers.
+	MatchedSnippets []string
+	// MatchedWords contains the snippets matched by each named matcher.
+	MatchedWords map[string][]string
 	// DynamicValues contains any dynamic values to be templated
 	DynamicValues map[string]interface{}
+	// AllDynamicValues contains all the values extracted by the internal
+	// extractors, used to iterate the next requests over each of them.
+	AllDynamicValues map[string][]string
 	// PayloadValues contains payload values provided by user. (Optional)
 	PayloadValues map[string]interface{}
 }
@@ -77,20 +102,35 @@ func (r *Result) Merge(result *Result) {
 	for k, v := range result.Matches {
 		r.Matches[k] = v
 	}
+	r.AndMatches = appendUnique(r.AndMatches, result.AndMatches...)
 	for k, v := range result.Extracts {
 		r.Extracts[k] = v
 	}
 	r.OutputExtracts = append(r.OutputExtracts, result.OutputExtracts...)
+	r.MatchedSnippets = appendUnique(r.MatchedSnippets, result.MatchedSnippets...)
+	if len(result.MatchedWords) > 0 && r.MatchedWords == nil {
+		r.MatchedWords = make(map[string][]string)
+	}
+	for k, v := range result.MatchedWords {
+		r.MatchedWords[k] = appendUnique(r.MatchedWords[k], v...)
+	}
 	for k, v := range result.DynamicValues {
 		r.DynamicValues[k] = v
 	}
+	if len(result.AllDynamicValues) > 0 && r.AllDynamicValues == nil {
+		r.AllDynamicValues = make(map[string][]string)
+	}
+	for k, v := range result.AllDynamicValues {
+		r.AllDynamicValues[k] = append(r.AllDynamicValues[k], v...)
+	}
 	for k, v := range result.PayloadValues {
 		r.PayloadValues[k] = v
 	}
 }
 
-// MatchFunc performs matching operation for a matcher on model and returns true or false.
-type MatchFunc func(data map[string]interface{}, matcher *matchers.Matcher) bool
+// MatchFunc performs matching operation for a matcher on model and returns true or false
+// with the matched snippets if any.
+type MatchFunc func(data map[string]interface{}, matcher *matchers.Matcher) (bool, []string)
 
 // ExtractFunc performs extracting operation for a extractor on model and returns true or false.
 type ExtractFunc func(data map[string]interface{}, matcher *extractors.Extractor) map[string]struct{}
@@ -101,11 +141,16 @@ func (r *Operators) Execute(data map[string]interface{}, match MatchFunc, extrac
 
 	var matches bool
 	result := &Result{
-		Matches:       make(map[string]struct{}),
-		Extracts:      make(map[string][]string),
-		DynamicValues: make(map[string]interface{}),
+		Matches:          make(map[string]struct{}),
+		MatchedWords:     make(map[string][]string),
+		Extracts:         make(map[string][]string),
+		DynamicValues:    make(map[string]interface{}),
+		AllDynamicValues: make(map[string][]string),
 	}
 
+	// Values of the to-file extractors are saved only if the matchers matched.
+	var toFileResults [][]string
+
 	// Start with the extractors first and evaluate them.
 	for _, extractor := range r.Extractors {
 		var extractorResults []string
@@ -121,14 +166,48 @@ func (r *Operators) Execute(data map[string]interface{}, match MatchFunc, extrac
 				result.OutputExtracts = append(result.OutputExtracts, match)
 			}
 		}
+		toFileResults = append(toFileResults, extractorResults)
 		if len(extractorResults) > 0 && !extractor.Internal && extractor.Name != "" {
 			result.Extracts[extractor.Name] = extractorResults
 		}
+		if len(extractorResults) > 0 && extractor.Internal {
+			result.AllDynamicValues[extractor.Name] = append(result.AllDynamicValues[extractor.Name], sortedCopy(extractorResults)...)
+		}
+		if !extractor.Internal {
+			continue
+		}
+
+		// The named groups of internal regexes are dynamic values too
+		for _, group := range extractor.NamedGroups() {
+			var groupResults []string
+			for match := range extract(data, group) {
+				groupResults = append(groupResults, match)
+			}
+			if len(groupResults) == 0 {
+				continue
+			}
+			sorted := sortedCopy(groupResults)
+			if _, ok := result.DynamicValues[group.Name]; !ok {
+				result.DynamicValues[group.Name] = sorted[0]
+			}
+			result.AllDynamicValues[group.Name] = append(result.AllDynamicValues[group.Name], sorted...)
+		}
 	}
 
 	for _, matcher := range r.Matchers {
 		// Check if the matcher matched
-		if !match(data, matcher) {
+		matched, matchedSnippets := match(data, matcher)
+		if matcherCondition == matchers.NOTCondition {
+			// If the condition is NOT and we have matched, try next request.
+			if matched {
+				if len(result.DynamicValues) > 0 {
+					return result, true
+				}
+				return nil, false
+			}
+			continue
+		}
+		if !matched {
 			// If the condition is AND we haven't matched, try next request.
 			if matcherCondition == matchers.ANDCondition {
 				if len(result.DynamicValues) > 0 {
@@ -137,17 +216,36 @@ func (r *Operators) Execute(data map[string]interface{}, match MatchFunc, extrac
 				return nil, false
 			}
 		} else {
-			// If the matcher has matched, and its an OR
-			// write the first output then move to next matcher.
-			if matcherCondition == matchers.ORCondition && matcher.Name != "" {
-				result.Matches[matcher.Name] = struct{}{}
+			// If the matcher has matched, record its name and snippets
+			// then move to next matcher.
+			if matcher.Name != "" {
+				if matcherCondition == matchers.ANDCondition {
+					result.AndMatches = append(result.AndMatches, matcher.Name)
+				} else {
+					result.Matches[matcher.Name] = struct{}{}
+				}
+				if len(matchedSnippets) > 0 {
+					result.MatchedWords[matcher.Name] = appendUnique(result.MatchedWords[matcher.Name], matchedSnippets...)
+				}
 			}
+			result.MatchedSnippets = appendUnique(result.MatchedSnippets, matchedSnippets...)
 			matches = true
+
+			if matcherCondition == matchers.ORCondition && r.StopAtFirstMatch {
+				break
+			}
 		}
 	}
+	// None of the matchers matched for the NOT condition
+	if matcherCondition == matchers.NOTCondition && len(r.Matchers) > 0 {
+		matches = true
+	}
 
 	result.Matched = matches
 	result.Extracted = len(result.OutputExtracts) > 0
+	if len(r.Matchers) == 0 || matches {
+		r.saveToFiles(toFileResults)
+	}
 	if len(result.DynamicValues) > 0 {
 		return result, true
 	}
@@ -162,3 +260,37 @@ func (r *Operators) Execute(data map[string]interface{}, match MatchFunc, extrac
 	}
 	return nil, false
 }
+
+// saveToFiles writes the values extracted by each extractor to its to-file
+func (r *Operators) saveToFiles(results [][]string) {
+	for i, values := range results {
+		if err := r.Extractors[i].SaveToFile(values); err != nil {
+			gologger.Warning().Msgf("Could not save extracted values: %s\n", err)
+		}
+	}
+}
+
+// appendUnique appends the values missing from a slice
+func appendUnique(slice []string, values ...string) []string {
+	for _, value := range values {
+		found := false
+		for _, item := range slice {
+			if item == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			slice = append(slice, value)
+		}
+	}
+	return slice
+}
+
+// sortedCopy returns a sorted copy of values, leaving the values as is
+func sortedCopy(values []string) []string {
+	sorted := make([]string, len(values))
+	copy(sorted, values)
+	sort.Strings(sorted)
+	return sorted
+}
@@ -0,0 +1,177 @@
+package operators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaklang/nuclei/v2/pkg/operators/extractors"
+	"github.com/yaklang/nuclei/v2/pkg/operators/matchers"
+	"github.com/yaklang/nuclei/v2/pkg/types"
+)
+
+func TestExecuteNegativeMatchers(t *testing.T) {
+	match := func(data map[string]interface{}, matcher *matchers.Matcher) (bool, []string) {
+		item, ok := data["body"]
+		if !ok {
+			return false, nil
+		}
+		return matcher.ResultWithMatchedSnippet(matcher.MatchWords(types.ToString(item)))
+	}
+	extract := func(data map[string]interface{}, extractor *extractors.Extractor) map[string]struct{} {
+		return nil
+	}
+	compile := func(condition string) *Operators {
+		operators := &Operators{
+			MatchersCondition: condition,
+			Matchers: []*matchers.Matcher{
+				{Type: "word", Name: "dashboard", Words: []string{"dashboard"}},
+				{Type: "word", Name: "no-login", Words: []string{"login"}, Negative: true},
+			},
+		}
+		err := operators.Compile()
+		require.Nil(t, err, "could not compile operators")
+		return operators
+	}
+
+	and := compile("and")
+	result, matched := and.Execute(map[string]interface{}{"body": "dashboard"}, match, extract)
+	require.True(t, matched, "could not match valid and condition with negative matcher")
+	require.Equal(t, []string{"dashboard"}, result.MatchedSnippets, "could not get matched snippets")
+	_, matched = and.Execute(map[string]interface{}{"body": "dashboard login"}, match, extract)
+	require.False(t, matched, "could match invalid and condition with negative matcher")
+
+	or := compile("or")
+	result, matched = or.Execute(map[string]interface{}{"body": "error"}, match, extract)
+	require.True(t, matched, "could not match valid or condition with negative matcher")
+	require.Equal(t, map[string]struct{}{"no-login": {}}, result.Matches, "could not get negative matcher name")
+
+	_, matched = or.Execute(map[string]interface{}{}, match, extract)
+	require.False(t, matched, "could match negative matcher without response")
+}
+
+func TestExecuteNamedGroups(t *testing.T) {
+	match := func(data map[string]interface{}, matcher *matchers.Matcher) (bool, []string) {
+		return false, nil
+	}
+	extract := func(data map[string]interface{}, extractor *extractors.Extractor) map[string]struct{} {
+		return extractor.ExtractRegex(types.ToString(data[extractor.Part]))
+	}
+	operators := &Operators{
+		Extractors: []*extractors.Extractor{{
+			Name:     "csrf",
+			Type:     "regex",
+			Internal: true,
+			Regex:    []string{`name="csrf" value="(?P<token>[a-z0-9]+)"`},
+		}},
+	}
+	err := operators.Compile()
+	require.Nil(t, err, "could not compile operators")
+
+	result, ok := operators.Execute(map[string]interface{}{"body": `<input name="csrf" value="abc123">`}, match, extract)
+	require.True(t, ok, "could not get dynamic values")
+	require.Equal(t, "abc123", result.DynamicValues["token"], "could not get named group dynamic value")
+	require.Equal(t, `name="csrf" value="abc123"`, result.DynamicValues["csrf"], "could not get extractor dynamic value")
+	require.Equal(t, []string{"abc123"}, result.AllDynamicValues["token"], "could not get all named group values")
+}
+
+func TestExecuteConditions(t *testing.T) {
+	var evaluated []string
+	match := func(data map[string]interface{}, matcher *matchers.Matcher) (bool, []string) {
+		evaluated = append(evaluated, matcher.Name)
+		return matcher.ResultWithMatchedSnippet(matcher.MatchWords(types.ToString(data["body"])))
+	}
+	extract := func(data map[string]interface{}, extractor *extractors.Extractor) map[string]struct{} {
+		return extractor.ExtractRegex(types.ToString(data["body"]))
+	}
+	compile := func(operators *Operators) *Operators {
+		operators.Matchers = []*matchers.Matcher{
+			{Type: "word", Name: "login", Words: []string{"login"}},
+			{Type: "word", Name: "admin", Words: []string{"admin"}},
+			{Type: "word", Name: "debug", Words: []string{"debug"}},
+		}
+		err := operators.Compile()
+		require.Nil(t, err, "could not compile operators")
+		return operators
+	}
+
+	t.Run("not", func(t *testing.T) {
+		not := compile(&Operators{MatchersCondition: "not"})
+		result, matched := not.Execute(map[string]interface{}{"body": "welcome"}, match, extract)
+		require.True(t, matched, "could not match not condition without matchers matching")
+		require.Empty(t, result.Matches, "could get matcher names for not condition")
+
+		_, matched = not.Execute(map[string]interface{}{"body": "welcome admin"}, match, extract)
+		require.False(t, matched, "could match not condition with a matcher matching")
+	})
+
+	t.Run("not-extractors", func(t *testing.T) {
+		not := &Operators{
+			MatchersCondition: "not",
+			Extractors:        []*extractors.Extractor{{Type: "regex", Regex: []string{"v[0-9]+"}}},
+		}
+		err := not.Compile()
+		require.Nil(t, err, "could not compile operators")
+
+		result, matched := not.Execute(map[string]interface{}{"body": "welcome v1"}, match, extract)
+		require.True(t, matched, "could not extract with not condition without matchers")
+		require.False(t, result.Matched, "could match not condition without matchers")
+		require.Equal(t, []string{"v1"}, result.OutputExtracts, "could not get extracts")
+
+		_, matched = not.Execute(map[string]interface{}{"body": "welcome"}, match, extract)
+		require.False(t, matched, "could match not condition without matchers and extracts")
+	})
+
+	t.Run("stop-at-first-match", func(t *testing.T) {
+		evaluated = nil
+		stop := compile(&Operators{StopAtFirstMatch: true})
+		result, matched := stop.Execute(map[string]interface{}{"body": "admin debug"}, match, extract)
+		require.True(t, matched, "could not match or condition")
+		require.Equal(t, map[string]struct{}{"admin": {}}, result.Matches, "could not get first matcher name")
+		require.Equal(t, []string{"login", "admin"}, evaluated, "could not stop at first match")
+
+		evaluated = nil
+		all := compile(&Operators{})
+		result, _ = all.Execute(map[string]interface{}{"body": "admin debug"}, match, extract)
+		require.Equal(t, map[string]struct{}{"admin": {}, "debug": {}}, result.Matches, "could not get all matcher names")
+		require.Len(t, evaluated, 3, "could stop without stop-at-first-match")
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		err := (&Operators{MatchersCondition: "xor"}).Compile()
+		require.NotNil(t, err, "could compile unknown matchers condition")
+	})
+}
+
+func TestExecuteMatchedWords(t *testing.T) {
+	match := func(data map[string]interface{}, matcher *matchers.Matcher) (bool, []string) {
+		corpus := types.ToString(data["body"])
+		switch matcher.GetType() {
+		case matchers.RegexMatcher:
+			return matcher.ResultWithMatchedSnippet(matcher.MatchRegex(corpus))
+		default:
+			return matcher.ResultWithMatchedSnippet(matcher.MatchWords(corpus))
+		}
+	}
+	extract := func(data map[string]interface{}, extractor *extractors.Extractor) map[string]struct{} {
+		return nil
+	}
+	operators := &Operators{
+		MatchersCondition: "and",
+		Matchers: []*matchers.Matcher{
+			{Type: "word", Name: "panel", Words: []string{"admin", "panel"}, Condition: "and"},
+			{Type: "regex", Name: "version", Regex: []string{"v[0-9.]+"}},
+			{Type: "word", Words: []string{"welcome"}},
+		},
+	}
+	err := operators.Compile()
+	require.Nil(t, err, "could not compile operators")
+
+	result, matched := operators.Execute(map[string]interface{}{"body": "welcome to admin panel v1.2"}, match, extract)
+	require.True(t, matched, "could not match and condition")
+	require.Empty(t, result.Matches, "and matcher names were recorded as separate matches")
+	require.Equal(t, []string{"panel", "version"}, result.AndMatches, "could not get and matcher names")
+	require.Equal(t, map[string][]string{"panel": {"admin", "panel"}, "version": {"v1.2"}}, result.MatchedWords, "could not get matched words")
+
+	_, matched = operators.Execute(map[string]interface{}{"body": "welcome to admin panel"}, match, extract)
+	require.False(t, matched, "could match invalid and condition")
+}
@@ -2,35 +2,73 @@ package output
 
 import (
 	"os"
+	"sync"
+	"time"
 )
 
+// completeMarkerSuffix is the suffix of the sidecar file written when
+// an output file was closed cleanly.
+const completeMarkerSuffix = ".complete"
+
 // fileWriter is a concurrent file based output writer.
+//
+// Each write is a single complete line so a killed process leaves at most
+// one partial line at the end of the file.
 type fileWriter struct {
-	file *os.File
+	file         *os.File
+	mutex        *sync.Mutex
+	syncInterval time.Duration
+	lastSync     time.Time
+	marker       bool
 }
 
-// NewFileOutputWriter creates a new buffered writer for a file
-func newFileOutputWriter(file string) (*fileWriter, error) {
+// NewFileOutputWriter creates a new line buffered writer for a file
+func newFileOutputWriter(file string, syncInterval time.Duration, marker bool) (*fileWriter, error) {
+	if marker {
+		_ = os.Remove(file + completeMarkerSuffix)
+	}
 	output, err := os.Create(file)
 	if err != nil {
 		return nil, err
 	}
-	return &fileWriter{file: output}, nil
+	return &fileWriter{file: output, mutex: &sync.Mutex{}, syncInterval: syncInterval, lastSync: time.Now(), marker: marker}, nil
 }
 
 // WriteString writes an output to the underlying file
 func (w *fileWriter) Write(data []byte) error {
-	_, err := w.file.Write(data)
-	if err != nil {
+	line := make([]byte, 0, len(data)+1)
+	line = append(line, data...)
+	line = append(line, '\n')
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if _, err := w.file.Write(line); err != nil {
 		return err
 	}
-	_, err = w.file.Write([]byte("\n"))
-	return err
+	if w.syncInterval > 0 && time.Since(w.lastSync) >= w.syncInterval {
+		w.lastSync = time.Now()
+		return w.file.Sync()
+	}
+	return nil
 }
 
 // Close closes the underlying writer flushing everything to disk
 func (w *fileWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
 	//nolint:errcheck // we don't care whether sync failed or succeeded.
 	w.file.Sync()
-	return w.file.Close()
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	if w.marker {
+		marker, err := os.Create(w.file.Name() + completeMarkerSuffix)
+		if err != nil {
+			return err
+		}
+		return marker.Close()
+	}
+	return nil
 }
@@ -2,6 +2,7 @@ package output
 
 import (
 	"bytes"
+	"strings"
 
 	"github.com/yaklang/nuclei/v2/pkg/types"
 )
@@ -21,6 +22,9 @@ func (w *StandardWriter) formatScreen(output *ResultEvent) []byte {
 		if output.MatcherName != "" {
 			builder.WriteString(":")
 			builder.WriteString(w.aurora.BrightGreen(output.MatcherName).Bold().String())
+		} else if len(output.MatcherNames) > 0 {
+			builder.WriteString(":")
+			builder.WriteString(w.aurora.BrightGreen(strings.Join(output.MatcherNames, ",")).Bold().String())
 		} else if output.ExtractorName != "" {
 			builder.WriteString(":")
 			builder.WriteString(w.aurora.BrightGreen(output.ExtractorName).Bold().String())
@@ -67,5 +71,12 @@ func (w *StandardWriter) formatScreen(output *ResultEvent) []byte {
 		}
 		builder.WriteString("]")
 	}
+
+	// Mark the templates that did not match with matcher status enabled
+	if output.MatcherStatus != nil && !*output.MatcherStatus {
+		builder.WriteString(" [")
+		builder.WriteString(w.aurora.BrightRed("failed").String())
+		builder.WriteString("]")
+	}
 	return builder.Bytes()
 }
@@ -24,6 +24,10 @@ type Writer interface {
 	Write(*ResultEvent) error
 	// Request logs a request in the trace log
 	Request(templateID, url, requestType string, err error)
+	// RequestWithProxy logs a request sent through a proxy in the trace log
+	RequestWithProxy(templateID, url, requestType, proxy string, err error)
+	// RequestWithData logs a request with the bytes sent and received in the trace log
+	RequestWithData(templateID, url, requestType string, sent, received []byte, err error)
 }
 
 // StandardWriter is a writer writing output to file and screen for results.
@@ -72,29 +76,75 @@ type ResultEvent struct {
 	Matched string `json:"matched,omitempty"`
 	// ExtractedResults contains the extraction result from the inputs.
 	ExtractedResults []string `json:"extracted_results,omitempty"`
+	// ExtractedNamed contains the values of the named extractors by name.
+	ExtractedNamed map[string][]string `json:"extracted_named,omitempty"`
+	// MatchedSnippets contains the words, regex matches and binary values
+	// matched by the matchers.
+	MatchedSnippets []string `json:"matched_snippets,omitempty"`
+	// MatchedWords contains the snippets matched by each named matcher.
+	MatchedWords map[string][]string `json:"matched_words,omitempty"`
+	// MatcherNames contains the names of the matchers of an AND condition.
+	MatcherNames []string `json:"matcher_names,omitempty"`
+	// Lines are the line numbers of the matches in files.
+	Lines []int `json:"lines,omitempty"`
+	// Offsets are the byte offsets of the matches in files.
+	Offsets []int `json:"offsets,omitempty"`
+	// Snippet is the line of the first match in files.
+	Snippet string `json:"snippet,omitempty"`
+	// ExtractedOffsets contains the byte offsets of each extracted value in files.
+	ExtractedOffsets map[string][]int `json:"extracted_offsets,omitempty"`
 	// Request is the optional dumped request for the match.
 	Request string `json:"request,omitempty"`
 	// Response is the optional dumped response for the match.
 	Response string `json:"response,omitempty"`
+	// ResponseHex is the hex encoded response of network matches, capped
+	// to the first bytes.
+	ResponseHex string `json:"response_hex,omitempty"`
 	// Metadata contains any optional metadata for the event
 	Metadata map[string]interface{} `json:"meta,omitempty"`
 	// IP is the IP address for the found result event.
 	IP string `json:"ip,omitempty"`
+	// WAF is the web application firewall detected for the host if any.
+	WAF string `json:"waf,omitempty"`
+	// CDN is the content delivery network detected for the host if any.
+	CDN string `json:"cdn,omitempty"`
 	// Timestamp is the time the result was found at.
 	Timestamp time.Time `json:"timestamp"`
+	// CurlCommand is the curl command reproducing the request of the result.
+	CurlCommand string `json:"curl_command,omitempty"`
 	// Interaction is the full details of interactsh interaction.
 	Interaction *server.Interaction `json:"interaction,omitempty"`
+	// StoredResponsePath is the file storing the request and response of
+	// the result when responses are stored.
+	StoredResponsePath string `json:"stored_response_path,omitempty"`
+	// Screenshots are the files of the screenshots taken by headless actions
+	Screenshots []string `json:"screenshots,omitempty"`
+	// MatcherStatus is false for the events of the templates that did not
+	// match an input, only set when matcher status events are enabled.
+	MatcherStatus *bool `json:"matcher-status,omitempty"`
 
 	FileToIndexPosition map[string]int `json:"-"`
 }
 
-// NewStandardWriter creates a new output writer based on user configurations
-func NewStandardWriter(colors, noMetadata, json bool, file, traceFile string) (*StandardWriter, error) {
+// TruncateRR truncates a dumped request or response of a result to size
+// bytes, leaving it unchanged if size is 0.
+func TruncateRR(data string, size int) string {
+	if size > 0 && len(data) > size {
+		return data[:size]
+	}
+	return data
+}
+
+// NewStandardWriter creates a new output writer based on user configurations.
+//
+// syncInterval is the minimum interval between fsync calls on the output file,
+// 0 disables periodic syncing.
+func NewStandardWriter(colors, noMetadata, json bool, file, traceFile string, syncInterval time.Duration) (*StandardWriter, error) {
 	auroraColorizer := aurora.NewAurora(colors)
 
 	var outputFile *fileWriter
 	if file != "" {
-		output, err := newFileOutputWriter(file)
+		output, err := newFileOutputWriter(file, syncInterval, true)
 		if err != nil {
 			return nil, errors.Wrap(err, "could not create output file")
 		}
@@ -102,7 +152,7 @@ func NewStandardWriter(colors, noMetadata, json bool, file, traceFile string) (*
 	}
 	var traceOutput *fileWriter
 	if traceFile != "" {
-		output, err := newFileOutputWriter(traceFile)
+		output, err := newFileOutputWriter(traceFile, 0, false)
 		if err != nil {
 			return nil, errors.Wrap(err, "could not create output file")
 		}
@@ -139,14 +189,15 @@ func (w *StandardWriter) Write(event *ResultEvent) error {
 	if len(data) == 0 {
 		return nil
 	}
-	_, _ = os.Stdout.Write(data)
-	_, _ = os.Stdout.Write([]byte("\n"))
+	w.outputMutex.Lock()
+	_, _ = os.Stdout.Write(append(data, '\n'))
+	w.outputMutex.Unlock()
 	if w.outputFile != nil {
 		if !w.json {
 			data = decolorizerRegex.ReplaceAll(data, []byte(""))
 		}
 		if writeErr := w.outputFile.Write(data); writeErr != nil {
-			return errors.Wrap(err, "could not write to output")
+			return errors.Wrap(writeErr, "could not write to output")
 		}
 	}
 	return nil
@@ -158,18 +209,46 @@ type JSONTraceRequest struct {
 	URL   string `json:"url"`
 	Error string `json:"error"`
 	Type  string `json:"type"`
+	Proxy string `json:"proxy,omitempty"`
+	// Sent and Received are the bytes of network requests, base64 encoded.
+	Sent     []byte `json:"sent,omitempty"`
+	Received []byte `json:"received,omitempty"`
 }
 
 // Request writes a log the requests trace log
 func (w *StandardWriter) Request(templateID, url, requestType string, err error) {
+	w.RequestWithProxy(templateID, url, requestType, "", err)
+}
+
+// RequestWithProxy writes a log of a request sent through a proxy to the trace log
+func (w *StandardWriter) RequestWithProxy(templateID, url, requestType, proxy string, err error) {
 	if w.traceFile == nil {
 		return
 	}
-	request := &JSONTraceRequest{
-		ID:   templateID,
-		URL:  url,
-		Type: requestType,
+	w.writeTrace(&JSONTraceRequest{
+		ID:    templateID,
+		URL:   url,
+		Type:  requestType,
+		Proxy: proxy,
+	}, err)
+}
+
+// RequestWithData writes a log of a request with the bytes sent and received to the trace log
+func (w *StandardWriter) RequestWithData(templateID, url, requestType string, sent, received []byte, err error) {
+	if w.traceFile == nil {
+		return
 	}
+	w.writeTrace(&JSONTraceRequest{
+		ID:       templateID,
+		URL:      url,
+		Type:     requestType,
+		Sent:     sent,
+		Received: received,
+	}, err)
+}
+
+// writeTrace writes a request with its error to the trace log
+func (w *StandardWriter) writeTrace(request *JSONTraceRequest, err error) {
 	if err != nil {
 		request.Error = err.Error()
 	} else {
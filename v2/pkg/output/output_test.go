@@ -0,0 +1,41 @@
+package output
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceRequestWithData(t *testing.T) {
+	directory, err := ioutil.TempDir("", "output-trace-*")
+	require.Nil(t, err, "could not create temp directory")
+	defer os.RemoveAll(directory)
+
+	file := path.Join(directory, "trace.json")
+	writer, err := NewStandardWriter(false, false, false, "", file, 0)
+	require.Nil(t, err, "could not create writer")
+	writer.RequestWithData("test", "127.0.0.1:6379", "network", []byte{0x00, 0xff}, []byte("PONG"), nil)
+	writer.Close()
+
+	data, err := ioutil.ReadFile(file)
+	require.Nil(t, err, "could not read trace file")
+	require.Contains(t, string(data), `"sent":"AP8="`, "could not base64 encode sent bytes")
+
+	request := &JSONTraceRequest{}
+	err = jsoniter.Unmarshal(bytes.TrimSpace(data), request)
+	require.Nil(t, err, "could not unmarshal trace request")
+	require.Equal(t, []byte{0x00, 0xff}, request.Sent, "could not get sent bytes")
+	require.Equal(t, []byte("PONG"), request.Received, "could not get received bytes")
+	require.Equal(t, "none", request.Error, "could not get trace error")
+}
+
+func TestTruncateRR(t *testing.T) {
+	require.Equal(t, "GET / HTTP/1.1", TruncateRR("GET / HTTP/1.1", 0), "could not keep data without limit")
+	require.Equal(t, "GET / HTTP/1.1", TruncateRR("GET / HTTP/1.1", 100), "could not keep data under limit")
+	require.Equal(t, "GET /", TruncateRR("GET / HTTP/1.1", 5), "could not truncate data")
+}
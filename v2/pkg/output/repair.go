@@ -0,0 +1,64 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+)
+
+// RepairResult contains the result of repairing a json output file
+type RepairResult struct {
+	// Recovered is the number of valid events in the file
+	Recovered int
+	// Invalid is the number of complete lines which were not valid json
+	Invalid int
+	// Truncated is the number of bytes removed from the end of the file
+	Truncated int64
+}
+
+// RepairFile repairs a json output file left behind by a killed process
+// by truncating a trailing partial line. The file is read in a streaming
+// manner so large output files can be repaired.
+func RepairFile(file string) (*RepairResult, error) {
+	f, err := os.OpenFile(file, os.O_RDWR, 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open output file")
+	}
+	defer f.Close()
+
+	result := &RepairResult{}
+	reader := bufio.NewReader(f)
+
+	var validSize int64
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr == io.EOF {
+			result.Truncated = int64(len(line))
+			break
+		}
+		if readErr != nil {
+			return nil, errors.Wrap(readErr, "could not read output file")
+		}
+		validSize += int64(len(line))
+
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		if jsoniter.Valid(line) {
+			result.Recovered++
+		} else {
+			result.Invalid++
+		}
+	}
+	if result.Truncated > 0 {
+		if err := f.Truncate(validSize); err != nil {
+			return nil, errors.Wrap(err, "could not truncate output file")
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,81 @@
+package output
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepairFile(t *testing.T) {
+	directory, err := ioutil.TempDir("", "output-repair-*")
+	require.Nil(t, err, "could not create temp directory")
+	defer os.RemoveAll(directory)
+
+	file := path.Join(directory, "output.json")
+	data := `{"templateID":"a"}` + "\n" + `{"templateID":"b"}` + "\n" + `{"templateID":"c","host":"exa`
+	err = ioutil.WriteFile(file, []byte(data), 0644)
+	require.Nil(t, err, "could not write output file")
+
+	result, err := RepairFile(file)
+	require.Nil(t, err, "could not repair output file")
+	require.Equal(t, 2, result.Recovered, "could not get correct recovered events")
+	require.Equal(t, int64(len(`{"templateID":"c","host":"exa`)), result.Truncated, "could not get correct truncated bytes")
+
+	repaired, err := ioutil.ReadFile(file)
+	require.Nil(t, err, "could not read repaired file")
+	require.Equal(t, `{"templateID":"a"}`+"\n"+`{"templateID":"b"}`+"\n", string(repaired), "could not get correct repaired file")
+
+	result, err = RepairFile(file)
+	require.Nil(t, err, "could not repair output file")
+	require.Equal(t, int64(0), result.Truncated, "truncated already valid file")
+}
+
+func TestFileWriterConcurrentJSON(t *testing.T) {
+	directory, err := ioutil.TempDir("", "output-writer-*")
+	require.Nil(t, err, "could not create temp directory")
+	defer os.RemoveAll(directory)
+
+	file := path.Join(directory, "output.json")
+	writer, err := newFileOutputWriter(file, time.Millisecond, true)
+	require.Nil(t, err, "could not create writer")
+
+	line := []byte(`{"templateID":"test","host":"` + strings.Repeat("a", 4096) + `"}`)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				_ = writer.Write(line)
+			}
+		}()
+	}
+	wg.Wait()
+
+	_, err = os.Stat(file + completeMarkerSuffix)
+	require.True(t, os.IsNotExist(err), "complete marker written before close")
+	writer.Close()
+	_, err = os.Stat(file + completeMarkerSuffix)
+	require.Nil(t, err, "could not find complete marker after close")
+
+	f, err := os.Open(file)
+	require.Nil(t, err, "could not open output file")
+	defer f.Close()
+
+	var lines int
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024)
+	for scanner.Scan() {
+		lines++
+		require.True(t, jsoniter.Valid(scanner.Bytes()), "got invalid json line")
+	}
+	require.Equal(t, 1000, lines, "could not get correct number of lines")
+}
@@ -0,0 +1,49 @@
+package output
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/yaklang/nuclei/v2/pkg/types"
+)
+
+// StoreResponse writes the request and response of a matched event to a
+// file of the directory and records its path in the results of the event.
+//
+// The file is named from the template id of the results and a hash of the
+// matched location and the request, so clustered results are stored under
+// their template and the requests to the same location don't overwrite
+// each other.
+func StoreResponse(directory string, event *InternalWrappedEvent) error {
+	if len(event.Results) == 0 {
+		return nil
+	}
+	result := event.Results[0]
+
+	// network and dns requests keep the full transaction in the raw part
+	response, ok := event.InternalEvent["response"]
+	if !ok {
+		response = event.InternalEvent["raw"]
+	}
+	request := types.ToString(event.InternalEvent["request"])
+	content := request + "\n\n" + types.ToString(response)
+
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return errors.Wrap(err, "could not create store response directory")
+	}
+	hash := sha1.Sum([]byte(result.Host + result.Matched + request))
+	name := strings.ReplaceAll(result.TemplateID, string(os.PathSeparator), "_") + "-" + hex.EncodeToString(hash[:])[:16] + ".txt"
+	path := filepath.Join(directory, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		return errors.Wrap(err, "could not write stored response")
+	}
+	for _, result := range event.Results {
+		result.StoredResponsePath = path
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package output
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreResponse(t *testing.T) {
+	directory, err := ioutil.TempDir("", "output-store-resp-*")
+	require.Nil(t, err, "could not create temp directory")
+	defer os.RemoveAll(directory)
+	directory = filepath.Join(directory, "responses")
+
+	event := &InternalWrappedEvent{
+		InternalEvent: InternalEvent{"request": "GET / HTTP/1.1", "response": "HTTP/1.1 200 OK"},
+		Results: []*ResultEvent{
+			{TemplateID: "tech-detect", Host: "https://example.com", Matched: "https://example.com/", MatcherName: "nginx"},
+			{TemplateID: "tech-detect", Host: "https://example.com", Matched: "https://example.com/", MatcherName: "php"},
+		},
+	}
+	err = StoreResponse(directory, event)
+	require.Nil(t, err, "could not store response")
+
+	path := event.Results[0].StoredResponsePath
+	require.True(t, strings.HasPrefix(filepath.Base(path), "tech-detect-"), "could not name stored response by template")
+	require.Equal(t, path, event.Results[1].StoredResponsePath, "could not set stored response path for all results")
+	data, err := ioutil.ReadFile(path)
+	require.Nil(t, err, "could not read stored response")
+	require.Equal(t, "GET / HTTP/1.1\n\nHTTP/1.1 200 OK", string(data), "could not get stored response")
+
+	network := &InternalWrappedEvent{
+		InternalEvent: InternalEvent{"request": "PING", "raw": "PONG"},
+		Results:       []*ResultEvent{{TemplateID: "tech-detect", Host: "example.com:6379", Matched: "example.com:6379"}},
+	}
+	err = StoreResponse(directory, network)
+	require.Nil(t, err, "could not store network response")
+	require.NotEqual(t, path, network.Results[0].StoredResponsePath, "could not store responses of other hosts separately")
+	data, err = ioutil.ReadFile(network.Results[0].StoredResponsePath)
+	require.Nil(t, err, "could not read stored network response")
+	require.Equal(t, "PING\n\nPONG", string(data), "could not get stored network response")
+
+	post := &InternalWrappedEvent{
+		InternalEvent: InternalEvent{"request": "POST / HTTP/1.1", "response": "HTTP/1.1 200 OK"},
+		Results:       []*ResultEvent{{TemplateID: "tech-detect", Host: "https://example.com", Matched: "https://example.com/"}},
+	}
+	err = StoreResponse(directory, post)
+	require.Nil(t, err, "could not store response")
+	require.NotEqual(t, path, post.Results[0].StoredResponsePath, "could not store other requests to the same location separately")
+}
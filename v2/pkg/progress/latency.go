@@ -0,0 +1,106 @@
+package progress
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// latencyBuckets is the number of histogram buckets for each host.
+	latencyBuckets = 56
+	// latencyBucketBase is the upper bound of the first bucket.
+	latencyBucketBase = time.Millisecond
+	// latencyBucketGrowth is the growth factor of the bucket bounds.
+	latencyBucketGrowth = 1.25
+)
+
+// HostLatency is the latency percentile of requests made to a host
+type HostLatency struct {
+	Host     string
+	Requests int
+	P95      time.Duration
+}
+
+// latencyTracker tracks latency of requests per host using a fixed size
+// log-scale histogram so the memory used per host stays constant.
+type latencyTracker struct {
+	mutex sync.Mutex
+	hosts map[string]*[latencyBuckets]uint32
+}
+
+func newLatencyTracker() *latencyTracker {
+	return &latencyTracker{hosts: make(map[string]*[latencyBuckets]uint32)}
+}
+
+// record records the latency of a request made to a host
+func (l *latencyTracker) record(host string, duration time.Duration) {
+	if host == "" {
+		return
+	}
+	bucket := latencyBucket(duration)
+
+	l.mutex.Lock()
+	histogram, ok := l.hosts[host]
+	if !ok {
+		histogram = &[latencyBuckets]uint32{}
+		l.hosts[host] = histogram
+	}
+	histogram[bucket]++
+	l.mutex.Unlock()
+}
+
+// slowest returns the count hosts with the highest p95 latency
+func (l *latencyTracker) slowest(count int) []HostLatency {
+	l.mutex.Lock()
+	results := make([]HostLatency, 0, len(l.hosts))
+	for host, histogram := range l.hosts {
+		results = append(results, percentile(host, histogram, 0.95))
+	}
+	l.mutex.Unlock()
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].P95 == results[j].P95 {
+			return results[i].Host < results[j].Host
+		}
+		return results[i].P95 > results[j].P95
+	})
+	if len(results) > count {
+		results = results[:count]
+	}
+	return results
+}
+
+// latencyBucket returns the histogram bucket for a duration
+func latencyBucket(duration time.Duration) int {
+	if duration <= latencyBucketBase {
+		return 0
+	}
+	bucket := int(math.Ceil(math.Log(float64(duration)/float64(latencyBucketBase)) / math.Log(latencyBucketGrowth)))
+	if bucket >= latencyBuckets {
+		bucket = latencyBuckets - 1
+	}
+	return bucket
+}
+
+// percentile returns the upper bound of the bucket containing the percentile
+func percentile(host string, histogram *[latencyBuckets]uint32, p float64) HostLatency {
+	var total uint32
+	for _, count := range histogram {
+		total += count
+	}
+	target := uint32(math.Ceil(float64(total) * p))
+
+	var cumulative uint32
+	bucket := 0
+	for i, count := range histogram {
+		cumulative += count
+		if cumulative >= target {
+			bucket = i
+			break
+		}
+	}
+	upper := time.Duration(float64(latencyBucketBase) * math.Pow(latencyBucketGrowth, float64(bucket)))
+	return HostLatency{Host: host, Requests: int(total), P95: upper}
+}
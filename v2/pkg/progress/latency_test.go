@@ -0,0 +1,28 @@
+package progress
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatencyTrackerSlowest(t *testing.T) {
+	tracker := newLatencyTracker()
+	for i := 0; i < 100; i++ {
+		tracker.record("fast.example.com", 10*time.Millisecond)
+		tracker.record("slow.example.com", 200*time.Millisecond)
+	}
+	for i := 0; i < 10; i++ {
+		tracker.record("mixed.example.com", 10*time.Millisecond)
+	}
+	tracker.record("mixed.example.com", 5*time.Second)
+	tracker.record("", time.Second)
+
+	hosts := tracker.slowest(2)
+	require.Len(t, hosts, 2, "could not get correct number of hosts")
+	require.Equal(t, "mixed.example.com", hosts[0].Host, "could not get slowest host")
+	require.Equal(t, "slow.example.com", hosts[1].Host, "could not get second slowest host")
+	require.Equal(t, 100, hosts[1].Requests, "could not get correct request count")
+	require.InEpsilon(t, float64(200*time.Millisecond), float64(hosts[1].P95), latencyBucketGrowth-1, "could not get correct p95 latency")
+}
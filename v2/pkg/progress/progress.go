@@ -23,6 +23,8 @@ type Progress interface {
 	Init(hostCount int64, rulesCount int, requestCount int64)
 	// AddToTotal adds a value to the total request count
 	AddToTotal(delta int64)
+	// SetShard sets the shard of the targets scanned by this instance
+	SetShard(shard string)
 	// IncrementRequests increments the requests counter by 1.
 	IncrementRequests()
 	// IncrementMatched increments the matched counter by 1.
@@ -32,6 +34,10 @@ type Progress interface {
 	// IncrementFailedRequestsBy increments the number of requests counter by count
 	// along with errors.
 	IncrementFailedRequestsBy(count int64)
+	// RecordLatency records the latency of a request made to a host.
+	RecordLatency(host string, duration time.Duration)
+	// SlowestHosts returns the hosts with the highest p95 latency.
+	SlowestHosts(count int) []HostLatency
 }
 
 var _ Progress = &StatsTicker{}
@@ -42,6 +48,7 @@ type StatsTicker struct {
 	tickDuration time.Duration
 	stats        clistats.StatisticsClient
 	server       *http.Server
+	latency      *latencyTracker
 }
 
 // NewStatsTicker creates and returns a new progress tracking object.
@@ -53,7 +60,7 @@ func NewStatsTicker(duration int, active, metrics bool, port int) (Progress, err
 		tickDuration = -1
 	}
 
-	progress := &StatsTicker{}
+	progress := &StatsTicker{latency: newLatencyTracker()}
 
 	stats, err := clistats.New()
 	if err != nil {
@@ -103,6 +110,11 @@ func (p *StatsTicker) AddToTotal(delta int64) {
 	p.stats.IncrementCounter("total", int(delta))
 }
 
+// SetShard sets the shard of the targets scanned by this instance
+func (p *StatsTicker) SetShard(shard string) {
+	p.stats.AddStatic("shard", shard)
+}
+
 // IncrementRequests increments the requests counter by 1.
 func (p *StatsTicker) IncrementRequests() {
 	p.stats.IncrementCounter("requests", 1)
@@ -125,6 +137,16 @@ func (p *StatsTicker) IncrementFailedRequestsBy(count int64) {
 	p.stats.IncrementCounter("errors", int(count))
 }
 
+// RecordLatency records the latency of a request made to a host.
+func (p *StatsTicker) RecordLatency(host string, duration time.Duration) {
+	p.latency.record(host, duration)
+}
+
+// SlowestHosts returns the hosts with the highest p95 latency.
+func (p *StatsTicker) SlowestHosts(count int) []HostLatency {
+	return p.latency.slowest(count)
+}
+
 func printCallback(stats clistats.StatisticsClient) {
 	builder := &strings.Builder{}
 	builder.WriteRune('[')
@@ -139,6 +161,10 @@ func printCallback(stats clistats.StatisticsClient) {
 	hosts, _ := stats.GetStatic("hosts")
 	builder.WriteString(" | Hosts: ")
 	builder.WriteString(clistats.String(hosts))
+	if shard, ok := stats.GetStatic("shard"); ok {
+		builder.WriteString(" | Shard: ")
+		builder.WriteString(clistats.String(shard))
+	}
 
 	requests, _ := stats.GetCounter("requests")
 	total, _ := stats.GetCounter("total")
@@ -183,6 +209,9 @@ func (p *StatsTicker) getMetrics() map[string]interface{} {
 	results["templates"] = clistats.String(templates)
 	hosts, _ := p.stats.GetStatic("hosts")
 	results["hosts"] = clistats.String(hosts)
+	if shard, ok := p.stats.GetStatic("shard"); ok {
+		results["shard"] = clistats.String(shard)
+	}
 	matched, _ := p.stats.GetCounter("matched")
 	results["matched"] = clistats.String(matched)
 	requests, _ := p.stats.GetCounter("requests")
@@ -0,0 +1,81 @@
+// Package clientcert loads client certificates used for mutual TLS
+// authentication with the scanned targets.
+package clientcert
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// Files contains the files of a client certificate
+type Files struct {
+	// Cert is the PEM encoded client certificate file
+	Cert string `yaml:"cert"`
+	// Key is the PEM encoded private key file of the certificate
+	Key string `yaml:"key"`
+	// CA is the optional PEM encoded CA file to verify the server with
+	CA string `yaml:"ca"`
+}
+
+// Resolve returns the files with relative paths resolved from directory
+func (f Files) Resolve(directory string) Files {
+	resolve := func(path string) string {
+		if path == "" || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(directory, path)
+	}
+	return Files{Cert: resolve(f.Cert), Key: resolve(f.Key), CA: resolve(f.CA)}
+}
+
+// Certificate is a loaded client certificate
+type Certificate struct {
+	files       Files
+	certificate tls.Certificate
+	rootCAs     *x509.CertPool
+}
+
+// Load loads and validates a client certificate from its files
+func Load(files Files) (*Certificate, error) {
+	if files.Cert == "" || files.Key == "" {
+		return nil, errors.New("client certificate and key must be provided together")
+	}
+	certificate, err := tls.LoadX509KeyPair(files.Cert, files.Key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not load client certificate %s with key %s", files.Cert, files.Key)
+	}
+	loaded := &Certificate{files: files, certificate: certificate}
+
+	if files.CA != "" {
+		data, err := ioutil.ReadFile(files.CA)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read client ca file")
+		}
+		loaded.rootCAs = x509.NewCertPool()
+		if !loaded.rootCAs.AppendCertsFromPEM(data) {
+			return nil, errors.Errorf("no certificates found in client ca file %s", files.CA)
+		}
+	}
+	return loaded, nil
+}
+
+// Hash returns a key identifying the certificate for client pooling
+func (c *Certificate) Hash() string {
+	return c.files.Cert + "|" + c.files.Key + "|" + c.files.CA
+}
+
+// Config returns a tls configuration presenting the certificate. The server
+// is only verified if a CA file was provided.
+func (c *Certificate) Config(serverName string) *tls.Config {
+	return &tls.Config{
+		Certificates:       []tls.Certificate{c.certificate},
+		RootCAs:            c.rootCAs,
+		ServerName:         serverName,
+		InsecureSkipVerify: c.rootCAs == nil,
+		Renegotiation:      tls.RenegotiateOnceAsClient,
+	}
+}
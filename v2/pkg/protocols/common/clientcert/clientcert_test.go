@@ -0,0 +1,42 @@
+package clientcert
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaklang/nuclei/v2/internal/testutils/certificates"
+)
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nuclei-clientcert-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(dir)
+
+	generated, err := certificates.Generate(dir)
+	require.Nil(t, err, "could not generate certificates")
+
+	certificate, err := Load(Files{Cert: generated.ClientCertFile, Key: generated.ClientKeyFile, CA: generated.CAFile})
+	require.Nil(t, err, "could not load client certificate")
+	config := certificate.Config("127.0.0.1")
+	require.Len(t, config.Certificates, 1, "could not get client certificate")
+	require.False(t, config.InsecureSkipVerify, "skipped verification with client ca")
+
+	_, err = Load(Files{Cert: generated.ClientCertFile})
+	require.NotNil(t, err, "loaded client certificate without key")
+
+	otherDir := filepath.Join(dir, "other")
+	require.Nil(t, os.Mkdir(otherDir, 0755), "could not create directory")
+	other, err := certificates.Generate(otherDir)
+	require.Nil(t, err, "could not generate certificates")
+	_, err = Load(Files{Cert: generated.ClientCertFile, Key: other.ClientKeyFile})
+	require.NotNil(t, err, "loaded client certificate with mismatched key")
+
+	_, err = Load(Files{Cert: generated.ClientCertFile, Key: generated.ClientKeyFile, CA: generated.ClientKeyFile})
+	require.NotNil(t, err, "loaded client ca without certificates")
+
+	resolved := Files{Cert: "client.pem", Key: "/keys/client-key.pem"}.Resolve("/templates/http")
+	require.Equal(t, Files{Cert: "/templates/http/client.pem", Key: "/keys/client-key.pem"}, resolved, "could not resolve relative paths")
+}
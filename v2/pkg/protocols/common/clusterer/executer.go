@@ -1,6 +1,8 @@
 package clusterer
 
 import (
+	"time"
+
 	"github.com/projectdiscovery/gologger"
 	"github.com/yaklang/nuclei/v2/pkg/operators"
 	"github.com/yaklang/nuclei/v2/pkg/output"
@@ -35,6 +37,7 @@ func NewExecuter(requests []*templates.Template, options *protocols.ExecuterOpti
 		options:  options,
 		requests: requests[0].RequestsHTTP[0],
 	}
+	executer.requests.SetClustered()
 	for _, req := range requests {
 		executer.operators = append(executer.operators, &clusteredOperator{
 			templateID:   req.ID,
@@ -60,50 +63,114 @@ func (e *Executer) Requests() int {
 
 // Execute executes the protocol group and returns true or false if results were found.
 func (e *Executer) Execute(input string) (bool, error) {
-	var results bool
+	var results, responded bool
 
 	previous := make(map[string]interface{})
-	dynamicValues := make(map[string]interface{})
+	dynamicValues := e.options.Options.VarsMap()
+	matchedTemplates := make(map[string]struct{})
 	err := e.requests.ExecuteWithResults(input, dynamicValues, previous, func(event *output.InternalWrappedEvent) {
+		responded = true
 		for _, operator := range e.operators {
+			if e.stopped(operator, matchedTemplates) {
+				continue
+			}
 			result, matched := operator.operator.Execute(event.InternalEvent, e.requests.Match, e.requests.Extract)
 			if matched && result != nil {
+				matchedTemplates[operator.templateID] = struct{}{}
 				event.OperatorsResult = result
 				event.InternalEvent["template-id"] = operator.templateID
 				event.InternalEvent["template-path"] = operator.templatePath
 				event.InternalEvent["template-info"] = operator.templateInfo
 				event.Results = e.requests.MakeResultEvent(event)
 				results = true
+				allowed := make([]*output.ResultEvent, 0, len(event.Results))
 				for _, r := range event.Results {
+					if e.options.ResultCap.Allow(r.TemplateID) {
+						allowed = append(allowed, r)
+					}
+				}
+				if len(allowed) > 0 && e.options.Options.StoreResponse {
+					if err := output.StoreResponse(e.options.Options.StoreResponseDir, event); err != nil {
+						gologger.Warning().Msgf("Could not store response: %s", err)
+					}
+				}
+				for _, r := range allowed {
 					if e.options.IssuesClient != nil {
 						if err := e.options.IssuesClient.CreateIssue(r); err != nil {
 							gologger.Warning().Msgf("Could not create issue on tracker: %s", err)
 						}
 					}
+					if e.options.Options.MatcherStatus {
+						matched := true
+						r.MatcherStatus = &matched
+					}
 					_ = e.options.Output.Write(r)
 					e.options.Progress.IncrementMatched()
 				}
 			}
 		}
 	})
+	if e.options.Options.MatcherStatus && responded && err == nil {
+		for _, operator := range e.operators {
+			if _, ok := matchedTemplates[operator.templateID]; !ok {
+				e.writeFailure(input, operator)
+			}
+		}
+	}
 	return results, err
 }
 
+// writeFailure writes an event with a false matcher status for an input a
+// template of the cluster did not match. It is only written to the output.
+func (e *Executer) writeFailure(input string, operator *clusteredOperator) {
+	matched := false
+	_ = e.options.Output.Write(&output.ResultEvent{
+		TemplateID:    operator.templateID,
+		TemplatePath:  operator.templatePath,
+		Info:          operator.templateInfo,
+		Type:          e.requests.EventType(),
+		Host:          input,
+		Matched:       input,
+		Timestamp:     time.Now(),
+		MatcherStatus: &matched,
+	})
+}
+
 // ExecuteWithResults executes the protocol requests and returns results instead of writing them.
 func (e *Executer) ExecuteWithResults(input string, callback protocols.OutputEventCallback) error {
-	dynamicValues := make(map[string]interface{})
+	dynamicValues := e.options.Options.VarsMap()
+	matchedTemplates := make(map[string]struct{})
 	err := e.requests.ExecuteWithResults(input, dynamicValues, nil, func(event *output.InternalWrappedEvent) {
 		for _, operator := range e.operators {
+			if e.stopped(operator, matchedTemplates) {
+				continue
+			}
 			result, matched := operator.operator.Execute(event.InternalEvent, e.requests.Match, e.requests.Extract)
 			if matched && result != nil {
+				matchedTemplates[operator.templateID] = struct{}{}
 				event.OperatorsResult = result
 				event.InternalEvent["template-id"] = operator.templateID
 				event.InternalEvent["template-path"] = operator.templatePath
 				event.InternalEvent["template-info"] = operator.templateInfo
 				event.Results = e.requests.MakeResultEvent(event)
+				if e.options.Options.StoreResponse {
+					if err := output.StoreResponse(e.options.Options.StoreResponseDir, event); err != nil {
+						gologger.Warning().Msgf("Could not store response: %s", err)
+					}
+				}
 				callback(event)
 			}
 		}
 	})
 	return err
 }
+
+// stopped returns true if a template of the cluster already matched the
+// input and must stop at the first match.
+func (e *Executer) stopped(operator *clusteredOperator, matchedTemplates map[string]struct{}) bool {
+	if !e.options.Options.StopAtFirstMatch {
+		return false
+	}
+	_, ok := matchedTemplates[operator.templateID]
+	return ok
+}
@@ -0,0 +1,66 @@
+package clusterer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaklang/nuclei/v2/internal/testutils"
+	"github.com/yaklang/nuclei/v2/pkg/operators"
+	"github.com/yaklang/nuclei/v2/pkg/operators/matchers"
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	httpProtocol "github.com/yaklang/nuclei/v2/pkg/protocols/http"
+	"github.com/yaklang/nuclei/v2/pkg/templates"
+)
+
+func TestClusterExecuterMatcherStatus(t *testing.T) {
+	options := *testutils.DefaultOptions
+	options.MatcherStatus = true
+
+	testutils.Init(&options)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("nuclei-info-detection"))
+	}))
+	defer ts.Close()
+
+	executerOpts := testutils.NewMockExecuterOptions(&options, &testutils.TemplateInfo{
+		ID:   "testing-cluster",
+		Info: map[string]interface{}{"severity": "info", "name": "test"},
+	})
+	var events []*output.ResultEvent
+	executerOpts.Output.(*testutils.MockOutputWriter).WriteCallback = func(o *output.ResultEvent) {
+		events = append(events, o)
+	}
+
+	newTemplate := func(id, word string) *templates.Template {
+		request := &httpProtocol.Request{
+			Path:   []string{"{{BaseURL}}"},
+			Method: "GET",
+			Operators: operators.Operators{
+				Matchers: []*matchers.Matcher{{Type: "word", Words: []string{word}}},
+			},
+		}
+		require.Nil(t, request.Operators.Compile(), "could not compile operators")
+		request.CompiledOperators = &request.Operators
+		return &templates.Template{ID: id, RequestsHTTP: []*httpProtocol.Request{request}}
+	}
+	executer := NewExecuter([]*templates.Template{
+		newTemplate("testing-matched", "nuclei-info-detection"),
+		newTemplate("testing-not-matched", "nothing-interesting"),
+	}, executerOpts)
+	require.Nil(t, executer.Compile(), "could not compile executer")
+
+	found, err := executer.Execute(ts.URL)
+	require.Nil(t, err, "could not execute cluster")
+	require.True(t, found, "could not match cluster")
+	require.Len(t, events, 2, "could not write matcher status events")
+
+	statuses := make(map[string]bool)
+	for _, event := range events {
+		require.NotNil(t, event.MatcherStatus, "could not get matcher status of %s", event.TemplateID)
+		statuses[event.TemplateID] = *event.MatcherStatus
+	}
+	require.Equal(t, map[string]bool{"testing-matched": true, "testing-not-matched": false}, statuses, "could not get matcher statuses")
+}
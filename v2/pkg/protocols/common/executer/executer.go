@@ -2,10 +2,13 @@ package executer
 
 import (
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/projectdiscovery/gologger"
 	"github.com/yaklang/nuclei/v2/pkg/output"
 	"github.com/yaklang/nuclei/v2/pkg/protocols"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/generators"
 )
 
 // Executer executes a group of requests for a protocol
@@ -15,6 +18,7 @@ type Executer struct {
 }
 
 var _ protocols.Executer = &Executer{}
+var _ protocols.ValuesExecuter = &Executer{}
 
 // NewExecuter creates a new request executer for list of requests
 func NewExecuter(requests []protocols.Request, options *protocols.ExecuterOptions) *Executer {
@@ -43,14 +47,27 @@ func (e *Executer) Requests() int {
 
 // Execute executes the protocol group and returns true or false if results were found.
 func (e *Executer) Execute(input string) (bool, error) {
-	var results bool
+	return e.ExecuteWithValues(input, nil)
+}
+
+// ExecuteWithValues executes the protocol group with initial dynamic values,
+// which override the variables of the template and of the command line.
+func (e *Executer) ExecuteWithValues(input string, values map[string]interface{}) (bool, error) {
+	var results, responded, failed bool
 
-	dynamicValues := make(map[string]interface{})
+	dynamicValues := e.initialValues(input, values)
 	previous := make(map[string]interface{})
+	mutex := &sync.Mutex{}
 	for _, req := range e.requests {
 		req := req
 
 		err := req.ExecuteWithResults(input, dynamicValues, previous, func(event *output.InternalWrappedEvent) {
+			responded = true
+			if event.OperatorsResult != nil && len(event.OperatorsResult.DynamicValues) > 0 {
+				mutex.Lock()
+				dynamicValues = generators.MergeMaps(dynamicValues, event.OperatorsResult.DynamicValues)
+				mutex.Unlock()
+			}
 			ID := req.GetID()
 			if ID != "" {
 				builder := &strings.Builder{}
@@ -65,33 +82,84 @@ func (e *Executer) Execute(input string) (bool, error) {
 			if event.OperatorsResult == nil {
 				return
 			}
+			allowed := make([]*output.ResultEvent, 0, len(event.Results))
 			for _, result := range event.Results {
+				results = true
+				if e.options.ResultCap.Allow(result.TemplateID) {
+					allowed = append(allowed, result)
+				}
+			}
+			if len(allowed) > 0 && e.options.Options.StoreResponse {
+				if err := output.StoreResponse(e.options.Options.StoreResponseDir, event); err != nil {
+					gologger.Warning().Msgf("Could not store response: %s", err)
+				}
+			}
+			for _, result := range allowed {
 				if e.options.IssuesClient != nil {
 					if err := e.options.IssuesClient.CreateIssue(result); err != nil {
 						gologger.Warning().Msgf("Could not create issue on tracker: %s", err)
 					}
 				}
-				results = true
+				if e.options.Options.MatcherStatus {
+					matched := true
+					result.MatcherStatus = &matched
+				}
 				_ = e.options.Output.Write(result)
 				e.options.Progress.IncrementMatched()
 			}
 		})
 		if err != nil {
+			failed = true
 			gologger.Warning().Msgf("[%s] Could not execute request for %s: %s\n", e.options.TemplateID, input, err)
 		}
 	}
+	if e.options.Options.MatcherStatus && !results && responded && !failed {
+		e.writeFailure(input)
+	}
 	return results, nil
 }
 
+// writeFailure writes an event with a false matcher status for an input the
+// template did not match. It is only written to the output, not reported.
+func (e *Executer) writeFailure(input string) {
+	var resultType string
+	if len(e.requests) > 0 {
+		resultType = e.requests[0].EventType()
+	}
+	matched := false
+	_ = e.options.Output.Write(&output.ResultEvent{
+		TemplateID:    e.options.TemplateID,
+		TemplatePath:  e.options.TemplatePath,
+		Info:          e.options.TemplateInfo,
+		Type:          resultType,
+		Host:          input,
+		Matched:       input,
+		Timestamp:     time.Now(),
+		MatcherStatus: &matched,
+	})
+}
+
 // ExecuteWithResults executes the protocol requests and returns results instead of writing them.
 func (e *Executer) ExecuteWithResults(input string, callback protocols.OutputEventCallback) error {
-	dynamicValues := make(map[string]interface{})
+	return e.ExecuteWithValuesAndResults(input, nil, callback)
+}
+
+// ExecuteWithValuesAndResults executes the protocol requests with initial
+// dynamic values and returns results instead of writing them.
+func (e *Executer) ExecuteWithValuesAndResults(input string, values map[string]interface{}, callback protocols.OutputEventCallback) error {
+	dynamicValues := e.initialValues(input, values)
 	previous := make(map[string]interface{})
+	mutex := &sync.Mutex{}
 
 	for _, req := range e.requests {
 		req := req
 
 		err := req.ExecuteWithResults(input, dynamicValues, previous, func(event *output.InternalWrappedEvent) {
+			if event.OperatorsResult != nil && len(event.OperatorsResult.DynamicValues) > 0 {
+				mutex.Lock()
+				dynamicValues = generators.MergeMaps(dynamicValues, event.OperatorsResult.DynamicValues)
+				mutex.Unlock()
+			}
 			ID := req.GetID()
 			if ID != "" {
 				builder := &strings.Builder{}
@@ -106,6 +174,11 @@ func (e *Executer) ExecuteWithResults(input string, callback protocols.OutputEve
 			if event.OperatorsResult == nil {
 				return
 			}
+			if e.options.Options.StoreResponse {
+				if err := output.StoreResponse(e.options.Options.StoreResponseDir, event); err != nil {
+					gologger.Warning().Msgf("Could not store response: %s", err)
+				}
+			}
 			callback(event)
 		})
 		if err != nil {
@@ -114,3 +187,11 @@ func (e *Executer) ExecuteWithResults(input string, callback protocols.OutputEve
 	}
 	return nil
 }
+
+// initialValues returns the dynamic values the requests start from, the
+// variables of the template overridden by the command line variables and
+// the values passed to the executer.
+func (e *Executer) initialValues(input string, values map[string]interface{}) map[string]interface{} {
+	dynamicValues := generators.MergeMaps(e.options.Variables.Evaluate(input), e.options.Options.VarsMap())
+	return generators.MergeMaps(dynamicValues, values)
+}
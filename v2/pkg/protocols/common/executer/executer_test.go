@@ -0,0 +1,346 @@
+package executer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaklang/nuclei/v2/internal/testutils"
+	"github.com/yaklang/nuclei/v2/pkg/operators"
+	"github.com/yaklang/nuclei/v2/pkg/operators/extractors"
+	"github.com/yaklang/nuclei/v2/pkg/operators/matchers"
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/yaklang/nuclei/v2/pkg/protocols"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/resultcap"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/variables"
+	httpProtocol "github.com/yaklang/nuclei/v2/pkg/protocols/http"
+	"go.uber.org/atomic"
+	"gopkg.in/yaml.v2"
+)
+
+func TestExecuterMaxResults(t *testing.T) {
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-max-results"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("nuclei-info-detection"))
+	}))
+	defer ts.Close()
+
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "info", "name": "test"},
+	})
+	written := &atomic.Int64{}
+	executerOpts.Output.(*testutils.MockOutputWriter).WriteCallback = func(o *output.ResultEvent) {
+		written.Inc()
+	}
+	executerOpts.ResultCap = resultcap.New(5)
+
+	request := &httpProtocol.Request{
+		ID:     templateID,
+		Path:   []string{"{{BaseURL}}"},
+		Method: "GET",
+		Operators: operators.Operators{
+			Matchers: []*matchers.Matcher{{Type: "word", Words: []string{"nuclei-info-detection"}}},
+		},
+	}
+	executer := NewExecuter([]protocols.Request{request}, executerOpts)
+	err := executer.Compile()
+	require.Nil(t, err, "could not compile executer")
+
+	hosts := 50
+	matched := &atomic.Int64{}
+	wg := &sync.WaitGroup{}
+	for i := 0; i < hosts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			found, err := executer.Execute(fmt.Sprintf("%s/?host=%d", ts.URL, i))
+			require.Nil(t, err, "could not execute template")
+			if found {
+				matched.Inc()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	require.Equal(t, int64(hosts), matched.Load(), "suppressed results were not reported as matches")
+	require.Equal(t, int64(5), written.Load(), "could not cap written results")
+
+	stats := executerOpts.ResultCap.Suppressed()
+	require.Len(t, stats, 1, "could not get suppressed stats")
+	require.Equal(t, resultcap.Stats{TemplateID: templateID, Matched: int64(hosts), Suppressed: int64(hosts - 5)}, stats[0], "could not get correct suppressed stats")
+
+	var events int
+	err = executer.ExecuteWithResults(ts.URL, func(event *output.InternalWrappedEvent) {
+		events++
+	})
+	require.Nil(t, err, "could not execute template with results")
+	require.Equal(t, 1, events, "cap affected internal results")
+	require.Equal(t, int64(5), written.Load(), "internal results were written")
+}
+
+func TestExecuterStoreResponse(t *testing.T) {
+	directory, err := ioutil.TempDir("", "nuclei-store-response-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(directory)
+
+	options := *testutils.DefaultOptions
+	options.StoreResponse = true
+	options.StoreResponseDir = directory
+
+	testutils.Init(&options)
+	templateID := "testing-store-response"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("nuclei-info-detection"))
+	}))
+	defer ts.Close()
+
+	executerOpts := testutils.NewMockExecuterOptions(&options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "info", "name": "test"},
+	})
+	executerOpts.ResultCap = resultcap.New(2)
+
+	request := &httpProtocol.Request{
+		ID:     templateID,
+		Path:   []string{"{{BaseURL}}"},
+		Method: "GET",
+		Operators: operators.Operators{
+			Matchers: []*matchers.Matcher{{Type: "word", Words: []string{"nuclei-info-detection"}}},
+		},
+	}
+	executer := NewExecuter([]protocols.Request{request}, executerOpts)
+	err = executer.Compile()
+	require.Nil(t, err, "could not compile executer")
+
+	for i := 0; i < 5; i++ {
+		_, err := executer.Execute(fmt.Sprintf("%s/?host=%d", ts.URL, i))
+		require.Nil(t, err, "could not execute template")
+	}
+	files, err := ioutil.ReadDir(directory)
+	require.Nil(t, err, "could not read store response directory")
+	require.Len(t, files, 2, "stored responses of suppressed results")
+
+	var stored string
+	err = executer.ExecuteWithResults(ts.URL+"/?host=workflow", func(event *output.InternalWrappedEvent) {
+		stored = event.Results[0].StoredResponsePath
+	})
+	require.Nil(t, err, "could not execute template with results")
+	require.NotEmpty(t, stored, "could not store response of internal results")
+	require.FileExists(t, stored, "could not find stored response of internal results")
+}
+
+func TestExecuterVariables(t *testing.T) {
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-variables"
+
+	var received []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.URL.Path+" "+r.Header.Get("X-Token"))
+		if r.URL.Path == "/login" {
+			_, _ = w.Write([]byte("token=extracted"))
+		}
+	}))
+	defer ts.Close()
+
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "info", "name": "test"},
+	})
+	executerOpts.Variables = &variables.Variable{}
+	err := yaml.Unmarshal([]byte("path: /api\ntoken: \"{{md5(path)}}\"\n"), executerOpts.Variables)
+	require.Nil(t, err, "could not unmarshal variables")
+
+	request := &httpProtocol.Request{
+		ID:      templateID,
+		Path:    []string{"{{BaseURL}}{{path}}", "{{BaseURL}}/login", "{{BaseURL}}/{{token}}"},
+		Method:  "GET",
+		Headers: map[string]string{"X-Token": "{{token}}"},
+		Operators: operators.Operators{
+			Extractors: []*extractors.Extractor{{Type: "regex", Name: "token", Internal: true, RegexGroup: 1, Regex: []string{"token=([a-z]+)"}}},
+		},
+	}
+	executer := NewExecuter([]protocols.Request{request}, executerOpts)
+	err = executer.Compile()
+	require.Nil(t, err, "could not compile executer")
+
+	_, err = executer.Execute(ts.URL)
+	require.Nil(t, err, "could not execute template")
+	require.Equal(t, []string{
+		"/api 944da21c517df0c48dfbef7af28f3093",
+		"/login 944da21c517df0c48dfbef7af28f3093",
+		"/extracted extracted",
+	}, received, "could not use variables in requests")
+}
+
+func TestExecuterDynamicValues(t *testing.T) {
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-dynamic-values"
+
+	var received []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.URL.Path+" "+r.Header.Get("Cookie"))
+		if r.URL.Path == "/login" {
+			_, _ = w.Write([]byte("session=abc123"))
+		}
+	}))
+	defer ts.Close()
+
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "info", "name": "test"},
+	})
+	login := &httpProtocol.Request{
+		Path:   []string{"{{BaseURL}}/login"},
+		Method: "GET",
+		Operators: operators.Operators{
+			Extractors: []*extractors.Extractor{{Type: "regex", Name: "session", Internal: true, Regex: []string{"session=[a-z0-9]+"}}},
+		},
+	}
+	profile := &httpProtocol.Request{
+		Path:    []string{"{{BaseURL}}/profile"},
+		Method:  "GET",
+		Headers: map[string]string{"Cookie": "{{session}}"},
+	}
+	executer := NewExecuter([]protocols.Request{login, profile}, executerOpts)
+	err := executer.Compile()
+	require.Nil(t, err, "could not compile executer")
+
+	_, err = executer.Execute(ts.URL)
+	require.Nil(t, err, "could not execute template")
+	require.Equal(t, []string{"/login ", "/profile session=abc123"}, received, "could not pass dynamic values to next request")
+}
+
+func TestExecuterWithValues(t *testing.T) {
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-values"
+
+	var received []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.URL.Path)
+	}))
+	defer ts.Close()
+
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "info", "name": "test"},
+	})
+	executerOpts.Variables = &variables.Variable{}
+	err := yaml.Unmarshal([]byte("path: /default\nversion: v1\n"), executerOpts.Variables)
+	require.Nil(t, err, "could not unmarshal variables")
+
+	request := &httpProtocol.Request{
+		Path:   []string{"{{BaseURL}}{{path}}/{{version}}"},
+		Method: "GET",
+	}
+	executer := NewExecuter([]protocols.Request{request}, executerOpts)
+	err = executer.Compile()
+	require.Nil(t, err, "could not compile executer")
+
+	_, err = executer.ExecuteWithValues(ts.URL, map[string]interface{}{"path": "/admin"})
+	require.Nil(t, err, "could not execute template with values")
+	require.Equal(t, []string{"/admin/v1"}, received, "could not use values in requests")
+}
+
+func TestExecuterCLIVars(t *testing.T) {
+	options := *testutils.DefaultOptions
+	options.Vars = []string{"token=abc", "env=staging"}
+
+	testutils.Init(&options)
+	templateID := "testing-cli-vars"
+
+	var received []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.URL.Path+" "+r.Header.Get("X-Token"))
+	}))
+	defer ts.Close()
+
+	executerOpts := testutils.NewMockExecuterOptions(&options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "info", "name": "test"},
+	})
+	executerOpts.Variables = &variables.Variable{}
+	err := yaml.Unmarshal([]byte("token: default\n"), executerOpts.Variables)
+	require.Nil(t, err, "could not unmarshal variables")
+
+	request := &httpProtocol.Request{
+		Path:    []string{"{{BaseURL}}/{{env}}"},
+		Method:  "GET",
+		Headers: map[string]string{"X-Token": "{{token}}"},
+	}
+	executer := NewExecuter([]protocols.Request{request}, executerOpts)
+	err = executer.Compile()
+	require.Nil(t, err, "could not compile executer")
+
+	_, err = executer.Execute(ts.URL)
+	require.Nil(t, err, "could not execute template")
+	_, err = executer.ExecuteWithValues(ts.URL, map[string]interface{}{"env": "production"})
+	require.Nil(t, err, "could not execute template with values")
+	require.Equal(t, []string{"/staging abc", "/production abc"}, received, "could not use command line variables")
+}
+
+func TestExecuterMatcherStatus(t *testing.T) {
+	options := *testutils.DefaultOptions
+	options.MatcherStatus = true
+
+	testutils.Init(&options)
+	templateID := "testing-matcher-status"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("nothing-interesting"))
+	}))
+
+	executerOpts := testutils.NewMockExecuterOptions(&options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "info", "name": "test"},
+	})
+	var events []*output.ResultEvent
+	executerOpts.Output.(*testutils.MockOutputWriter).WriteCallback = func(o *output.ResultEvent) {
+		events = append(events, o)
+	}
+
+	request := &httpProtocol.Request{
+		ID:     templateID,
+		Path:   []string{"{{BaseURL}}"},
+		Method: "GET",
+		Operators: operators.Operators{
+			Matchers: []*matchers.Matcher{{Type: "word", Words: []string{"nuclei-info-detection"}}},
+		},
+	}
+	executer := NewExecuter([]protocols.Request{request}, executerOpts)
+	err := executer.Compile()
+	require.Nil(t, err, "could not compile executer")
+
+	found, err := executer.Execute(ts.URL)
+	require.Nil(t, err, "could not execute template")
+	require.False(t, found, "failure event was reported as a match")
+	require.Len(t, events, 1, "could not write failure event")
+	require.Equal(t, templateID, events[0].TemplateID, "could not get failure template")
+	require.Equal(t, "http", events[0].Type, "could not get failure type")
+	require.Equal(t, ts.URL, events[0].Matched, "could not get failure input")
+	require.NotNil(t, events[0].MatcherStatus, "could not get matcher status")
+	require.False(t, *events[0].MatcherStatus, "could not get false matcher status")
+
+	ts.Close()
+	events = nil
+	found, err = executer.Execute(ts.URL)
+	require.Nil(t, err, "could not execute template")
+	require.False(t, found, "errored host was reported as a match")
+	require.Empty(t, events, "failure event was written for errored host")
+}
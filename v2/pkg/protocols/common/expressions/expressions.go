@@ -9,7 +9,14 @@ import (
 	"github.com/yaklang/nuclei/v2/pkg/protocols/common/replacer"
 )
 
-var templateExpressionRegex = regexp.MustCompile(`(?m)\{\{[^}]+\}\}["'\)\}]*`)
+var (
+	templateExpressionRegex = regexp.MustCompile(`(?m)\{\{[^}]+\}\}["'\)\}]*`)
+	randomStringRegex       = regexp.MustCompile(`\{\{(randstr(?:_[0-9]+)?)\}\}`)
+	randomHelperRegex       = regexp.MustCompile(`\{\{(rand_[a-z_]+\([^{}]*\))\}\}`)
+)
+
+// randomStringLength is the length of the randstr values
+const randomStringLength = 27
 
 // Evaluate checks if the match contains a dynamic variable, for each
 // found one we will check if it's an expression and can
@@ -37,3 +44,33 @@ func Evaluate(data string, base map[string]interface{}) (string, error) {
 	// Replacer dynamic values if any in raw request and parse  it
 	return replacer.Replace(data, dynamicValues), nil
 }
+
+// RandomValues returns a new random value for each of the randstr and
+// randstr_N markers and of the rand_* helper expressions found in the data.
+// The helper values are keyed by their expression, so the same expression
+// gets the same value in all the data.
+func RandomValues(data ...string) map[string]interface{} {
+	values := make(map[string]interface{})
+	for _, item := range data {
+		for _, match := range randomStringRegex.FindAllStringSubmatch(item, -1) {
+			if _, ok := values[match[1]]; !ok {
+				values[match[1]] = dsl.RandomAlphanumeric(randomStringLength)
+			}
+		}
+		for _, match := range randomHelperRegex.FindAllStringSubmatch(item, -1) {
+			if _, ok := values[match[1]]; ok {
+				continue
+			}
+			compiled, err := govaluate.NewEvaluableExpressionWithFunctions(match[1], dsl.HelperFunctions())
+			if err != nil {
+				continue
+			}
+			result, err := compiled.Evaluate(nil)
+			if err != nil {
+				continue
+			}
+			values[match[1]] = result
+		}
+	}
+	return values
+}
@@ -1,6 +1,7 @@
 package expressions
 
 import (
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -17,6 +18,7 @@ func TestEvaluate(t *testing.T) {
 		{input: "{{hex_encode(Item)}}", expected: "50494e47", extra: map[string]interface{}{"Item": "PING"}},
 		{input: "{{hex_encode(Item)}}\r\n", expected: "50494e47\r\n", extra: map[string]interface{}{"Item": "PING"}},
 		{input: "{{someTestData}}{{hex_encode('PING')}}", expected: "{{someTestData}}50494e47", extra: map[string]interface{}{}},
+		{input: "sig={{hmac_sha256('secret', Item)}}&hash={{md5(Item)}}", expected: "sig=0329a06b62cd16b33eb6792be8c60b158d89a2ee3a876fce9a881ebb488c0914&hash=098f6bcd4621d373cade4e832627b4f6", extra: map[string]interface{}{"Item": "test"}},
 		{input: `_IWP_JSON_PREFIX_{{base64("{\"iwp_action\":\"add_site\",\"params\":{\"username\":\"\"}}")}}`, expected: "_IWP_JSON_PREFIX_eyJpd3BfYWN0aW9uIjoiYWRkX3NpdGUiLCJwYXJhbXMiOnsidXNlcm5hbWUiOiIifX0=", extra: map[string]interface{}{}},
 	}
 	for _, item := range items {
@@ -26,3 +28,21 @@ func TestEvaluate(t *testing.T) {
 		require.Equal(t, item.expected, value, "could not get correct expression")
 	}
 }
+
+func TestRandomHelpers(t *testing.T) {
+	values := RandomValues("{{randstr}}/{{randstr_1}}", "{{randstr}}", "{{rand_int(1, 10)}}/{{rand_int(1, 10)}}")
+	require.Len(t, values, 3, "could not get random markers")
+	require.IsType(t, 0, values["rand_int(1, 10)"], "could not get random helper value")
+	require.Len(t, values["randstr"], randomStringLength, "could not get random string")
+	require.NotEqual(t, values["randstr"], values["randstr_1"], "could not get distinct random strings")
+
+	value, err := Evaluate("{{rand_int(1, 10)}}", map[string]interface{}{})
+	require.Nil(t, err, "could not evaluate rand_int helper")
+	number, err := strconv.Atoi(value)
+	require.Nil(t, err, "could not get random integer")
+	require.True(t, number >= 1 && number < 10, "could not get random integer in range")
+
+	value, err = Evaluate("{{rand_text_alphanumeric(8)}}", map[string]interface{}{})
+	require.Nil(t, err, "could not evaluate rand_text_alphanumeric helper")
+	require.Len(t, value, 8, "could not get random text of length")
+}
@@ -0,0 +1,49 @@
+package fingerprint
+
+// DefaultRules returns the builtin fingerprint rules used when no
+// fingerprints file is present in the templates directory.
+func DefaultRules() []*Rule {
+	return []*Rule{
+		{
+			Name:    "cloudflare",
+			WAF:     true,
+			CDN:     true,
+			Headers: map[string]string{"Server": `(?i)^cloudflare`, "CF-RAY": `.+`},
+			Cookies: []string{"__cfduid", "__cf_bm", "cf_clearance"},
+		},
+		{
+			Name:    "akamai",
+			WAF:     true,
+			CDN:     true,
+			Headers: map[string]string{"Server": `(?i)^akamaighost`, "X-Akamai-Transformed": `.+`},
+			Cookies: []string{"ak_bmsc", "bm_sz"},
+		},
+		{
+			Name:    "incapsula",
+			WAF:     true,
+			CDN:     true,
+			Headers: map[string]string{"X-Iinfo": `.+`, "X-CDN": `(?i)incapsula`},
+			Cookies: []string{"incap_ses_", "visid_incap_"},
+		},
+		{
+			Name:    "sucuri",
+			WAF:     true,
+			Headers: map[string]string{"Server": `(?i)^sucuri`, "X-Sucuri-ID": `.+`},
+		},
+		{
+			Name:    "aws-waf",
+			WAF:     true,
+			Cookies: []string{"aws-waf-token"},
+		},
+		{
+			Name:    "cloudfront",
+			CDN:     true,
+			Headers: map[string]string{"X-Amz-Cf-Id": `.+`, "Via": `(?i)cloudfront`},
+		},
+		{
+			Name:    "fastly",
+			CDN:     true,
+			Headers: map[string]string{"X-Fastly-Request-ID": `.+`, "X-Served-By": `(?i)^cache-`},
+		},
+	}
+}
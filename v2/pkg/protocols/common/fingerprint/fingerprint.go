@@ -0,0 +1,159 @@
+// Package fingerprint implements lightweight WAF and CDN detection for
+// hosts from the first responses received from them.
+package fingerprint
+
+import (
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// maxResponses is the number of responses per host used for detection
+const maxResponses = 3
+
+// Rule is a fingerprint rule for a WAF and/or CDN
+type Rule struct {
+	// Name is the name of the detected product
+	Name string `yaml:"name"`
+	// WAF marks the product as a web application firewall
+	WAF bool `yaml:"waf"`
+	// CDN marks the product as a content delivery network
+	CDN bool `yaml:"cdn"`
+	// Headers maps header names to regexes matched against their values
+	Headers map[string]string `yaml:"headers"`
+	// Cookies contains the name prefixes of cookies set by the product
+	Cookies []string `yaml:"cookies"`
+	// Status restricts the rule to responses with the status codes if set
+	Status []int `yaml:"status"`
+
+	headers map[string]*regexp.Regexp
+}
+
+// Result is the detected WAF and CDN for a host
+type Result struct {
+	WAF string
+	CDN string
+}
+
+// Fingerprinter detects the WAF and CDN of hosts
+type Fingerprinter struct {
+	rules []*Rule
+	hosts sync.Map
+}
+
+type hostState struct {
+	mutex     sync.Mutex
+	responses int
+	result    Result
+}
+
+// ReadRules reads fingerprint rules from a yaml file
+func ReadRules(file string) ([]*Rule, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open fingerprints file")
+	}
+	defer f.Close()
+
+	var rules []*Rule
+	if err := yaml.NewDecoder(f).Decode(&rules); err != nil {
+		return nil, errors.Wrap(err, "could not parse fingerprints file")
+	}
+	return rules, nil
+}
+
+// New creates a new fingerprinter compiling the rules
+func New(rules []*Rule) (*Fingerprinter, error) {
+	for _, rule := range rules {
+		if rule.Name == "" {
+			return nil, errors.New("no name specified for fingerprint")
+		}
+		rule.headers = make(map[string]*regexp.Regexp, len(rule.Headers))
+		for header, pattern := range rule.Headers {
+			compiled, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not compile header regex for %s", rule.Name)
+			}
+			rule.headers[http.CanonicalHeaderKey(header)] = compiled
+		}
+	}
+	return &Fingerprinter{rules: rules}, nil
+}
+
+// Observe runs detection on a response from a host if the host has not
+// been fingerprinted yet and returns the detection result for the host.
+func (f *Fingerprinter) Observe(host string, resp *http.Response) Result {
+	if f == nil {
+		return Result{}
+	}
+	value, _ := f.hosts.LoadOrStore(host, &hostState{})
+	state := value.(*hostState)
+
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	if state.responses >= maxResponses {
+		return state.result
+	}
+	state.responses++
+	for _, rule := range f.rules {
+		if !rule.match(resp) {
+			continue
+		}
+		if rule.WAF && state.result.WAF == "" {
+			state.result.WAF = rule.Name
+		}
+		if rule.CDN && state.result.CDN == "" {
+			state.result.CDN = rule.Name
+		}
+	}
+	if state.result.WAF != "" || state.result.CDN != "" {
+		state.responses = maxResponses
+	}
+	return state.result
+}
+
+// Annotate adds the detection result to an event map
+func (r Result) Annotate(data map[string]interface{}) {
+	data["waf"] = r.WAF
+	data["cdn"] = r.CDN
+}
+
+// match returns true if the response matches the rule
+func (r *Rule) match(resp *http.Response) bool {
+	if len(r.Status) > 0 {
+		var found bool
+		for _, status := range r.Status {
+			if status == resp.StatusCode {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for header, regex := range r.headers {
+		for _, value := range resp.Header.Values(header) {
+			if regex.MatchString(value) {
+				return true
+			}
+		}
+	}
+	if len(r.Cookies) > 0 {
+		for _, cookie := range resp.Cookies() {
+			cookieName := strings.ToLower(cookie.Name)
+			for _, name := range r.Cookies {
+				if strings.HasPrefix(cookieName, strings.ToLower(name)) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package fingerprint
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newResponse(status int, headers map[string]string) *http.Response {
+	resp := &http.Response{StatusCode: status, Header: make(http.Header)}
+	for k, v := range headers {
+		resp.Header.Add(k, v)
+	}
+	return resp
+}
+
+func TestFingerprintRulesFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nuclei-fingerprints-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "waf-cdn.yaml")
+	err = ioutil.WriteFile(file, []byte(`- name: example-waf
+  waf: true
+  status: [406]
+  headers:
+    server: (?i)^example
+- name: example-cdn
+  cdn: true
+  cookies:
+    - edge_
+`), 0644)
+	require.Nil(t, err, "could not write fingerprints file")
+
+	rules, err := ReadRules(file)
+	require.Nil(t, err, "could not read fingerprints file")
+	fingerprinter, err := New(rules)
+	require.Nil(t, err, "could not create fingerprinter")
+
+	result := fingerprinter.Observe("waf.example.com", newResponse(200, map[string]string{"Server": "Example"}))
+	require.Equal(t, Result{}, result, "matched waf rule with wrong status")
+	result = fingerprinter.Observe("waf.example.com", newResponse(406, map[string]string{"Server": "Example"}))
+	require.Equal(t, Result{WAF: "example-waf"}, result, "could not detect waf from status and header")
+
+	result = fingerprinter.Observe("cdn.example.com", newResponse(200, map[string]string{"Set-Cookie": "edge_1234=abc; Path=/"}))
+	require.Equal(t, Result{CDN: "example-cdn"}, result, "could not detect cdn from cookie prefix")
+
+	for i := 0; i < maxResponses; i++ {
+		result = fingerprinter.Observe("plain.example.com", newResponse(200, nil))
+	}
+	result = fingerprinter.Observe("plain.example.com", newResponse(406, map[string]string{"Server": "Example"}))
+	require.Equal(t, Result{}, result, "ran detection after the first responses")
+
+	data := make(map[string]interface{})
+	result.Annotate(data)
+	require.Equal(t, map[string]interface{}{"waf": "", "cdn": ""}, data, "could not annotate undetected host")
+}
@@ -3,7 +3,7 @@
 package generators
 
 import (
-	"errors"
+	"sort"
 )
 
 // Generator is the generator struct for generating payloads
@@ -22,13 +22,16 @@ const (
 	PitchFork
 	// ClusterBomb replaces variables with all possible combinations of values
 	ClusterBomb
+	// BatteringRam replaces all variables with the same value at a time.
+	BatteringRam
 )
 
 // StringToType is an table for conversion of attack type from string.
 var StringToType = map[string]Type{
-	"sniper":      Sniper,
-	"pitchfork":   PitchFork,
-	"clusterbomb": ClusterBomb,
+	"sniper":       Sniper,
+	"pitchfork":    PitchFork,
+	"clusterbomb":  ClusterBomb,
+	"batteringram": BatteringRam,
 }
 
 // New creates a new generator structure for payload generation
@@ -44,17 +47,6 @@ func New(payloads map[string]interface{}, payloadType Type, templatePath string)
 	}
 	generator.Type = payloadType
 	generator.payloads = compiled
-
-	// Validate the payload types
-	if payloadType == PitchFork {
-		var totalLength int
-		for v := range compiled {
-			if totalLength != 0 && totalLength != len(v) {
-				return nil, errors.New("pitchfork payloads must be of equal number")
-			}
-			totalLength = len(v)
-		}
-	}
 	return generator, nil
 }
 
@@ -74,6 +66,10 @@ func (g *Generator) NewIterator() *Iterator {
 	for name, values := range g.payloads {
 		payloads = append(payloads, &payloadIterator{name: name, values: values})
 	}
+	// Sort the payloads by name for a stable iteration order
+	sort.Slice(payloads, func(i, j int) bool {
+		return payloads[i].name < payloads[j].name
+	})
 	iterator := &Iterator{
 		Type:     g.Type,
 		payloads: payloads,
@@ -101,12 +97,17 @@ func (i *Iterator) Remaining() int {
 func (i *Iterator) Total() int {
 	count := 0
 	switch i.Type {
-	case Sniper:
+	case Sniper, BatteringRam:
 		for _, p := range i.payloads {
 			count += len(p.values)
 		}
 	case PitchFork:
-		count = len(i.payloads[0].values)
+		// pitchfork stops at the shortest payload list
+		for index, p := range i.payloads {
+			if index == 0 || len(p.values) < count {
+				count = len(p.values)
+			}
+		}
 	case ClusterBomb:
 		count = 1
 		for _, p := range i.payloads {
@@ -125,6 +126,8 @@ func (i *Iterator) Value() (map[string]interface{}, bool) {
 		return i.pitchforkValue()
 	case ClusterBomb:
 		return i.clusterbombValue()
+	case BatteringRam:
+		return i.batteringRamValue()
 	default:
 		return i.sniperValue()
 	}
@@ -149,6 +152,26 @@ func (i *Iterator) sniperValue() (map[string]interface{}, bool) {
 	return values, true
 }
 
+// batteringRamValue returns a map with the same payload value for all keywords
+func (i *Iterator) batteringRamValue() (map[string]interface{}, bool) {
+	if i.msbIterator >= len(i.payloads) {
+		return nil, false
+	}
+	payload := i.payloads[i.msbIterator]
+	if !payload.next() {
+		i.msbIterator++
+		return i.batteringRamValue()
+	}
+	value := payload.value()
+	values := make(map[string]interface{}, len(i.payloads))
+	for _, p := range i.payloads {
+		values[p.name] = value
+	}
+	payload.incrementPosition()
+	i.position++
+	return values, true
+}
+
 // pitchforkValue returns a map of keyword:value pairs in same index
 func (i *Iterator) pitchforkValue() (map[string]interface{}, bool) {
 	values := make(map[string]interface{}, len(i.payloads))
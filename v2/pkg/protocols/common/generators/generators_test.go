@@ -1,6 +1,7 @@
 package generators
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -79,3 +80,79 @@ func TestClusterbombGenerator(t *testing.T) {
 	}
 	require.Equal(t, 3, count, "could not get correct clusterbomb counts")
 }
+
+func TestBatteringRamGenerator(t *testing.T) {
+	usernames := []string{"admin", "root"}
+	passwords := []string{"toor"}
+
+	generator, err := New(map[string]interface{}{"username": usernames, "password": passwords}, BatteringRam, "")
+	require.Nil(t, err, "could not create generator")
+
+	iterator := generator.NewIterator()
+	require.Equal(t, 3, iterator.Total(), "could not get correct batteringram total")
+	var values []map[string]interface{}
+	for {
+		value, ok := iterator.Value()
+		if !ok {
+			break
+		}
+		values = append(values, value)
+	}
+	require.Equal(t, []map[string]interface{}{
+		{"username": "toor", "password": "toor"},
+		{"username": "admin", "password": "admin"},
+		{"username": "root", "password": "root"},
+	}, values, "could not get correct batteringram values")
+}
+
+func TestAttackTypesMultiplePayloads(t *testing.T) {
+	payloads := map[string]interface{}{
+		"a": []string{"a1", "a2", "a3"},
+		"b": []string{"b1", "b2"},
+		"c": []string{"c1", "c2", "c3", "c4"},
+	}
+	tests := []struct {
+		name     string
+		payloads []string
+		attack   Type
+		expected int
+	}{
+		{"pitchfork-2", []string{"a", "b"}, PitchFork, 2},
+		{"pitchfork-3", []string{"a", "b", "c"}, PitchFork, 2},
+		{"clusterbomb-2", []string{"a", "b"}, ClusterBomb, 6},
+		{"clusterbomb-3", []string{"a", "b", "c"}, ClusterBomb, 24},
+		{"batteringram-3", []string{"a", "b", "c"}, BatteringRam, 9},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			selected := make(map[string]interface{})
+			for _, name := range test.payloads {
+				selected[name] = payloads[name]
+			}
+			generator, err := New(selected, test.attack, "")
+			require.Nil(t, err, "could not create generator")
+
+			iterator := generator.NewIterator()
+			require.Equal(t, test.expected, iterator.Total(), "could not get correct total")
+			seen := make(map[string]struct{})
+			for {
+				value, ok := iterator.Value()
+				if !ok {
+					break
+				}
+				require.Len(t, value, len(test.payloads), "could not get value for all payloads")
+				key := fmt.Sprint(value)
+				require.NotContains(t, seen, key, "got duplicate combination")
+				seen[key] = struct{}{}
+				if test.attack == PitchFork {
+					index := value["a"].(string)[1:]
+					for name, v := range value {
+						require.Equal(t, name+index, v, "could not get positional pitchfork values")
+					}
+				}
+			}
+			require.Len(t, seen, test.expected, "could not iterate all combinations")
+			require.Equal(t, 0, iterator.Remaining(), "could not get remaining requests")
+		})
+	}
+}
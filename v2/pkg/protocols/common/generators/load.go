@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -58,3 +59,26 @@ func loadPayloadsFromFile(filepath string) ([]string, error) {
 	}
 	return lines, nil
 }
+
+// SandboxPath returns an error if the payload file is not inside one of
+// the directories once symlinks are resolved.
+func SandboxPath(path string, directories ...string) error {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return errors.Wrap(err, "could not resolve payload file")
+	}
+	for _, directory := range directories {
+		if directory == "" {
+			continue
+		}
+		resolvedDirectory, err := filepath.EvalSymlinks(directory)
+		if err != nil {
+			return errors.Wrap(err, "could not resolve sandbox directory")
+		}
+		rel, err := filepath.Rel(resolvedDirectory, resolved)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil
+		}
+	}
+	return errors.Errorf("payload file %s is outside of the allowed directories", path)
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/yaklang/nuclei/v2/pkg/operators"
 	"github.com/yaklang/nuclei/v2/pkg/output"
 	"github.com/yaklang/nuclei/v2/pkg/progress"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/resultcap"
 	"github.com/yaklang/nuclei/v2/pkg/reporting"
 	"github.com/valyala/fasttemplate"
 )
@@ -61,6 +62,8 @@ type Options struct {
 	Output output.Writer
 	// IssuesClient is a client for issue exporting
 	IssuesClient *reporting.Client
+	// ResultCap limits the number of results written per template
+	ResultCap *resultcap.Limiter
 	// Progress is the nuclei progress bar implementation.
 	Progress progress.Progress
 }
@@ -143,10 +146,13 @@ func (c *Client) processInteractionForRequest(interaction *server.Interaction, d
 
 	for _, result := range data.Event.Results {
 		result.Interaction = interaction
-		_ = c.options.Output.Write(result)
 		if !c.matched {
 			c.matched = true
 		}
+		if !c.options.ResultCap.Allow(result.TemplateID) {
+			continue
+		}
+		_ = c.options.Output.Write(result)
 		c.options.Progress.IncrementMatched()
 
 		if c.options.IssuesClient != nil {
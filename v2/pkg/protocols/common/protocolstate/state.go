@@ -3,12 +3,16 @@ package protocolstate
 import (
 	"github.com/pkg/errors"
 	"github.com/projectdiscovery/fastdialer/fastdialer"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/clientcert"
 	"github.com/yaklang/nuclei/v2/pkg/types"
 )
 
 // Dialer is a shared fastdialer instance for host DNS resolution
 var Dialer *fastdialer.Dialer
 
+// ClientCertificate is the client certificate configured by the user if any
+var ClientCertificate *clientcert.Certificate
+
 // Init creates the Dialer instance based on user configuration
 func Init(options *types.Options) error {
 	opts := fastdialer.DefaultOptions
@@ -23,6 +27,15 @@ func Init(options *types.Options) error {
 		return errors.Wrap(err, "could not create dialer")
 	}
 	Dialer = dialer
+
+	ClientCertificate = nil
+	if options.ClientCertFile != "" || options.ClientKeyFile != "" || options.ClientCAFile != "" {
+		certificate, err := clientcert.Load(clientcert.Files{Cert: options.ClientCertFile, Key: options.ClientKeyFile, CA: options.ClientCAFile})
+		if err != nil {
+			return errors.Wrap(err, "could not load client certificate")
+		}
+		ClientCertificate = certificate
+	}
 	return nil
 }
 
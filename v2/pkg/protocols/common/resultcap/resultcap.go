@@ -0,0 +1,110 @@
+// Package resultcap implements a cap on the number of results emitted
+// per template, counting the suppressed results for the scan summary.
+package resultcap
+
+import (
+	"sort"
+	"sync"
+
+	"go.uber.org/atomic"
+)
+
+// Limiter limits the number of results written for each template
+type Limiter struct {
+	defaultMax int
+	mutex      sync.RWMutex
+	max        map[string]int
+	counters   map[string]*counter
+}
+
+type counter struct {
+	matched    atomic.Int64
+	suppressed atomic.Int64
+}
+
+// Stats contains the result counts for a template
+type Stats struct {
+	TemplateID string
+	Matched    int64
+	Suppressed int64
+}
+
+// New creates a new result limiter with a default maximum for templates.
+// A max of 0 means no limit.
+func New(defaultMax int) *Limiter {
+	return &Limiter{
+		defaultMax: defaultMax,
+		max:        make(map[string]int),
+		counters:   make(map[string]*counter),
+	}
+}
+
+// SetMax sets the maximum results for a template overriding the default.
+func (l *Limiter) SetMax(templateID string, max int) {
+	l.mutex.Lock()
+	l.max[templateID] = max
+	l.mutex.Unlock()
+}
+
+// Allow counts a result for a template and returns true if it is within
+// the cap and should be written. A nil limiter allows all the results.
+func (l *Limiter) Allow(templateID string) bool {
+	if l == nil {
+		return true
+	}
+	l.mutex.RLock()
+	c, ok := l.counters[templateID]
+	max, hasMax := l.max[templateID]
+	l.mutex.RUnlock()
+
+	if !ok {
+		l.mutex.Lock()
+		if c, ok = l.counters[templateID]; !ok {
+			c = &counter{}
+			l.counters[templateID] = c
+		}
+		l.mutex.Unlock()
+	}
+	if !hasMax {
+		max = l.defaultMax
+	}
+
+	if matched := c.matched.Inc(); max > 0 && matched > int64(max) {
+		c.suppressed.Inc()
+		return false
+	}
+	return true
+}
+
+// Totals returns the total matched and suppressed results of all templates
+func (l *Limiter) Totals() (matched, suppressed int64) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	for _, c := range l.counters {
+		matched += c.matched.Load()
+		suppressed += c.suppressed.Load()
+	}
+	return matched, suppressed
+}
+
+// Suppressed returns the stats of templates which had results suppressed
+// sorted by the number of matches.
+func (l *Limiter) Suppressed() []Stats {
+	l.mutex.RLock()
+	var stats []Stats
+	for templateID, c := range l.counters {
+		if suppressed := c.suppressed.Load(); suppressed > 0 {
+			stats = append(stats, Stats{TemplateID: templateID, Matched: c.matched.Load(), Suppressed: suppressed})
+		}
+	}
+	l.mutex.RUnlock()
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Matched == stats[j].Matched {
+			return stats[i].TemplateID < stats[j].TemplateID
+		}
+		return stats[i].Matched > stats[j].Matched
+	})
+	return stats
+}
@@ -0,0 +1,43 @@
+package resultcap
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+)
+
+func TestLimiterAllow(t *testing.T) {
+	limiter := New(10)
+	limiter.SetMax("custom", 2)
+	limiter.SetMax("unlimited", 0)
+
+	allowed := map[string]*atomic.Int64{"default": {}, "custom": {}, "unlimited": {}}
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 100; i++ {
+		for templateID, count := range allowed {
+			wg.Add(1)
+			go func(templateID string, count *atomic.Int64) {
+				defer wg.Done()
+				if limiter.Allow(templateID) {
+					count.Inc()
+				}
+			}(templateID, count)
+		}
+	}
+	wg.Wait()
+
+	require.Equal(t, int64(10), allowed["default"].Load(), "could not apply default cap")
+	require.Equal(t, int64(2), allowed["custom"].Load(), "could not apply template cap")
+	require.Equal(t, int64(100), allowed["unlimited"].Load(), "could not disable cap for template")
+
+	require.Equal(t, []Stats{
+		{TemplateID: "custom", Matched: 100, Suppressed: 98},
+		{TemplateID: "default", Matched: 100, Suppressed: 90},
+	}, limiter.Suppressed(), "could not get suppressed stats")
+
+	var nilLimiter *Limiter
+	require.True(t, nilLimiter.Allow("any"), "nil limiter did not allow result")
+}
@@ -0,0 +1,288 @@
+// Package session implements scan-level authentication sessions which
+// are shared by all the templates running against in-scope hosts.
+package session
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Options contains the configuration options for an authentication session
+type Options struct {
+	// Auth is the type of the authentication. Only jwt is supported for now.
+	Auth string `yaml:"auth"`
+	// TokenURL is the endpoint to request tokens from
+	TokenURL string `yaml:"token-url"`
+	// ClientID is the client id for client credentials grant
+	ClientID string `yaml:"client-id"`
+	// ClientSecret is the client secret for client credentials grant
+	ClientSecret string `yaml:"client-secret"`
+	// RefreshToken is the refresh token used for refresh token grant
+	RefreshToken string `yaml:"refresh-token"`
+	// Scope is the optional scope requested for the token
+	Scope string `yaml:"scope"`
+	// Header is the header the token is sent in (default Authorization)
+	Header string `yaml:"header"`
+	// ExpiryMargin is the duration before expiry at which the token is refreshed (default 10s)
+	ExpiryMargin string `yaml:"expiry-margin"`
+	// Hosts is the list of in-scope hosts. Subdomains of the hosts are in
+	// scope as well. An empty list puts every host in scope.
+	Hosts []string `yaml:"hosts"`
+}
+
+// ErrSessionPaused is returned when the token could not be refreshed and
+// requests to the in-scope hosts are paused.
+var ErrSessionPaused = errors.New("authentication session paused")
+
+const (
+	defaultExpiryMargin = 10 * time.Second
+	// retryBackoff is the time to wait after a failed refresh before trying again.
+	retryBackoff = 5 * time.Second
+)
+
+// Manager manages a token based authentication session for a scan
+type Manager struct {
+	options      *Options
+	client       *http.Client
+	header       string
+	margin       time.Duration
+	mutex        sync.RWMutex
+	token        string
+	refreshToken string
+	expiry       time.Time
+	failedAt     time.Time
+	lastErr      error
+}
+
+// ReadOptions reads the session options from a yaml configuration file
+func ReadOptions(file string) (*Options, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open auth config file")
+	}
+	defer f.Close()
+
+	options := &Options{}
+	if err := yaml.NewDecoder(f).Decode(options); err != nil {
+		return nil, errors.Wrap(err, "could not parse auth config file")
+	}
+	return options, nil
+}
+
+// New creates a new session manager and fetches the initial token.
+func New(options *Options, timeout int) (*Manager, error) {
+	if !strings.EqualFold(options.Auth, "jwt") {
+		return nil, errors.Errorf("unsupported auth type: %s", options.Auth)
+	}
+	if options.TokenURL == "" {
+		return nil, errors.New("no token-url specified for jwt auth")
+	}
+	if options.RefreshToken == "" && options.ClientID == "" {
+		return nil, errors.New("either client credentials or refresh-token must be specified for jwt auth")
+	}
+	margin := defaultExpiryMargin
+	if options.ExpiryMargin != "" {
+		parsed, err := time.ParseDuration(options.ExpiryMargin)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not parse expiry-margin")
+		}
+		margin = parsed
+	}
+	header := options.Header
+	if header == "" {
+		header = "Authorization"
+	}
+
+	manager := &Manager{
+		options:      options,
+		header:       header,
+		margin:       margin,
+		refreshToken: options.RefreshToken,
+		client: &http.Client{
+			Timeout: time.Duration(timeout) * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+		},
+	}
+	if err := manager.Refresh(); err != nil {
+		return nil, err
+	}
+	return manager, nil
+}
+
+// InScope returns true if the session applies to a host
+func (m *Manager) InScope(host string) bool {
+	if len(m.options.Hosts) == 0 {
+		return true
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+	for _, scope := range m.options.Hosts {
+		scope = strings.ToLower(scope)
+		if host == scope || strings.HasSuffix(host, "."+scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// Header returns the name of the header used for the token
+func (m *Manager) Header() string {
+	return m.header
+}
+
+// HeaderValue returns the header value for the current token refreshing
+// it proactively if it is about to expire.
+func (m *Manager) HeaderValue() (string, error) {
+	m.mutex.RLock()
+	token, expiry := m.token, m.expiry
+	m.mutex.RUnlock()
+
+	if token != "" && time.Now().Add(m.margin).Before(expiry) {
+		return m.formatToken(token), nil
+	}
+	if err := m.refreshIfStale(expiry); err != nil {
+		return "", err
+	}
+	m.mutex.RLock()
+	token = m.token
+	m.mutex.RUnlock()
+	return m.formatToken(token), nil
+}
+
+// Refresh forces a refresh of the token.
+func (m *Manager) Refresh() error {
+	m.mutex.RLock()
+	expiry := m.expiry
+	m.mutex.RUnlock()
+
+	return m.refreshIfStale(expiry)
+}
+
+// refreshIfStale refreshes the token unless another goroutine already did
+// it after the token with the provided expiry was observed.
+func (m *Manager) refreshIfStale(seen time.Time) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.token != "" && !m.expiry.Equal(seen) {
+		return nil
+	}
+	if !m.failedAt.IsZero() && time.Since(m.failedAt) < retryBackoff {
+		return errors.Wrap(ErrSessionPaused, m.lastErr.Error())
+	}
+	token, refreshToken, expiry, err := m.fetchToken()
+	if err != nil {
+		m.failedAt = time.Now()
+		m.lastErr = err
+		m.token = ""
+		return errors.Wrap(ErrSessionPaused, err.Error())
+	}
+	m.token = token
+	m.expiry = expiry
+	if refreshToken != "" {
+		m.refreshToken = refreshToken
+	}
+	m.failedAt = time.Time{}
+	m.lastErr = nil
+	return nil
+}
+
+func (m *Manager) formatToken(token string) string {
+	if strings.EqualFold(m.header, "Authorization") {
+		return "Bearer " + token
+	}
+	return token
+}
+
+// tokenResponse is the response returned by an oauth2 style token endpoint
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// fetchToken requests a new token from the token endpoint
+func (m *Manager) fetchToken() (string, string, time.Time, error) {
+	values := url.Values{}
+	if m.refreshToken != "" {
+		values.Set("grant_type", "refresh_token")
+		values.Set("refresh_token", m.refreshToken)
+	} else {
+		values.Set("grant_type", "client_credentials")
+	}
+	if m.options.ClientID != "" {
+		values.Set("client_id", m.options.ClientID)
+		values.Set("client_secret", m.options.ClientSecret)
+	}
+	if m.options.Scope != "" {
+		values.Set("scope", m.options.Scope)
+	}
+
+	resp, err := m.client.PostForm(m.options.TokenURL, values)
+	if err != nil {
+		return "", "", time.Time{}, errors.Wrap(err, "could not request token")
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", time.Time{}, errors.Wrap(err, "could not read token response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", "", time.Time{}, errors.Errorf("token endpoint returned status %d", resp.StatusCode)
+	}
+	data := &tokenResponse{}
+	if err := json.Unmarshal(body, data); err != nil {
+		return "", "", time.Time{}, errors.Wrap(err, "could not decode token response")
+	}
+	if data.AccessToken == "" {
+		return "", "", time.Time{}, errors.New("no access_token in token response")
+	}
+
+	var expiry time.Time
+	if data.ExpiresIn > 0 {
+		expiry = time.Now().Add(time.Duration(data.ExpiresIn) * time.Second)
+	} else if exp, ok := tokenExpiry(data.AccessToken); ok {
+		expiry = exp
+	} else {
+		return "", "", time.Time{}, errors.New("could not determine token expiry")
+	}
+	return data.AccessToken, data.RefreshToken, expiry, nil
+}
+
+// tokenExpiry returns the exp claim of a jwt token without verifying it.
+func tokenExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+	claims := struct {
+		Exp float64 `json:"exp"`
+	}{}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+	sec := int64(claims.Exp)
+	nsec := int64((claims.Exp - float64(sec)) * float64(time.Second))
+	return time.Unix(sec, nsec), true
+}
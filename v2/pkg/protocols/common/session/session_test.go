@@ -0,0 +1,107 @@
+package session
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+)
+
+// newTestToken creates an unsigned jwt token expiring at exp
+func newTestToken(exp time.Time) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"sub":"nuclei","exp":%d}`, exp.Unix())))
+	return header + "." + payload + ".sig"
+}
+
+func TestSessionJWTRefresh(t *testing.T) {
+	issued := &atomic.Int64{}
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.PostForm.Get("client_id") != "nuclei" || r.PostForm.Get("client_secret") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		issued.Inc()
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"access_token": newTestToken(time.Now().Add(2 * time.Second))})
+	}))
+	defer tokenServer.Close()
+
+	unauthorized := &atomic.Int64{}
+	protected := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if exp, ok := tokenExpiry(token); !ok || time.Now().After(exp) {
+			unauthorized.Inc()
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer protected.Close()
+
+	manager, err := New(&Options{Auth: "jwt", TokenURL: tokenServer.URL, ClientID: "nuclei", ClientSecret: "secret", ExpiryMargin: "500ms"}, 5)
+	require.Nil(t, err, "could not create session manager")
+
+	wg := &sync.WaitGroup{}
+	deadline := time.Now().Add(5 * time.Second)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for time.Now().Before(deadline) {
+				value, err := manager.HeaderValue()
+				require.Nil(t, err, "could not get session header")
+
+				req, _ := http.NewRequest(http.MethodGet, protected.URL, nil)
+				req.Header.Set(manager.Header(), value)
+				resp, err := http.DefaultClient.Do(req)
+				require.Nil(t, err, "could not make request")
+				resp.Body.Close()
+				time.Sleep(50 * time.Millisecond)
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, int64(0), unauthorized.Load(), "got unauthorized responses during scan")
+	require.GreaterOrEqual(t, issued.Load(), int64(3), "token was not refreshed proactively")
+	require.LessOrEqual(t, issued.Load(), int64(6), "token was refreshed too many times")
+}
+
+func TestSessionRefreshFailure(t *testing.T) {
+	fail := &atomic.Bool{}
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "opaque", "expires_in": 60})
+	}))
+	defer tokenServer.Close()
+
+	manager, err := New(&Options{Auth: "jwt", TokenURL: tokenServer.URL, RefreshToken: "refresh", Hosts: []string{"example.com"}}, 5)
+	require.Nil(t, err, "could not create session manager")
+
+	value, err := manager.HeaderValue()
+	require.Nil(t, err, "could not get session header")
+	require.Equal(t, "Bearer opaque", value, "could not get correct header value")
+
+	require.True(t, manager.InScope("api.example.com:443"), "could not match in-scope subdomain")
+	require.False(t, manager.InScope("example.org"), "out-of-scope host was matched")
+
+	fail.Store(true)
+	err = manager.Refresh()
+	require.True(t, errors.Is(err, ErrSessionPaused), "refresh failure did not pause session")
+
+	_, err = manager.HeaderValue()
+	require.True(t, errors.Is(err, ErrSessionPaused), "paused session returned a token")
+}
@@ -0,0 +1,128 @@
+// Package tlsconfig builds the tls configurations used to connect to the
+// scanned targets from the tls settings of the requests.
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/fastdialer/fastdialer"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/clientcert"
+)
+
+// Verification contains the server verification settings of a request
+type Verification struct {
+	skipVerify bool
+	caFile     string
+	rootCAs    *x509.CertPool
+}
+
+// Load loads the verification settings of a request, resolving a relative
+// CA file from directory. It returns nil if the request doesn't override
+// the verification of the servers.
+func Load(skipVerify bool, caFile, directory string) (*Verification, error) {
+	if !skipVerify && caFile == "" {
+		return nil, nil
+	}
+	if caFile != "" && !filepath.IsAbs(caFile) {
+		caFile = filepath.Join(directory, caFile)
+	}
+	if absolute, err := filepath.Abs(caFile); caFile != "" && err == nil {
+		caFile = absolute
+	}
+	verification := &Verification{skipVerify: skipVerify, caFile: caFile}
+	if caFile != "" {
+		data, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read tls ca file")
+		}
+		verification.rootCAs = x509.NewCertPool()
+		if !verification.rootCAs.AppendCertsFromPEM(data) {
+			return nil, errors.Errorf("no certificates found in tls ca file %s", caFile)
+		}
+	}
+	return verification, nil
+}
+
+// Hash returns a key identifying the verification for client pooling
+func (v *Verification) Hash() string {
+	return strconv.FormatBool(v.skipVerify) + "|" + v.caFile
+}
+
+// CAFile returns the absolute path of the CA file of the verification
+func (v *Verification) CAFile() string {
+	if v == nil {
+		return ""
+	}
+	return v.caFile
+}
+
+// Config returns the tls configuration of a connection to a server, presenting
+// the client certificate and applying the verification if any. Servers are
+// not verified by default, unless a CA file was provided.
+func Config(serverName string, certificate *clientcert.Certificate, verification *Verification) *tls.Config {
+	config := &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: true,
+		Renegotiation:      tls.RenegotiateOnceAsClient,
+	}
+	if certificate != nil {
+		config = certificate.Config(serverName)
+	}
+	if verification != nil {
+		if verification.rootCAs != nil {
+			config.RootCAs = verification.rootCAs
+			config.InsecureSkipVerify = false
+		}
+		if verification.skipVerify {
+			config.InsecureSkipVerify = true
+		}
+	}
+	return config
+}
+
+// DialTLS connects to an address with the dialer and performs a tls
+// handshake presenting the certificate and applying the verification.
+func DialTLS(ctx context.Context, dialer *fastdialer.Dialer, network, address string, certificate *clientcert.Certificate, verification *Verification) (net.Conn, error) {
+	return DialTLSWithServerName(ctx, dialer, network, address, "", certificate, verification)
+}
+
+// DialTLSWithServerName is DialTLS sending a server name different from the
+// host of the address, the host is used if the server name is empty.
+func DialTLSWithServerName(ctx context.Context, dialer *fastdialer.Dialer, network, address, serverName string, certificate *clientcert.Certificate, verification *Verification) (net.Conn, error) {
+	conn, err := dialer.Dial(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	host := serverName
+	if host == "" {
+		if host, _, err = net.SplitHostPort(address); err != nil {
+			host = address
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	tlsConn, err := Client(conn, host, certificate, verification)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// Client performs a tls handshake on an established connection, like for
+// starttls upgrades. The connection is not closed on errors.
+func Client(conn net.Conn, serverName string, certificate *clientcert.Certificate, verification *Verification) (*tls.Conn, error) {
+	tlsConn := tls.Client(conn, Config(serverName, certificate, verification))
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, errors.Wrap(err, "could not perform tls handshake")
+	}
+	return tlsConn, nil
+}
@@ -0,0 +1,41 @@
+package tlsconfig
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaklang/nuclei/v2/internal/testutils/certificates"
+)
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nuclei-tlsconfig-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(dir)
+
+	generated, err := certificates.Generate(dir)
+	require.Nil(t, err, "could not generate certificates")
+
+	verification, err := Load(false, "", dir)
+	require.Nil(t, err, "could not load empty verification")
+	require.Nil(t, verification, "loaded verification without settings")
+	require.True(t, Config("", nil, verification).InsecureSkipVerify, "verified server by default")
+
+	verification, err = Load(false, filepath.Base(generated.CAFile), filepath.Dir(generated.CAFile))
+	require.Nil(t, err, "could not load relative ca file")
+	config := Config("127.0.0.1", nil, verification)
+	require.False(t, config.InsecureSkipVerify, "skipped verification with ca file")
+	require.NotNil(t, config.RootCAs, "could not set ca file")
+
+	skipped, err := Load(true, generated.CAFile, dir)
+	require.Nil(t, err, "could not load verification")
+	require.True(t, Config("", nil, skipped).InsecureSkipVerify, "verified server with skip verify")
+	require.NotEqual(t, verification.Hash(), skipped.Hash(), "could not distinguish verifications")
+
+	_, err = Load(false, generated.ClientKeyFile, dir)
+	require.NotNil(t, err, "loaded ca file without certificates")
+	_, err = Load(false, "missing.pem", dir)
+	require.NotNil(t, err, "loaded missing ca file")
+}
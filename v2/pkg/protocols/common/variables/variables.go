@@ -0,0 +1,106 @@
+// Package variables implements the template-level variables evaluated
+// once for each input and shared by all the requests of a template.
+package variables
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/Knetic/govaluate"
+	"github.com/projectdiscovery/gologger"
+	"github.com/yaklang/nuclei/v2/pkg/operators/common/dsl"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/expressions"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/generators"
+	"github.com/yaklang/nuclei/v2/pkg/types"
+	"gopkg.in/yaml.v2"
+)
+
+// expressionRegex matches the expressions of a variable without braces in them
+var expressionRegex = regexp.MustCompile(`\{\{([^{}]+)\}\}`)
+
+// Variable is the variables block of a template, keeping the
+// declaration order of the variables.
+type Variable struct {
+	names  []string
+	values map[string]string
+}
+
+// UnmarshalYAML unmarshals the variables keeping their declaration order
+func (v *Variable) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var items yaml.MapSlice
+	if err := unmarshal(&items); err != nil {
+		return err
+	}
+	v.names = make([]string, 0, len(items))
+	v.values = make(map[string]string, len(items))
+	for _, item := range items {
+		name := types.ToString(item.Key)
+		if _, ok := v.values[name]; ok {
+			return fmt.Errorf("duplicate variable %s", name)
+		}
+		v.names = append(v.names, name)
+		v.values[name] = types.ToString(item.Value)
+	}
+	return nil
+}
+
+// Len returns the number of variables
+func (v *Variable) Len() int {
+	if v == nil {
+		return 0
+	}
+	return len(v.names)
+}
+
+// Validate checks that the expressions of the variables can be compiled
+func (v *Variable) Validate() error {
+	if v == nil {
+		return nil
+	}
+	for _, name := range v.names {
+		for _, match := range expressionRegex.FindAllStringSubmatch(v.values[name], -1) {
+			if _, err := govaluate.NewEvaluableExpressionWithFunctions(match[1], dsl.HelperFunctions()); err != nil {
+				return fmt.Errorf("invalid expression %s in variable %s: %s", match[0], name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Evaluate evaluates the variables for an input in declaration order, so
+// the variables can reference the input values and the earlier variables.
+func (v *Variable) Evaluate(input string) map[string]interface{} {
+	values := make(map[string]interface{}, v.Len())
+	if v.Len() == 0 {
+		return values
+	}
+	base := inputValues(input)
+	for _, name := range v.names {
+		evaluated, _ := expressions.Evaluate(v.values[name], generators.MergeMaps(base, values))
+		// Plain markers are left for the requests to replace, while a helper
+		// call left in the value could not be evaluated for the input.
+		for _, match := range expressionRegex.FindAllStringSubmatch(evaluated, -1) {
+			if strings.Contains(match[1], "(") {
+				gologger.Warning().Msgf("Could not evaluate expression %s of variable %s for %s\n", match[0], name, input)
+			}
+		}
+		values[name] = evaluated
+	}
+	return values
+}
+
+// inputValues returns the values of an input usable by the variables
+func inputValues(input string) map[string]interface{} {
+	parsed, err := url.Parse(input)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return map[string]interface{}{"Hostname": input, "FQDN": input}
+	}
+	return map[string]interface{}{
+		"BaseURL":  input,
+		"RootURL":  parsed.Scheme + "://" + parsed.Host,
+		"Hostname": parsed.Host,
+		"FQDN":     parsed.Hostname(),
+	}
+}
@@ -0,0 +1,50 @@
+package variables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestVariablesEvaluate(t *testing.T) {
+	data := `
+encoded: "{{base64(Hostname)}}"
+path: "/api/{{encoded}}"
+url: "{{BaseURL}}{{path}}"
+`
+	variables := &Variable{}
+	err := yaml.Unmarshal([]byte(data), variables)
+	require.Nil(t, err, "could not unmarshal variables")
+	require.Equal(t, []string{"encoded", "path", "url"}, variables.names, "could not keep declaration order")
+
+	values := variables.Evaluate("https://example.com:8443")
+	require.Equal(t, map[string]interface{}{
+		"encoded": "ZXhhbXBsZS5jb206ODQ0Mw==",
+		"path":    "/api/ZXhhbXBsZS5jb206ODQ0Mw==",
+		"url":     "https://example.com:8443/api/ZXhhbXBsZS5jb206ODQ0Mw==",
+	}, values, "could not evaluate variables")
+
+	values = variables.Evaluate("example.com")
+	require.Equal(t, "ZXhhbXBsZS5jb20=", values["encoded"], "could not evaluate variables for domain")
+
+	var empty *Variable
+	require.Empty(t, empty.Evaluate("example.com"), "could not evaluate empty variables")
+
+	err = yaml.Unmarshal([]byte("a: 1\na: 2\n"), &Variable{})
+	require.NotNil(t, err, "could unmarshal duplicate variables")
+}
+
+func TestVariablesValidate(t *testing.T) {
+	variables := &Variable{}
+	err := yaml.Unmarshal([]byte("url: \"{{BaseURL}}/{{interactsh-url}}\"\nencoded: \"{{base64(Hostname)}}\"\n"), variables)
+	require.Nil(t, err, "could not unmarshal variables")
+	require.Nil(t, variables.Validate(), "could not validate variables")
+
+	for _, data := range []string{"a: \"{{base64(Hostname}}\"", "a: \"{{bas64(Hostname)}}\""} {
+		variables := &Variable{}
+		err := yaml.Unmarshal([]byte(data), variables)
+		require.Nil(t, err, "could not unmarshal variables")
+		require.NotNil(t, variables.Validate(), "could validate invalid expression %s", data)
+	}
+}
@@ -8,8 +8,10 @@ import (
 	"github.com/pkg/errors"
 	"github.com/yaklang/nuclei/v2/pkg/operators"
 	"github.com/yaklang/nuclei/v2/pkg/protocols"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/generators"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/common/replacer"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/dns/dnsclientpool"
+	"github.com/yaklang/nuclei/v2/pkg/types"
 	"github.com/projectdiscovery/retryabledns"
 )
 
@@ -28,9 +30,20 @@ type Request struct {
 	Class string `yaml:"class"`
 	// Retries is the number of retries for the DNS request
 	Retries int `yaml:"retries"`
+	// Resolvers are the resolvers queried instead of the global ones,
+	// as ip:port, tls://host:port (dns over tls) or https:// urls
+	// (dns over https). The next one is tried after a failure. They are
+	// the servers of zone transfers instead of the nameservers of the domain.
+	Resolvers []string `yaml:"resolvers"`
+	// WildcardCheck resolves a random name under the apex of the name to
+	// add is_wildcard and wildcard_ips to the response.
+	WildcardCheck bool `yaml:"wildcard-check"`
+	// EDNS contains the EDNS0 options of the request
+	EDNS *EDNS `yaml:"edns"`
 
 	CompiledOperators *operators.Operators
 	dnsClient         *retryabledns.Client
+	resolversClient   *dnsclientpool.ResolversClient
 	options           *protocols.ExecuterOptions
 
 	// cache any variables that may be needed for operation.
@@ -46,6 +59,11 @@ func (r *Request) GetID() string {
 	return r.ID
 }
 
+// EventType returns the type of the result events of the request.
+func (r *Request) EventType() string {
+	return "dns"
+}
+
 // Compile compiles the protocol request for further execution.
 func (r *Request) Compile(options *protocols.ExecuterOptions) error {
 	// Create a dns client for the class
@@ -57,6 +75,38 @@ func (r *Request) Compile(options *protocols.ExecuterOptions) error {
 	}
 	r.dnsClient = client
 
+	if _, ok := questionTypes[strings.ToUpper(strings.TrimSpace(r.Type))]; r.Type != "" && !ok {
+		return errors.Errorf("invalid dns type %s", r.Type)
+	}
+	r.question = questionTypeToInt(r.Type)
+
+	if r.EDNS != nil {
+		if err := r.EDNS.compile(); err != nil {
+			return err
+		}
+	}
+
+	if r.question == dns.TypeAXFR {
+		// Zone transfers are made over tcp with the resolvers as servers
+		for _, resolver := range r.Resolvers {
+			if strings.Contains(resolver, "://") {
+				return errors.Errorf("zone transfers are not supported with resolver %s", resolver)
+			}
+		}
+	} else if len(r.Resolvers) > 0 || r.EDNS != nil {
+		// Requests with EDNS options use their own client to retry
+		// the truncated responses over tcp.
+		resolvers := r.Resolvers
+		if len(resolvers) == 0 {
+			resolvers = dnsclientpool.GetResolvers(options.Options)
+		}
+		resolversClient, err := dnsclientpool.NewResolversClient(resolvers, r.Retries, options.Options.ProtocolTimeout(types.DNSProtocol))
+		if err != nil {
+			return errors.Wrap(err, "could not create resolvers client")
+		}
+		r.resolversClient = resolversClient
+	}
+
 	if len(r.Matchers) > 0 || len(r.Extractors) > 0 {
 		compiled := &r.Operators
 		if err := compiled.Compile(); err != nil {
@@ -66,7 +116,6 @@ func (r *Request) Compile(options *protocols.ExecuterOptions) error {
 	}
 	r.class = classToInt(r.Class)
 	r.options = options
-	r.question = questionTypeToInt(r.Type)
 	return nil
 }
 
@@ -76,11 +125,21 @@ func (r *Request) Requests() int {
 }
 
 // Make returns the request to be sent for the protocol
-func (r *Request) Make(domain string) (*dns.Msg, error) {
+func (r *Request) Make(domain string, values map[string]interface{}) (*dns.Msg, error) {
 	if r.question != dns.TypePTR && net.ParseIP(domain) != nil {
 		return nil, errors.New("cannot use IP address as DNS input")
 	}
-	domain = dns.Fqdn(domain)
+	templateValues := map[string]interface{}{"FQDN": dns.Fqdn(domain)}
+
+	// Addresses are looked up on their arpa names
+	if net.ParseIP(domain) != nil {
+		reverse, err := dns.ReverseAddr(domain)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not get reverse address")
+		}
+		templateValues["FQDN"] = reverse
+		templateValues["IP"] = domain
+	}
 
 	// Build a request on the specified URL
 	req := new(dns.Msg)
@@ -89,39 +148,55 @@ func (r *Request) Make(domain string) (*dns.Msg, error) {
 
 	var q dns.Question
 
-	final := replacer.Replace(r.Name, map[string]interface{}{"FQDN": domain})
+	final := replacer.Replace(r.Name, generators.MergeMaps(values, templateValues))
 
+	// Names of ptr requests replaced with an address, like {{IP}}, are
+	// looked up on their arpa names too.
+	if r.question == dns.TypePTR && net.ParseIP(strings.TrimSuffix(final, ".")) != nil {
+		reverse, err := dns.ReverseAddr(strings.TrimSuffix(final, "."))
+		if err != nil {
+			return nil, errors.Wrap(err, "could not get reverse address")
+		}
+		final = reverse
+	}
 	q.Name = dns.Fqdn(final)
 	q.Qclass = r.class
 	q.Qtype = r.question
 	req.Question = append(req.Question, q)
+
+	if r.EDNS != nil {
+		r.EDNS.apply(req)
+	}
+	// Answers of any queries usually don't fit in 512 bytes
+	if r.question == dns.TypeANY && req.IsEdns0() == nil {
+		req.SetEdns0(4096, false)
+	}
 	return req, nil
 }
 
+// questionTypes are the supported DNS question types
+var questionTypes = map[string]uint16{
+	"A":     dns.TypeA,
+	"NS":    dns.TypeNS,
+	"CNAME": dns.TypeCNAME,
+	"SOA":   dns.TypeSOA,
+	"PTR":   dns.TypePTR,
+	"MX":    dns.TypeMX,
+	"TXT":   dns.TypeTXT,
+	"AAAA":  dns.TypeAAAA,
+	"SRV":   dns.TypeSRV,
+	"CAA":   dns.TypeCAA,
+	"ANY":   dns.TypeANY,
+	"AXFR":  dns.TypeAXFR,
+}
+
 // questionTypeToInt converts DNS question type to internal representation
 func questionTypeToInt(questionType string) uint16 {
 	questionType = strings.TrimSpace(strings.ToUpper(questionType))
-	question := dns.TypeA
-
-	switch questionType {
-	case "A":
-		question = dns.TypeA
-	case "NS":
-		question = dns.TypeNS
-	case "CNAME":
-		question = dns.TypeCNAME
-	case "SOA":
-		question = dns.TypeSOA
-	case "PTR":
-		question = dns.TypePTR
-	case "MX":
-		question = dns.TypeMX
-	case "TXT":
-		question = dns.TypeTXT
-	case "AAAA":
-		question = dns.TypeAAAA
+	if question, ok := questionTypes[questionType]; ok {
+		return question
 	}
-	return question
+	return dns.TypeA
 }
 
 // classToInt converts a dns class name to it's internal representation
@@ -1,10 +1,14 @@
 package dns
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/miekg/dns"
 	"github.com/yaklang/nuclei/v2/internal/testutils"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/variables"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
 )
 
 func TestDNSCompileMake(t *testing.T) {
@@ -27,7 +31,107 @@ func TestDNSCompileMake(t *testing.T) {
 	err := request.Compile(executerOpts)
 	require.Nil(t, err, "could not compile dns request")
 
-	req, err := request.Make("one.one.one.one")
+	req, err := request.Make("one.one.one.one", nil)
 	require.Nil(t, err, "could not make dns request")
 	require.Equal(t, "one.one.one.one.", req.Question[0].Name, "could not get correct dns question")
+
+	templateVariables := &variables.Variable{}
+	err = yaml.Unmarshal([]byte("prefix: \"_{{tolower('DMARC')}}\""), templateVariables)
+	require.Nil(t, err, "could not unmarshal variables")
+
+	request.Name = "{{prefix}}.{{FQDN}}"
+	req, err = request.Make("one.one.one.one", templateVariables.Evaluate("one.one.one.one"))
+	require.Nil(t, err, "could not make dns request with variables")
+	require.Equal(t, "_dmarc.one.one.one.one.", req.Question[0].Name, "could not use variables in dns question")
+}
+
+func TestDNSCompileMakeTypes(t *testing.T) {
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	const templateID = "testing-dns"
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+
+	for _, questionType := range []string{"SRV", "caa", "SOA", "NS", "ANY"} {
+		request := &Request{Type: questionType, ID: templateID, Name: "{{FQDN}}"}
+		err := request.Compile(executerOpts)
+		require.Nil(t, err, "could not compile dns request for %s", questionType)
+
+		req, err := request.Make("example.com", nil)
+		require.Nil(t, err, "could not make dns request for %s", questionType)
+		require.Equal(t, dns.StringToType[strings.ToUpper(questionType)], req.Question[0].Qtype, "could not get correct question type")
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		request := &Request{Type: "DS", ID: templateID, Name: "{{FQDN}}"}
+		err := request.Compile(executerOpts)
+		require.NotNil(t, err, "could compile dns request with invalid type")
+	})
+}
+
+func TestDNSMakeReverse(t *testing.T) {
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	const templateID = "testing-dns"
+	request := &Request{Type: "PTR", ID: templateID, Name: "{{FQDN}}"}
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	err := request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile dns request")
+
+	req, err := request.Make("192.0.2.10", nil)
+	require.Nil(t, err, "could not make dns request for ipv4")
+	require.Equal(t, "10.2.0.192.in-addr.arpa.", req.Question[0].Name, "could not get ipv4 reverse name")
+
+	req, err = request.Make("2001:db8::1", nil)
+	require.Nil(t, err, "could not make dns request for ipv6")
+	require.Equal(t, "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa.", req.Question[0].Name, "could not get ipv6 reverse name")
+
+	request.Name = "{{IP}}"
+	req, err = request.Make("192.0.2.10", nil)
+	require.Nil(t, err, "could not make dns request with ip")
+	require.Equal(t, "10.2.0.192.in-addr.arpa.", req.Question[0].Name, "could not get reverse name of ip")
+}
+
+func TestDNSMakeEDNS(t *testing.T) {
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	const templateID = "testing-dns"
+	request := &Request{
+		Type: "A",
+		ID:   templateID,
+		Name: "{{FQDN}}",
+		EDNS: &EDNS{ClientSubnet: "1.2.3.0/24", DO: true, UDPSize: 1232},
+	}
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	err := request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile dns request")
+
+	req, err := request.Make("example.com", nil)
+	require.Nil(t, err, "could not make dns request")
+	opt := req.IsEdns0()
+	require.NotNil(t, opt, "could not get opt record")
+	require.True(t, opt.Do(), "could not get do bit")
+	require.Equal(t, uint16(1232), opt.UDPSize(), "could not get udp size")
+	require.Len(t, opt.Option, 1, "could not get client subnet option")
+	subnet := opt.Option[0].(*dns.EDNS0_SUBNET)
+	require.Equal(t, uint8(24), subnet.SourceNetmask, "could not get client subnet netmask")
+	require.Equal(t, "1.2.3.0", subnet.Address.String(), "could not get client subnet address")
+
+	_, err = req.Pack()
+	require.Nil(t, err, "could not pack dns request with edns options")
+
+	request.EDNS = &EDNS{ClientSubnet: "invalid"}
+	err = request.Compile(executerOpts)
+	require.NotNil(t, err, "could compile dns request with invalid client subnet")
 }
@@ -32,12 +32,17 @@ func Init(options *types.Options) error {
 	poolMutex = &sync.RWMutex{}
 	clientPool = make(map[string]*retryabledns.Client)
 
-	resolvers := defaultResolvers
+	normalClient = retryabledns.New(GetResolvers(options), 1)
+	return nil
+}
+
+// GetResolvers returns the resolvers of the scan, the trusted ones
+// unless a resolvers file was provided.
+func GetResolvers(options *types.Options) []string {
 	if options.ResolversFile != "" {
-		resolvers = options.InternalResolversList
+		return options.InternalResolversList
 	}
-	normalClient = retryabledns.New(resolvers, 1)
-	return nil
+	return defaultResolvers
 }
 
 // Configuration contains the custom configuration options for a client
@@ -69,11 +74,7 @@ func Get(options *types.Options, configuration *Configuration) (*retryabledns.Cl
 	}
 	poolMutex.RUnlock()
 
-	resolvers := defaultResolvers
-	if options.ResolversFile != "" {
-		resolvers = options.InternalResolversList
-	}
-	client := retryabledns.New(resolvers, configuration.Retries)
+	client := retryabledns.New(GetResolvers(options), configuration.Retries)
 
 	poolMutex.Lock()
 	clientPool[hash] = client
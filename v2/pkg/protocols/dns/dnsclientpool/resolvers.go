@@ -0,0 +1,165 @@
+package dnsclientpool
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/tlsconfig"
+)
+
+// resolver is a dns server queried over udp, tls or https
+type resolver struct {
+	// name is the resolver as specified by the user
+	name    string
+	address string
+	client  *dns.Client
+	// tcpClient retries the truncated udp responses over tcp
+	tcpClient *dns.Client
+}
+
+// ResolversClient sends the queries of a request to its own resolvers
+// instead of the global ones.
+type ResolversClient struct {
+	resolvers  []*resolver
+	retries    int
+	httpClient *http.Client
+}
+
+// NewResolversClient creates a client for a list of ip:port, tls://host:port
+// (dns over tls) and https:// (dns over https) resolvers. The port defaults
+// to 53 and 853 for dns over tls.
+func NewResolversClient(resolvers []string, retries int, timeout time.Duration) (*ResolversClient, error) {
+	client := &ResolversClient{
+		retries:    retries,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+	for _, value := range resolvers {
+		parsed, err := parseResolver(strings.TrimSpace(value), timeout)
+		if err != nil {
+			return nil, err
+		}
+		client.resolvers = append(client.resolvers, parsed)
+	}
+	if len(client.resolvers) == 0 {
+		return nil, errors.New("no resolvers specified")
+	}
+	return client, nil
+}
+
+// parseResolver parses a resolver and creates its dns client
+func parseResolver(value string, timeout time.Duration) (*resolver, error) {
+	parsed := &resolver{name: value}
+	switch {
+	case strings.HasPrefix(value, "https://"):
+		if _, err := url.Parse(value); err != nil {
+			return nil, errors.Wrapf(err, "could not parse resolver %s", value)
+		}
+		parsed.address = value
+		return parsed, nil
+	case strings.HasPrefix(value, "tls://"):
+		parsed.address = withDefaultPort(strings.TrimPrefix(value, "tls://"), "853")
+		host, _, _ := net.SplitHostPort(parsed.address)
+		parsed.client = &dns.Client{Net: "tcp-tls", Timeout: timeout, TLSConfig: tlsconfig.Config(host, nil, nil)}
+	case strings.Contains(value, "://"):
+		return nil, errors.Errorf("unsupported resolver %s", value)
+	default:
+		parsed.address = withDefaultPort(value, "53")
+		parsed.client = &dns.Client{Net: "udp", Timeout: timeout}
+		parsed.tcpClient = &dns.Client{Net: "tcp", Timeout: timeout}
+	}
+	if _, _, err := net.SplitHostPort(parsed.address); err != nil {
+		return nil, errors.Wrapf(err, "could not parse resolver %s", value)
+	}
+	return parsed, nil
+}
+
+// withDefaultPort adds a port to an address without one
+func withDefaultPort(address, port string) string {
+	if _, _, err := net.SplitHostPort(address); err == nil {
+		return address
+	}
+	return net.JoinHostPort(strings.Trim(address, "[]"), port)
+}
+
+// Do sends a query to the resolvers in order, falling back to the next one
+// after errors and server failures. The retries are shared by the resolvers,
+// which are all tried at least once. Truncated udp responses are sent again
+// over tcp. It returns the resolver of the response, which is the last
+// server failure if no resolver answered successfully.
+func (c *ResolversClient) Do(msg *dns.Msg) (*dns.Msg, string, error) {
+	attempts := c.retries
+	if attempts < len(c.resolvers) {
+		attempts = len(c.resolvers)
+	}
+
+	var failure *dns.Msg
+	var failedBy string
+	var err error
+	for i := 0; i < attempts; i++ {
+		current := c.resolvers[i%len(c.resolvers)]
+		var resp *dns.Msg
+		if current.client == nil {
+			resp, err = c.doHTTPS(current.address, msg)
+		} else {
+			resp, _, err = current.client.Exchange(msg, current.address)
+			if err == nil && resp != nil && resp.Truncated && current.tcpClient != nil {
+				resp, _, err = current.tcpClient.Exchange(msg, current.address)
+			}
+		}
+		if err != nil || resp == nil {
+			continue
+		}
+		if resp.Rcode == dns.RcodeServerFailure || resp.Rcode == dns.RcodeRefused {
+			failure, failedBy = resp, current.name
+			continue
+		}
+		return resp, current.name, nil
+	}
+	if failure != nil {
+		return failure, failedBy, nil
+	}
+	if err == nil {
+		err = errors.New("could not resolve, max retries exceeded")
+	}
+	return nil, "", err
+}
+
+// doHTTPS sends a query to a dns over https resolver
+func (c *ResolversClient) doHTTPS(address string, msg *dns.Msg) (*dns.Msg, error) {
+	packed, err := msg.Pack()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not pack dns message")
+	}
+	req, err := http.NewRequest(http.MethodPost, address, bytes.NewReader(packed))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+
+	httpResp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status code %d", httpResp.StatusCode)
+	}
+	data, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read dns over https response")
+	}
+	resp := new(dns.Msg)
+	if err := resp.Unpack(data); err != nil {
+		return nil, errors.Wrap(err, "could not unpack dns message")
+	}
+	return resp, nil
+}
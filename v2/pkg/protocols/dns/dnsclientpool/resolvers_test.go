@@ -0,0 +1,140 @@
+package dnsclientpool
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+)
+
+// answerA answers the a queries with a fixed address
+func answerA(w dns.ResponseWriter, req *dns.Msg) {
+	resp := new(dns.Msg)
+	resp.SetReply(req)
+	resp.Answer = append(resp.Answer, &dns.A{
+		Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+		A:   net.ParseIP("127.0.0.2"),
+	})
+	_ = w.WriteMsg(resp)
+}
+
+func TestResolversClient(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err, "could not listen for dns server")
+	server := &dns.Server{PacketConn: conn, Handler: dns.HandlerFunc(answerA)}
+	go func() { _ = server.ActivateAndServe() }()
+	defer server.Shutdown()
+
+	msg := new(dns.Msg)
+	msg.SetQuestion("example.com.", dns.TypeA)
+
+	t.Run("fallback", func(t *testing.T) {
+		client, err := NewResolversClient([]string{"127.0.0.1:1", conn.LocalAddr().String()}, 1, time.Second)
+		require.Nil(t, err, "could not create resolvers client")
+
+		resp, resolver, err := client.Do(msg)
+		require.Nil(t, err, "could not resolve with fallback resolver")
+		require.Equal(t, conn.LocalAddr().String(), resolver, "could not get used resolver")
+		require.Len(t, resp.Answer, 1, "could not get answer")
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		failConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.Nil(t, err, "could not listen for dns server")
+		failServer := &dns.Server{PacketConn: failConn, Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+			resp := new(dns.Msg)
+			resp.SetRcode(req, dns.RcodeServerFailure)
+			_ = w.WriteMsg(resp)
+		})}
+		go func() { _ = failServer.ActivateAndServe() }()
+		defer failServer.Shutdown()
+
+		client, err := NewResolversClient([]string{failConn.LocalAddr().String(), "127.0.0.1:1"}, 1, time.Second)
+		require.Nil(t, err, "could not create resolvers client")
+
+		resp, resolver, err := client.Do(msg)
+		require.Nil(t, err, "could not get server failure response")
+		require.Equal(t, failConn.LocalAddr().String(), resolver, "could not get failed resolver")
+		require.Equal(t, dns.RcodeServerFailure, resp.Rcode, "could not get server failure rcode")
+
+		client, err = NewResolversClient([]string{"127.0.0.1:1"}, 1, time.Second)
+		require.Nil(t, err, "could not create resolvers client")
+
+		resp, _, err = client.Do(msg)
+		require.NotNil(t, err, "could resolve with unreachable resolver")
+		require.Nil(t, resp, "could get response from unreachable resolver")
+	})
+
+	t.Run("https", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			data, _ := ioutil.ReadAll(r.Body)
+			req := new(dns.Msg)
+			if err := req.Unpack(data); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			resp := new(dns.Msg)
+			resp.SetReply(req)
+			resp.Answer = append(resp.Answer, &dns.TXT{
+				Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeTXT, Class: dns.ClassINET},
+				Txt: []string{"doh"},
+			})
+			packed, _ := resp.Pack()
+			w.Header().Set("Content-Type", "application/dns-message")
+			_, _ = w.Write(packed)
+		}))
+		defer ts.Close()
+
+		// The test server is served over http, only the scheme is checked
+		client, err := NewResolversClient([]string{"https://doh.invalid/dns-query"}, 1, time.Second)
+		require.Nil(t, err, "could not create resolvers client")
+		client.resolvers[0].address = ts.URL
+
+		resp, resolver, err := client.Do(msg)
+		require.Nil(t, err, "could not resolve with dns over https resolver")
+		require.Equal(t, "https://doh.invalid/dns-query", resolver, "could not get used resolver")
+		require.Equal(t, "doh", resp.Answer[0].(*dns.TXT).Txt[0], "could not get answer")
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.Nil(t, err, "could not listen for dns server")
+		packetConn, err := net.ListenPacket("udp", listener.Addr().String())
+		require.Nil(t, err, "could not listen for dns server")
+
+		udpServer := &dns.Server{PacketConn: packetConn, Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+			resp := new(dns.Msg)
+			resp.SetReply(req)
+			resp.Truncated = true
+			_ = w.WriteMsg(resp)
+		})}
+		tcpServer := &dns.Server{Listener: listener, Handler: dns.HandlerFunc(answerA)}
+		go func() { _ = udpServer.ActivateAndServe() }()
+		go func() { _ = tcpServer.ActivateAndServe() }()
+		defer udpServer.Shutdown()
+		defer tcpServer.Shutdown()
+
+		client, err := NewResolversClient([]string{listener.Addr().String()}, 1, time.Second)
+		require.Nil(t, err, "could not create resolvers client")
+
+		resp, _, err := client.Do(msg)
+		require.Nil(t, err, "could not resolve truncated response")
+		require.False(t, resp.Truncated, "could not retry truncated response over tcp")
+		require.Len(t, resp.Answer, 1, "could not get answer over tcp")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewResolversClient([]string{"quic://1.1.1.1"}, 1, time.Second)
+		require.NotNil(t, err, "could create client with unsupported resolver")
+
+		client, err := NewResolversClient([]string{"1.1.1.1", "tls://[2606:4700:4700::1111]"}, 1, time.Second)
+		require.Nil(t, err, "could not create resolvers client")
+		require.Equal(t, "1.1.1.1:53", client.resolvers[0].address, "could not get default port")
+		require.Equal(t, "[2606:4700:4700::1111]:853", client.resolvers[1].address, "could not get default tls port")
+	})
+}
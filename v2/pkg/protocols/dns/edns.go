@@ -0,0 +1,69 @@
+package dns
+
+import (
+	"net"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+)
+
+// EDNS contains the EDNS0 options of a DNS request
+type EDNS struct {
+	// ClientSubnet is the client subnet sent to the resolvers as a cidr
+	ClientSubnet string `yaml:"client-subnet"`
+	// DO sets the DNSSEC OK bit to get the signatures of the records
+	DO bool `yaml:"do"`
+	// UDPSize is the advertised udp payload size, 4096 by default
+	UDPSize uint16 `yaml:"udp-size"`
+
+	subnet *net.IPNet
+}
+
+// compile parses the client subnet of the options
+func (e *EDNS) compile() error {
+	if e.ClientSubnet == "" {
+		return nil
+	}
+	_, subnet, err := net.ParseCIDR(e.ClientSubnet)
+	if err != nil {
+		return errors.Wrap(err, "could not parse client subnet")
+	}
+	e.subnet = subnet
+	return nil
+}
+
+// apply adds the OPT record of the options to a message
+func (e *EDNS) apply(msg *dns.Msg) {
+	size := e.UDPSize
+	if size == 0 {
+		size = dns.DefaultMsgSize
+	}
+	msg.SetEdns0(size, e.DO)
+	if e.subnet == nil {
+		return
+	}
+	ones, _ := e.subnet.Mask.Size()
+	option := &dns.EDNS0_SUBNET{
+		Code:          dns.EDNS0SUBNET,
+		Family:        1,
+		SourceNetmask: uint8(ones),
+		Address:       e.subnet.IP,
+	}
+	if e.subnet.IP.To4() == nil {
+		option.Family = 2
+	}
+	opt := msg.IsEdns0()
+	opt.Option = append(opt.Option, option)
+}
+
+// isSigned returns true if a response contains signatures of records
+func isSigned(msg *dns.Msg) bool {
+	for _, section := range [][]dns.RR{msg.Answer, msg.Ns} {
+		for _, record := range section {
+			if _, ok := record.(*dns.RRSIG); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package dns
 
 import (
 	"bytes"
+	"strings"
 	"time"
 
 	"github.com/miekg/dns"
@@ -12,33 +13,37 @@ import (
 )
 
 // Match matches a generic data response again a given matcher
-func (r *Request) Match(data map[string]interface{}, matcher *matchers.Matcher) bool {
+func (r *Request) Match(data map[string]interface{}, matcher *matchers.Matcher) (bool, []string) {
 	partString := matcher.Part
 	switch partString {
 	case "body", "all", "":
 		partString = "raw"
 	}
+	// Status matchers compare the rcode of the responses
+	if matcher.GetType() == matchers.StatusMatcher {
+		partString = "status_code"
+	}
 
 	item, ok := data[partString]
 	if !ok {
-		return false
+		return false, nil
 	}
 
 	switch matcher.GetType() {
 	case matchers.StatusMatcher:
-		return matcher.Result(matcher.MatchStatusCode(item.(int)))
+		return matcher.Result(matcher.MatchStatusCode(item.(int))), nil
 	case matchers.SizeMatcher:
-		return matcher.Result(matcher.MatchSize(len(types.ToString(item))))
+		return matcher.Result(matcher.MatchSize(len(partToString(item)))), nil
 	case matchers.WordsMatcher:
-		return matcher.Result(matcher.MatchWords(types.ToString(item)))
+		return matcher.ResultWithMatchedSnippet(matcher.MatchWords(partToString(item)))
 	case matchers.RegexMatcher:
-		return matcher.Result(matcher.MatchRegex(types.ToString(item)))
+		return matcher.ResultWithMatchedSnippet(matcher.MatchRegex(partToString(item)))
 	case matchers.BinaryMatcher:
-		return matcher.Result(matcher.MatchBinary(types.ToString(item)))
+		return matcher.ResultWithMatchedSnippet(matcher.MatchBinary(partToString(item)))
 	case matchers.DSLMatcher:
-		return matcher.Result(matcher.MatchDSL(data))
+		return matcher.Result(matcher.MatchDSL(data)), nil
 	}
-	return false
+	return false, nil
 }
 
 // Extract performs extracting operation for a extractor on model and returns true or false.
@@ -53,7 +58,7 @@ func (r *Request) Extract(data map[string]interface{}, extractor *extractors.Ext
 	if !ok {
 		return nil
 	}
-	itemStr := types.ToString(item)
+	itemStr := partToString(item)
 
 	switch extractor.GetType() {
 	case extractors.RegexExtractor:
@@ -66,14 +71,22 @@ func (r *Request) Extract(data map[string]interface{}, extractor *extractors.Ext
 
 // responseToDSLMap converts a DNS response to a map for use in DSL matching
 func (r *Request) responseToDSLMap(req, resp *dns.Msg, host, matched string) output.InternalEvent {
-	data := make(output.InternalEvent, 11)
+	data := make(output.InternalEvent, 24)
 
 	// Some data regarding the request metadata
 	data["host"] = host
 	data["matched"] = matched
 	data["request"] = req.String()
 
-	data["rcode"] = resp.Rcode
+	data["rcode"] = dns.RcodeToString[resp.Rcode]
+	data["status_code"] = resp.Rcode
+	data["qr"] = resp.Response
+	data["aa"] = resp.Authoritative
+	data["tc"] = resp.Truncated
+	data["rd"] = resp.RecursionDesired
+	data["ra"] = resp.RecursionAvailable
+	data["answer_count"] = len(resp.Answer)
+	data["dnssec_signed"] = isSigned(resp)
 	buffer := &bytes.Buffer{}
 	for _, question := range resp.Question {
 		buffer.WriteString(question.String())
@@ -85,10 +98,23 @@ func (r *Request) responseToDSLMap(req, resp *dns.Msg, host, matched string) out
 		buffer.WriteString(extra.String())
 	}
 	data["extra"] = buffer.String()
+	data["additional"] = data["extra"]
 	buffer.Reset()
 
+	data["ip"] = ""
 	for _, answer := range resp.Answer {
 		buffer.WriteString(answer.String())
+
+		// the first resolved address is the ip of the host
+		if data["ip"] != "" {
+			continue
+		}
+		switch record := answer.(type) {
+		case *dns.A:
+			data["ip"] = record.A.String()
+		case *dns.AAAA:
+			data["ip"] = record.AAAA.String()
+		}
 	}
 	data["answer"] = buffer.String()
 	buffer.Reset()
@@ -97,8 +123,23 @@ func (r *Request) responseToDSLMap(req, resp *dns.Msg, host, matched string) out
 		buffer.WriteString(ns.String())
 	}
 	data["ns"] = buffer.String()
+	data["authority"] = data["ns"]
 	buffer.Reset()
 
+	// Parsed values of the answer and authority records by type,
+	// the authority contains the ns and soa records of delegations.
+	// The ns records are under ns_records as ns is the authority.
+	for _, section := range [][]dns.RR{resp.Answer, resp.Ns} {
+		for _, record := range section {
+			name, value := recordValue(record)
+			if name == "" {
+				continue
+			}
+			values, _ := data[name].([]string)
+			data[name] = append(values, value)
+		}
+	}
+
 	rawData := resp.String()
 	data["raw"] = rawData
 	data["template-id"] = r.options.TemplateID
@@ -107,6 +148,41 @@ func (r *Request) responseToDSLMap(req, resp *dns.Msg, host, matched string) out
 	return data
 }
 
+// recordValue returns the part name and value of a dns record, or
+// an empty name for the record types without a part.
+func recordValue(record dns.RR) (string, string) {
+	switch record := record.(type) {
+	case *dns.A:
+		return "a", record.A.String()
+	case *dns.AAAA:
+		return "aaaa", record.AAAA.String()
+	case *dns.CNAME:
+		return "cname", strings.TrimSuffix(record.Target, ".")
+	case *dns.NS:
+		return "ns_records", strings.TrimSuffix(record.Ns, ".")
+	case *dns.MX:
+		return "mx", strings.TrimSuffix(record.Mx, ".")
+	case *dns.PTR:
+		return "ptr", strings.TrimSuffix(record.Ptr, ".")
+	case *dns.TXT:
+		return "txt", strings.Join(record.Txt, "")
+	case *dns.SOA, *dns.SRV, *dns.CAA:
+		// The values of these records are their data fields
+		name := strings.ToLower(dns.TypeToString[record.Header().Rrtype])
+		return name, strings.TrimPrefix(record.String(), record.Header().String())
+	}
+	return "", ""
+}
+
+// partToString returns the string of a part, the values of the parsed
+// record parts are joined with new lines.
+func partToString(item interface{}) string {
+	if values, ok := item.([]string); ok {
+		return strings.Join(values, "\n")
+	}
+	return types.ToString(item)
+}
+
 // MakeResultEvent creates a result event from internal wrapped event
 func (r *Request) MakeResultEvent(wrapped *output.InternalWrappedEvent) []*output.ResultEvent {
 	if len(wrapped.OperatorsResult.DynamicValues) > 0 {
@@ -144,11 +220,16 @@ func (r *Request) makeResultEventItem(wrapped *output.InternalWrappedEvent) *out
 		Host:             types.ToString(wrapped.InternalEvent["host"]),
 		Matched:          types.ToString(wrapped.InternalEvent["matched"]),
 		ExtractedResults: wrapped.OperatorsResult.OutputExtracts,
+		MatchedSnippets:  wrapped.OperatorsResult.MatchedSnippets,
+		MatchedWords:     wrapped.OperatorsResult.MatchedWords,
+		MatcherNames:     wrapped.OperatorsResult.AndMatches,
+		ExtractedNamed:   wrapped.OperatorsResult.Extracts,
+		IP:               types.ToString(wrapped.InternalEvent["ip"]),
 		Timestamp:        time.Now(),
 	}
 	if r.options.Options.JSONRequests {
-		data.Request = types.ToString(wrapped.InternalEvent["request"])
-		data.Response = types.ToString(wrapped.InternalEvent["raw"])
+		data.Request = output.TruncateRR(types.ToString(wrapped.InternalEvent["request"]), r.options.Options.JSONRequestsMaxSize)
+		data.Response = output.TruncateRR(types.ToString(wrapped.InternalEvent["raw"]), r.options.Options.JSONRequestsMaxSize)
 	}
 	return data
 }
@@ -2,7 +2,6 @@ package dns
 
 import (
 	"net"
-	"strconv"
 	"testing"
 
 	"github.com/miekg/dns"
@@ -42,8 +41,93 @@ func TestResponseToDSLMap(t *testing.T) {
 	resp.Answer = append(resp.Answer, &dns.A{A: net.ParseIP("1.1.1.1"), Hdr: dns.RR_Header{Name: "one.one.one.one."}})
 
 	event := request.responseToDSLMap(req, resp, "one.one.one.one", "one.one.one.one")
-	require.Len(t, event, 12, "could not get correct number of items in dsl map")
-	require.Equal(t, dns.RcodeSuccess, event["rcode"], "could not get correct rcode")
+	require.Len(t, event, 24, "could not get correct number of items in dsl map")
+	require.Equal(t, "NOERROR", event["rcode"], "could not get correct rcode")
+	require.Equal(t, dns.RcodeSuccess, event["status_code"], "could not get correct rcode code")
+	require.Equal(t, 1, event["answer_count"], "could not get correct answer count")
+	require.Equal(t, "1.1.1.1", event["ip"], "could not get correct ip")
+	require.Equal(t, []string{"1.1.1.1"}, event["a"], "could not get correct a records")
+	require.Equal(t, false, event["dnssec_signed"], "could get signed response")
+
+	resp.Answer = append(resp.Answer, &dns.RRSIG{Hdr: dns.RR_Header{Name: "one.one.one.one.", Rrtype: dns.TypeRRSIG}, TypeCovered: dns.TypeA})
+	event = request.responseToDSLMap(req, resp, "one.one.one.one", "one.one.one.one")
+	require.Equal(t, true, event["dnssec_signed"], "could not get signed response")
+}
+
+func TestResponseToDSLMapSections(t *testing.T) {
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-dns"
+	request := &Request{
+		Type: "NS",
+		ID:   templateID,
+		Name: "{{FQDN}}",
+	}
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	err := request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile dns request")
+
+	req := new(dns.Msg)
+	req.Question = append(req.Question, dns.Question{Name: "example.com.", Qtype: dns.TypeNS, Qclass: dns.ClassINET})
+
+	header := func(rrtype uint16) dns.RR_Header {
+		return dns.RR_Header{Name: "example.com.", Rrtype: rrtype, Class: dns.ClassINET, Ttl: 300}
+	}
+	resp := new(dns.Msg)
+	resp.Answer = []dns.RR{
+		&dns.NS{Hdr: header(dns.TypeNS), Ns: "a.iana-servers.net."},
+		&dns.NS{Hdr: header(dns.TypeNS), Ns: "b.iana-servers.net."},
+		&dns.CNAME{Hdr: header(dns.TypeCNAME), Target: "www.example.org."},
+		&dns.TXT{Hdr: header(dns.TypeTXT), Txt: []string{"v=spf1 ", "-all"}},
+		&dns.SRV{Hdr: header(dns.TypeSRV), Priority: 10, Weight: 5, Port: 5060, Target: "sip.example.com."},
+		&dns.CAA{Hdr: header(dns.TypeCAA), Tag: "issue", Value: "letsencrypt.org"},
+	}
+	resp.Ns = []dns.RR{
+		&dns.SOA{Hdr: header(dns.TypeSOA), Ns: "ns.icann.org.", Mbox: "noc.dns.icann.org.", Serial: 1},
+	}
+	resp.Extra = []dns.RR{&dns.A{Hdr: header(dns.TypeA), A: net.ParseIP("199.43.135.53")}}
+
+	event := request.responseToDSLMap(req, resp, "example.com", "example.com")
+	require.Equal(t, []string{"a.iana-servers.net", "b.iana-servers.net"}, event["ns_records"], "could not get ns records")
+	require.Equal(t, []string{"www.example.org"}, event["cname"], "could not get cname records")
+	require.Equal(t, []string{"v=spf1 -all"}, event["txt"], "could not get txt records")
+	require.Equal(t, []string{"10 5 5060 sip.example.com."}, event["srv"], "could not get srv records")
+	require.Equal(t, []string{"0 issue \"letsencrypt.org\""}, event["caa"], "could not get caa records")
+	require.Len(t, event["soa"], 1, "could not get soa record from authority")
+	require.Contains(t, event["authority"], "noc.dns.icann.org.", "could not get authority section")
+	require.Equal(t, event["authority"], event["ns"], "could not get authority section as ns")
+	require.Contains(t, event["additional"], "199.43.135.53", "could not get additional section")
+	require.NotContains(t, event, "a", "could get a records of additional section")
+
+	t.Run("answer", func(t *testing.T) {
+		matcher := &matchers.Matcher{
+			Part:  "answer",
+			Type:  "word",
+			Words: []string{"a.iana-servers.net."},
+		}
+		err := matcher.CompileMatchers()
+		require.Nil(t, err, "could not compile matcher")
+
+		matched, _ := request.Match(event, matcher)
+		require.True(t, matched, "could not match answer section")
+	})
+
+	t.Run("ns_records", func(t *testing.T) {
+		matcher := &matchers.Matcher{
+			Part:  "ns_records",
+			Type:  "regex",
+			Regex: []string{"(?m)^b\\.iana-servers\\.net$"},
+		}
+		err := matcher.CompileMatchers()
+		require.Nil(t, err, "could not compile matcher")
+
+		matched, _ := request.Match(event, matcher)
+		require.True(t, matched, "could not match ns records")
+	})
 }
 
 func TestDNSOperatorMatch(t *testing.T) {
@@ -84,7 +168,7 @@ func TestDNSOperatorMatch(t *testing.T) {
 		err = matcher.CompileMatchers()
 		require.Nil(t, err, "could not compile matcher")
 
-		matched := request.Match(event, matcher)
+		matched, _ := request.Match(event, matcher)
 		require.True(t, matched, "could not match valid response")
 	})
 
@@ -97,7 +181,7 @@ func TestDNSOperatorMatch(t *testing.T) {
 		err = matcher.CompileMatchers()
 		require.Nil(t, err, "could not compile rcode matcher")
 
-		matched := request.Match(event, matcher)
+		matched, _ := request.Match(event, matcher)
 		require.True(t, matched, "could not match valid rcode response")
 	})
 
@@ -111,7 +195,7 @@ func TestDNSOperatorMatch(t *testing.T) {
 		err := matcher.CompileMatchers()
 		require.Nil(t, err, "could not compile negative matcher")
 
-		matched := request.Match(event, matcher)
+		matched, _ := request.Match(event, matcher)
 		require.True(t, matched, "could not match valid negative response matcher")
 	})
 
@@ -124,7 +208,7 @@ func TestDNSOperatorMatch(t *testing.T) {
 		err := matcher.CompileMatchers()
 		require.Nil(t, err, "could not compile matcher")
 
-		matched := request.Match(event, matcher)
+		matched, _ := request.Match(event, matcher)
 		require.False(t, matched, "could match invalid response matcher")
 	})
 }
@@ -182,7 +266,7 @@ func TestDNSOperatorExtract(t *testing.T) {
 
 		data := request.Extract(event, extractor)
 		require.Greater(t, len(data), 0, "could not extractor kval valid response")
-		require.Equal(t, map[string]struct{}{strconv.Itoa(dns.RcodeSuccess): {}}, data, "could not extract correct kval data")
+		require.Equal(t, map[string]struct{}{dns.RcodeToString[dns.RcodeSuccess]: {}}, data, "could not extract correct kval data")
 	})
 }
 
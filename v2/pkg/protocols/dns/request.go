@@ -1,12 +1,17 @@
 package dns
 
 import (
+	"net"
 	"net/url"
+	"time"
+
+	"github.com/miekg/dns"
 
 	"github.com/pkg/errors"
 	"github.com/projectdiscovery/gologger"
 	"github.com/yaklang/nuclei/v2/pkg/output"
 	"github.com/yaklang/nuclei/v2/pkg/protocols"
+	"github.com/yaklang/nuclei/v2/pkg/types"
 )
 
 var _ protocols.Request = &Request{}
@@ -20,9 +25,13 @@ func (r *Request) ExecuteWithResults(input string, metadata, previous output.Int
 	} else {
 		domain = input
 	}
+	if r.question != dns.TypePTR && net.ParseIP(domain) != nil {
+		gologger.Verbose().Msgf("[%s] Skipped DNS request for IP address %s", r.options.TemplateID, domain)
+		return nil
+	}
 
 	// Compile each request for the template based on the URL
-	compiledRequest, err := r.Make(domain)
+	compiledRequest, err := r.Make(domain, metadata)
 	if err != nil {
 		r.options.Output.Request(r.options.TemplateID, domain, "dns", err)
 		r.options.Progress.IncrementFailedRequestsBy(1)
@@ -33,19 +42,26 @@ func (r *Request) ExecuteWithResults(input string, metadata, previous output.Int
 		gologger.Info().Str("domain", domain).Msgf("[%s] Dumped DNS request for %s", r.options.TemplateID, domain)
 		gologger.Print().Msgf("%s", compiledRequest.String())
 	}
+	if r.question == dns.TypeAXFR {
+		return r.executeTransfers(input, domain, compiledRequest, previous, callback)
+	}
 
 	// Send the request to the target servers
-	resp, err := r.dnsClient.Do(compiledRequest)
+	timeStart := time.Now()
+	resp, resolver, err := r.doWithTimeout(compiledRequest)
+	duration := time.Since(timeStart)
+	r.options.Progress.RecordLatency(domain, duration)
+	if err == nil && resp == nil {
+		err = errors.New("no response received")
+	}
 	if err != nil {
 		r.options.Output.Request(r.options.TemplateID, domain, "dns", err)
 		r.options.Progress.IncrementFailedRequestsBy(1)
-	}
-	if resp == nil {
 		return errors.Wrap(err, "could not send dns request")
 	}
 	r.options.Progress.IncrementRequests()
 
-	r.options.Output.Request(r.options.TemplateID, domain, "dns", err)
+	r.options.Output.Request(r.options.TemplateID, domain, "dns", nil)
 	gologger.Verbose().Msgf("[%s] Sent DNS request to %s", r.options.TemplateID, domain)
 
 	if r.options.Options.Debug || r.options.Options.DebugResponse {
@@ -53,6 +69,11 @@ func (r *Request) ExecuteWithResults(input string, metadata, previous output.Int
 		gologger.Print().Msgf("%s", resp.String())
 	}
 	outputEvent := r.responseToDSLMap(compiledRequest, resp, input, input)
+	outputEvent["duration"] = duration.Seconds()
+	outputEvent["resolver"] = resolver
+	if r.WildcardCheck {
+		r.addWildcard(outputEvent, compiledRequest.Question[0].Name, resp)
+	}
 	for k, v := range previous {
 		outputEvent[k] = v
 	}
@@ -69,6 +90,31 @@ func (r *Request) ExecuteWithResults(input string, metadata, previous output.Int
 	return nil
 }
 
+// doWithTimeout sends the dns request to the resolvers giving up
+// after the dns protocol timeout. The resolver used is only returned
+// for the resolvers of the request, which time out on each attempt.
+func (r *Request) doWithTimeout(msg *dns.Msg) (*dns.Msg, string, error) {
+	if r.resolversClient != nil {
+		return r.resolversClient.Do(msg)
+	}
+	type response struct {
+		msg *dns.Msg
+		err error
+	}
+	results := make(chan response, 1)
+	go func() {
+		resp, err := r.dnsClient.Do(msg)
+		results <- response{msg: resp, err: err}
+	}()
+
+	select {
+	case result := <-results:
+		return result.msg, "", result.err
+	case <-time.After(r.options.Options.ProtocolTimeout(types.DNSProtocol)):
+		return nil, "", errors.New("dns request timed out")
+	}
+}
+
 // isURL tests a string to determine if it is a well-structured url or not.
 func isURL(toTest string) bool {
 	_, err := url.ParseRequestURI(toTest)
@@ -1,8 +1,13 @@
 package dns
 
 import (
+	"net"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/miekg/dns"
 	"github.com/yaklang/nuclei/v2/internal/testutils"
 	"github.com/yaklang/nuclei/v2/pkg/operators"
 	"github.com/yaklang/nuclei/v2/pkg/operators/extractors"
@@ -58,6 +63,9 @@ func TestDNSExecuteWithResults(t *testing.T) {
 	require.Equal(t, "test", finalEvent.Results[0].MatcherName, "could not get correct matcher name of results")
 	require.Equal(t, 1, len(finalEvent.Results[0].ExtractedResults), "could not get correct number of extracted results")
 	require.Equal(t, "93.184.216.34", finalEvent.Results[0].ExtractedResults[0], "could not get correct extracted results")
+	require.Contains(t, finalEvent.InternalEvent, "duration", "could not get duration of request")
+	require.Equal(t, "93.184.216.34", finalEvent.InternalEvent["ip"], "could not get ip of request")
+	require.Equal(t, "93.184.216.34", finalEvent.Results[0].IP, "could not get ip of result")
 	finalEvent = nil
 
 	t.Run("url-to-domain", func(t *testing.T) {
@@ -74,4 +82,256 @@ func TestDNSExecuteWithResults(t *testing.T) {
 	require.Equal(t, 1, len(finalEvent.Results[0].ExtractedResults), "could not get correct number of extracted results")
 	require.Equal(t, "93.184.216.34", finalEvent.Results[0].ExtractedResults[0], "could not get correct extracted results")
 	finalEvent = nil
+
+	nsRequest := &Request{
+		Type:    "NS",
+		Class:   "INET",
+		Retries: 5,
+		ID:      templateID,
+		Name:    "{{FQDN}}",
+		Operators: operators.Operators{
+			Matchers: []*matchers.Matcher{{
+				Name:  "ns",
+				Part:  "ns_records",
+				Type:  "regex",
+				Regex: []string{"[a-z0-9.-]+\\.[a-z]+"},
+			}},
+		},
+	}
+	err = nsRequest.Compile(executerOpts)
+	require.Nil(t, err, "could not compile dns ns request")
+
+	t.Run("ns", func(t *testing.T) {
+		metadata := make(output.InternalEvent)
+		previous := make(output.InternalEvent)
+		err := nsRequest.ExecuteWithResults("example.com", metadata, previous, func(event *output.InternalWrappedEvent) {
+			finalEvent = event
+		})
+		require.Nil(t, err, "could not execute dns ns request")
+	})
+	require.NotNil(t, finalEvent, "could not get event output from ns request")
+	require.Equal(t, 1, len(finalEvent.Results), "could not get correct number of ns results")
+	require.Equal(t, "ns", finalEvent.Results[0].MatcherName, "could not get correct matcher name of ns results")
+	require.NotEmpty(t, finalEvent.InternalEvent["ns_records"], "could not get ns records of request")
+}
+
+func TestDNSExecuteWithResolvers(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err, "could not listen for dns server")
+	server := &dns.Server{PacketConn: conn, Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		resp := new(dns.Msg)
+		resp.SetReply(req)
+		resp.Answer = append(resp.Answer, &dns.CNAME{
+			Hdr:    dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: 60},
+			Target: "takeover.example.org.",
+		})
+		_ = w.WriteMsg(resp)
+	})}
+	go func() { _ = server.ActivateAndServe() }()
+	defer server.Shutdown()
+
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-dns"
+	request := &Request{
+		Type:      "CNAME",
+		Retries:   2,
+		ID:        templateID,
+		Name:      "{{FQDN}}",
+		Resolvers: []string{conn.LocalAddr().String()},
+		Operators: operators.Operators{
+			Matchers: []*matchers.Matcher{{
+				Type: "dsl",
+				DSL:  []string{"contains(resolver, '127.0.0.1') && contains(answer, 'takeover.example.org')"},
+			}},
+		},
+	}
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	err = request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile dns request")
+
+	var finalEvent *output.InternalWrappedEvent
+	err = request.ExecuteWithResults("example.com", make(output.InternalEvent), make(output.InternalEvent), func(event *output.InternalWrappedEvent) {
+		finalEvent = event
+	})
+	require.Nil(t, err, "could not execute dns request")
+	require.NotNil(t, finalEvent, "could not get event output from request")
+	require.Equal(t, conn.LocalAddr().String(), finalEvent.InternalEvent["resolver"], "could not get resolver of request")
+	require.Equal(t, 1, len(finalEvent.Results), "could not get correct number of results")
+}
+
+func TestDNSExecuteProtocolTimeout(t *testing.T) {
+	// The server reads the queries without ever answering them
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err, "could not listen for dns server")
+	defer conn.Close()
+
+	options := *testutils.DefaultOptions
+	options.Timeout = 30
+	options.TimeoutDNS = 1
+
+	testutils.Init(&options)
+	templateID := "testing-dns"
+	request := &Request{
+		Type:      "A",
+		Retries:   1,
+		ID:        templateID,
+		Name:      "{{FQDN}}",
+		Resolvers: []string{conn.LocalAddr().String()},
+	}
+	executerOpts := testutils.NewMockExecuterOptions(&options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	err = request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile dns request")
+
+	started := time.Now()
+	err = request.ExecuteWithResults("example.com", make(output.InternalEvent), make(output.InternalEvent), func(event *output.InternalWrappedEvent) {})
+	require.NotNil(t, err, "could get response from silent server")
+	require.Less(t, time.Since(started), 5*time.Second, "could not use dns protocol timeout")
+}
+
+func TestDNSExecuteSkipsIPs(t *testing.T) {
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-dns"
+	request := &Request{Type: "A", ID: templateID, Name: "{{FQDN}}"}
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	err := request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile dns request")
+
+	for _, input := range []string{"192.0.2.10", "2001:db8::1", "http://[2001:db8::1]:8080/"} {
+		var called bool
+		err := request.ExecuteWithResults(input, make(output.InternalEvent), make(output.InternalEvent), func(event *output.InternalWrappedEvent) {
+			called = true
+		})
+		require.Nil(t, err, "could not skip dns request for %s", input)
+		require.False(t, called, "could send dns request for %s", input)
+	}
+}
+
+func TestDNSExecuteResponseFlags(t *testing.T) {
+	server := testutils.NewDNSServer("127.0.0.1:0", func(w dns.ResponseWriter, req *dns.Msg) {
+		resp := new(dns.Msg)
+		resp.SetRcode(req, dns.RcodeNameError)
+		resp.Authoritative = true
+		_ = w.WriteMsg(resp)
+	})
+	defer server.Close()
+
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-dns"
+	request := &Request{
+		Type:      "CNAME",
+		ID:        templateID,
+		Name:      "{{FQDN}}",
+		Recursion: true,
+		Resolvers: []string{server.Address},
+		Operators: operators.Operators{
+			MatchersCondition: "and",
+			Matchers: []*matchers.Matcher{
+				{Name: "status", Type: "status", Status: []int{dns.RcodeNameError}},
+				{Name: "rcode", Type: "word", Part: "rcode", Words: []string{"NXDOMAIN"}},
+				{Name: "flags", Type: "dsl", DSL: []string{"aa && qr && rd && !tc && answer_count == 0"}},
+			},
+		},
+	}
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	err := request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile dns request")
+
+	var finalEvent *output.InternalWrappedEvent
+	err = request.ExecuteWithResults("dangling.example.com", make(output.InternalEvent), make(output.InternalEvent), func(event *output.InternalWrappedEvent) {
+		finalEvent = event
+	})
+	require.Nil(t, err, "could not execute dns request")
+	require.NotNil(t, finalEvent, "could not get event output from request")
+	require.Equal(t, "NXDOMAIN", finalEvent.InternalEvent["rcode"], "could not get rcode name")
+	require.Equal(t, true, finalEvent.InternalEvent["aa"], "could not get authoritative flag")
+	require.Equal(t, false, finalEvent.InternalEvent["ra"], "could not get recursion available flag")
+	require.Len(t, finalEvent.Results, 1, "could not match nxdomain response")
+	require.Equal(t, []string{"status", "rcode", "flags"}, finalEvent.Results[0].MatcherNames, "could not get and matcher names")
+}
+
+func TestDNSExecuteWildcardCheck(t *testing.T) {
+	var probes int32
+	server := testutils.NewDNSServer("127.0.0.1:0", func(w dns.ResponseWriter, req *dns.Msg) {
+		resp := new(dns.Msg)
+		resp.SetReply(req)
+
+		name := req.Question[0].Name
+		switch {
+		case strings.HasSuffix(name, ".wild.test."):
+			if !strings.HasPrefix(name, "www.") {
+				atomic.AddInt32(&probes, 1)
+			}
+			record, _ := dns.NewRR(name + " 60 IN A 10.0.0.1")
+			resp.Answer = append(resp.Answer, record)
+		case name == "www.plain.test.":
+			record, _ := dns.NewRR(name + " 60 IN A 10.0.0.2")
+			resp.Answer = append(resp.Answer, record)
+		default:
+			resp.Rcode = dns.RcodeNameError
+		}
+		_ = w.WriteMsg(resp)
+	})
+	defer server.Close()
+
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-dns"
+	request := &Request{
+		Type:          "A",
+		ID:            templateID,
+		Name:          "{{FQDN}}",
+		Resolvers:     []string{server.Address},
+		WildcardCheck: true,
+		Operators: operators.Operators{
+			Matchers: []*matchers.Matcher{{Type: "dsl", DSL: []string{"!is_wildcard"}}},
+		},
+	}
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	err := request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile dns request")
+
+	execute := func(domain string) *output.InternalWrappedEvent {
+		var finalEvent *output.InternalWrappedEvent
+		err := request.ExecuteWithResults(domain, make(output.InternalEvent), make(output.InternalEvent), func(event *output.InternalWrappedEvent) {
+			finalEvent = event
+		})
+		require.Nil(t, err, "could not execute dns request")
+		require.NotNil(t, finalEvent, "could not get event output from request")
+		return finalEvent
+	}
+
+	event := execute("www.wild.test")
+	require.Equal(t, true, event.InternalEvent["is_wildcard"], "could not detect wildcard")
+	require.Equal(t, []string{"10.0.0.1"}, event.InternalEvent["wildcard_ips"], "could not get wildcard ips")
+	require.Empty(t, event.Results, "could match wildcard answer")
+
+	event = execute("www.wild.test")
+	require.Equal(t, true, event.InternalEvent["is_wildcard"], "could not detect cached wildcard")
+	require.Equal(t, int32(1), atomic.LoadInt32(&probes), "could not cache wildcard of apex")
+
+	event = execute("www.plain.test")
+	require.Equal(t, false, event.InternalEvent["is_wildcard"], "could detect wildcard without one")
+	require.Len(t, event.Results, 1, "could not match answer without wildcard")
 }
@@ -0,0 +1,130 @@
+package dns
+
+import (
+	"net"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/yaklang/nuclei/v2/pkg/protocols"
+	"github.com/yaklang/nuclei/v2/pkg/types"
+)
+
+// executeTransfers performs the zone transfer of a domain from each of its
+// nameservers, or from the resolvers of the request. Only the complete
+// transfers are matched, refused and partial ones are logged as failures.
+func (r *Request) executeTransfers(input, domain string, msg *dns.Msg, previous output.InternalEvent, callback protocols.OutputEventCallback) error {
+	servers, err := r.transferServers(msg.Question[0].Name)
+	if err != nil {
+		r.options.Output.Request(r.options.TemplateID, domain, "dns", err)
+		r.options.Progress.IncrementFailedRequestsBy(1)
+		return errors.Wrap(err, "could not get nameservers")
+	}
+
+	var transferred bool
+	for _, server := range servers {
+		timeStart := time.Now()
+		records, err := r.transfer(msg, server)
+		duration := time.Since(timeStart)
+		r.options.Output.Request(r.options.TemplateID, server, "dns", err)
+		if err != nil {
+			gologger.Verbose().Msgf("[%s] Could not transfer zone of %s from %s: %s\n", r.options.TemplateID, domain, server, err)
+			continue
+		}
+		transferred = true
+		gologger.Verbose().Msgf("[%s] Transferred zone of %s from %s", r.options.TemplateID, domain, server)
+
+		resp := new(dns.Msg)
+		resp.SetReply(msg)
+		resp.Answer = records
+		if r.options.Options.Debug || r.options.Options.DebugResponse {
+			gologger.Debug().Msgf("[%s] Dumped DNS zone transfer response for %s", r.options.TemplateID, domain)
+			gologger.Print().Msgf("%s", resp.String())
+		}
+
+		outputEvent := r.responseToDSLMap(msg, resp, input, input)
+		outputEvent["duration"] = duration.Seconds()
+		outputEvent["resolver"] = server
+		outputEvent["records_count"] = len(records)
+		for k, v := range previous {
+			outputEvent[k] = v
+		}
+
+		event := &output.InternalWrappedEvent{InternalEvent: outputEvent}
+		if r.CompiledOperators != nil {
+			result, ok := r.CompiledOperators.Execute(outputEvent, r.Match, r.Extract)
+			if ok && result != nil {
+				event.OperatorsResult = result
+				event.Results = r.MakeResultEvent(event)
+			}
+		}
+		callback(event)
+	}
+	if transferred {
+		r.options.Progress.IncrementRequests()
+	} else {
+		r.options.Progress.IncrementFailedRequestsBy(1)
+	}
+	return nil
+}
+
+// transferServers returns the servers to transfer a zone from, the
+// resolvers of the request or the nameservers of the zone.
+func (r *Request) transferServers(zone string) ([]string, error) {
+	var servers []string
+	for _, resolver := range r.Resolvers {
+		resolver = strings.TrimSpace(resolver)
+		if _, _, err := net.SplitHostPort(resolver); err != nil {
+			resolver = net.JoinHostPort(strings.Trim(resolver, "[]"), "53")
+		}
+		servers = append(servers, resolver)
+	}
+	if len(servers) > 0 {
+		return servers, nil
+	}
+
+	query := new(dns.Msg)
+	query.SetQuestion(zone, dns.TypeNS)
+	resp, _, err := r.doWithTimeout(query)
+	if resp == nil {
+		return nil, err
+	}
+	for _, answer := range resp.Answer {
+		if record, ok := answer.(*dns.NS); ok {
+			servers = append(servers, net.JoinHostPort(strings.TrimSuffix(record.Ns, "."), "53"))
+		}
+	}
+	if len(servers) == 0 {
+		return nil, errors.Errorf("no nameservers found for %s", zone)
+	}
+	return servers, nil
+}
+
+// transfer transfers a zone from a server, returning an error for refused
+// and incomplete transfers.
+func (r *Request) transfer(msg *dns.Msg, server string) ([]dns.RR, error) {
+	timeout := r.options.Options.ProtocolTimeout(types.DNSProtocol)
+	transfer := &dns.Transfer{DialTimeout: timeout, ReadTimeout: timeout}
+
+	envelopes, err := transfer.In(msg, server)
+	if err != nil {
+		return nil, err
+	}
+	var records []dns.RR
+	for envelope := range envelopes {
+		if envelope.Error != nil {
+			// Drain the channel to let the transfer close the connection
+			for range envelopes {
+			}
+			return nil, envelope.Error
+		}
+		records = append(records, envelope.RR...)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("empty zone transfer")
+	}
+	return records, nil
+}
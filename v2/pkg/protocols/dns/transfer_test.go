@@ -0,0 +1,89 @@
+package dns
+
+import (
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+	"github.com/yaklang/nuclei/v2/internal/testutils"
+	"github.com/yaklang/nuclei/v2/pkg/operators"
+	"github.com/yaklang/nuclei/v2/pkg/operators/matchers"
+	"github.com/yaklang/nuclei/v2/pkg/output"
+)
+
+// zoneTransferHandler allows the transfer of example.test, refuses the
+// one of refused.test and stops the one of partial.test midway.
+func zoneTransferHandler(w dns.ResponseWriter, req *dns.Msg) {
+	resp := new(dns.Msg)
+	resp.SetReply(req)
+
+	zone := req.Question[0].Name
+	soa, _ := dns.NewRR(zone + " 3600 IN SOA ns1." + zone + " admin." + zone + " 1 7200 3600 1209600 3600")
+	a, _ := dns.NewRR("internal." + zone + " 3600 IN A 10.0.0.1")
+	switch zone {
+	case "example.test.":
+		resp.Answer = []dns.RR{soa, a, soa}
+	case "partial.test.":
+		resp.Answer = []dns.RR{soa, a}
+	default:
+		resp.Rcode = dns.RcodeRefused
+	}
+	_ = w.WriteMsg(resp)
+	_ = w.Close()
+}
+
+func TestDNSZoneTransfer(t *testing.T) {
+	server := testutils.NewDNSServer("127.0.0.1:0", zoneTransferHandler)
+	defer server.Close()
+
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-dns"
+	request := &Request{
+		Type:      "AXFR",
+		ID:        templateID,
+		Name:      "{{FQDN}}",
+		Resolvers: []string{server.Address},
+		Operators: operators.Operators{
+			Matchers: []*matchers.Matcher{{
+				Type: "dsl",
+				DSL:  []string{"records_count > 0"},
+			}},
+		},
+	}
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	err := request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile dns request")
+
+	var events []*output.InternalWrappedEvent
+	execute := func(domain string) {
+		events = nil
+		err := request.ExecuteWithResults(domain, make(output.InternalEvent), make(output.InternalEvent), func(event *output.InternalWrappedEvent) {
+			events = append(events, event)
+		})
+		require.Nil(t, err, "could not execute dns request")
+	}
+
+	execute("example.test")
+	require.Len(t, events, 1, "could not get transfer event")
+	require.Equal(t, 3, events[0].InternalEvent["records_count"], "could not get records count")
+	require.Contains(t, events[0].InternalEvent["answer"], "internal.example.test.", "could not get transferred records")
+	require.Equal(t, server.Address, events[0].InternalEvent["resolver"], "could not get transfer server")
+	require.Len(t, events[0].Results, 1, "could not match transfer")
+
+	execute("refused.test")
+	require.Empty(t, events, "could get event for refused transfer")
+
+	execute("partial.test")
+	require.Empty(t, events, "could get event for partial transfer")
+
+	t.Run("invalid", func(t *testing.T) {
+		request := &Request{Type: "AXFR", Name: "{{FQDN}}", Resolvers: []string{"https://dns.google/dns-query"}}
+		err := request.Compile(executerOpts)
+		require.NotNil(t, err, "could compile zone transfer with dns over https resolver")
+	})
+}
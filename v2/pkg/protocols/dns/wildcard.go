@@ -0,0 +1,93 @@
+package dns
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/miekg/dns"
+	"github.com/rs/xid"
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"golang.org/x/net/publicsuffix"
+)
+
+// wildcard contains the answers of a random name under an apex
+type wildcard struct {
+	values []string
+	ips    []string
+}
+
+var (
+	wildcardsMutex sync.Mutex
+	wildcards      = make(map[string]*wildcard)
+)
+
+// addWildcard adds to the event whether the answers of a name are the ones
+// of a wildcard of its apex, along with the ips of the wildcard.
+func (r *Request) addWildcard(event output.InternalEvent, name string, resp *dns.Msg) {
+	current := r.getWildcard(name)
+	if current == nil {
+		current = &wildcard{}
+	}
+	event["wildcard_ips"] = current.ips
+
+	isWildcard := len(current.values) > 0 && len(resp.Answer) > 0
+	for _, record := range resp.Answer {
+		if _, value := recordValue(record); !containsString(current.values, value) {
+			isWildcard = false
+			break
+		}
+	}
+	event["is_wildcard"] = isWildcard
+}
+
+// getWildcard resolves a random name under the apex of a name, the result
+// is cached for the run by apex and question type.
+func (r *Request) getWildcard(name string) *wildcard {
+	name = strings.TrimSuffix(name, ".")
+	apex, err := publicsuffix.EffectiveTLDPlusOne(name)
+	if err != nil {
+		apex = name
+	}
+	key := apex + ":" + strconv.Itoa(int(r.question))
+
+	wildcardsMutex.Lock()
+	cached, ok := wildcards[key]
+	wildcardsMutex.Unlock()
+	if ok {
+		return cached
+	}
+
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(xid.New().String()+"."+apex), r.question)
+	resp, _, _ := r.doWithTimeout(msg)
+	if resp == nil {
+		return nil
+	}
+	result := &wildcard{}
+	for _, record := range resp.Answer {
+		part, value := recordValue(record)
+		if part == "" {
+			continue
+		}
+		result.values = append(result.values, value)
+		if part == "a" || part == "aaaa" {
+			result.ips = append(result.ips, value)
+		}
+	}
+
+	wildcardsMutex.Lock()
+	wildcards[key] = result
+	wildcardsMutex.Unlock()
+	return result
+}
+
+// containsString returns true if a slice contains a value
+func containsString(slice []string, value string) bool {
+	for _, item := range slice {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package file
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+)
+
+// zipExtensions are the extensions of the zip based archives
+var zipExtensions = map[string]struct{}{".zip": {}, ".jar": {}, ".war": {}, ".ear": {}, ".docx": {}, ".xlsx": {}, ".pptx": {}}
+
+// archiveType returns the type of archive of a path - zip, tar or tar.gz,
+// or an empty string if the path is not an archive.
+func archiveType(item string) string {
+	lower := strings.ToLower(item)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "tar.gz"
+	case strings.HasSuffix(lower, ".tar"):
+		return "tar"
+	}
+	if _, ok := zipExtensions[path.Ext(lower)]; ok {
+		return "zip"
+	}
+	return ""
+}
+
+// archiveReader reads the entries of an archive up to a total size
+type archiveReader struct {
+	request   *Request
+	remaining int64
+	callback  func(name string, data []byte)
+}
+
+// readArchive calls the callback with the name and data of each valid
+// entry of an archive smaller than max-size.
+func (r *Request) readArchive(file *os.File, size int64, kind string, callback func(name string, data []byte)) error {
+	reader := &archiveReader{request: r, remaining: 10 * int64(r.MaxSize), callback: callback}
+	if kind == "zip" {
+		return reader.readZip(file, size)
+	}
+
+	var input io.Reader = file
+	if kind == "tar.gz" {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return errors.Wrap(err, "could not read gzip archive")
+		}
+		defer gzipReader.Close()
+		input = gzipReader
+	}
+	return reader.readTar(input)
+}
+
+// readZip reads the entries of a zip archive
+func (a *archiveReader) readZip(file *os.File, size int64) error {
+	zipReader, err := zip.NewReader(file, size)
+	if err != nil {
+		return errors.Wrap(err, "could not read zip archive")
+	}
+	for _, entry := range zipReader.File {
+		if entry.FileInfo().IsDir() || !a.validate(entry.Name) {
+			continue
+		}
+		entryReader, err := entry.Open()
+		if err != nil {
+			return errors.Wrapf(err, "could not open entry %s", entry.Name)
+		}
+		err = a.read(entry.Name, entryReader)
+		entryReader.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readTar reads the entries of a tar archive
+func (a *archiveReader) readTar(input io.Reader) error {
+	tarReader := tar.NewReader(input)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "could not read tar archive")
+		}
+		if header.Typeflag != tar.TypeReg || !a.validate(header.Name) {
+			continue
+		}
+		if err := a.read(header.Name, tarReader); err != nil {
+			return err
+		}
+	}
+}
+
+// validate validates the extension of an entry and its path in the
+// archive for the include and exclude globs.
+func (a *archiveReader) validate(name string) bool {
+	return a.request.validateExtension(name) && a.request.includedPath(name)
+}
+
+// read reads an entry skipping it if larger than max-size, the sizes in
+// the headers are not trusted.
+func (a *archiveReader) read(name string, entry io.Reader) error {
+	limit := int64(a.request.MaxSize)
+	capped := a.remaining < limit
+	if capped {
+		limit = a.remaining
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(entry, limit+1))
+	if err != nil {
+		return errors.Wrapf(err, "could not read entry %s", name)
+	}
+	if int64(len(data)) > limit {
+		if capped {
+			return errors.New("exceeded decompressed size of archive")
+		}
+		gologger.Verbose().Msgf("Could not process archive entry %s: exceeded max size\n", name)
+		return nil
+	}
+	a.remaining -= int64(len(data))
+	a.callback(name, data)
+	return nil
+}
@@ -0,0 +1,126 @@
+package file
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/yaklang/nuclei/v2/internal/testutils"
+	"github.com/yaklang/nuclei/v2/pkg/operators"
+	"github.com/yaklang/nuclei/v2/pkg/operators/matchers"
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+func writeZip(t *testing.T, file string, entries map[string]string) {
+	buffer := &bytes.Buffer{}
+	writer := zip.NewWriter(buffer)
+	for name, data := range entries {
+		entry, err := writer.Create(name)
+		require.Nil(t, err, "could not create zip entry")
+		_, err = entry.Write([]byte(data))
+		require.Nil(t, err, "could not write zip entry")
+	}
+	require.Nil(t, writer.Close(), "could not close zip archive")
+	require.Nil(t, ioutil.WriteFile(file, buffer.Bytes(), 0644), "could not write zip archive")
+}
+
+func writeTarGz(t *testing.T, file string, entries map[string]string) {
+	buffer := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(buffer)
+	writer := tar.NewWriter(gzipWriter)
+	for name, data := range entries {
+		err := writer.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg})
+		require.Nil(t, err, "could not write tar header")
+		_, err = writer.Write([]byte(data))
+		require.Nil(t, err, "could not write tar entry")
+	}
+	require.Nil(t, writer.Close(), "could not close tar archive")
+	require.Nil(t, gzipWriter.Close(), "could not close gzip archive")
+	require.Nil(t, ioutil.WriteFile(file, buffer.Bytes(), 0644), "could not write tar.gz archive")
+}
+
+func TestFileExecuteArchives(t *testing.T) {
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-file-archive"
+	request := &Request{
+		ID:                templateID,
+		MaxSize:           1024,
+		Extensions:        []string{"all"},
+		ExtensionDenylist: []string{".go"},
+		Archive:           true,
+		Operators: operators.Operators{
+			Matchers: []*matchers.Matcher{{Part: "raw", Type: "word", Words: []string{"AKIA"}}},
+		},
+	}
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	err := request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile file request")
+
+	tempDir, err := ioutil.TempDir("", "test-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	writeZip(t, path.Join(tempDir, "app.jar"), map[string]string{
+		"config/secrets.properties": "key=AKIA1234",
+		"main.go":                   "AKIA denied",
+		"image.png":                 "AKIA denied",
+		"large.txt":                 strings.Repeat("AKIA", 1024),
+	})
+	writeTarGz(t, path.Join(tempDir, "backup.tar.gz"), map[string]string{
+		"etc/app.env": "AWS_KEY=AKIA5678",
+		"readme.txt":  "nothing",
+	})
+
+	var matched []string
+	err = request.ExecuteWithResults(tempDir, make(output.InternalEvent), make(output.InternalEvent), func(event *output.InternalWrappedEvent) {
+		for _, result := range event.Results {
+			matched = append(matched, result.Matched)
+		}
+	})
+	require.Nil(t, err, "could not execute file request")
+	require.ElementsMatch(t, []string{
+		path.Join(tempDir, "app.jar") + ":config/secrets.properties",
+		path.Join(tempDir, "backup.tar.gz") + ":etc/app.env",
+	}, matched, "could not match archive entries")
+
+	t.Run("decompressed-size", func(t *testing.T) {
+		bomb := path.Join(tempDir, "bomb.zip")
+		entries := make(map[string]string)
+		for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l"} {
+			entries[name+".txt"] = strings.Repeat("0", 1000)
+		}
+		writeZip(t, bomb, entries)
+
+		file, err := os.Open(bomb)
+		require.Nil(t, err, "could not open archive")
+		defer file.Close()
+		stat, err := file.Stat()
+		require.Nil(t, err, "could not stat archive")
+
+		var read int
+		err = request.readArchive(file, stat.Size(), "zip", func(name string, data []byte) {
+			read += len(data)
+		})
+		require.NotNil(t, err, "could read archive above decompressed size")
+		require.LessOrEqual(t, read, 10*request.MaxSize, "could read more than decompressed size")
+	})
+}
+
+func TestArchiveType(t *testing.T) {
+	require.Equal(t, "zip", archiveType("report.DOCX"), "could not get zip type")
+	require.Equal(t, "tar.gz", archiveType("backup.tgz"), "could not get tar.gz type")
+	require.Equal(t, "tar", archiveType("backup.tar"), "could not get tar type")
+	require.Equal(t, "", archiveType("config.gz"), "could get type of compressed file")
+}
@@ -1,10 +1,12 @@
 package file
 
 import (
+	"regexp"
 	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/yaklang/nuclei/v2/pkg/operators"
+	"github.com/yaklang/nuclei/v2/pkg/operators/matchers"
 	"github.com/yaklang/nuclei/v2/pkg/protocols"
 )
 
@@ -16,6 +18,13 @@ type Request struct {
 	Extensions []string `yaml:"extensions"`
 	// ExtensionDenylist is the list of file extensions to deny during matching.
 	ExtensionDenylist []string `yaml:"denylist"`
+	// IncludePaths are the globs of the paths to run the request on, relative
+	// to the scanned folder. ** matches any number of folders, so **/id_rsa
+	// matches id_rsa files at any depth. The extensions lists still apply.
+	IncludePaths []string `yaml:"include-paths"`
+	// ExcludePaths are the globs of the paths to skip, relative to the scanned
+	// folder. Excluded folders are not walked, and excludes win over includes.
+	ExcludePaths []string `yaml:"exclude-paths"`
 
 	ID string `yaml:"id"`
 
@@ -29,9 +38,28 @@ type Request struct {
 	options           *protocols.ExecuterOptions
 	extensions        map[string]struct{}
 	extensionDenylist map[string]struct{}
+	overlap           int
+	streamRegexes     map[*matchers.Matcher][]*regexp.Regexp
+	includePaths      []*regexp.Regexp
+	excludePaths      []*regexp.Regexp
+	metadataOnly      bool
 
 	// NoRecursive specifies whether to not do recursive checks if folders are provided.
 	NoRecursive bool `yaml:"no-recursive"`
+	// Archive runs the request on the entries of zip, jar, war, docx, tar
+	// and tar.gz files within max-size, reported as archive.zip:entry/path.
+	// Entries are filtered with the extensions lists and the path globs
+	// relative to the archive, and the decompressed
+	// size of the entries of an archive is capped to ten times max-size.
+	Archive bool `yaml:"archive"`
+	// Stream reads files of any size in overlapping chunks, running the
+	// word and regex matchers and regex extractors on each chunk. The other
+	// matchers and extractors run on the first chunk, and size matchers on
+	// the size of the file. The results of streamed files have no lines,
+	// offsets or snippets.
+	Stream bool `yaml:"stream"`
+	// ChunkSize is the size of the chunks of streamed files, 1MB by default.
+	ChunkSize int `yaml:"chunk-size"`
 
 	allExtensions bool
 }
@@ -44,6 +72,11 @@ func (r *Request) GetID() string {
 	return r.ID
 }
 
+// EventType returns the type of the result events of the request.
+func (r *Request) EventType() string {
+	return "file"
+}
+
 // Compile compiles the protocol request for further execution.
 func (r *Request) Compile(options *protocols.ExecuterOptions) error {
 	if len(r.Matchers) > 0 || len(r.Extractors) > 0 {
@@ -53,11 +86,25 @@ func (r *Request) Compile(options *protocols.ExecuterOptions) error {
 		}
 		r.CompiledOperators = compiled
 	}
+	r.metadataOnly = r.isMetadataOnly()
 	// By default use 5mb as max size to read.
 	if r.MaxSize == 0 {
 		r.MaxSize = 5 * 1024 * 1024
 	}
 	r.options = options
+	if r.Stream {
+		if err := r.compileStream(); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if r.includePaths, err = compileGlobs(r.IncludePaths); err != nil {
+		return errors.Wrap(err, "could not compile include paths")
+	}
+	if r.excludePaths, err = compileGlobs(r.ExcludePaths); err != nil {
+		return errors.Wrap(err, "could not compile exclude paths")
+	}
 
 	r.extensions = make(map[string]struct{})
 	r.extensionDenylist = make(map[string]struct{})
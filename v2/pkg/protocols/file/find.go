@@ -17,9 +17,9 @@ import (
 func (r *Request) getInputPaths(target string, callback func(string)) error {
 	processed := make(map[string]struct{})
 
-	// Template input includes a wildcard
-	if strings.Contains(target, "*") && !r.NoRecursive {
-		err := r.findGlobPathMatches(target, processed, callback)
+	// Template input includes a wildcard, globs don't recurse into folders
+	if strings.Contains(target, "*") {
+		err := r.findGlobPathMatches(target, globRoot(target), processed, callback)
 		if err != nil {
 			return errors.Wrap(err, "could not find glob matches")
 		}
@@ -27,7 +27,7 @@ func (r *Request) getInputPaths(target string, callback func(string)) error {
 	}
 
 	// Template input is either a file or a directory
-	file, err := r.findFileMatches(target, processed, callback)
+	file, err := r.findFileMatches(target, filepath.Dir(target), processed, callback)
 	if err != nil {
 		return errors.Wrap(err, "could not find file")
 	}
@@ -47,13 +47,19 @@ func (r *Request) getInputPaths(target string, callback func(string)) error {
 }
 
 // findGlobPathMatches returns the matched files from a glob path
-func (r *Request) findGlobPathMatches(absPath string, processed map[string]struct{}, callback func(string)) error {
+func (r *Request) findGlobPathMatches(absPath, root string, processed map[string]struct{}, callback func(string)) error {
 	matches, err := filepath.Glob(absPath)
 	if err != nil {
 		return errors.Errorf("wildcard found, but unable to glob: %s\n", err)
 	}
 	for _, match := range matches {
-		if !r.validatePath(match) {
+		if err := r.context().Err(); err != nil {
+			return err
+		}
+		if !r.validatePath(root, match) {
+			continue
+		}
+		if info, err := os.Stat(match); err != nil || !info.Mode().IsRegular() {
 			continue
 		}
 		if _, ok := processed[match]; !ok {
@@ -66,7 +72,7 @@ func (r *Request) findGlobPathMatches(absPath string, processed map[string]struc
 
 // findFileMatches finds if a path is an absolute file. If the path
 // is a file, it returns true otherwise false with no errors.
-func (r *Request) findFileMatches(absPath string, processed map[string]struct{}, callback func(string)) (bool, error) {
+func (r *Request) findFileMatches(absPath, root string, processed map[string]struct{}, callback func(string)) (bool, error) {
 	info, err := os.Stat(absPath)
 	if err != nil {
 		return false, err
@@ -75,7 +81,7 @@ func (r *Request) findFileMatches(absPath string, processed map[string]struct{},
 		return false, nil
 	}
 	if _, ok := processed[absPath]; !ok {
-		if !r.validatePath(absPath) {
+		if !r.validatePath(root, absPath) {
 			return false, nil
 		}
 		processed[absPath] = struct{}{}
@@ -89,13 +95,23 @@ func (r *Request) findDirectoryMatches(absPath string, processed map[string]stru
 	err := godirwalk.Walk(absPath, &godirwalk.Options{
 		Unsorted: true,
 		ErrorCallback: func(fsPath string, err error) godirwalk.ErrorAction {
+			if r.context().Err() != nil {
+				return godirwalk.Halt
+			}
 			return godirwalk.SkipNode
 		},
 		Callback: func(path string, d *godirwalk.Dirent) error {
+			if err := r.context().Err(); err != nil {
+				return err
+			}
 			if d.IsDir() {
+				// Excluded folders are skipped without being walked
+				if path != absPath && r.excludedPath(relativePath(absPath, path)) {
+					return godirwalk.SkipThis
+				}
 				return nil
 			}
-			if !r.validatePath(path) {
+			if !r.validatePath(absPath, path) {
 				return nil
 			}
 			if _, ok := processed[path]; !ok {
@@ -108,8 +124,20 @@ func (r *Request) findDirectoryMatches(absPath string, processed map[string]stru
 	return err
 }
 
-// validatePath validates a file path for blacklist and whitelist options
-func (r *Request) validatePath(item string) bool {
+// validatePath validates a file path for blacklist and whitelist options,
+// and for the include and exclude globs relative to the scanned root.
+func (r *Request) validatePath(root, item string) bool {
+	relative := relativePath(root, item)
+	// Archives are scanned by entries with their own validation
+	if r.Archive && archiveType(item) != "" {
+		return !r.excludedPath(relative)
+	}
+	return r.validateExtension(item) && r.includedPath(relative)
+}
+
+// validateExtension validates the extension of a path or an archive entry
+// for blacklist and whitelist options
+func (r *Request) validateExtension(item string) bool {
 	extension := path.Ext(item)
 
 	if len(r.extensions) > 0 {
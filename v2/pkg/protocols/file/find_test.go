@@ -60,4 +60,14 @@ func TestFindInputPaths(t *testing.T) {
 	})
 	require.Nil(t, err, "could not get input paths for directory")
 	require.ElementsMatch(t, expected, got, "could not get correct file matches for directory")
+
+	request.NoRecursive = true
+	require.Nil(t, os.Mkdir(path.Join(tempDir, "folder.yaml"), 0755), "could not create temporary directory")
+	got = []string{}
+	err = request.getInputPaths(tempDir+"/*", func(item string) {
+		base := path.Base(item)
+		got = append(got, base)
+	})
+	require.Nil(t, err, "could not get input paths for no-recursive glob")
+	require.ElementsMatch(t, expected, got, "could not get correct file matches for no-recursive glob")
 }
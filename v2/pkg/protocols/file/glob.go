@@ -0,0 +1,148 @@
+package file
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// compileGlob compiles a glob to a regex matching slash separated paths.
+// ** matches any number of folders, * and ? don't match separators, and
+// [abc], [!abc] and {a,b} are supported.
+func compileGlob(pattern string) (*regexp.Regexp, error) {
+	builder := &strings.Builder{}
+	builder.WriteString("^")
+
+	var alternatives int
+	for i := 0; i < len(pattern); i++ {
+		char := pattern[i]
+		switch char {
+		case '/':
+			if pattern[i:] == "/**" {
+				builder.WriteString("(?:/.*)?")
+				i += 2
+				continue
+			}
+			builder.WriteByte('/')
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				atStart := i == 0 || pattern[i-1] == '/'
+				if atStart && i+2 < len(pattern) && pattern[i+2] == '/' {
+					builder.WriteString("(?:.*/)?")
+					i += 2
+					continue
+				}
+				builder.WriteString(".*")
+				i++
+				continue
+			}
+			builder.WriteString("[^/]*")
+		case '?':
+			builder.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 1 {
+				return nil, errors.Errorf("unterminated class in glob %s", pattern)
+			}
+			class := pattern[i+1 : i+1+end]
+			if class[0] == '!' {
+				class = "^" + class[1:]
+			}
+			builder.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '{':
+			alternatives++
+			builder.WriteString("(?:")
+		case ',':
+			if alternatives > 0 {
+				builder.WriteByte('|')
+			} else {
+				builder.WriteByte(',')
+			}
+		case '}':
+			if alternatives == 0 {
+				return nil, errors.Errorf("unbalanced braces in glob %s", pattern)
+			}
+			alternatives--
+			builder.WriteByte(')')
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+			builder.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(char)))
+		}
+	}
+	if alternatives > 0 {
+		return nil, errors.Errorf("unbalanced braces in glob %s", pattern)
+	}
+	builder.WriteString("$")
+
+	compiled, err := regexp.Compile(builder.String())
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not compile glob %s", pattern)
+	}
+	return compiled, nil
+}
+
+// compileGlobs compiles a list of globs
+func compileGlobs(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		glob, err := compileGlob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, glob)
+	}
+	return compiled, nil
+}
+
+// globRoot returns the folder of a glob input before its first wildcard
+func globRoot(target string) string {
+	root := target
+	for strings.ContainsAny(root, "*?[") {
+		root = filepath.Dir(root)
+	}
+	return root
+}
+
+// relativePath returns the slash separated path of an item relative to
+// the scanned root.
+func relativePath(root, item string) string {
+	relative, err := filepath.Rel(root, item)
+	if err != nil {
+		relative = item
+	}
+	return filepath.ToSlash(relative)
+}
+
+// excludedPath returns true if a relative path matches an exclude glob
+func (r *Request) excludedPath(relative string) bool {
+	for _, glob := range r.excludePaths {
+		if glob.MatchString(relative) {
+			return true
+		}
+	}
+	return false
+}
+
+// includedPath returns true if a relative path matches an include glob, or
+// if there are none. Excluded paths are never included.
+func (r *Request) includedPath(relative string) bool {
+	if r.excludedPath(relative) {
+		return false
+	}
+	if len(r.includePaths) == 0 {
+		return true
+	}
+	for _, glob := range r.includePaths {
+		if glob.MatchString(relative) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/yaklang/nuclei/v2/internal/testutils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileGlob(t *testing.T) {
+	tests := []struct {
+		glob    string
+		matches []string
+		misses  []string
+	}{
+		{"**/id_rsa", []string{"id_rsa", "home/user/.ssh/id_rsa"}, []string{"id_rsa.pub", "home/not_id_rsa"}},
+		{"*.env*", []string{".env", "app.env.local"}, []string{"config/.env"}},
+		{"**/*.env*", []string{".env", "config/.env.prod"}, []string{"config/env"}},
+		{"node_modules/**", []string{"node_modules", "node_modules/lib/index.js"}, []string{"src/node_modules/index.js", "node_modules_old/a"}},
+		{"src/**/test/*.go", []string{"src/test/a.go", "src/a/b/test/c.go"}, []string{"src/test/a/b.go"}},
+		{"config.{yml,yaml}", []string{"config.yml", "config.yaml"}, []string{"config.json"}},
+		{"file-[!0-4]?.txt", []string{"file-5a.txt"}, []string{"file-1a.txt", "file-5/.txt"}},
+		{"a+b(c).txt", []string{"a+b(c).txt"}, []string{"aab(c).txt"}},
+	}
+	for _, test := range tests {
+		compiled, err := compileGlob(test.glob)
+		require.Nil(t, err, "could not compile glob %s", test.glob)
+		for _, match := range test.matches {
+			require.True(t, compiled.MatchString(match), "could not match %s with %s", match, test.glob)
+		}
+		for _, miss := range test.misses {
+			require.False(t, compiled.MatchString(miss), "could match %s with %s", miss, test.glob)
+		}
+	}
+
+	for _, invalid := range []string{"file[", "{a,b", "a}"} {
+		_, err := compileGlob(invalid)
+		require.NotNil(t, err, "could compile invalid glob %s", invalid)
+	}
+}
+
+func TestFindInputPathsGlobs(t *testing.T) {
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-file-globs"
+	request := &Request{
+		ID:           templateID,
+		MaxSize:      1024,
+		Extensions:   []string{"all"},
+		IncludePaths: []string{"**/id_rsa", "**/*.env*", "**/*.png"},
+		ExcludePaths: []string{"node_modules/**", "**/test/**"},
+	}
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	err := request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile file request")
+
+	tempDir, err := ioutil.TempDir("", "test-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	files := []string{
+		"id_rsa",
+		"home/user/.ssh/id_rsa",
+		"home/user/.ssh/id_rsa.pub",
+		"app/.env",
+		"app/config/.env.production",
+		"app/logo.png",
+		"app/main.js",
+		"node_modules/lib/.env",
+		"app/test/fixtures/.env",
+	}
+	for _, file := range files {
+		file = path.Join(tempDir, filepath.FromSlash(file))
+		require.Nil(t, os.MkdirAll(path.Dir(file), 0755), "could not create temporary directory")
+		require.Nil(t, ioutil.WriteFile(file, []byte("TEST"), 0644), "could not write temporary file")
+	}
+
+	// Included paths still go through the extensions denylist
+	expected := []string{"id_rsa", "home/user/.ssh/id_rsa", "app/.env", "app/config/.env.production"}
+	got := []string{}
+	err = request.getInputPaths(tempDir, func(item string) {
+		got = append(got, relativePath(tempDir, item))
+	})
+	require.Nil(t, err, "could not get input paths for directory")
+	require.ElementsMatch(t, expected, got, "could not get correct file matches for globs")
+
+	got = []string{}
+	err = request.getInputPaths(path.Join(tempDir, "app", "*"), func(item string) {
+		got = append(got, relativePath(tempDir, item))
+	})
+	require.Nil(t, err, "could not get input paths for glob")
+	require.ElementsMatch(t, []string{"app/.env"}, got, "could not get correct file matches for glob input")
+
+	got = []string{}
+	err = request.getInputPaths(path.Join(tempDir, "node_modules", "lib", ".env"), func(item string) {
+		got = append(got, relativePath(tempDir, item))
+	})
+	require.Nil(t, err, "could not get input paths for file")
+	require.ElementsMatch(t, []string{"node_modules/lib/.env"}, got, "could not get file input relative to its folder")
+}
@@ -0,0 +1,95 @@
+package file
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/yaklang/nuclei/v2/pkg/output"
+)
+
+// maxSnippetSize is the maximum size of the snippet around the first match
+const maxSnippetSize = 256
+
+// findLocations returns the line numbers and byte offsets of the
+// occurrences of a value in the data.
+func findLocations(data, value string) (lines, offsets []int) {
+	if value == "" {
+		return nil, nil
+	}
+	line, counted := 1, 0
+	for start := 0; start < len(data); {
+		index := strings.Index(data[start:], value)
+		if index == -1 {
+			break
+		}
+		offset := start + index
+		line += strings.Count(data[counted:offset], "\n")
+		counted = offset
+		lines = append(lines, line)
+		offsets = append(offsets, offset)
+		start = offset + len(value)
+	}
+	return lines, offsets
+}
+
+// lineSnippet returns the line around an offset of the data
+func lineSnippet(data string, offset int) string {
+	start := strings.LastIndex(data[:offset], "\n") + 1
+	end := strings.Index(data[offset:], "\n")
+	if end == -1 {
+		end = len(data)
+	} else {
+		end += offset
+	}
+	snippet := strings.TrimSpace(data[start:end])
+	if len(snippet) > maxSnippetSize {
+		snippet = snippet[:maxSnippetSize]
+	}
+	return snippet
+}
+
+// addLocations adds the line numbers and byte offsets of the matched
+// snippets to a result, or of the extracted values for extractor results,
+// with the offsets of each extracted value.
+func addLocations(result *output.ResultEvent, raw string) {
+	snippets := result.MatchedSnippets
+	if words, ok := result.MatchedWords[result.MatcherName]; ok {
+		snippets = words
+	}
+	if len(snippets) == 0 {
+		snippets = result.ExtractedResults
+	}
+
+	positions := make(map[int]int)
+	for _, snippet := range snippets {
+		lines, offsets := findLocations(raw, snippet)
+		for i, offset := range offsets {
+			positions[offset] = lines[i]
+		}
+	}
+	for _, value := range result.ExtractedResults {
+		if _, offsets := findLocations(raw, value); len(offsets) > 0 {
+			if result.ExtractedOffsets == nil {
+				result.ExtractedOffsets = make(map[string][]int)
+			}
+			result.ExtractedOffsets[value] = offsets
+		}
+	}
+	if len(positions) == 0 {
+		return
+	}
+
+	result.Offsets = make([]int, 0, len(positions))
+	for offset := range positions {
+		result.Offsets = append(result.Offsets, offset)
+	}
+	sort.Ints(result.Offsets)
+	for _, offset := range result.Offsets {
+		line := positions[offset]
+		if len(result.Lines) == 0 || result.Lines[len(result.Lines)-1] != line {
+			result.Lines = append(result.Lines, line)
+		}
+	}
+	result.Snippet = lineSnippet(raw, result.Offsets[0])
+	result.FileToIndexPosition = map[string]int{result.Matched: result.Lines[0]}
+}
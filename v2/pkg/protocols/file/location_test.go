@@ -0,0 +1,36 @@
+package file
+
+import (
+	"testing"
+
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindLocations(t *testing.T) {
+	data := "first line\npassword=a\n\npassword=b password=c"
+	lines, offsets := findLocations(data, "password=")
+	require.Equal(t, []int{2, 4, 4}, lines, "could not get line numbers")
+	require.Equal(t, []int{11, 23, 34}, offsets, "could not get byte offsets")
+
+	lines, offsets = findLocations(data, "missing")
+	require.Empty(t, lines, "could get lines of missing value")
+	require.Empty(t, offsets, "could get offsets of missing value")
+}
+
+func TestAddLocations(t *testing.T) {
+	raw := "config\n  secret = AKIA1234  \nkey=AKIA5678\n"
+	result := &output.ResultEvent{
+		Matched:          "/tmp/config",
+		MatcherName:      "aws",
+		MatchedSnippets:  []string{"AKIA", "config"},
+		MatchedWords:     map[string][]string{"aws": {"AKIA"}},
+		ExtractedResults: []string{"AKIA1234", "AKIA5678"},
+	}
+	addLocations(result, raw)
+	require.Equal(t, []int{2, 3}, result.Lines, "could not get lines of named matcher")
+	require.Equal(t, []int{18, 33}, result.Offsets, "could not get offsets of named matcher")
+	require.Equal(t, "secret = AKIA1234", result.Snippet, "could not get snippet")
+	require.Equal(t, map[string][]int{"AKIA1234": {18}, "AKIA5678": {33}}, result.ExtractedOffsets, "could not get extracted offsets")
+	require.Equal(t, map[string]int{"/tmp/config": 2}, result.FileToIndexPosition, "could not get index position")
+}
@@ -0,0 +1,72 @@
+package file
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/yaklang/nuclei/v2/pkg/operators/extractors"
+	"github.com/yaklang/nuclei/v2/pkg/operators/matchers"
+	"github.com/yaklang/nuclei/v2/pkg/output"
+)
+
+// metadataFields are the fields of the data map available without
+// reading the content of the file.
+var metadataFields = map[string]struct{}{
+	"size":          {},
+	"mtime":         {},
+	"permissions":   {},
+	"extension":     {},
+	"owner":         {},
+	"path":          {},
+	"matched":       {},
+	"template-id":   {},
+	"template-info": {},
+	"template-path": {},
+}
+
+// addMetadata adds the size, modification time, permissions, extension and
+// owner of a file to the data map. Archive entries have no file info, so
+// only their size and extension are added.
+func addMetadata(data output.InternalEvent, matched string, size int, info os.FileInfo) {
+	data["extension"] = path.Ext(matched)
+	if info == nil {
+		data["size"] = size
+		return
+	}
+	data["size"] = info.Size()
+	data["mtime"] = info.ModTime().Unix()
+	data["permissions"] = fmt.Sprintf("%04o", info.Mode().Perm())
+	if owner, ok := fileOwner(info); ok {
+		data["owner"] = owner
+	}
+}
+
+// isMetadataOnly returns true if the matchers and extractors only use the
+// metadata of the files, which are then not read.
+func (r *Request) isMetadataOnly() bool {
+	if r.CompiledOperators == nil || len(r.CompiledOperators.Matchers) == 0 {
+		return false
+	}
+	for _, matcher := range r.CompiledOperators.Matchers {
+		if matcher.GetType() != matchers.DSLMatcher || !isMetadataFields(matcher.DSLVariables()) {
+			return false
+		}
+	}
+	for _, extractor := range r.CompiledOperators.Extractors {
+		if extractor.GetType() != extractors.KValExtractor || !isMetadataFields(extractor.KVal) {
+			return false
+		}
+	}
+	return true
+}
+
+// isMetadataFields returns true if all the fields are metadata fields
+func isMetadataFields(fields []string) bool {
+	for _, field := range fields {
+		if _, ok := metadataFields[field]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/yaklang/nuclei/v2/internal/testutils"
+	"github.com/yaklang/nuclei/v2/pkg/operators"
+	"github.com/yaklang/nuclei/v2/pkg/operators/extractors"
+	"github.com/yaklang/nuclei/v2/pkg/operators/matchers"
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+func compileMetadataRequest(t *testing.T, request *Request) {
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	request.ID = "testing-file-metadata"
+	request.MaxSize = 16
+	request.Extensions = []string{"all"}
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   request.ID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	err := request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile file request")
+}
+
+func TestFileExecuteMetadata(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "test-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	writable := path.Join(tempDir, "app.conf")
+	err = ioutil.WriteFile(writable, []byte("password=secret-value-above-max-size"), 0644)
+	require.Nil(t, err, "could not write temporary file")
+	require.Nil(t, os.Chmod(writable, 0777), "could not change file permissions")
+	err = ioutil.WriteFile(path.Join(tempDir, "other.conf"), []byte("password"), 0644)
+	require.Nil(t, err, "could not write temporary file")
+
+	t.Run("metadata-only", func(t *testing.T) {
+		request := &Request{
+			Operators: operators.Operators{
+				Matchers:   []*matchers.Matcher{{Type: "dsl", DSL: []string{"permissions == '0777' && size > 16 && extension == '.conf'"}}},
+				Extractors: []*extractors.Extractor{{Type: "kval", KVal: []string{"permissions"}}},
+			},
+		}
+		compileMetadataRequest(t, request)
+		require.True(t, request.metadataOnly, "could not detect metadata only operators")
+
+		var events []*output.InternalWrappedEvent
+		err := request.ExecuteWithResults(tempDir, make(output.InternalEvent), make(output.InternalEvent), func(event *output.InternalWrappedEvent) {
+			events = append(events, event)
+		})
+		require.Nil(t, err, "could not execute file request")
+		require.Len(t, events, 2, "could not get events for files")
+
+		var matched []string
+		for _, event := range events {
+			require.Equal(t, "", event.InternalEvent["raw"], "could read file with metadata only operators")
+			require.NotNil(t, event.InternalEvent["mtime"], "could not get modification time of file")
+			for _, result := range event.Results {
+				matched = append(matched, result.Matched)
+				require.Equal(t, []string{"0777"}, result.ExtractedResults, "could not extract permissions")
+			}
+		}
+		require.Equal(t, []string{writable}, matched, "could not match file above max size by metadata")
+	})
+
+	t.Run("content", func(t *testing.T) {
+		request := &Request{
+			Operators: operators.Operators{
+				MatchersCondition: "and",
+				Matchers: []*matchers.Matcher{
+					{Type: "word", Words: []string{"password"}},
+					{Type: "dsl", DSL: []string{"permissions == '0644' && extension == '.conf'"}},
+				},
+			},
+		}
+		compileMetadataRequest(t, request)
+		require.False(t, request.metadataOnly, "could detect metadata only operators with content matchers")
+
+		var matched []string
+		err := request.ExecuteWithResults(tempDir, make(output.InternalEvent), make(output.InternalEvent), func(event *output.InternalWrappedEvent) {
+			for _, result := range event.Results {
+				matched = append(matched, result.Matched)
+			}
+		})
+		require.Nil(t, err, "could not execute file request")
+		require.Equal(t, []string{path.Join(tempDir, "other.conf")}, matched, "could not match file by content and metadata")
+	})
+}
@@ -0,0 +1,17 @@
+// +build !windows
+
+package file
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileOwner returns the user id of the owner of a file
+func fileOwner(info os.FileInfo) (int, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return int(stat.Uid), true
+}
@@ -0,0 +1,12 @@
+// +build windows
+
+package file
+
+import (
+	"os"
+)
+
+// fileOwner returns false as files have no user id on windows
+func fileOwner(info os.FileInfo) (int, bool) {
+	return 0, false
+}
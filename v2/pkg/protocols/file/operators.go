@@ -1,8 +1,6 @@
 package file
 
 import (
-	"bufio"
-	"strings"
 	"time"
 
 	"github.com/yaklang/nuclei/v2/pkg/operators/extractors"
@@ -12,7 +10,7 @@ import (
 )
 
 // Match matches a generic data response again a given matcher
-func (r *Request) Match(data map[string]interface{}, matcher *matchers.Matcher) bool {
+func (r *Request) Match(data map[string]interface{}, matcher *matchers.Matcher) (bool, []string) {
 	partString := matcher.Part
 	switch partString {
 	case "body", "all", "data", "":
@@ -21,23 +19,23 @@ func (r *Request) Match(data map[string]interface{}, matcher *matchers.Matcher)
 
 	item, ok := data[partString]
 	if !ok {
-		return false
+		return false, nil
 	}
 	itemStr := types.ToString(item)
 
 	switch matcher.GetType() {
 	case matchers.SizeMatcher:
-		return matcher.Result(matcher.MatchSize(len(itemStr)))
+		return matcher.Result(matcher.MatchSize(len(itemStr))), nil
 	case matchers.WordsMatcher:
-		return matcher.Result(matcher.MatchWords(itemStr))
+		return matcher.ResultWithMatchedSnippet(matcher.MatchWords(itemStr))
 	case matchers.RegexMatcher:
-		return matcher.Result(matcher.MatchRegex(itemStr))
+		return matcher.ResultWithMatchedSnippet(matcher.MatchRegex(itemStr))
 	case matchers.BinaryMatcher:
-		return matcher.Result(matcher.MatchBinary(itemStr))
+		return matcher.ResultWithMatchedSnippet(matcher.MatchBinary(itemStr))
 	case matchers.DSLMatcher:
-		return matcher.Result(matcher.MatchDSL(data))
+		return matcher.Result(matcher.MatchDSL(data)), nil
 	}
-	return false
+	return false, nil
 }
 
 // Extract performs extracting operation for a extractor on model and returns true or false.
@@ -59,6 +57,10 @@ func (r *Request) Extract(data map[string]interface{}, extractor *extractors.Ext
 		return extractor.ExtractRegex(itemStr)
 	case extractors.KValExtractor:
 		return extractor.ExtractKval(data)
+	case extractors.XPathExtractor:
+		return extractor.ExtractXPath(itemStr, false)
+	case extractors.JSONExtractor:
+		return extractor.ExtractJSON(itemStr)
 	}
 	return nil
 }
@@ -79,6 +81,25 @@ func (r *Request) responseToDSLMap(raw, host, matched string) output.InternalEve
 
 // MakeResultEvent creates a result event from internal wrapped event
 func (r *Request) MakeResultEvent(wrapped *output.InternalWrappedEvent) []*output.ResultEvent {
+	results := r.makeResultEvents(wrapped)
+	raw, ok := wrapped.InternalEvent["raw"]
+	if !ok {
+		return results
+	}
+	rawStr, ok := raw.(string)
+	if !ok {
+		return results
+	}
+
+	for _, result := range results {
+		addLocations(result, rawStr)
+	}
+	return results
+}
+
+// makeResultEvents creates the result events of a wrapped event without
+// the locations of the matches in the file.
+func (r *Request) makeResultEvents(wrapped *output.InternalWrappedEvent) []*output.ResultEvent {
 	if len(wrapped.OperatorsResult.DynamicValues) > 0 {
 		return nil
 	}
@@ -102,33 +123,6 @@ func (r *Request) MakeResultEvent(wrapped *output.InternalWrappedEvent) []*outpu
 		data := r.makeResultEventItem(wrapped)
 		results = append(results, data)
 	}
-	raw, ok := wrapped.InternalEvent["raw"]
-	if !ok {
-		return results
-	}
-	rawStr, ok := raw.(string)
-	if !ok {
-		return results
-	}
-
-	// Identify the position of match in file using a dirty hack.
-	for _, result := range results {
-		for _, extraction := range result.ExtractedResults {
-			scanner := bufio.NewScanner(strings.NewReader(rawStr))
-
-			line := 1
-			for scanner.Scan() {
-				if strings.Contains(scanner.Text(), extraction) {
-					if result.FileToIndexPosition == nil {
-						result.FileToIndexPosition = make(map[string]int)
-					}
-					result.FileToIndexPosition[result.Matched] = line
-					continue
-				}
-				line++
-			}
-		}
-	}
 	return results
 }
 
@@ -142,10 +136,14 @@ func (r *Request) makeResultEventItem(wrapped *output.InternalWrappedEvent) *out
 		Matched:          types.ToString(wrapped.InternalEvent["matched"]),
 		Host:             types.ToString(wrapped.InternalEvent["matched"]),
 		ExtractedResults: wrapped.OperatorsResult.OutputExtracts,
+		MatchedSnippets:  wrapped.OperatorsResult.MatchedSnippets,
+		MatchedWords:     wrapped.OperatorsResult.MatchedWords,
+		MatcherNames:     wrapped.OperatorsResult.AndMatches,
+		ExtractedNamed:   wrapped.OperatorsResult.Extracts,
 		Timestamp:        time.Now(),
 	}
 	if r.options.Options.JSONRequests {
-		data.Response = types.ToString(wrapped.InternalEvent["raw"])
+		data.Response = output.TruncateRR(types.ToString(wrapped.InternalEvent["raw"]), r.options.Options.JSONRequestsMaxSize)
 	}
 	return data
 }
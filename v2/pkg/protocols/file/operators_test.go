@@ -69,7 +69,7 @@ func TestFileOperatorMatch(t *testing.T) {
 		err = matcher.CompileMatchers()
 		require.Nil(t, err, "could not compile matcher")
 
-		matched := request.Match(event, matcher)
+		matched, _ := request.Match(event, matcher)
 		require.True(t, matched, "could not match valid response")
 	})
 
@@ -83,7 +83,7 @@ func TestFileOperatorMatch(t *testing.T) {
 		err := matcher.CompileMatchers()
 		require.Nil(t, err, "could not compile negative matcher")
 
-		matched := request.Match(event, matcher)
+		matched, _ := request.Match(event, matcher)
 		require.True(t, matched, "could not match valid negative response matcher")
 	})
 
@@ -96,7 +96,7 @@ func TestFileOperatorMatch(t *testing.T) {
 		err := matcher.CompileMatchers()
 		require.Nil(t, err, "could not compile matcher")
 
-		matched := request.Match(event, matcher)
+		matched, _ := request.Match(event, matcher)
 		require.False(t, matched, "could match invalid response matcher")
 	})
 }
@@ -201,4 +201,7 @@ func TestFileMakeResult(t *testing.T) {
 	require.Equal(t, 1, len(finalEvent.Results), "could not get correct number of results")
 	require.Equal(t, "test", finalEvent.Results[0].MatcherName, "could not get correct matcher name of results")
 	require.Equal(t, "1.1.1.1", finalEvent.Results[0].ExtractedResults[0], "could not get correct extracted results")
+	require.Equal(t, []int{2}, finalEvent.Results[0].Lines, "could not get correct lines of results")
+	require.Equal(t, []int{11}, finalEvent.Results[0].Offsets, "could not get correct offsets of results")
+	require.Equal(t, "1.1.1.1", finalEvent.Results[0].Snippet, "could not get correct snippet of results")
 }
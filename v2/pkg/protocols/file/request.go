@@ -1,74 +1,71 @@
 package file
 
 import (
+	"context"
 	"io/ioutil"
 	"os"
+	"sync"
 
 	"github.com/pkg/errors"
 	"github.com/projectdiscovery/gologger"
 	"github.com/yaklang/nuclei/v2/pkg/output"
 	"github.com/yaklang/nuclei/v2/pkg/protocols"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/common/tostring"
-	"github.com/remeh/sizedwaitgroup"
 )
 
 var _ protocols.Request = &Request{}
 
 // ExecuteWithResults executes the protocol requests and returns results instead of writing them.
 func (r *Request) ExecuteWithResults(input string, metadata, previous output.InternalEvent, callback protocols.OutputEventCallback) error {
-	wg := sizedwaitgroup.New(r.options.Options.BulkSize)
+	ctx := r.context()
+	concurrency := r.options.Options.FileConcurrency
+	if concurrency <= 0 {
+		concurrency = r.options.Options.BulkSize
+	}
+	if concurrency <= 0 {
+		concurrency = 1
+	}
 
-	err := r.getInputPaths(input, func(data string) {
-		wg.Add()
+	// The workers use a copy of the previous values, which the callback
+	// may update, and the callback is never called concurrently.
+	previousValues := make(output.InternalEvent, len(previous))
+	for k, v := range previous {
+		previousValues[k] = v
+	}
+	callbackMutex := &sync.Mutex{}
+	lockedCallback := func(event *output.InternalWrappedEvent) {
+		callbackMutex.Lock()
+		defer callbackMutex.Unlock()
+		callback(event)
+	}
 
-		go func(data string) {
+	// The files are counted as they are found, and processed by a fixed
+	// number of workers while the walk goes on.
+	paths := make(chan string)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
 			defer wg.Done()
-
-			file, err := os.Open(data)
-			if err != nil {
-				gologger.Error().Msgf("Could not open file path %s: %s\n", data, err)
-				return
-			}
-			defer file.Close()
-
-			stat, err := file.Stat()
-			if err != nil {
-				gologger.Error().Msgf("Could not stat file path %s: %s\n", data, err)
-				return
-			}
-			if stat.Size() >= int64(r.MaxSize) {
-				gologger.Verbose().Msgf("Could not process path %s: exceeded max size\n", data)
-				return
-			}
-
-			buffer, err := ioutil.ReadAll(file)
-			if err != nil {
-				gologger.Error().Msgf("Could not read file path %s: %s\n", data, err)
-				return
+			for data := range paths {
+				r.processFile(data, input, previousValues, lockedCallback)
+				r.options.Progress.IncrementRequests()
 			}
-			dataStr := tostring.UnsafeToString(buffer)
-			if r.options.Options.Debug || r.options.Options.DebugRequests {
-				gologger.Info().Msgf("[%s] Dumped file request for %s", r.options.TemplateID, data)
-				gologger.Print().Msgf("%s", dataStr)
-			}
-			gologger.Verbose().Msgf("[%s] Sent FILE request to %s", r.options.TemplateID, data)
-			outputEvent := r.responseToDSLMap(dataStr, input, data)
-			for k, v := range previous {
-				outputEvent[k] = v
-			}
-
-			event := &output.InternalWrappedEvent{InternalEvent: outputEvent}
-			if r.CompiledOperators != nil {
-				result, ok := r.CompiledOperators.Execute(outputEvent, r.Match, r.Extract)
-				if ok && result != nil {
-					event.OperatorsResult = result
-					event.Results = r.MakeResultEvent(event)
-				}
-			}
-			callback(event)
-		}(data)
+		}()
+	}
+	err := r.getInputPaths(input, func(data string) {
+		select {
+		case paths <- data:
+			r.options.Progress.AddToTotal(1)
+		case <-ctx.Done():
+		}
 	})
+	close(paths)
 	wg.Wait()
+	if ctx.Err() != nil {
+		// The walk was stopped as the scan was interrupted
+		return nil
+	}
 	if err != nil {
 		r.options.Output.Request(r.options.TemplateID, input, "file", err)
 		r.options.Progress.IncrementFailedRequestsBy(1)
@@ -77,3 +74,93 @@ func (r *Request) ExecuteWithResults(input string, metadata, previous output.Int
 	r.options.Progress.IncrementRequests()
 	return nil
 }
+
+// context returns the context of the scan, cancelled when it is interrupted
+func (r *Request) context() context.Context {
+	if r.options.Context != nil {
+		return r.options.Context
+	}
+	return context.Background()
+}
+
+// processFile runs the request on a file, its archive entries or its chunks
+func (r *Request) processFile(data, input string, previous output.InternalEvent, callback protocols.OutputEventCallback) {
+	// Files are not read if the operators only use their metadata
+	if r.metadataOnly && !(r.Archive && archiveType(data) != "") {
+		stat, err := os.Stat(data)
+		if err != nil {
+			gologger.Error().Msgf("Could not stat file path %s: %s\n", data, err)
+			return
+		}
+		r.processData("", input, data, stat, previous, callback)
+		return
+	}
+
+	file, err := os.Open(data)
+	if err != nil {
+		gologger.Error().Msgf("Could not open file path %s: %s\n", data, err)
+		return
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		gologger.Error().Msgf("Could not stat file path %s: %s\n", data, err)
+		return
+	}
+	kind := archiveType(data)
+	isArchive := r.Archive && kind != ""
+	// Streamed files are not limited in size, unlike archives
+	if stat.Size() >= int64(r.MaxSize) && (isArchive || !r.Stream) {
+		gologger.Verbose().Msgf("Could not process path %s: exceeded max size\n", data)
+		return
+	}
+
+	if isArchive {
+		err := r.readArchive(file, stat.Size(), kind, func(name string, buffer []byte) {
+			r.processData(tostring.UnsafeToString(buffer), input, data+":"+name, nil, previous, callback)
+		})
+		if err != nil {
+			gologger.Error().Msgf("Could not read archive path %s: %s\n", data, err)
+		}
+		return
+	}
+	if r.Stream {
+		if err := r.streamFile(file, stat, input, data, previous, callback); err != nil {
+			gologger.Error().Msgf("Could not stream file path %s: %s\n", data, err)
+		}
+		return
+	}
+
+	buffer, err := ioutil.ReadAll(file)
+	if err != nil {
+		gologger.Error().Msgf("Could not read file path %s: %s\n", data, err)
+		return
+	}
+	r.processData(tostring.UnsafeToString(buffer), input, data, stat, previous, callback)
+}
+
+// processData runs the operators on the data of a file or an archive entry,
+// archive entries having no file info.
+func (r *Request) processData(dataStr, input, matched string, info os.FileInfo, previous output.InternalEvent, callback protocols.OutputEventCallback) {
+	if (r.options.Options.Debug || r.options.Options.DebugRequests) && !r.metadataOnly {
+		gologger.Info().Msgf("[%s] Dumped file request for %s", r.options.TemplateID, matched)
+		gologger.Print().Msgf("%s", dataStr)
+	}
+	gologger.Verbose().Msgf("[%s] Sent FILE request to %s", r.options.TemplateID, matched)
+	outputEvent := r.responseToDSLMap(dataStr, input, matched)
+	addMetadata(outputEvent, matched, len(dataStr), info)
+	for k, v := range previous {
+		outputEvent[k] = v
+	}
+
+	event := &output.InternalWrappedEvent{InternalEvent: outputEvent}
+	if r.CompiledOperators != nil {
+		result, ok := r.CompiledOperators.Execute(outputEvent, r.Match, r.Extract)
+		if ok && result != nil {
+			event.OperatorsResult = result
+			event.Results = r.MakeResultEvent(event)
+		}
+	}
+	callback(event)
+}
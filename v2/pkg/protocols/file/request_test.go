@@ -1,9 +1,12 @@
 package file
 
 import (
+	"context"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
+	"sync"
 	"testing"
 
 	"github.com/yaklang/nuclei/v2/internal/testutils"
@@ -11,7 +14,11 @@ import (
 	"github.com/yaklang/nuclei/v2/pkg/operators/extractors"
 	"github.com/yaklang/nuclei/v2/pkg/operators/matchers"
 	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/yaklang/nuclei/v2/pkg/progress"
+	"github.com/yaklang/nuclei/v2/pkg/protocols"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/executer"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
 )
 
 func TestFileExecuteWithResults(t *testing.T) {
@@ -74,3 +81,120 @@ func TestFileExecuteWithResults(t *testing.T) {
 	require.Equal(t, "1.1.1.1", finalEvent.Results[0].ExtractedResults[0], "could not get correct extracted results")
 	finalEvent = nil
 }
+
+// countingProgress counts the files found and processed by a request
+type countingProgress struct {
+	progress.Progress
+	total    atomic.Int64
+	requests atomic.Int64
+}
+
+func (p *countingProgress) AddToTotal(delta int64) { p.total.Add(delta) }
+func (p *countingProgress) IncrementRequests()     { p.requests.Add(1) }
+
+func TestFileExecuteConcurrency(t *testing.T) {
+	options := *testutils.DefaultOptions
+	options.FileConcurrency = 4
+
+	testutils.Init(&options)
+	templateID := "testing-file-concurrency"
+	request := &Request{
+		ID:                templateID,
+		MaxSize:           1024,
+		Extensions:        []string{"all"},
+		ExtensionDenylist: []string{".go"},
+		Operators: operators.Operators{
+			Matchers: []*matchers.Matcher{{Part: "raw", Type: "word", Words: []string{"TEST"}}},
+		},
+	}
+	executerOpts := testutils.NewMockExecuterOptions(&options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	counter := &countingProgress{Progress: executerOpts.Progress}
+	executerOpts.Progress = counter
+	err := request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile file request")
+
+	tempDir, err := ioutil.TempDir("", "test-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	for i := 0; i < 50; i++ {
+		dir := path.Join(tempDir, fmt.Sprintf("dir-%d", i%5))
+		require.Nil(t, os.MkdirAll(dir, 0755), "could not create temporary directory")
+		err = ioutil.WriteFile(path.Join(dir, fmt.Sprintf("file-%d.txt", i)), []byte("TEST"), 0644)
+		require.Nil(t, err, "could not write temporary file")
+		err = ioutil.WriteFile(path.Join(dir, fmt.Sprintf("file-%d.go", i)), []byte("TEST"), 0644)
+		require.Nil(t, err, "could not write temporary file")
+	}
+
+	var mutex sync.Mutex
+	matched := make(map[string]struct{})
+	err = request.ExecuteWithResults(tempDir, make(output.InternalEvent), make(output.InternalEvent), func(event *output.InternalWrappedEvent) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		for _, result := range event.Results {
+			matched[result.Matched] = struct{}{}
+		}
+	})
+	require.Nil(t, err, "could not execute file request")
+	require.Len(t, matched, 50, "could not match all files")
+	require.Equal(t, int64(50), counter.total.Load(), "could not count found files")
+	require.Equal(t, int64(51), counter.requests.Load(), "could not count processed files")
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		executerOpts.Context = ctx
+		defer func() { executerOpts.Context = nil }()
+
+		var results int
+		err := request.ExecuteWithResults(tempDir, make(output.InternalEvent), make(output.InternalEvent), func(event *output.InternalWrappedEvent) {
+			results++
+		})
+		require.Nil(t, err, "could not stop file request")
+		require.Equal(t, 0, results, "could process files of cancelled scan")
+	})
+}
+
+func TestFileExecuterConcurrency(t *testing.T) {
+	options := *testutils.DefaultOptions
+	options.FileConcurrency = 8
+
+	testutils.Init(&options)
+	templateID := "testing-file-executer"
+	request := &Request{
+		ID:         "files",
+		MaxSize:    1024,
+		Extensions: []string{"all"},
+		Operators: operators.Operators{
+			Matchers: []*matchers.Matcher{{Part: "raw", Type: "word", Words: []string{"TEST"}}},
+		},
+	}
+	executerOpts := testutils.NewMockExecuterOptions(&options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	written := &atomic.Int64{}
+	executerOpts.Output.(*testutils.MockOutputWriter).WriteCallback = func(o *output.ResultEvent) {
+		written.Inc()
+	}
+
+	tempDir, err := ioutil.TempDir("", "test-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	for i := 0; i < 200; i++ {
+		err = ioutil.WriteFile(path.Join(tempDir, fmt.Sprintf("file-%d.txt", i)), []byte("TEST"), 0644)
+		require.Nil(t, err, "could not write temporary file")
+	}
+
+	fileExecuter := executer.NewExecuter([]protocols.Request{request}, executerOpts)
+	require.Nil(t, fileExecuter.Compile(), "could not compile executer")
+
+	found, err := fileExecuter.Execute(tempDir)
+	require.Nil(t, err, "could not execute file request")
+	require.True(t, found, "could not match files")
+	require.Equal(t, int64(200), written.Load(), "could not write all results")
+}
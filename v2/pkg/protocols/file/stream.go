@@ -0,0 +1,249 @@
+package file
+
+import (
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/yaklang/nuclei/v2/pkg/operators/extractors"
+	"github.com/yaklang/nuclei/v2/pkg/operators/matchers"
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/yaklang/nuclei/v2/pkg/protocols"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/tostring"
+)
+
+const (
+	// defaultChunkSize is the default size of the chunks of streamed files
+	defaultChunkSize = 1024 * 1024
+	// regexWindow is the overlap of the chunks for regexes, which can
+	// match more than their longest literal.
+	regexWindow = 1024
+	// maxStreamValues is the maximum number of snippets and extracted
+	// values kept for each matcher and extractor of a streamed file.
+	maxStreamValues = 1024
+)
+
+// isRawPart returns true if a part is the content of the file
+func isRawPart(part string) bool {
+	switch part {
+	case "body", "all", "data", "raw", "":
+		return true
+	}
+	return false
+}
+
+// compileStream compiles the regexes of the streamed matchers and computes
+// the overlap of the chunks from the longest word or the regex window.
+func (r *Request) compileStream() error {
+	if r.ChunkSize == 0 {
+		r.ChunkSize = defaultChunkSize
+	}
+	if r.ChunkSize < 0 {
+		return errors.New("chunk size must be positive")
+	}
+	r.streamRegexes = make(map[*matchers.Matcher][]*regexp.Regexp)
+	if r.CompiledOperators == nil {
+		return nil
+	}
+	for _, matcher := range r.CompiledOperators.Matchers {
+		if !isRawPart(matcher.Part) {
+			continue
+		}
+		switch matcher.GetType() {
+		case matchers.WordsMatcher:
+			for _, word := range matcher.Words {
+				if len(word)-1 > r.overlap {
+					r.overlap = len(word) - 1
+				}
+			}
+		case matchers.RegexMatcher:
+			for _, regex := range matcher.Regex {
+				compiled, err := regexp.Compile(regex)
+				if err != nil {
+					return errors.Wrapf(err, "could not compile regex %s", regex)
+				}
+				r.streamRegexes[matcher] = append(r.streamRegexes[matcher], compiled)
+			}
+			if r.overlap < regexWindow {
+				r.overlap = regexWindow
+			}
+		}
+	}
+	for _, extractor := range r.CompiledOperators.Extractors {
+		if isRawPart(extractor.Part) && extractor.GetType() == extractors.RegexExtractor && r.overlap < regexWindow {
+			r.overlap = regexWindow
+		}
+	}
+	if r.overlap > r.ChunkSize/2 {
+		r.overlap = r.ChunkSize / 2
+	}
+	return nil
+}
+
+// streamMatcher contains the words or regexes of a matcher found in the chunks
+type streamMatcher struct {
+	found    []bool
+	snippets map[string]struct{}
+}
+
+// streamState aggregates the matches and extracts of the chunks of a file
+type streamState struct {
+	request  *Request
+	matchers map[*matchers.Matcher]*streamMatcher
+	extracts map[*extractors.Extractor]map[string]struct{}
+}
+
+// newStreamState returns the state of the streamed matchers and extractors
+func (r *Request) newStreamState() *streamState {
+	state := &streamState{
+		request:  r,
+		matchers: make(map[*matchers.Matcher]*streamMatcher),
+		extracts: make(map[*extractors.Extractor]map[string]struct{}),
+	}
+	if r.CompiledOperators == nil {
+		return state
+	}
+	for _, matcher := range r.CompiledOperators.Matchers {
+		if !isRawPart(matcher.Part) {
+			continue
+		}
+		switch matcher.GetType() {
+		case matchers.WordsMatcher:
+			state.matchers[matcher] = &streamMatcher{found: make([]bool, len(matcher.Words)), snippets: make(map[string]struct{})}
+		case matchers.RegexMatcher:
+			state.matchers[matcher] = &streamMatcher{found: make([]bool, len(matcher.Regex)), snippets: make(map[string]struct{})}
+		}
+	}
+	for _, extractor := range r.CompiledOperators.Extractors {
+		if isRawPart(extractor.Part) && extractor.GetType() == extractors.RegexExtractor {
+			state.extracts[extractor] = make(map[string]struct{})
+		}
+	}
+	return state
+}
+
+// process runs the streamed matchers and extractors on a chunk. The chunk
+// is reused after, so the values kept are copied.
+func (s *streamState) process(chunk string) {
+	for matcher, streamed := range s.matchers {
+		if matcher.GetType() == matchers.WordsMatcher {
+			for i, word := range matcher.Words {
+				if !streamed.found[i] && strings.Contains(chunk, word) {
+					streamed.found[i] = true
+					streamed.snippets[word] = struct{}{}
+				}
+			}
+			continue
+		}
+		for i, regex := range s.request.streamRegexes[matcher] {
+			for _, match := range regex.FindAllString(chunk, -1) {
+				streamed.found[i] = true
+				if len(streamed.snippets) < maxStreamValues {
+					streamed.snippets[copyString(match)] = struct{}{}
+				}
+			}
+		}
+	}
+	for extractor, values := range s.extracts {
+		for value := range extractor.ExtractRegex(chunk) {
+			if len(values) < maxStreamValues {
+				values[copyString(value)] = struct{}{}
+			}
+		}
+	}
+}
+
+// match returns the result of a streamed matcher with its sorted snippets
+func (s *streamState) match(matcher *matchers.Matcher, streamed *streamMatcher) (bool, []string) {
+	matched := matcher.Condition == "and"
+	for _, found := range streamed.found {
+		if matcher.Condition == "and" {
+			matched = matched && found
+		} else {
+			matched = matched || found
+		}
+	}
+	if !matched {
+		return matcher.ResultWithMatchedSnippet(false, nil)
+	}
+	snippets := make([]string, 0, len(streamed.snippets))
+	for snippet := range streamed.snippets {
+		snippets = append(snippets, snippet)
+	}
+	sort.Strings(snippets)
+	return matcher.ResultWithMatchedSnippet(true, snippets)
+}
+
+// copyString copies a string out of the chunk
+func copyString(value string) string {
+	return string([]byte(value))
+}
+
+// streamFile reads a file in chunks overlapping by the longest word or
+// the regex window, and runs the operators with the aggregated results.
+func (r *Request) streamFile(file *os.File, info os.FileInfo, input, path string, previous output.InternalEvent, callback protocols.OutputEventCallback) error {
+	state := r.newStreamState()
+	buffer := make([]byte, r.ChunkSize)
+
+	var first string
+	var filled int
+	for {
+		if err := r.context().Err(); err != nil {
+			return err
+		}
+		n, err := io.ReadFull(file, buffer[filled:])
+		filled += n
+		if n > 0 {
+			chunk := buffer[:filled]
+			if first == "" {
+				first = string(chunk)
+			}
+			state.process(tostring.UnsafeToString(chunk))
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "could not read file")
+		}
+		// Keep the end of the chunk for the matches across chunks
+		filled = copy(buffer, buffer[filled-r.overlap:filled])
+	}
+	gologger.Verbose().Msgf("[%s] Sent FILE request to %s", r.options.TemplateID, path)
+
+	outputEvent := r.responseToDSLMap(first, input, path)
+	addMetadata(outputEvent, path, int(info.Size()), info)
+	for k, v := range previous {
+		outputEvent[k] = v
+	}
+	event := &output.InternalWrappedEvent{InternalEvent: outputEvent}
+	if r.CompiledOperators != nil {
+		match := func(data map[string]interface{}, matcher *matchers.Matcher) (bool, []string) {
+			if streamed, ok := state.matchers[matcher]; ok {
+				return state.match(matcher, streamed)
+			}
+			if matcher.GetType() == matchers.SizeMatcher {
+				return matcher.Result(matcher.MatchSize(int(info.Size()))), nil
+			}
+			return r.Match(data, matcher)
+		}
+		extract := func(data map[string]interface{}, extractor *extractors.Extractor) map[string]struct{} {
+			if values, ok := state.extracts[extractor]; ok {
+				return values
+			}
+			return r.Extract(data, extractor)
+		}
+		result, ok := r.CompiledOperators.Execute(outputEvent, match, extract)
+		if ok && result != nil {
+			event.OperatorsResult = result
+			// The locations are only known in the first chunk kept as raw
+			event.Results = r.makeResultEvents(event)
+		}
+	}
+	callback(event)
+	return nil
+}
@@ -0,0 +1,132 @@
+package file
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/yaklang/nuclei/v2/internal/testutils"
+	"github.com/yaklang/nuclei/v2/pkg/operators"
+	"github.com/yaklang/nuclei/v2/pkg/operators/extractors"
+	"github.com/yaklang/nuclei/v2/pkg/operators/matchers"
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+func compileStreamRequest(t *testing.T, request *Request) {
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	request.ID = "testing-file-stream"
+	request.Extensions = []string{"all"}
+	request.Stream = true
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   request.ID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	err := request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile file request")
+}
+
+func TestFileExecuteStream(t *testing.T) {
+	request := &Request{
+		MaxSize:   64,
+		ChunkSize: 32,
+		Operators: operators.Operators{
+			MatchersCondition: "and",
+			Matchers: []*matchers.Matcher{
+				{Name: "words", Type: "word", Words: []string{"BEGIN", "boundary-word", "END"}, Condition: "and"},
+				{Name: "regex", Type: "regex", Regex: []string{"key=[0-9]+"}},
+				{Name: "size", Type: "size", Size: []int{100}},
+			},
+			Extractors: []*extractors.Extractor{{Type: "regex", Regex: []string{"key=[0-9]+"}}},
+		},
+	}
+	compileStreamRequest(t, request)
+	require.Equal(t, 16, request.overlap, "could not cap overlap to half of the chunk size")
+
+	tempDir, err := ioutil.TempDir("", "test-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	// The words are in different chunks, one of them across two chunks
+	data := "BEGIN" + strings.Repeat(".", 22) + "boundary-word" + strings.Repeat(".", 20) + "key=1234" + strings.Repeat(".", 29) + "END"
+	require.Len(t, data, 100, "could not build file data")
+	file := path.Join(tempDir, "app.log")
+	err = ioutil.WriteFile(file, []byte(data), 0644)
+	require.Nil(t, err, "could not write temporary file")
+
+	var finalEvent *output.InternalWrappedEvent
+	err = request.ExecuteWithResults(file, make(output.InternalEvent), make(output.InternalEvent), func(event *output.InternalWrappedEvent) {
+		finalEvent = event
+	})
+	require.Nil(t, err, "could not execute file request")
+	require.NotNil(t, finalEvent, "could not get event output from request")
+	require.True(t, finalEvent.OperatorsResult.Matched, "could not match streamed file above max size")
+	require.Equal(t, []string{"key=1234"}, finalEvent.OperatorsResult.OutputExtracts, "could not extract from streamed file")
+	require.Equal(t, []string{"BEGIN", "END", "boundary-word"}, finalEvent.OperatorsResult.MatchedWords["words"], "could not get streamed snippets")
+	for _, result := range finalEvent.Results {
+		require.Empty(t, result.Offsets, "could get offsets of streamed file")
+		require.Empty(t, result.Lines, "could get lines of streamed file")
+	}
+}
+
+func TestFileStreamOverlap(t *testing.T) {
+	request := &Request{
+		ChunkSize: 8192,
+		Operators: operators.Operators{
+			Matchers: []*matchers.Matcher{
+				{Type: "word", Words: []string{strings.Repeat("a", 2000)}},
+				{Type: "regex", Regex: []string{"key=[0-9]+"}},
+			},
+			Extractors: []*extractors.Extractor{{Type: "regex", Regex: []string{"key=[0-9]+"}}},
+		},
+	}
+	compileStreamRequest(t, request)
+	require.Equal(t, 1999, request.overlap, "could not keep overlap of longest word")
+}
+
+func TestFileExecuteStreamLarge(t *testing.T) {
+	request := &Request{
+		Operators: operators.Operators{
+			Matchers:   []*matchers.Matcher{{Type: "word", Words: []string{"FATAL"}}},
+			Extractors: []*extractors.Extractor{{Type: "regex", Regex: []string{"secret=[a-z0-9]+"}}},
+		},
+	}
+	compileStreamRequest(t, request)
+
+	tempDir, err := ioutil.TempDir("", "test-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	file := path.Join(tempDir, "large.log")
+	f, err := os.Create(file)
+	require.Nil(t, err, "could not create temporary file")
+	block := bytes.Repeat([]byte("INFO request served in 10ms\n"), 1024*1024/28)
+	for written := 0; written < 100*1024*1024; written += len(block) {
+		_, err = f.Write(block)
+		require.Nil(t, err, "could not write temporary file")
+	}
+	_, err = f.WriteString("FATAL secret=abc123\n")
+	require.Nil(t, err, "could not write temporary file")
+	f.Close()
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	var finalEvent *output.InternalWrappedEvent
+	err = request.ExecuteWithResults(file, make(output.InternalEvent), make(output.InternalEvent), func(event *output.InternalWrappedEvent) {
+		finalEvent = event
+	})
+	runtime.ReadMemStats(&after)
+	require.Nil(t, err, "could not execute file request")
+	require.NotNil(t, finalEvent, "could not get event output from request")
+	require.Equal(t, 1, len(finalEvent.Results), "could not match end of large file")
+	require.Equal(t, []string{"secret=abc123"}, finalEvent.Results[0].ExtractedResults, "could not extract from end of large file")
+	require.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(32*1024*1024), "could not bound memory of streamed file")
+}
@@ -1,6 +1,12 @@
 package engine
 
-import "strings"
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
 
 // ActionType defines the action type for a browser action
 type ActionType int8
@@ -50,56 +56,86 @@ const (
 	ActionDebug
 	// ActionSleep executes a sleep for a specified duration
 	ActionSleep
+	// ActionIntercept records the xhr and fetch requests of the page in the
+	// xhr_urls, xhr_bodies and xhr_requests parts, and blocks the requests
+	// matching a pattern.
+	ActionIntercept
+	// ActionWaitForSelector waits for an element to be visible on the page.
+	ActionWaitForSelector
+	// ActionHover moves the mouse over an element.
+	ActionHover
+	// ActionScroll scrolls to an element or by a number of pixels.
+	ActionScroll
+	// ActionUploadFile sets a file input from a file or an inline content.
+	ActionUploadFile
+	// ActionSetCookie sets a cookie in the browser.
+	ActionSetCookie
+	// ActionWaitForConsole waits for a console message matching a pattern.
+	ActionWaitForConsole
 )
 
 // ActionStringToAction converts an action from string to internal representation
 var ActionStringToAction = map[string]ActionType{
-	"navigate":     ActionNavigate,
-	"script":       ActionScript,
-	"click":        ActionClick,
-	"rightclick":   ActionRightClick,
-	"text":         ActionTextInput,
-	"screenshot":   ActionScreenshot,
-	"time":         ActionTimeInput,
-	"select":       ActionSelectInput,
-	"files":        ActionFilesInput,
-	"waitload":     ActionWaitLoad,
-	"getresource":  ActionGetResource,
-	"extract":      ActionExtract,
-	"setmethod":    ActionSetMethod,
-	"addheader":    ActionAddHeader,
-	"setheader":    ActionSetHeader,
-	"deleteheader": ActionDeleteHeader,
-	"setbody":      ActionSetBody,
-	"waitevent":    ActionWaitEvent,
-	"keyboard":     ActionKeyboard,
-	"debug":        ActionDebug,
-	"sleep":        ActionSleep,
+	"navigate":        ActionNavigate,
+	"script":          ActionScript,
+	"click":           ActionClick,
+	"rightclick":      ActionRightClick,
+	"text":            ActionTextInput,
+	"screenshot":      ActionScreenshot,
+	"time":            ActionTimeInput,
+	"select":          ActionSelectInput,
+	"files":           ActionFilesInput,
+	"waitload":        ActionWaitLoad,
+	"getresource":     ActionGetResource,
+	"extract":         ActionExtract,
+	"setmethod":       ActionSetMethod,
+	"addheader":       ActionAddHeader,
+	"setheader":       ActionSetHeader,
+	"deleteheader":    ActionDeleteHeader,
+	"setbody":         ActionSetBody,
+	"waitevent":       ActionWaitEvent,
+	"keyboard":        ActionKeyboard,
+	"debug":           ActionDebug,
+	"sleep":           ActionSleep,
+	"intercept":       ActionIntercept,
+	"waitforselector": ActionWaitForSelector,
+	"hover":           ActionHover,
+	"scroll":          ActionScroll,
+	"uploadfile":      ActionUploadFile,
+	"setcookie":       ActionSetCookie,
+	"waitforconsole":  ActionWaitForConsole,
 }
 
 // ActionToActionString converts an action from  internal representation to string
 var ActionToActionString = map[ActionType]string{
-	ActionNavigate:     "navigate",
-	ActionScript:       "script",
-	ActionClick:        "click",
-	ActionRightClick:   "rightclick",
-	ActionTextInput:    "text",
-	ActionScreenshot:   "screenshot",
-	ActionTimeInput:    "time",
-	ActionSelectInput:  "select",
-	ActionFilesInput:   "files",
-	ActionWaitLoad:     "waitload",
-	ActionGetResource:  "getresource",
-	ActionExtract:      "extract",
-	ActionSetMethod:    "set-method",
-	ActionAddHeader:    "addheader",
-	ActionSetHeader:    "setheader",
-	ActionDeleteHeader: "deleteheader",
-	ActionSetBody:      "setbody",
-	ActionWaitEvent:    "waitevent",
-	ActionKeyboard:     "keyboard",
-	ActionDebug:        "debug",
-	ActionSleep:        "sleep",
+	ActionNavigate:        "navigate",
+	ActionScript:          "script",
+	ActionClick:           "click",
+	ActionRightClick:      "rightclick",
+	ActionTextInput:       "text",
+	ActionScreenshot:      "screenshot",
+	ActionTimeInput:       "time",
+	ActionSelectInput:     "select",
+	ActionFilesInput:      "files",
+	ActionWaitLoad:        "waitload",
+	ActionGetResource:     "getresource",
+	ActionExtract:         "extract",
+	ActionSetMethod:       "set-method",
+	ActionAddHeader:       "addheader",
+	ActionSetHeader:       "setheader",
+	ActionDeleteHeader:    "deleteheader",
+	ActionSetBody:         "setbody",
+	ActionWaitEvent:       "waitevent",
+	ActionKeyboard:        "keyboard",
+	ActionDebug:           "debug",
+	ActionSleep:           "sleep",
+	ActionIntercept:       "intercept",
+	ActionWaitForSelector: "waitforselector",
+	ActionHover:           "hover",
+	ActionScroll:          "scroll",
+	ActionUploadFile:      "uploadfile",
+	ActionSetCookie:       "setcookie",
+	ActionWaitForConsole:  "waitforconsole",
 }
 
 // Action is an action taken by the browser to reach a navigation
@@ -141,3 +177,71 @@ func (a *Action) GetArg(name string) string {
 	}
 	return v
 }
+
+// Validate validates the type of an action and the arguments of the
+// actions which can be checked before launching the browser.
+func (a *Action) Validate() error {
+	actionType, ok := ActionStringToAction[a.ActionType]
+	if !ok {
+		return errors.Errorf("unknown action %s", a.ActionType)
+	}
+	for _, name := range []string{"timeout", "x", "y"} {
+		if value := a.GetArg(name); value != "" {
+			if _, err := strconv.Atoi(value); err != nil {
+				return errors.Errorf("invalid %s %s", name, value)
+			}
+		}
+	}
+
+	switch actionType {
+	case ActionWaitForSelector, ActionHover:
+		return validateElementArgs(a.Data)
+	case ActionScroll:
+		if a.GetArg("x") == "" && a.GetArg("y") == "" {
+			return validateElementArgs(a.Data)
+		}
+	case ActionUploadFile:
+		if (a.GetArg("file") == "") == (a.GetArg("content") == "") {
+			return errors.New("one of file or content is required")
+		}
+		return validateElementArgs(a.Data)
+	case ActionSetCookie:
+		if a.GetArg("name") == "" {
+			return errors.New("cookie name is required")
+		}
+	case ActionWaitForConsole:
+		pattern := a.GetArg("pattern")
+		if pattern == "" {
+			return errors.New("pattern is required")
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return errors.Wrap(err, "invalid pattern")
+		}
+	}
+	return nil
+}
+
+// validateElementArgs validates the arguments selecting an element
+func validateElementArgs(data map[string]string) error {
+	var required []string
+	switch data["by"] {
+	case "r", "regex":
+		required = []string{"selector", "regex"}
+	case "x", "xpath":
+		required = []string{"xpath"}
+	case "js":
+		required = []string{"js"}
+	case "search":
+		required = []string{"query"}
+	case "":
+		required = []string{"selector"}
+	default:
+		return errors.Errorf("unknown element selector %s", data["by"])
+	}
+	for _, name := range required {
+		if data[name] == "" {
+			return errors.Errorf("%s is required", name)
+		}
+	}
+	return nil
+}
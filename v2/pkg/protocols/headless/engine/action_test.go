@@ -0,0 +1,41 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateActions(t *testing.T) {
+	valid := []*Action{
+		{ActionType: "navigate", Data: map[string]string{"url": "{{BaseURL}}"}},
+		{ActionType: "waitforselector", Data: map[string]string{"selector": "#login", "timeout": "5"}},
+		{ActionType: "hover", Data: map[string]string{"by": "xpath", "xpath": "//div"}},
+		{ActionType: "scroll", Data: map[string]string{"y": "500"}},
+		{ActionType: "scroll", Data: map[string]string{"selector": "#footer"}},
+		{ActionType: "uploadfile", Data: map[string]string{"selector": "input", "content": "test", "filename": "shell.php"}},
+		{ActionType: "uploadfile", Data: map[string]string{"selector": "input", "file": "payloads/test.txt"}},
+		{ActionType: "setcookie", Data: map[string]string{"name": "session", "value": "test"}},
+		{ActionType: "waitforconsole", Data: map[string]string{"pattern": "nuclei-[0-9]+", "timeout": "5"}},
+	}
+	for _, action := range valid {
+		require.Nil(t, action.Validate(), "could not validate %s", action)
+	}
+
+	invalid := []*Action{
+		{ActionType: "waitforselectr", Data: map[string]string{"selector": "#login"}},
+		{ActionType: "waitforselector", Data: map[string]string{"selector": "#login", "timeout": "5s"}},
+		{ActionType: "hover"},
+		{ActionType: "hover", Data: map[string]string{"by": "css", "selector": "div"}},
+		{ActionType: "scroll", Data: map[string]string{"y": "down"}},
+		{ActionType: "scroll"},
+		{ActionType: "uploadfile", Data: map[string]string{"selector": "input"}},
+		{ActionType: "uploadfile", Data: map[string]string{"selector": "input", "content": "test", "file": "test.txt"}},
+		{ActionType: "setcookie", Data: map[string]string{"value": "test"}},
+		{ActionType: "waitforconsole"},
+		{ActionType: "waitforconsole", Data: map[string]string{"pattern": "nuclei-[0-9"}},
+	}
+	for _, action := range invalid {
+		require.NotNil(t, action.Validate(), "could validate %s", action)
+	}
+}
@@ -0,0 +1,132 @@
+package engine
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+)
+
+// defaultWaitForConsoleTimeout is the default time to wait for a console message
+const defaultWaitForConsoleTimeout = 10 * time.Second
+
+// consoleRecorder records the console messages and the uncaught errors of
+// a page, waking up the actions waiting for a message on each new one.
+type consoleRecorder struct {
+	mutex    sync.Mutex
+	updated  chan struct{}
+	messages []string
+	errors   []string
+}
+
+// newConsoleRecorder creates a new console recorder
+func newConsoleRecorder() *consoleRecorder {
+	return &consoleRecorder{updated: make(chan struct{})}
+}
+
+// onConsole records a console message of the page
+func (c *consoleRecorder) onConsole(e *proto.RuntimeConsoleAPICalled) {
+	args := make([]string, 0, len(e.Args))
+	for _, arg := range e.Args {
+		args = append(args, remoteObjectString(arg))
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.messages = append(c.messages, strings.Join(args, " "))
+	close(c.updated)
+	c.updated = make(chan struct{})
+}
+
+// onException records an uncaught error of the page
+func (c *consoleRecorder) onException(e *proto.RuntimeExceptionThrown) {
+	if e.ExceptionDetails == nil {
+		return
+	}
+	message := e.ExceptionDetails.Text
+	if exception := e.ExceptionDetails.Exception; exception != nil && exception.Description != "" {
+		// Only keep the error line of the description, not the stack
+		message = strings.SplitN(exception.Description, "\n", 2)[0]
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.errors = append(c.errors, message)
+}
+
+// wait waits until a console message matches the pattern, returning false
+// if none did before the timeout or the done channel is closed.
+func (c *consoleRecorder) wait(pattern *regexp.Regexp, timeout time.Duration, done <-chan struct{}) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var seen int
+	for {
+		c.mutex.Lock()
+		for _, message := range c.messages[seen:] {
+			if pattern.MatchString(message) {
+				c.mutex.Unlock()
+				return true
+			}
+		}
+		seen = len(c.messages)
+		updated := c.updated
+		c.mutex.Unlock()
+
+		select {
+		case <-updated:
+		case <-timer.C:
+			return false
+		case <-done:
+			return false
+		}
+	}
+}
+
+// remoteObjectString returns the string logged for a console argument
+func remoteObjectString(object *proto.RuntimeRemoteObject) string {
+	switch {
+	case object.Type == proto.RuntimeRemoteObjectTypeString:
+		return object.Value.Str()
+	case object.UnserializableValue != "":
+		return string(object.UnserializableValue)
+	case object.Description != "":
+		return object.Description
+	case !object.Value.Nil():
+		return object.Value.JSON("", "")
+	}
+	return string(object.Type)
+}
+
+// WaitForConsole waits for a console message matching a pattern
+func (p *Page) WaitForConsole(act *Action, out map[string]string) error {
+	pattern, err := regexp.Compile(act.GetArg("pattern"))
+	if err != nil {
+		return errors.Wrap(err, "could not compile pattern")
+	}
+	timeout := defaultWaitForConsoleTimeout
+	if value := act.GetArg("timeout"); value != "" {
+		seconds, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.Wrap(err, "could not get timeout")
+		}
+		timeout = time.Duration(seconds) * time.Second
+	}
+	if !p.console.wait(pattern, timeout, p.page.GetContext().Done()) {
+		return errors.Errorf("no console message matching %s", pattern)
+	}
+	return nil
+}
+
+// Console returns the console messages and the uncaught errors of the page
+// in the console and page_errors parts, one per line.
+func (p *Page) Console() map[string]string {
+	p.console.mutex.Lock()
+	defer p.console.mutex.Unlock()
+	return map[string]string{
+		"console":     strings.Join(p.console.messages, "\n"),
+		"page_errors": strings.Join(p.console.errors, "\n"),
+	}
+}
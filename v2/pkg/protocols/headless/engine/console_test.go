@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/ysmood/gson"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionWaitForConsole(t *testing.T) {
+	_, page, cleanup := runTestdataPage(t, "console.html#1234", []*Action{
+		{ActionType: "waitforconsole", Data: map[string]string{"pattern": "nuclei-marker-[0-9]+", "timeout": "5"}},
+	})
+	defer cleanup()
+
+	parts := page.Console()
+	require.Equal(t, "page loaded\nnuclei-marker-1234", parts["console"], "could not get console messages")
+	require.Contains(t, parts["page_errors"], "undefinedFunction is not defined", "could not get page errors")
+}
+
+func TestConsoleRecorder(t *testing.T) {
+	recorder := newConsoleRecorder()
+	log := func(args ...*proto.RuntimeRemoteObject) {
+		recorder.onConsole(&proto.RuntimeConsoleAPICalled{Type: proto.RuntimeConsoleAPICalledTypeLog, Args: args})
+	}
+	log(&proto.RuntimeRemoteObject{Type: proto.RuntimeRemoteObjectTypeString, Value: gson.New("count")}, &proto.RuntimeRemoteObject{Type: proto.RuntimeRemoteObjectTypeNumber, Value: gson.New(1), Description: "1"})
+	recorder.onException(&proto.RuntimeExceptionThrown{ExceptionDetails: &proto.RuntimeExceptionDetails{
+		Text:      "Uncaught",
+		Exception: &proto.RuntimeRemoteObject{Type: proto.RuntimeRemoteObjectTypeObject, Description: "ReferenceError: test is not defined\n    at <anonymous>:1:1"},
+	}})
+
+	require.True(t, recorder.wait(regexp.MustCompile("count 1"), time.Second, nil), "could not match previous console message")
+	require.False(t, recorder.wait(regexp.MustCompile("nuclei"), 100*time.Millisecond, nil), "could match missing console message")
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		log(&proto.RuntimeRemoteObject{Type: proto.RuntimeRemoteObjectTypeString, Value: gson.New("nuclei-marker")})
+	}()
+	require.True(t, recorder.wait(regexp.MustCompile("nuclei-marker"), 5*time.Second, nil), "could not wait for console message")
+
+	page := &Page{console: recorder}
+	require.Equal(t, map[string]string{
+		"console":     "count 1\nnuclei-marker",
+		"page_errors": "ReferenceError: test is not defined",
+	}, page.Console(), "could not get console parts")
+}
@@ -23,6 +23,7 @@ type Browser struct {
 	engine       *rod.Browser
 	httpclient   *http.Client
 	options      *types.Options
+	pool         *pagePool
 }
 
 // New creates a new nuclei headless browser module
@@ -84,6 +85,9 @@ func New(options *types.Options) (*Browser, error) {
 		httpclient:  httpclient,
 		options:     options,
 	}
+	if options.HeadlessConcurrency > 0 {
+		engine.pool = newPagePool(options.HeadlessConcurrency, options.HeadlessPageUses)
+	}
 	engine.previouspids = engine.findChromeProcesses()
 	return engine, nil
 }
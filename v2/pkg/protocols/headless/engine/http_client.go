@@ -1,19 +1,35 @@
 package engine
 
 import (
+	"context"
 	"crypto/tls"
+	"net"
 	"net/http"
-	"time"
 
 	"github.com/yaklang/nuclei/v2/pkg/protocols/common/protocolstate"
 	"github.com/yaklang/nuclei/v2/pkg/types"
 )
 
-// newhttpClient creates a new http client for headless communication with a timeout
+// newhttpClient creates a new http client for headless communication with a timeout.
+// The local network restriction of the page a request is made for is applied to
+// each redirect and to the addresses actually dialed.
 func newhttpClient(options *types.Options) *http.Client {
 	dialer := protocolstate.Dialer
 	transport := &http.Transport{
-		DialContext:         dialer.Dial,
+		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			conn, err := dialer.Dial(ctx, network, address)
+			if err != nil {
+				return nil, err
+			}
+			if p, ok := ctx.Value(pageContextKey{}).(*Page); ok {
+				hostname, _, _ := net.SplitHostPort(address)
+				if err := p.checkDialedAddress(hostname, conn.RemoteAddr()); err != nil {
+					conn.Close()
+					return nil, err
+				}
+			}
+			return conn, nil
+		},
 		MaxIdleConns:        500,
 		MaxIdleConnsPerHost: 500,
 		MaxConnsPerHost:     500,
@@ -22,5 +38,9 @@ func newhttpClient(options *types.Options) *http.Client {
 			InsecureSkipVerify: true,
 		},
 	}
-	return &http.Client{Transport: transport, Timeout: time.Duration(options.Timeout*3) * time.Second}
+	return &http.Client{
+		Transport:     transport,
+		CheckRedirect: checkRedirect,
+		Timeout:       options.ProtocolTimeout(types.HeadlessProtocol),
+	}
 }
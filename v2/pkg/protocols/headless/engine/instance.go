@@ -11,8 +11,15 @@ import (
 
 // Instance is an isolated browser instance opened for doing operations with it.
 type Instance struct {
-	browser *Browser
-	engine  *rod.Browser
+	browser    *Browser
+	engine     *rod.Browser
+	templateID string
+
+	// pool is the pool of the instance if acquired from one, in which case
+	// its page is reused between the executions.
+	pool *pagePool
+	page *rod.Page
+	uses int
 }
 
 // NewInstance creates a new instance for the current browser.
@@ -34,8 +41,18 @@ func (b *Browser) NewInstance() (*Instance, error) {
 	return &Instance{browser: b, engine: browser}, nil
 }
 
-// Close closes all the tabs and pages for a browser instance
+// SetTemplateID sets the template ID the screenshots of the instance are named from
+func (i *Instance) SetTemplateID(templateID string) {
+	i.templateID = templateID
+}
+
+// Close closes all the tabs and pages for a browser instance, or releases
+// the instance to its pool.
 func (i *Instance) Close() error {
+	if i.pool != nil {
+		i.pool.release(i)
+		return nil
+	}
 	return i.engine.Close()
 }
 
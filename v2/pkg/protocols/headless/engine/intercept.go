@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+)
+
+// defaultInterceptSize is the default maximum size of the recorded bodies
+const defaultInterceptSize = 64 * 1024
+
+// interceptor records the xhr and fetch requests of a page once enabled,
+// and blocks the requests matching its pattern.
+type interceptor struct {
+	mutex    sync.Mutex
+	enabled  bool
+	block    *regexp.Regexp
+	maxSize  int
+	requests []*interceptedRequest
+}
+
+// interceptedRequest is a recorded xhr or fetch request
+type interceptedRequest struct {
+	method string
+	url    string
+	status int
+	body   string
+}
+
+// Intercept executes an intercept action recording the xhr and fetch
+// requests of the page, blocking the requests matching the block pattern.
+func (p *Page) Intercept(act *Action, out map[string]string) error {
+	maxSize := defaultInterceptSize
+	if value := act.GetArg("max-size"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size <= 0 {
+			return errors.New("invalid max-size provided")
+		}
+		maxSize = size
+	}
+	var block *regexp.Regexp
+	if pattern := act.GetArg("block"); pattern != "" {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return errors.Wrap(err, "could not compile block pattern")
+		}
+		block = compiled
+	}
+
+	p.interceptor.mutex.Lock()
+	defer p.interceptor.mutex.Unlock()
+	p.interceptor.enabled = true
+	p.interceptor.block = block
+	p.interceptor.maxSize = maxSize
+	return nil
+}
+
+// blocked returns true if a request URL matches the block pattern
+func (i *interceptor) blocked(URL string) bool {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	return i.block != nil && i.block.MatchString(URL)
+}
+
+// record records a loaded xhr or fetch request with its capped body
+func (i *interceptor) record(ctx *rod.Hijack) {
+	resourceType := ctx.Request.Type()
+	if resourceType != proto.NetworkResourceTypeXHR && resourceType != proto.NetworkResourceTypeFetch {
+		return
+	}
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	if !i.enabled {
+		return
+	}
+	body := ctx.Response.Payload().Body
+	if len(body) > i.maxSize {
+		body = body[:i.maxSize]
+	}
+	i.requests = append(i.requests, &interceptedRequest{
+		method: ctx.Request.Method(),
+		url:    ctx.Request.URL().String(),
+		status: ctx.Response.Payload().ResponseCode,
+		body:   string(body),
+	})
+}
+
+// Intercepted returns the parts of the recorded requests, one line per
+// request for xhr_urls and xhr_requests, nil if nothing was intercepted.
+func (p *Page) Intercepted() map[string]string {
+	p.interceptor.mutex.Lock()
+	defer p.interceptor.mutex.Unlock()
+	if !p.interceptor.enabled {
+		return nil
+	}
+	urls := &strings.Builder{}
+	bodies := &strings.Builder{}
+	requests := &strings.Builder{}
+	for _, request := range p.interceptor.requests {
+		urls.WriteString(request.url + "\n")
+		bodies.WriteString(request.body + "\n")
+		requests.WriteString(request.method + " " + request.url + " " + strconv.Itoa(request.status) + "\n")
+	}
+	return map[string]string{
+		"xhr_urls":     urls.String(),
+		"xhr_bodies":   bodies.String(),
+		"xhr_requests": requests.String(),
+	}
+}
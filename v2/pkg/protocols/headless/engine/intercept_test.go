@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/protocolstate"
+	"github.com/yaklang/nuclei/v2/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionIntercept(t *testing.T) {
+	_ = protocolstate.Init(&types.Options{})
+
+	browser, err := New(&types.Options{ShowBrowser: false})
+	require.Nil(t, err, "could not create browser")
+	defer browser.Close()
+
+	instance, err := browser.NewInstance()
+	require.Nil(t, err, "could not create browser instance")
+	defer instance.Close()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/config":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"apiKey":"secret-key-1234"}`)
+		case "/analytics.js":
+			fmt.Fprint(w, `document.title = "tracked"`)
+		default:
+			fmt.Fprint(w, `<html><head><title>Nuclei Test Page</title><script src="/analytics.js"></script></head>
+			<body><div id="done"></div><script>
+			fetch("/api/config").then(r => r.json()).then(data => { document.getElementById("done").setAttribute("key", data.apiKey) })
+			</script></body></html>`)
+		}
+	}))
+	defer ts.Close()
+
+	parsed, err := url.Parse(ts.URL)
+	require.Nil(t, err, "could not parse URL")
+
+	actions := []*Action{
+		{ActionType: "intercept", Data: map[string]string{"block": "analytics"}},
+		{ActionType: "navigate", Data: map[string]string{"url": "{{BaseURL}}"}},
+		{ActionType: "waitload"},
+		{ActionType: "script", Name: "key", Data: map[string]string{"code": "new Promise(resolve => { const check = () => { const key = document.getElementById('done').getAttribute('key'); key ? resolve(key) : setTimeout(check, 50) }; check() })"}},
+	}
+	out, page, err := instance.Run(parsed, actions, 20*time.Second)
+	require.Nil(t, err, "could not run page actions")
+	defer page.Close()
+
+	require.Equal(t, "secret-key-1234", out["key"], "could not fetch json endpoint")
+	require.Equal(t, "Nuclei Test Page", page.Page().MustInfo().Title, "could not block analytics script")
+	intercepted := page.Intercepted()
+	require.Equal(t, ts.URL+"/api/config\n", intercepted["xhr_urls"], "could not intercept fetch url")
+	require.Equal(t, `{"apiKey":"secret-key-1234"}`+"\n", intercepted["xhr_bodies"], "could not intercept fetch body")
+	require.Equal(t, "GET "+ts.URL+"/api/config 200\n", intercepted["xhr_requests"], "could not intercept fetch request")
+}
+
+func TestIntercept(t *testing.T) {
+	page := &Page{}
+	require.Nil(t, page.Intercepted(), "could get parts without intercept action")
+
+	err := page.Intercept(&Action{ActionType: "intercept", Data: map[string]string{"max-size": "0"}}, nil)
+	require.NotNil(t, err, "could intercept with invalid max-size")
+	err = page.Intercept(&Action{ActionType: "intercept", Data: map[string]string{"block": "("}}, nil)
+	require.NotNil(t, err, "could intercept with invalid block pattern")
+
+	err = page.Intercept(&Action{ActionType: "intercept", Data: map[string]string{"block": `google-analytics\.com|/ads/`, "max-size": "4"}}, nil)
+	require.Nil(t, err, "could not intercept")
+	require.True(t, page.interceptor.blocked("https://www.google-analytics.com/collect"), "could not block analytics request")
+	require.False(t, page.interceptor.blocked("https://example.com/api"), "could block api request")
+	require.Equal(t, 4, page.interceptor.maxSize, "could not set max-size")
+	require.Equal(t, map[string]string{"xhr_urls": "", "xhr_bodies": "", "xhr_requests": ""}, page.Intercepted(), "could not get empty parts")
+}
@@ -2,6 +2,8 @@ package engine
 
 import (
 	"net/url"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/go-rod/rod"
@@ -10,17 +12,46 @@ import (
 
 // Page is a single page in an isolated browser instanace
 type Page struct {
-	page     *rod.Page
-	rules    []requestRule
-	instance *Instance
-	router   *rod.HijackRouter
+	page        *rod.Page
+	rules       []requestRule
+	instance    *Instance
+	router      *rod.HijackRouter
+	baseURL     *url.URL
+	screenshots []string
+	interceptor interceptor
+	console     *consoleRecorder
+	stopConsole func()
+
+	// origins are the origins requested by the page, cleared on close
+	originsMutex sync.Mutex
+	origins      map[string]struct{}
 }
 
-// Run runs a list of actions by creating a new page in the browser.
+// Run runs a list of actions by creating a new page in the browser, or on
+// the page of the instance if it's pooled.
 func (i *Instance) Run(baseURL *url.URL, actions []*Action, timeout time.Duration) (map[string]string, *Page, error) {
-	page, err := i.engine.Page(proto.TargetCreateTarget{})
-	if err != nil {
-		return nil, nil, err
+	data, page, err := i.run(baseURL, actions, timeout)
+	if err != nil && i.pool != nil {
+		// The reused page is in an unknown state after a failure
+		i.discardPage()
+	}
+	return data, page, err
+}
+
+// run runs the actions on a page, returning the page if they succeeded
+func (i *Instance) run(baseURL *url.URL, actions []*Action, timeout time.Duration) (map[string]string, *Page, error) {
+	var page *rod.Page
+	if i.pool != nil {
+		if err := i.pooledPage(); err != nil {
+			return nil, nil, err
+		}
+		page = i.page
+	} else {
+		created, err := i.engine.Page(proto.TargetCreateTarget{})
+		if err != nil {
+			return nil, nil, err
+		}
+		page = created
 	}
 	page = page.Timeout(timeout)
 
@@ -30,14 +61,19 @@ func (i *Instance) Run(baseURL *url.URL, actions []*Action, timeout time.Duratio
 		}
 	}
 
-	createdPage := &Page{page: page, instance: i}
+	createdPage := &Page{page: page, instance: i, baseURL: baseURL}
+	consolePage, stopConsole := page.WithCancel()
+	createdPage.console = newConsoleRecorder()
+	createdPage.stopConsole = stopConsole
+	go consolePage.EachEvent(createdPage.console.onConsole, createdPage.console.onException)()
+
 	router := page.HijackRequests()
 	if routerErr := router.Add("*", "", createdPage.routingRuleHandler); routerErr != nil {
 		return nil, nil, routerErr
 	}
 	createdPage.router = router
 
-	err = page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{Viewport: &proto.PageViewport{
+	err := page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{Viewport: &proto.PageViewport{
 		Scale:  1,
 		Width:  float64(1920),
 		Height: float64(1080),
@@ -52,6 +88,7 @@ func (i *Instance) Run(baseURL *url.URL, actions []*Action, timeout time.Duratio
 	go router.Run()
 	data, err := createdPage.ExecuteActions(baseURL, actions)
 	if err != nil {
+		createdPage.stop()
 		return nil, nil, err
 	}
 	return data, createdPage, nil
@@ -59,15 +96,58 @@ func (i *Instance) Run(baseURL *url.URL, actions []*Action, timeout time.Duratio
 
 // Close closes a browser page
 func (p *Page) Close() {
-	_ = p.router.Stop()
+	p.stop()
+	if p.instance.pool != nil {
+		current, _ := url.Parse(p.URL())
+		p.addOrigin(p.baseURL)
+		p.addOrigin(current)
+		p.instance.resetPage(p.visitedOrigins())
+		return
+	}
 	p.page.Close()
 }
 
+// addOrigin records the origin of a url requested by the page
+func (p *Page) addOrigin(u *url.URL) {
+	if u == nil || u.Host == "" {
+		return
+	}
+	p.originsMutex.Lock()
+	defer p.originsMutex.Unlock()
+	if p.origins == nil {
+		p.origins = make(map[string]struct{})
+	}
+	p.origins[u.Scheme+"://"+u.Host] = struct{}{}
+}
+
+// visitedOrigins returns the sorted origins requested by the page
+func (p *Page) visitedOrigins() []string {
+	p.originsMutex.Lock()
+	defer p.originsMutex.Unlock()
+	origins := make([]string, 0, len(p.origins))
+	for origin := range p.origins {
+		origins = append(origins, origin)
+	}
+	sort.Strings(origins)
+	return origins
+}
+
+// stop stops the listeners of the page
+func (p *Page) stop() {
+	p.stopConsole()
+	_ = p.router.Stop()
+}
+
 // Page returns the current page for the actions
 func (p *Page) Page() *rod.Page {
 	return p.page
 }
 
+// Screenshots returns the files of the screenshots taken on the page
+func (p *Page) Screenshots() []string {
+	return p.screenshots
+}
+
 // Browser returns the browser that created the current page
 func (p *Page) Browser() *rod.Browser {
 	return p.instance.engine
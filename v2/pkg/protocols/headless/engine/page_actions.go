@@ -1,9 +1,12 @@
 package engine
 
 import (
+	"fmt"
 	"io/ioutil"
 	"net"
 	"net/url"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -12,6 +15,7 @@ import (
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/proto"
 	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
 	"github.com/segmentio/ksuid"
 	"github.com/valyala/fasttemplate"
 )
@@ -67,6 +71,20 @@ func (p *Page) ExecuteActions(baseURL *url.URL, actions []*Action) (map[string]s
 			err = p.DebugAction(act, outData)
 		case ActionSleep:
 			err = p.SleepAction(act, outData)
+		case ActionIntercept:
+			err = p.Intercept(act, outData)
+		case ActionWaitForSelector:
+			err = p.WaitForSelector(act, outData)
+		case ActionHover:
+			err = p.HoverElement(act, outData)
+		case ActionScroll:
+			err = p.Scroll(act, outData)
+		case ActionUploadFile:
+			err = p.UploadFile(act, outData)
+		case ActionSetCookie:
+			err = p.SetCookie(act, outData)
+		case ActionWaitForConsole:
+			err = p.WaitForConsole(act, outData)
 		default:
 			continue
 		}
@@ -176,7 +194,16 @@ func (p *Page) NavigateURL(action *Action, out map[string]string, parsed *url.UR
 	values["BaseURL"] = parsedString
 
 	final := fasttemplate.ExecuteStringStd(URL, "{{", "}}", values)
-	err := p.page.Navigate(final)
+	if target, parseErr := url.Parse(final); parseErr == nil {
+		if err := p.checkAccess(target); err != nil {
+			return err
+		}
+	}
+	page := p.page
+	if timeout := p.instance.browser.options.NavigationTimeout; timeout > 0 {
+		page = page.Timeout(time.Duration(timeout) * time.Second)
+	}
+	err := page.Navigate(final)
 	if err != nil {
 		return errors.Wrap(err, "could not navigate")
 	}
@@ -239,14 +266,30 @@ func (p *Page) RightClickElement(act *Action, out map[string]string) error {
 	return nil
 }
 
-// Screenshot executes screenshot action on a page
+// screenshotNameReplacer replaces the separators of template IDs and hosts
+var screenshotNameReplacer = strings.NewReplacer("/", "_", "\\", "_", ":", "_")
+
+// Screenshot executes screenshot action on a page. Without a to argument,
+// the screenshot is written to the screenshot directory named from the
+// template ID and host. Failures are logged without failing the actions.
 func (p *Page) Screenshot(act *Action, out map[string]string) error {
+	options := p.instance.browser.options
+	if options.NoScreenshots {
+		return nil
+	}
 	to := act.GetArg("to")
 	if to == "" {
-		to = ksuid.New().String()
-		if act.Name != "" {
-			out[act.Name] = to
+		directory := options.ScreenshotDir
+		if directory == "" {
+			directory = options.StoreResponseDir
 		}
+		if directory != "" {
+			if err := os.MkdirAll(directory, 0755); err != nil {
+				gologger.Warning().Msgf("Could not create screenshot directory: %s\n", err)
+				return nil
+			}
+		}
+		to = filepath.Join(directory, p.screenshotName())
 	}
 	var data []byte
 	var err error
@@ -256,15 +299,37 @@ func (p *Page) Screenshot(act *Action, out map[string]string) error {
 		data, err = p.page.Screenshot(false, &proto.PageCaptureScreenshot{})
 	}
 	if err != nil {
-		return errors.Wrap(err, "could not take screenshot")
+		gologger.Warning().Msgf("Could not take screenshot of %s: %s\n", p.baseURL, err)
+		return nil
 	}
-	err = ioutil.WriteFile(to+".png", data, 0540)
-	if err != nil {
-		return errors.Wrap(err, "could not write screenshot")
+	file := to + ".png"
+	if err = ioutil.WriteFile(file, data, 0644); err != nil {
+		gologger.Warning().Msgf("Could not write screenshot %s: %s\n", file, err)
+		return nil
 	}
+	if act.Name != "" {
+		out[act.Name] = file
+	}
+	p.screenshots = append(p.screenshots, file)
 	return nil
 }
 
+// screenshotName returns the name of the next screenshot of the page
+func (p *Page) screenshotName() string {
+	var host string
+	if p.baseURL != nil {
+		host = p.baseURL.Host
+	}
+	name := strings.Trim(p.instance.templateID+"-"+host, "-")
+	if name == "" {
+		name = ksuid.New().String()
+	}
+	if len(p.screenshots) > 0 {
+		name += "-" + strconv.Itoa(len(p.screenshots)+1)
+	}
+	return screenshotNameReplacer.Replace(name)
+}
+
 // InputElement executes input element actions for an element.
 func (p *Page) InputElement(act *Action, out map[string]string) error {
 	value := act.GetArg("value")
@@ -452,11 +517,15 @@ func (p *Page) WaitEvent(act *Action, out map[string]string) error {
 // Supported values for by: r -> selector & regex, x -> xpath, js -> eval js,
 // search => query, default ("") => selector.
 func (p *Page) pageElementBy(data map[string]string) (*rod.Element, error) {
+	return elementBy(p.page, data)
+}
+
+// elementBy returns an element of a page from a variety of inputs
+func elementBy(page *rod.Page, data map[string]string) (*rod.Element, error) {
 	by, ok := data["by"]
 	if !ok {
 		by = ""
 	}
-	page := p.page
 
 	switch by {
 	case "r", "regex":
@@ -479,6 +548,118 @@ func (p *Page) pageElementBy(data map[string]string) (*rod.Element, error) {
 	}
 }
 
+// defaultWaitForSelectorTimeout is the default time to wait for an element
+const defaultWaitForSelectorTimeout = 10 * time.Second
+
+// WaitForSelector waits for an element to be visible on the page
+func (p *Page) WaitForSelector(act *Action, out map[string]string) error {
+	timeout := defaultWaitForSelectorTimeout
+	if value := act.GetArg("timeout"); value != "" {
+		seconds, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.Wrap(err, "could not get timeout")
+		}
+		timeout = time.Duration(seconds) * time.Second
+	}
+	element, err := elementBy(p.page.Timeout(timeout), act.Data)
+	if err != nil {
+		return errors.Wrap(err, "could not get element")
+	}
+	if err = element.WaitVisible(); err != nil {
+		return errors.Wrap(err, "could not wait for element")
+	}
+	return nil
+}
+
+// HoverElement moves the mouse over an element
+func (p *Page) HoverElement(act *Action, out map[string]string) error {
+	element, err := p.pageElementBy(act.Data)
+	if err != nil {
+		return errors.Wrap(err, "could not get element")
+	}
+	if err = element.ScrollIntoView(); err != nil {
+		return errors.Wrap(err, "could not scroll into view")
+	}
+	if err = element.Hover(); err != nil {
+		return errors.Wrap(err, "could not hover element")
+	}
+	return nil
+}
+
+// Scroll scrolls the page by the x and y pixels, or to an element
+func (p *Page) Scroll(act *Action, out map[string]string) error {
+	x, y := act.GetArg("x"), act.GetArg("y")
+	if x == "" && y == "" {
+		element, err := p.pageElementBy(act.Data)
+		if err != nil {
+			return errors.Wrap(err, "could not get element")
+		}
+		if err = element.ScrollIntoView(); err != nil {
+			return errors.Wrap(err, "could not scroll into view")
+		}
+		return nil
+	}
+	pixelsX, _ := strconv.Atoi(x)
+	pixelsY, _ := strconv.Atoi(y)
+	if _, err := p.page.Eval(fmt.Sprintf("window.scrollBy(%d, %d)", pixelsX, pixelsY)); err != nil {
+		return errors.Wrap(err, "could not scroll page")
+	}
+	return nil
+}
+
+// UploadFile sets a file input from a file, resolved from the template
+// folder at compile, or from an inline content written to a file named
+// from the filename argument.
+func (p *Page) UploadFile(act *Action, out map[string]string) error {
+	file := act.GetArg("file")
+	if content := act.GetArg("content"); content != "" {
+		directory, err := ioutil.TempDir(p.instance.browser.tempDir, "upload-")
+		if err != nil {
+			return errors.Wrap(err, "could not create upload directory")
+		}
+		name := act.GetArg("filename")
+		if name == "" {
+			name = "upload.txt"
+		}
+		file = filepath.Join(directory, filepath.Base(name))
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			return errors.Wrap(err, "could not write upload file")
+		}
+	}
+	element, err := p.pageElementBy(act.Data)
+	if err != nil {
+		return errors.Wrap(err, "could not get element")
+	}
+	if err = element.ScrollIntoView(); err != nil {
+		return errors.Wrap(err, "could not scroll into view")
+	}
+	if err = element.SetFiles([]string{file}); err != nil {
+		return errors.Wrap(err, "could not set files")
+	}
+	return nil
+}
+
+// SetCookie sets a cookie for the url or domain of the arguments, or for
+// the url of the input if none is set.
+func (p *Page) SetCookie(act *Action, out map[string]string) error {
+	cookie := &proto.NetworkCookieParam{
+		Name:     act.GetArg("name"),
+		Value:    act.GetArg("value"),
+		URL:      act.GetArg("url"),
+		Domain:   act.GetArg("domain"),
+		Path:     act.GetArg("path"),
+		Secure:   act.GetArg("secure") == "true",
+		HTTPOnly: act.GetArg("httponly") == "true",
+	}
+	if cookie.URL == "" && cookie.Domain == "" && p.baseURL != nil {
+		cookie.URL = p.baseURL.String()
+	}
+	if err := p.page.SetCookies([]*proto.NetworkCookieParam{cookie}); err != nil {
+		return errors.Wrap(err, "could not set cookie")
+	}
+	return nil
+}
+
 // DebugAction enables debug action on a page.
 func (p *Page) DebugAction(act *Action, out map[string]string) error {
 	p.instance.browser.engine.SlowMotion(5 * time.Second)
@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/protocolstate"
+	"github.com/yaklang/nuclei/v2/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+// runTestdataActions runs actions on the static actions page of testdata
+func runTestdataActions(t *testing.T, actions []*Action) (map[string]string, *Page, func()) {
+	return runTestdataPage(t, "actions.html", actions)
+}
+
+// runTestdataPage runs actions on a static page of testdata
+func runTestdataPage(t *testing.T, name string, actions []*Action) (map[string]string, *Page, func()) {
+	_ = protocolstate.Init(&types.Options{})
+
+	browser, err := New(&types.Options{ShowBrowser: false})
+	require.Nil(t, err, "could not create browser")
+
+	instance, err := browser.NewInstance()
+	require.Nil(t, err, "could not create browser instance")
+
+	ts := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	parsed, err := url.Parse(ts.URL)
+	require.Nil(t, err, "could not parse URL")
+
+	actions = append([]*Action{
+		{ActionType: "navigate", Data: map[string]string{"url": "{{BaseURL}}/" + name}},
+		{ActionType: "waitload"},
+	}, actions...)
+	out, page, err := instance.Run(parsed, actions, 20*time.Second)
+	require.Nil(t, err, "could not run page actions")
+	return out, page, func() {
+		page.Close()
+		ts.Close()
+		instance.Close()
+		browser.Close()
+	}
+}
+
+func TestActionWaitForSelector(t *testing.T) {
+	out, _, cleanup := runTestdataActions(t, []*Action{
+		{ActionType: "waitforselector", Data: map[string]string{"selector": "#delayed", "timeout": "5"}},
+		{ActionType: "script", Name: "text", Data: map[string]string{"code": "document.getElementById('delayed').textContent"}},
+	})
+	defer cleanup()
+
+	require.Equal(t, "loaded", out["text"], "could not wait for delayed element")
+}
+
+func TestActionHover(t *testing.T) {
+	out, _, cleanup := runTestdataActions(t, []*Action{
+		{ActionType: "hover", Data: map[string]string{"selector": "#menu"}},
+		{ActionType: "script", Name: "hovered", Data: map[string]string{"code": "document.getElementById('menu').getAttribute('hovered')"}},
+	})
+	defer cleanup()
+
+	require.Equal(t, "true", out["hovered"], "could not hover element")
+}
+
+func TestActionScroll(t *testing.T) {
+	out, _, cleanup := runTestdataActions(t, []*Action{
+		{ActionType: "scroll", Data: map[string]string{"y": "1000"}},
+		{ActionType: "script", Name: "pixels", Data: map[string]string{"code": "String(window.scrollY)"}},
+		{ActionType: "scroll", Data: map[string]string{"selector": "#footer"}},
+		{ActionType: "script", Name: "element", Data: map[string]string{"code": "String(window.scrollY > 3000)"}},
+	})
+	defer cleanup()
+
+	require.Equal(t, "1000", out["pixels"], "could not scroll by pixels")
+	require.Equal(t, "true", out["element"], "could not scroll to element")
+}
+
+func TestActionUploadFile(t *testing.T) {
+	out, _, cleanup := runTestdataActions(t, []*Action{
+		{ActionType: "uploadfile", Data: map[string]string{"selector": "#upload", "content": "<?php echo 1; ?>", "filename": "shell.php"}},
+		{ActionType: "script", Name: "name", Data: map[string]string{"code": "document.getElementById('upload').files[0].name"}},
+		{ActionType: "script", Name: "content", Data: map[string]string{"code": "document.getElementById('upload').files[0].text()"}},
+	})
+	defer cleanup()
+
+	require.Equal(t, "shell.php", out["name"], "could not upload file with name")
+	require.Equal(t, "<?php echo 1; ?>", out["content"], "could not upload file content")
+}
+
+func TestActionSetCookie(t *testing.T) {
+	out, _, cleanup := runTestdataActions(t, []*Action{
+		{ActionType: "setcookie", Data: map[string]string{"name": "session", "value": "nuclei"}},
+		{ActionType: "navigate", Data: map[string]string{"url": "{{BaseURL}}/actions.html"}},
+		{ActionType: "waitload"},
+		{ActionType: "script", Name: "cookie", Data: map[string]string{"code": "document.cookie"}},
+	})
+	defer cleanup()
+
+	require.Equal(t, "session=nuclei", out["cookie"], "could not set cookie")
+}
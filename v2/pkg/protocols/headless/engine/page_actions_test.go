@@ -2,9 +2,12 @@ package engine
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -278,3 +281,64 @@ func TestActionHeadersChange(t *testing.T) {
 
 	require.Equal(t, "found", strings.ToLower(strings.TrimSpace(page.Page().MustElement("html").MustText())), "could not set header correctly")
 }
+
+func TestActionScreenshot(t *testing.T) {
+	_ = protocolstate.Init(&types.Options{})
+
+	tempDir, err := ioutil.TempDir("", "test-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	browser, err := New(&types.Options{ShowBrowser: false, ScreenshotDir: tempDir})
+	require.Nil(t, err, "could not create browser")
+	defer browser.Close()
+
+	instance, err := browser.NewInstance()
+	require.Nil(t, err, "could not create browser instance")
+	defer instance.Close()
+	instance.SetTemplateID("tech/login")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `<html><head><title>Nuclei Test Page</title></head><body><h1>Nuclei Test</h1></body></html>`)
+	}))
+	defer ts.Close()
+
+	parsed, err := url.Parse(ts.URL)
+	require.Nil(t, err, "could not parse URL")
+
+	actions := []*Action{
+		{ActionType: "navigate", Data: map[string]string{"url": "{{BaseURL}}"}},
+		{ActionType: "waitload"},
+		{ActionType: "screenshot", Name: "shot"},
+		{ActionType: "screenshot", Data: map[string]string{"fullpage": "true"}},
+	}
+	out, page, err := instance.Run(parsed, actions, 20*time.Second)
+	require.Nil(t, err, "could not run page actions")
+	defer page.Close()
+
+	name := "tech_login-" + strings.ReplaceAll(parsed.Host, ":", "_")
+	expected := []string{filepath.Join(tempDir, name+".png"), filepath.Join(tempDir, name+"-2.png")}
+	require.Equal(t, expected, page.Screenshots(), "could not get screenshots of page")
+	require.Equal(t, expected[0], out["shot"], "could not get named screenshot")
+	for _, file := range expected {
+		_, err := os.Stat(file)
+		require.Nil(t, err, "could not write screenshot")
+	}
+}
+
+func TestScreenshotDisabled(t *testing.T) {
+	page := &Page{instance: &Instance{browser: &Browser{options: &types.Options{NoScreenshots: true}}}}
+	err := page.Screenshot(&Action{ActionType: "screenshot", Name: "shot"}, make(map[string]string))
+	require.Nil(t, err, "could not skip disabled screenshot")
+	require.Empty(t, page.Screenshots(), "could take disabled screenshot")
+}
+
+func TestScreenshotName(t *testing.T) {
+	parsed, err := url.Parse("https://example.com:8443/login")
+	require.Nil(t, err, "could not parse URL")
+
+	page := &Page{instance: &Instance{templateID: "cves/2021/CVE-2021-1234"}, baseURL: parsed}
+	require.Equal(t, "cves_2021_CVE-2021-1234-example.com_8443", page.screenshotName(), "could not get screenshot name")
+	page.screenshots = []string{"first.png"}
+	require.Equal(t, "cves_2021_CVE-2021-1234-example.com_8443-2", page.screenshotName(), "could not get second screenshot name")
+}
@@ -0,0 +1,107 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// resetPageTimeout is the maximum time to clear a pooled page after a use
+const resetPageTimeout = 5 * time.Second
+
+// pagePool is a pool of isolated browser instances keeping their page open
+// between the template executions, until it has been used maxUses times.
+type pagePool struct {
+	instances chan *Instance
+	maxUses   int
+}
+
+// newPagePool creates a pool of size instances, created on first use
+func newPagePool(size, maxUses int) *pagePool {
+	pool := &pagePool{instances: make(chan *Instance, size), maxUses: maxUses}
+	for i := 0; i < size; i++ {
+		pool.instances <- nil
+	}
+	return pool
+}
+
+// AcquireInstance returns an instance from the pool of the browser, waiting
+// for one to be released if all of them are in use. Closing the instance
+// releases it to the pool. A new instance is returned if there's no pool.
+func (b *Browser) AcquireInstance(ctx context.Context) (*Instance, error) {
+	if b.pool == nil {
+		return b.NewInstance()
+	}
+
+	var instance *Instance
+	select {
+	case instance = <-b.pool.instances:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if instance != nil {
+		return instance, nil
+	}
+	instance, err := b.NewInstance()
+	if err != nil {
+		b.pool.instances <- nil
+		return nil, err
+	}
+	instance.pool = b.pool
+	return instance, nil
+}
+
+// release returns an instance to the pool, closing it once it has been
+// used the maximum number of times to contain the memory of the browser.
+func (p *pagePool) release(instance *Instance) {
+	if p.maxUses > 0 && instance.uses >= p.maxUses {
+		_ = instance.engine.Close()
+		instance = nil
+	}
+	p.instances <- instance
+}
+
+// pooledPage returns the page of a pooled instance, creating it if the
+// instance has none yet.
+func (i *Instance) pooledPage() error {
+	i.uses++
+	if i.page != nil {
+		return nil
+	}
+	page, err := i.engine.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		return err
+	}
+	i.page = page
+	return nil
+}
+
+// resetPage clears the cookies and the storage left by an execution for the
+// origins requested by the page of a pooled instance, discarding the page
+// if it can't be cleared.
+func (i *Instance) resetPage(origins []string) {
+	page := i.page.Timeout(resetPageTimeout)
+	err := page.Navigate("about:blank")
+	if err == nil {
+		err = proto.NetworkClearBrowserCookies{}.Call(page)
+	}
+	for _, origin := range origins {
+		if err != nil {
+			break
+		}
+		err = proto.StorageClearDataForOrigin{Origin: origin, StorageTypes: "all"}.Call(page)
+	}
+	if err != nil {
+		i.discardPage()
+	}
+}
+
+// discardPage closes the page of a pooled instance left in an unknown state
+func (i *Instance) discardPage() {
+	if i.page == nil {
+		return
+	}
+	_ = i.page.Close()
+	i.page = nil
+}
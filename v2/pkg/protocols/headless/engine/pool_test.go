@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/protocolstate"
+	"github.com/yaklang/nuclei/v2/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPagePoolAcquire(t *testing.T) {
+	pool := newPagePool(1, 10)
+	<-pool.instances
+	instance := &Instance{pool: pool}
+	pool.instances <- instance
+	browser := &Browser{pool: pool}
+
+	acquired, err := browser.AcquireInstance(context.Background())
+	require.Nil(t, err, "could not acquire instance")
+	require.Same(t, instance, acquired, "could not get pooled instance")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = browser.AcquireInstance(ctx)
+	require.Equal(t, context.DeadlineExceeded, err, "could acquire instance in use")
+
+	require.Nil(t, acquired.Close(), "could not release instance")
+	acquired, err = browser.AcquireInstance(context.Background())
+	require.Nil(t, err, "could not acquire released instance")
+	require.Same(t, instance, acquired, "could not reuse released instance")
+}
+
+func TestPageOrigins(t *testing.T) {
+	page := &Page{}
+	for _, value := range []string{"https://example.com/login", "https://example.com/api", "http://cdn.example.com:8080/app.js", "about:blank"} {
+		parsed, err := url.Parse(value)
+		require.Nil(t, err, "could not parse URL")
+		page.addOrigin(parsed)
+	}
+	page.addOrigin(nil)
+	require.Equal(t, []string{"http://cdn.example.com:8080", "https://example.com"}, page.visitedOrigins(), "could not get visited origins")
+}
+
+func TestActionPagePool(t *testing.T) {
+	_ = protocolstate.Init(&types.Options{})
+
+	browser, err := New(&types.Options{ShowBrowser: false, HeadlessConcurrency: 1, HeadlessPageUses: 2})
+	require.Nil(t, err, "could not create browser")
+	defer browser.Close()
+
+	ts := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer ts.Close()
+	parsed, err := url.Parse(ts.URL)
+	require.Nil(t, err, "could not parse URL")
+
+	actions := []*Action{
+		{ActionType: "navigate", Data: map[string]string{"url": "{{BaseURL}}/actions.html"}},
+		{ActionType: "waitload"},
+		{ActionType: "script", Name: "cookie", Data: map[string]string{"code": "document.cookie"}},
+		{ActionType: "script", Data: map[string]string{"code": "document.cookie = 'session=nuclei'"}},
+	}
+	var instances []*Instance
+	for i := 0; i < 3; i++ {
+		instance, err := browser.AcquireInstance(context.Background())
+		require.Nil(t, err, "could not acquire instance")
+		out, page, err := instance.Run(parsed, actions, 20*time.Second)
+		require.Nil(t, err, "could not run page actions")
+		require.Equal(t, "", out["cookie"], "could not reset pooled page")
+		page.Close()
+		instances = append(instances, instance)
+		require.Nil(t, instance.Close(), "could not release instance")
+	}
+	require.Same(t, instances[0], instances[1], "could not reuse pooled instance")
+	require.NotSame(t, instances[1], instances[2], "could not recycle pooled instance")
+}
@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/protocolstate"
+)
+
+// localNetworks are the private, loopback and link-local networks, the
+// latter including the metadata endpoints of the cloud providers.
+var localNetworks = parseNetworks(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+)
+
+// parseNetworks parses a list of CIDR networks
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// isLocalIP returns true if an IP is in a local network
+func isLocalIP(ip net.IP) bool {
+	for _, network := range localNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLocalHost returns true if a hostname is or resolves to a local IP
+func isLocalHost(hostname string) bool {
+	hostname = strings.ToLower(strings.TrimSuffix(hostname, "."))
+	if hostname == "localhost" || strings.HasSuffix(hostname, ".localhost") {
+		return true
+	}
+	if ip := net.ParseIP(hostname); ip != nil {
+		return isLocalIP(ip)
+	}
+	if protocolstate.Dialer == nil {
+		return false
+	}
+	data, err := protocolstate.Dialer.GetDNSData(hostname)
+	if err != nil || data == nil {
+		return false
+	}
+	for _, address := range append(data.A, data.AAAA...) {
+		if ip := net.ParseIP(address); ip != nil && isLocalIP(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// deniedReason returns why the page is not allowed to access a URL, or an
+// empty string if it's allowed. The host of the scanned input is always
+// allowed, as it was chosen by the user.
+func (p *Page) deniedReason(target *url.URL) string {
+	options := p.instance.browser.options
+	if strings.EqualFold(target.Scheme, "file") {
+		if options.AllowLocalFileAccess {
+			return ""
+		}
+		return "local file access is not allowed"
+	}
+	if options.AllowLocalNetworkAccess {
+		return ""
+	}
+	hostname := target.Hostname()
+	if hostname == "" || (p.baseURL != nil && strings.EqualFold(hostname, p.baseURL.Hostname())) {
+		return ""
+	}
+	if isLocalHost(hostname) {
+		return "local network access is not allowed"
+	}
+	return ""
+}
+
+// checkAccess returns an error if the page is not allowed to access a URL
+func (p *Page) checkAccess(target *url.URL) error {
+	reason := p.deniedReason(target)
+	if reason == "" {
+		return nil
+	}
+	gologger.Verbose().Msgf("[%s] Denied headless access to %s: %s\n", p.instance.templateID, target, reason)
+	return errors.Errorf("access to %s denied: %s", target, reason)
+}
+
+// pageContextKey is the context key of the page a hijacked request is loaded for
+type pageContextKey struct{}
+
+// withPage returns a copy of the context carrying the page the request is made for
+func withPage(ctx context.Context, p *Page) context.Context {
+	return context.WithValue(ctx, pageContextKey{}, p)
+}
+
+// checkRedirect checks the access of the page to each redirect location
+// followed while loading a hijacked request.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	if p, ok := req.Context().Value(pageContextKey{}).(*Page); ok {
+		return p.checkAccess(req.URL)
+	}
+	return nil
+}
+
+// checkDialedAddress returns an error if the page is not allowed to access
+// the address a connection to a host was actually made to, which protects
+// from hostnames resolving to a local IP after they were checked.
+func (p *Page) checkDialedAddress(hostname string, address net.Addr) error {
+	if p.instance.browser.options.AllowLocalNetworkAccess {
+		return nil
+	}
+	if p.baseURL != nil && strings.EqualFold(strings.TrimSuffix(hostname, "."), p.baseURL.Hostname()) {
+		return nil
+	}
+	tcpAddr, ok := address.(*net.TCPAddr)
+	if !ok || !isLocalIP(tcpAddr.IP) {
+		return nil
+	}
+	gologger.Verbose().Msgf("[%s] Denied headless access to %s (%s): local network access is not allowed\n", p.instance.templateID, hostname, tcpAddr.IP)
+	return errors.Errorf("access to %s denied: local network access is not allowed", hostname)
+}
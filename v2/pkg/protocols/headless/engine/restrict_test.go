@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/protocolstate"
+	"github.com/yaklang/nuclei/v2/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckAccess(t *testing.T) {
+	_ = protocolstate.Init(&types.Options{})
+
+	baseURL, err := url.Parse("http://10.0.0.5:8080/login")
+	require.Nil(t, err, "could not parse URL")
+	options := &types.Options{}
+	page := &Page{instance: &Instance{browser: &Browser{options: options}}, baseURL: baseURL}
+
+	check := func(target string) error {
+		parsed, err := url.Parse(target)
+		require.Nil(t, err, "could not parse URL")
+		return page.checkAccess(parsed)
+	}
+	for _, target := range []string{"file:///etc/passwd", "http://169.254.169.254/latest/meta-data/", "http://192.168.1.1/", "http://172.20.0.1/", "http://127.0.0.1:9000/", "http://localhost/", "http://[::1]/", "http://[fd00:ec2::254]/", "http://0.0.0.0:8080/"} {
+		require.NotNil(t, check(target), "could access %s", target)
+	}
+	for _, target := range []string{"http://10.0.0.5:8080/admin", "https://93.184.216.34/", "about:blank", "data:text/html,test"} {
+		require.Nil(t, check(target), "could not access %s", target)
+	}
+
+	options.AllowLocalFileAccess = true
+	options.AllowLocalNetworkAccess = true
+	require.Nil(t, check("file:///etc/passwd"), "could not access allowed local file")
+	require.Nil(t, check("http://169.254.169.254/"), "could not access unrestricted local network")
+}
+
+func TestCheckRedirectAndDialedAddress(t *testing.T) {
+	baseURL, err := url.Parse("http://example.com/")
+	require.Nil(t, err, "could not parse URL")
+	page := &Page{instance: &Instance{browser: &Browser{options: &types.Options{}}}, baseURL: baseURL}
+
+	req, err := http.NewRequestWithContext(withPage(context.Background(), page), http.MethodGet, "http://169.254.169.254/latest/meta-data/", nil)
+	require.Nil(t, err, "could not create request")
+	require.NotNil(t, checkRedirect(req, []*http.Request{req}), "could follow redirect to metadata IP")
+
+	local := &net.TCPAddr{IP: net.ParseIP("169.254.169.254"), Port: 80}
+	require.NotNil(t, page.checkDialedAddress("rebind.example.org", local), "could dial local IP of a public hostname")
+	require.Nil(t, page.checkDialedAddress("example.com", local), "could not dial the input host")
+	require.Nil(t, page.checkDialedAddress("rebind.example.org", &net.TCPAddr{IP: net.ParseIP("93.184.216.34"), Port: 80}), "could not dial public IP")
+}
+
+func TestActionNavigateDenied(t *testing.T) {
+	_ = protocolstate.Init(&types.Options{})
+	browser, err := New(&types.Options{ShowBrowser: false})
+	require.Nil(t, err, "could not create browser")
+	defer browser.Close()
+
+	instance, err := browser.NewInstance()
+	require.Nil(t, err, "could not create browser instance")
+	defer instance.Close()
+
+	parsed, err := url.Parse("http://127.0.0.1")
+	require.Nil(t, err, "could not parse URL")
+	_, _, err = instance.Run(parsed, []*Action{{ActionType: "navigate", Data: map[string]string{"url": "file:///etc/passwd"}}}, 20*time.Second)
+	require.NotNil(t, err, "could navigate to local file")
+	require.Contains(t, err.Error(), "local file access is not allowed", "could not get denied reason")
+}
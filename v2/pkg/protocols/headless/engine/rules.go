@@ -4,10 +4,20 @@ import (
 	"fmt"
 
 	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
 )
 
 // routingRuleHandler handles proxy rule for actions related to request/response modification
 func (p *Page) routingRuleHandler(ctx *rod.Hijack) {
+	if p.checkAccess(ctx.Request.URL()) != nil {
+		ctx.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
+		return
+	}
+	if p.interceptor.blocked(ctx.Request.URL().String()) {
+		ctx.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
+		return
+	}
+	p.addOrigin(ctx.Request.URL())
 	for _, rule := range p.rules {
 		if rule.Part != "request" {
 			continue
@@ -27,7 +37,9 @@ func (p *Page) routingRuleHandler(ctx *rod.Hijack) {
 			ctx.Request.SetBody(body)
 		}
 	}
+	ctx.Request.SetContext(withPage(ctx.Request.Req().Context(), p))
 	_ = ctx.LoadResponse(p.instance.browser.httpclient, true)
+	p.interceptor.record(ctx)
 
 	for _, rule := range p.rules {
 		if rule.Part != "response" {
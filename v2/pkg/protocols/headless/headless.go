@@ -1,9 +1,13 @@
 package headless
 
 import (
+	"os"
+	"path/filepath"
+
 	"github.com/pkg/errors"
 	"github.com/yaklang/nuclei/v2/pkg/operators"
 	"github.com/yaklang/nuclei/v2/pkg/protocols"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/generators"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/headless/engine"
 )
 
@@ -13,6 +17,10 @@ type Request struct {
 
 	// Steps is the list of actions to run for headless request
 	Steps []*engine.Action `yaml:"steps"`
+	// ExportLocalStorage exports the local storage of the page as json in
+	// the headless_local_storage value, along with the cookies exported in
+	// headless_cookies for the next requests of the template.
+	ExportLocalStorage bool `yaml:"export-local-storage"`
 
 	// Operators for the current request go here.
 	operators.Operators `yaml:",inline,omitempty"`
@@ -33,6 +41,11 @@ func (r *Request) GetID() string {
 	return r.ID
 }
 
+// EventType returns the type of the result events of the request.
+func (r *Request) EventType() string {
+	return "headless"
+}
+
 // Compile compiles the protocol request for further execution.
 func (r *Request) Compile(options *protocols.ExecuterOptions) error {
 	if len(r.Matchers) > 0 || len(r.Extractors) > 0 {
@@ -43,6 +56,39 @@ func (r *Request) Compile(options *protocols.ExecuterOptions) error {
 		r.CompiledOperators = compiled
 	}
 	r.options = options
+
+	for i, step := range r.Steps {
+		if err := step.Validate(); err != nil {
+			return errors.Wrapf(err, "could not validate step %d", i+1)
+		}
+		if err := r.resolveUploadFile(step); err != nil {
+			return errors.Wrapf(err, "could not validate step %d", i+1)
+		}
+	}
+	return nil
+}
+
+// resolveUploadFile resolves the file of an uploadfile action relative to
+// the folder of the template. The file must be inside the folder of the
+// template unless local file access is allowed.
+func (r *Request) resolveUploadFile(step *engine.Action) error {
+	file := step.GetArg("file")
+	if step.ActionType != "uploadfile" || file == "" {
+		return nil
+	}
+	templateDirectory := filepath.Dir(r.options.TemplatePath)
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(templateDirectory, file)
+	}
+	if _, err := os.Stat(file); err != nil {
+		return errors.Wrap(err, "could not find upload file")
+	}
+	if !r.options.Options.AllowLocalFileAccess {
+		if err := generators.SandboxPath(file, templateDirectory); err != nil {
+			return errors.Wrap(err, "upload file is outside of the template directory")
+		}
+	}
+	step.Data["file"] = file
 	return nil
 }
 
@@ -0,0 +1,78 @@
+package headless
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yaklang/nuclei/v2/internal/testutils"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/headless/engine"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeadlessCompile(t *testing.T) {
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	tempDir, err := ioutil.TempDir("", "test-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	err = ioutil.WriteFile(filepath.Join(tempDir, "shell.php"), []byte("<?php echo 1; ?>"), 0644)
+	require.Nil(t, err, "could not write upload file")
+
+	templateID := "testing-headless"
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+		Path: filepath.Join(tempDir, "upload.yaml"),
+	})
+
+	request := &Request{ID: templateID, Steps: []*engine.Action{
+		{ActionType: "navigate", Data: map[string]string{"url": "{{BaseURL}}"}},
+		{ActionType: "uploadfile", Data: map[string]string{"selector": "input[type=file]", "file": "shell.php"}},
+	}}
+	err = request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile headless request")
+	require.Equal(t, filepath.Join(tempDir, "shell.php"), request.Steps[1].GetArg("file"), "could not resolve upload file from template folder")
+
+	request = &Request{ID: templateID, Steps: []*engine.Action{
+		{ActionType: "uploadfile", Data: map[string]string{"selector": "input[type=file]", "file": "missing.php"}},
+	}}
+	err = request.Compile(executerOpts)
+	require.NotNil(t, err, "could compile headless request with missing upload file")
+
+	outsideDir, err := ioutil.TempDir("", "test-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(outsideDir)
+	outside := filepath.Join(outsideDir, "id_rsa")
+	err = ioutil.WriteFile(outside, []byte("secret"), 0600)
+	require.Nil(t, err, "could not write outside file")
+
+	for _, file := range []string{outside, filepath.Join("..", filepath.Base(outsideDir), "id_rsa")} {
+		request = &Request{ID: templateID, Steps: []*engine.Action{
+			{ActionType: "uploadfile", Data: map[string]string{"selector": "input[type=file]", "file": file}},
+		}}
+		err = request.Compile(executerOpts)
+		require.NotNil(t, err, "could compile headless request with upload file %s outside template folder", file)
+	}
+
+	allowedOptions := *options
+	allowedOptions.AllowLocalFileAccess = true
+	executerOpts.Options = &allowedOptions
+	request = &Request{ID: templateID, Steps: []*engine.Action{
+		{ActionType: "uploadfile", Data: map[string]string{"selector": "input[type=file]", "file": outside}},
+	}}
+	err = request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile headless request with allowed local file access")
+	executerOpts.Options = options
+
+	request = &Request{ID: templateID, Steps: []*engine.Action{
+		{ActionType: "navigate", Data: map[string]string{"url": "{{BaseURL}}"}},
+		{ActionType: "hovr", Data: map[string]string{"selector": "#menu"}},
+	}}
+	err = request.Compile(executerOpts)
+	require.NotNil(t, err, "could compile headless request with unknown action")
+	require.Contains(t, err.Error(), "step 2", "could not get invalid step")
+}
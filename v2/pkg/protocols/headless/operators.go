@@ -10,7 +10,7 @@ import (
 )
 
 // Match matches a generic data response again a given matcher
-func (r *Request) Match(data map[string]interface{}, matcher *matchers.Matcher) bool {
+func (r *Request) Match(data map[string]interface{}, matcher *matchers.Matcher) (bool, []string) {
 	partString := matcher.Part
 	switch partString {
 	case "body", "resp", "":
@@ -19,23 +19,23 @@ func (r *Request) Match(data map[string]interface{}, matcher *matchers.Matcher)
 
 	item, ok := data[partString]
 	if !ok {
-		return false
+		return false, nil
 	}
 	itemStr := types.ToString(item)
 
 	switch matcher.GetType() {
 	case matchers.SizeMatcher:
-		return matcher.Result(matcher.MatchSize(len(itemStr)))
+		return matcher.Result(matcher.MatchSize(len(itemStr))), nil
 	case matchers.WordsMatcher:
-		return matcher.Result(matcher.MatchWords(itemStr))
+		return matcher.ResultWithMatchedSnippet(matcher.MatchWords(itemStr))
 	case matchers.RegexMatcher:
-		return matcher.Result(matcher.MatchRegex(itemStr))
+		return matcher.ResultWithMatchedSnippet(matcher.MatchRegex(itemStr))
 	case matchers.BinaryMatcher:
-		return matcher.Result(matcher.MatchBinary(itemStr))
+		return matcher.ResultWithMatchedSnippet(matcher.MatchBinary(itemStr))
 	case matchers.DSLMatcher:
-		return matcher.Result(matcher.MatchDSL(data))
+		return matcher.Result(matcher.MatchDSL(data)), nil
 	}
-	return false
+	return false, nil
 }
 
 // Extract performs extracting operation for a extractor on model and returns true or false.
@@ -57,6 +57,10 @@ func (r *Request) Extract(data map[string]interface{}, extractor *extractors.Ext
 		return extractor.ExtractRegex(itemStr)
 	case extractors.KValExtractor:
 		return extractor.ExtractKval(data)
+	case extractors.XPathExtractor:
+		return extractor.ExtractXPath(itemStr, false)
+	case extractors.JSONExtractor:
+		return extractor.ExtractJSON(itemStr)
 	}
 	return nil
 }
@@ -113,12 +117,19 @@ func (r *Request) makeResultEventItem(wrapped *output.InternalWrappedEvent) *out
 		Host:             types.ToString(wrapped.InternalEvent["host"]),
 		Matched:          types.ToString(wrapped.InternalEvent["matched"]),
 		ExtractedResults: wrapped.OperatorsResult.OutputExtracts,
+		MatchedSnippets:  wrapped.OperatorsResult.MatchedSnippets,
+		MatchedWords:     wrapped.OperatorsResult.MatchedWords,
+		MatcherNames:     wrapped.OperatorsResult.AndMatches,
+		ExtractedNamed:   wrapped.OperatorsResult.Extracts,
 		Timestamp:        time.Now(),
 		IP:               types.ToString(wrapped.InternalEvent["ip"]),
 	}
+	if screenshots, ok := wrapped.InternalEvent["screenshots"].([]string); ok {
+		data.Screenshots = screenshots
+	}
 	if r.options.Options.JSONRequests {
-		data.Request = types.ToString(wrapped.InternalEvent["request"])
-		data.Response = types.ToString(wrapped.InternalEvent["data"])
+		data.Request = output.TruncateRR(types.ToString(wrapped.InternalEvent["request"]), r.options.Options.JSONRequestsMaxSize)
+		data.Response = output.TruncateRR(types.ToString(wrapped.InternalEvent["data"]), r.options.Options.JSONRequestsMaxSize)
 	}
 	return data
 }
@@ -1,27 +1,36 @@
 package headless
 
 import (
+	"context"
 	"net/url"
 	"strings"
 	"time"
 
+	"github.com/go-rod/rod/lib/proto"
 	"github.com/pkg/errors"
 	"github.com/projectdiscovery/gologger"
+	"github.com/yaklang/nuclei/v2/pkg/operators"
 	"github.com/yaklang/nuclei/v2/pkg/output"
 	"github.com/yaklang/nuclei/v2/pkg/protocols"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/headless/engine"
 )
 
 var _ protocols.Request = &Request{}
 
 // ExecuteWithResults executes the protocol requests and returns results instead of writing them.
 func (r *Request) ExecuteWithResults(input string, metadata, previous output.InternalEvent, callback protocols.OutputEventCallback) error {
-	instance, err := r.options.Browser.NewInstance()
+	ctx := r.options.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	instance, err := r.options.Browser.AcquireInstance(ctx)
 	if err != nil {
 		r.options.Output.Request(r.options.TemplateID, input, "headless", err)
 		r.options.Progress.IncrementFailedRequestsBy(1)
 		return errors.Wrap(err, "could get html element")
 	}
 	defer instance.Close()
+	instance.SetTemplateID(r.options.TemplateID)
 
 	parsed, err := url.Parse(input)
 	if err != nil {
@@ -29,7 +38,9 @@ func (r *Request) ExecuteWithResults(input string, metadata, previous output.Int
 		r.options.Progress.IncrementFailedRequestsBy(1)
 		return errors.Wrap(err, "could get html element")
 	}
+	timeStart := time.Now()
 	out, page, err := instance.Run(parsed, r.Steps, time.Duration(r.options.Options.PageTimeout)*time.Second)
+	r.options.Progress.RecordLatency(parsed.Host, time.Since(timeStart))
 	if err != nil {
 		r.options.Output.Request(r.options.TemplateID, input, "headless", err)
 		r.options.Progress.IncrementFailedRequestsBy(1)
@@ -57,10 +68,23 @@ func (r *Request) ExecuteWithResults(input string, metadata, previous output.Int
 	if err == nil {
 		respBody, _ = html.HTML()
 	}
+	session := r.sessionValues(page, input)
 	outputEvent := r.responseToDSLMap(respBody, reqBuilder.String(), input, input)
+	for k, v := range session {
+		outputEvent[k] = v
+	}
 	for k, v := range out {
 		outputEvent[k] = v
 	}
+	for k, v := range page.Intercepted() {
+		outputEvent[k] = v
+	}
+	for k, v := range page.Console() {
+		outputEvent[k] = v
+	}
+	if screenshots := page.Screenshots(); len(screenshots) > 0 {
+		outputEvent["screenshots"] = screenshots
+	}
 
 	if r.options.Options.Debug || r.options.Options.DebugResponse {
 		gologger.Debug().Msgf("[%s] Dumped Headless response for %s", r.options.TemplateID, input)
@@ -75,6 +99,52 @@ func (r *Request) ExecuteWithResults(input string, metadata, previous output.Int
 			event.Results = r.MakeResultEvent(event)
 		}
 	}
+	// The session values are published once the results are made, so that
+	// the next requests of the template can use them.
+	if len(session) > 0 {
+		if event.OperatorsResult == nil {
+			event.OperatorsResult = &operators.Result{DynamicValues: make(map[string]interface{})}
+		}
+		for k, v := range session {
+			event.OperatorsResult.DynamicValues[k] = v
+		}
+	}
 	callback(event)
 	return nil
 }
+
+// sessionValues returns the cookies of the page for the input as a cookie
+// header in headless_cookies, and its local storage if exported.
+func (r *Request) sessionValues(page *engine.Page, input string) map[string]interface{} {
+	values := make(map[string]interface{})
+	cookies, err := page.Page().Cookies([]string{input})
+	if err != nil {
+		gologger.Warning().Msgf("[%s] Could not get cookies of %s: %s\n", r.options.TemplateID, input, err)
+	} else if len(cookies) > 0 {
+		values["headless_cookies"] = cookieHeader(cookies)
+	}
+	if !r.ExportLocalStorage {
+		return values
+	}
+	storage, err := page.Page().Eval("JSON.stringify(Object.assign({}, window.localStorage))")
+	if err != nil {
+		gologger.Warning().Msgf("[%s] Could not get local storage of %s: %s\n", r.options.TemplateID, input, err)
+	} else {
+		values["headless_local_storage"] = storage.Value.String()
+	}
+	return values
+}
+
+// cookieHeader returns the value of a cookie header for cookies
+func cookieHeader(cookies []*proto.NetworkCookie) string {
+	builder := &strings.Builder{}
+	for i, cookie := range cookies {
+		if i > 0 {
+			builder.WriteString("; ")
+		}
+		builder.WriteString(cookie.Name)
+		builder.WriteString("=")
+		builder.WriteString(cookie.Value)
+	}
+	return builder.String()
+}
@@ -0,0 +1,15 @@
+package headless
+
+import (
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCookieHeader(t *testing.T) {
+	require.Equal(t, "", cookieHeader(nil), "could not get empty cookie header")
+
+	cookies := []*proto.NetworkCookie{{Name: "session", Value: "abc123"}, {Name: "lang", Value: "en"}}
+	require.Equal(t, "session=abc123; lang=en", cookieHeader(cookies), "could not get cookie header")
+}
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/replacer"
+)
+
+const (
+	// NTLMAuth is the auth type for ntlm authentication
+	NTLMAuth = "ntlm"
+	// DigestAuth is the auth type for http digest authentication
+	DigestAuth = "digest"
+)
+
+// Auth contains the authentication configuration for a http request
+type Auth struct {
+	// Type is the type of the authentication, ntlm or digest.
+	Type string `yaml:"type"`
+	// Username is the username to authenticate with
+	Username string `yaml:"username"`
+	// Password is the password to authenticate with
+	Password string `yaml:"password"`
+	// Domain is the optional domain of the user for ntlm
+	Domain string `yaml:"domain"`
+}
+
+// validate validates the authentication configuration of the request
+func (a *Auth) validate(request *Request) error {
+	if !strings.EqualFold(a.Type, NTLMAuth) && !strings.EqualFold(a.Type, DigestAuth) {
+		return errors.Errorf("invalid auth type %s", a.Type)
+	}
+	if request.Unsafe || request.Pipeline || request.Race {
+		return errors.Errorf("%s auth is not supported with unsafe, pipeline or race requests", strings.ToLower(a.Type))
+	}
+	return nil
+}
+
+// isNTLM returns true if the authentication is ntlm
+func (a *Auth) isNTLM() bool {
+	return a != nil && strings.EqualFold(a.Type, NTLMAuth)
+}
+
+// resolve returns the credentials with the values substituted
+func (a *Auth) resolve(values map[string]interface{}) *Auth {
+	return &Auth{
+		Type:     a.Type,
+		Username: replacer.Replace(a.Username, values),
+		Password: replacer.Replace(a.Password, values),
+		Domain:   replacer.Replace(a.Domain, values),
+	}
+}
+
+// doWithAuth sends a request performing the authentication handshake
+// of its auth type if the server asks for it.
+func (r *Request) doWithAuth(req *generatedRequest) (*http.Response, error) {
+	if req.auth.isNTLM() {
+		return r.doWithNTLM(req)
+	}
+	return r.doWithDigest(req)
+}
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"bytes"
 	"context"
 	"io"
 	"io/ioutil"
@@ -15,10 +16,11 @@ import (
 	"github.com/pkg/errors"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/common/expressions"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/common/generators"
-	"github.com/yaklang/nuclei/v2/pkg/protocols/common/replacer"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/http/httpclientpool"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/http/race"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/http/raw"
 	"github.com/projectdiscovery/rawhttp"
+	"github.com/projectdiscovery/rawhttp/client"
 	"github.com/projectdiscovery/retryablehttp-go"
 )
 
@@ -31,8 +33,13 @@ type generatedRequest struct {
 	original        *Request
 	rawRequest      *raw.Request
 	meta            map[string]interface{}
+	randomValues    map[string]interface{}
 	pipelinedClient *rawhttp.PipelineClient
 	request         *retryablehttp.Request
+	client          *retryablehttp.Client
+	proxy           *url.URL
+	auth            *Auth
+	annotations     raw.Annotations
 }
 
 // Make creates a http request for the provided input.
@@ -43,39 +50,124 @@ func (r *requestGenerator) Make(baseURL string, dynamicValues map[string]interfa
 	if !ok {
 		return nil, io.EOF
 	}
+	return r.makeRequest(baseURL, data, payloads, dynamicValues, interactURL)
+}
+
+// makeRequest creates a http request for a path or raw request of the generator
+func (r *requestGenerator) makeRequest(baseURL, data string, payloads, dynamicValues map[string]interface{}, interactURL string) (*generatedRequest, error) {
 	ctx := context.Background()
 
-	parsed, err := url.Parse(baseURL)
+	var values map[string]interface{}
+	var baseURLString string
+	// Self-contained requests use absolute urls without a base url
+	if r.request.SelfContained {
+		values = generators.MergeMaps(dynamicValues, nil)
+	} else {
+		parsed, err := url.Parse(baseURL)
+		if err != nil {
+			return nil, err
+		}
+
+		data, parsed = baseURLWithTemplatePrefs(data, parsed)
+		values = generators.MergeMaps(dynamicValues, map[string]interface{}{
+			"Hostname": parsed.Host,
+		})
+
+		if len(r.request.Raw) == 0 && strings.HasSuffix(parsed.Path, "/") && strings.Contains(data, "{{BaseURL}}/") {
+			parsed.Path = strings.TrimSuffix(parsed.Path, "/")
+		}
+		baseURLString = parsed.String()
+		values["BaseURL"] = baseURLString
+	}
+
+	// Random values are generated for each request and kept in the
+	// request metadata so the matchers can reference the sent values.
+	randomValues := r.randomValues(data)
+	values = generators.MergeMaps(values, randomValues)
+
+	// If data contains \n it's a raw request, process it like raw. Else
+	// continue with the template based request flow.
+	var request *generatedRequest
+	var err error
+	if len(r.request.Raw) > 0 {
+		request, err = r.makeHTTPRequestFromRaw(ctx, baseURLString, data, values, payloads, interactURL)
+	} else {
+		request, err = r.makeHTTPRequestFromModel(ctx, data, values, interactURL)
+	}
 	if err != nil {
 		return nil, err
 	}
+	if len(randomValues) > 0 {
+		request.meta = generators.MergeMaps(request.meta, randomValues)
+		request.randomValues = randomValues
+	}
+	request.client = r.client
+	if request.client == nil {
+		request.client = r.request.httpClient
+	}
+	if request.request != nil {
+		request.proxy = r.request.proxy
+		if request.proxy == nil {
+			request.proxy = httpclientpool.NextProxy()
+		}
+		if request.proxy != nil {
+			request.request = request.request.WithContext(httpclientpool.WithProxy(request.request.Context(), request.proxy))
+		}
+		if err := request.applyAnnotations(); err != nil {
+			return nil, err
+		}
+	}
+	return request, nil
+}
 
-	data, parsed = baseURLWithTemplatePrefs(data, parsed)
-	values := generators.MergeMaps(dynamicValues, map[string]interface{}{
-		"Hostname": parsed.Host,
-	})
+// applyAnnotations uses a client sending the tls server name and
+// applying the timeout of the annotations of the request.
+func (g *generatedRequest) applyAnnotations() error {
+	if g.annotations.SNI != "" {
+		client, err := httpclientpool.WithServerName(g.client, g.annotations.SNI)
+		if err != nil {
+			return err
+		}
+		g.client = client
+	}
+	if g.annotations.Timeout > 0 {
+		g.client = httpclientpool.WithTimeout(g.client, g.annotations.Timeout)
+	}
+	return nil
+}
 
-	isRawRequest := len(r.request.Raw) > 0
-	if !isRawRequest && strings.HasSuffix(parsed.Path, "/") && strings.Contains(data, "{{BaseURL}}/") {
-		parsed.Path = strings.TrimSuffix(parsed.Path, "/")
+// randomValues returns the random values for the markers and helpers of a request
+func (r *requestGenerator) randomValues(data string) map[string]interface{} {
+	parts := []string{data, r.request.Body}
+	for _, value := range r.request.Headers {
+		parts = append(parts, value)
 	}
-	parsedString := parsed.String()
-	values["BaseURL"] = parsedString
+	return expressions.RandomValues(parts...)
+}
 
-	// If data contains \n it's a raw request, process it like raw. Else
-	// continue with the template based request flow.
-	if isRawRequest {
-		return r.makeHTTPRequestFromRaw(ctx, parsedString, data, values, payloads, interactURL)
+// targetURL returns the scheme and host the request is sent to
+func (g *generatedRequest) targetURL() string {
+	target := &url.URL{}
+	if g.request != nil {
+		target = g.request.URL
+	} else if g.rawRequest != nil {
+		if parsed, err := url.Parse(g.rawRequest.FullURL); err == nil {
+			target = parsed
+		}
 	}
-	return r.makeHTTPRequestFromModel(ctx, data, values, interactURL)
+	return target.Scheme + "://" + target.Host
 }
 
-// Total returns the total number of requests for the generator
-func (r *requestGenerator) Total() int {
+// Remaining returns the number of requests left for the generator
+func (r *requestGenerator) Remaining() int {
 	if r.payloadIterator != nil {
-		return len(r.request.Raw) * r.payloadIterator.Remaining()
+		if r.currentIndex >= len(r.request.Raw) {
+			return 0
+		}
+		// the iterator is reset for each of the next raw requests
+		return (len(r.request.Raw)-r.currentIndex-1)*r.payloadIterator.Total() + r.payloadIterator.Remaining()
 	}
-	return len(r.request.Path)
+	return len(r.request.Path) + len(r.request.Raw) - r.currentIndex
 }
 
 // baseURLWithTemplatePrefs returns the url for BaseURL keeping
@@ -97,7 +189,10 @@ func baseURLWithTemplatePrefs(data string, parsed *url.URL) (string, *url.URL) {
 
 // MakeHTTPRequestFromModel creates a *http.Request from a request template
 func (r *requestGenerator) makeHTTPRequestFromModel(ctx context.Context, data string, values map[string]interface{}, interactURL string) (*generatedRequest, error) {
-	final := replacer.Replace(data, values)
+	final, err := expressions.Evaluate(data, values)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not evaluate helper expressions")
+	}
 	if interactURL != "" {
 		final = r.options.Interactsh.ReplaceMarkers(final, interactURL)
 	}
@@ -112,7 +207,11 @@ func (r *requestGenerator) makeHTTPRequestFromModel(ctx context.Context, data st
 	if err != nil {
 		return nil, err
 	}
-	return &generatedRequest{request: request, original: r.request}, nil
+	generated := &generatedRequest{request: request, original: r.request}
+	if r.request.Auth != nil {
+		generated.auth = r.request.Auth.resolve(values)
+	}
+	return generated, nil
 }
 
 // makeHTTPRequestFromRaw creates a *http.Request from a raw request
@@ -142,7 +241,10 @@ func (r *requestGenerator) handleRawWithPayloads(ctx context.Context, rawRequest
 
 	// Unsafe option uses rawhttp library
 	if r.request.Unsafe {
-		unsafeReq := &generatedRequest{rawRequest: rawRequestData, meta: generatorValues, original: r.request}
+		if err := r.addUnsafeCustomHeaders(rawRequestData, finalValues); err != nil {
+			return nil, err
+		}
+		unsafeReq := &generatedRequest{rawRequest: rawRequestData, meta: generatorValues, original: r.request, annotations: rawRequestData.Annotations}
 		return unsafeReq, nil
 	}
 
@@ -150,9 +252,7 @@ func (r *requestGenerator) handleRawWithPayloads(ctx context.Context, rawRequest
 	var body io.ReadCloser
 	body = ioutil.NopCloser(strings.NewReader(rawRequestData.Data))
 	if r.request.Race {
-		// More or less this ensures that all requests hit the endpoint at the same approximated time
-		// Todo: sync internally upon writing latest request byte
-		body = race.NewOpenGateWithTimeout(body, time.Duration(2)*time.Second)
+		body = r.raceBody(body)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, rawRequestData.Method, rawRequestData.FullURL, body)
@@ -168,12 +268,81 @@ func (r *requestGenerator) handleRawWithPayloads(ctx context.Context, rawRequest
 			req.Host = value
 		}
 	}
+	if r.request.HTTP2 {
+		if err := applyPseudoHeaders(req, rawRequestData.PseudoHeaders); err != nil {
+			return nil, err
+		}
+	}
 	request, err := r.fillRequest(req, values, "")
 	if err != nil {
 		return nil, err
 	}
+	generated := &generatedRequest{request: request, meta: generatorValues, original: r.request, annotations: rawRequestData.Annotations}
+	if r.request.Auth != nil {
+		generated.auth = r.request.Auth.resolve(finalValues)
+	}
+	return generated, nil
+}
 
-	return &generatedRequest{request: request, meta: generatorValues, original: r.request}, nil
+// addUnsafeCustomHeaders adds the global custom headers not set by the
+// template to an unsafe request, right after its request line.
+func (r *requestGenerator) addUnsafeCustomHeaders(request *raw.Request, values map[string]interface{}) error {
+	lineEnd := bytes.IndexByte(request.UnsafeRawBytes, '\n')
+	if lineEnd == -1 {
+		return nil
+	}
+	headers := &bytes.Buffer{}
+	var added client.Headers
+	for header, value := range r.request.customHeaders {
+		var found bool
+		for _, line := range request.UnsafeHeaders {
+			if key := strings.SplitN(line.Key, ":", 2)[0]; strings.EqualFold(strings.TrimSpace(key), header) {
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		value, err := expressions.Evaluate(value, values)
+		if err != nil {
+			return errors.Wrap(err, "could not evaluate helper expressions")
+		}
+		headers.WriteString(header + ": " + value + "\r\n")
+		added = append(added, client.Header{Key: header + ": " + value})
+	}
+	if headers.Len() == 0 {
+		return nil
+	}
+	request.UnsafeHeaders = append(added, request.UnsafeHeaders...)
+	rawBytes := make([]byte, 0, len(request.UnsafeRawBytes)+headers.Len())
+	rawBytes = append(rawBytes, request.UnsafeRawBytes[:lineEnd+1]...)
+	rawBytes = append(rawBytes, headers.Bytes()...)
+	request.UnsafeRawBytes = append(rawBytes, request.UnsafeRawBytes[lineEnd+1:]...)
+	return nil
+}
+
+// applyPseudoHeaders applies the HTTP/2 pseudo-headers of a raw request
+func applyPseudoHeaders(req *http.Request, pseudoHeaders map[string]string) error {
+	for name, value := range pseudoHeaders {
+		switch name {
+		case "method":
+			req.Method = value
+		case "scheme":
+			req.URL.Scheme = value
+		case "authority":
+			req.Host = value
+		case "path":
+			parsed, err := url.ParseRequestURI(value)
+			if err != nil {
+				return errors.Wrap(err, "could not parse :path pseudo-header")
+			}
+			req.URL.Path, req.URL.RawPath, req.URL.RawQuery = parsed.Path, parsed.RawPath, parsed.RawQuery
+		default:
+			return errors.Errorf("unknown pseudo-header :%s", name)
+		}
+	}
+	return nil
 }
 
 // fillRequest fills various headers in the request with values
@@ -183,35 +352,96 @@ func (r *requestGenerator) fillRequest(req *http.Request, values map[string]inte
 		if interactURL != "" {
 			value = r.options.Interactsh.ReplaceMarkers(value, interactURL)
 		}
-		req.Header[header] = []string{replacer.Replace(value, values)}
+		value, err := expressions.Evaluate(value, values)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not evaluate helper expressions")
+		}
+		req.Header[header] = []string{value}
 		if header == "Host" {
-			req.Host = replacer.Replace(value, values)
+			req.Host = value
 		}
 	}
 
-	// In case of multiple threads the underlying connection should remain open to allow reuse
-	if r.request.Threads <= 0 && req.Header.Get("Connection") == "" {
+	// Global custom headers are only added if the template didn't set them
+	for header, value := range r.request.customHeaders {
+		if hasHeader(req.Header, header) {
+			continue
+		}
+		value, err := expressions.Evaluate(value, values)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not evaluate helper expressions")
+		}
+		setHeader(req, header, value)
+	}
+
+	// In case of multiple threads, connection based authentication or http2
+	// the underlying connection should remain open to allow reuse
+	if r.request.Threads <= 0 && r.request.Auth == nil && !r.request.HTTP2 && req.Header.Get("Connection") == "" {
 		req.Close = true
 	}
 
 	// Check if the user requested a request body
 	if r.request.Body != "" {
-		body := r.request.Body
+		body, err := expressions.Evaluate(r.request.Body, values)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not evaluate helper expressions")
+		}
 		if interactURL != "" {
 			body = r.options.Interactsh.ReplaceMarkers(body, interactURL)
 		}
 		req.Body = ioutil.NopCloser(strings.NewReader(body))
+		if r.request.Race {
+			req.Body = r.raceBody(req.Body)
+		}
+	}
+	if !r.options.Options.NoRandomAgent && !hasHeader(req.Header, "User-Agent") {
+		req.Header.Set("User-Agent", uarand.GetRandom())
 	}
-	setHeader(req, "User-Agent", uarand.GetRandom())
 
 	// Only set these headers on non raw requests
 	if len(r.request.Raw) == 0 {
 		setHeader(req, "Accept", "*/*")
 		setHeader(req, "Accept-Language", "en")
 	}
+
+	// The signature is computed last, once the request is complete
+	if r.request.awsCredentials != nil {
+		if err := signAWS(req, r.request.awsCredentials, time.Now()); err != nil {
+			return nil, errors.Wrap(err, "could not sign request")
+		}
+	}
 	return retryablehttp.FromRequest(req)
 }
 
+// raceBody gates the body of a race request so the last bytes of the
+// race requests are written at the same time.
+func (r *requestGenerator) raceBody(body io.ReadCloser) io.ReadCloser {
+	if r.raceGate != nil {
+		return race.NewSyncedReadCloserWithGate(body, r.raceGate)
+	}
+	return race.NewOpenGateWithTimeout(body, raceGateDelay)
+}
+
+// hasHeader returns true if a header is set, ignoring the case of its name
+func hasHeader(header http.Header, name string) bool {
+	for key := range header {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasCustomHeader returns true if a custom header is set, ignoring the case of its name
+func hasCustomHeader(headers map[string]string, name string) bool {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
+
 // setHeader sets some headers only if the header wasn't supplied by the user
 func setHeader(req *http.Request, name, value string) {
 	if _, ok := req.Header[name]; !ok {
@@ -2,6 +2,7 @@ package http
 
 import (
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/yaklang/nuclei/v2/internal/testutils"
@@ -156,3 +157,56 @@ Accept-Encoding: gzip`},
 	authorization = req.request.Header.Get("Authorization")
 	require.Equal(t, "Basic YWRtaW46Z3Vlc3Q=", authorization, "could not get correct authorization headers from raw")
 }
+
+func TestMakeRequestUserAgent(t *testing.T) {
+	options := *testutils.DefaultOptions
+	options.CustomUserAgent = "scanner/{{tolower('X')}} ({{Hostname}})"
+	options.NoRandomAgent = true
+
+	testutils.Init(&options)
+	templateID := "testing-http-user-agent"
+
+	userAgent := func(request *Request) string {
+		executerOpts := testutils.NewMockExecuterOptions(&options, &testutils.TemplateInfo{
+			ID:   templateID,
+			Info: map[string]interface{}{"severity": "low", "name": "test"},
+		})
+		err := request.Compile(executerOpts)
+		require.Nil(t, err, "could not compile http request")
+
+		generator := request.newGenerator()
+		req, err := generator.Make("https://example.com", map[string]interface{}{}, "")
+		require.Nil(t, err, "could not make http request")
+
+		var agents []string
+		for name, values := range req.request.Header {
+			if strings.EqualFold(name, "User-Agent") {
+				agents = append(agents, values...)
+			}
+		}
+		require.LessOrEqual(t, len(agents), 1, "could not set a single user agent")
+		return strings.Join(agents, "")
+	}
+
+	t.Run("model", func(t *testing.T) {
+		agent := userAgent(&Request{ID: templateID, Path: []string{"{{BaseURL}}"}, Method: "GET"})
+		require.Equal(t, "scanner/x (example.com)", agent, "could not set custom user agent")
+
+		agent = userAgent(&Request{ID: templateID, Path: []string{"{{BaseURL}}"}, Method: "GET", Headers: map[string]string{"User-Agent": "template"}})
+		require.Equal(t, "template", agent, "could not prefer template user agent")
+	})
+
+	t.Run("raw", func(t *testing.T) {
+		agent := userAgent(&Request{ID: templateID, Raw: []string{"GET / HTTP/1.1\r\nHost: {{Hostname}}\r\n\r\n"}})
+		require.Equal(t, "scanner/x (example.com)", agent, "could not set custom user agent")
+
+		agent = userAgent(&Request{ID: templateID, Raw: []string{"GET / HTTP/1.1\r\nHost: {{Hostname}}\r\nuser-agent: template\r\n\r\n"}})
+		require.Equal(t, "template", agent, "could not prefer template user agent")
+	})
+
+	t.Run("no-random-agent", func(t *testing.T) {
+		options.CustomUserAgent = ""
+		agent := userAgent(&Request{ID: templateID, Path: []string{"{{BaseURL}}"}, Method: "GET"})
+		require.Empty(t, agent, "set random user agent")
+	})
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	rawclient "github.com/projectdiscovery/rawhttp/client"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/clientcert"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/protocolstate"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/tlsconfig"
+	"github.com/yaklang/nuclei/v2/pkg/types"
+)
+
+// clientCertificate returns the client certificate to present for the
+// request, the template override taking precedence over the user one.
+func (r *Request) clientCertificate() *clientcert.Certificate {
+	if r.certificate != nil {
+		return r.certificate
+	}
+	return protocolstate.ClientCertificate
+}
+
+// doUnsafeWithTLS sends an unsafe request over a tls connection presenting
+// the client certificate, applying the verification settings and sending the
+// server name of the annotations, as rawhttp connections can't be configured.
+// Redirects are not followed for such requests.
+func (r *Request) doUnsafeWithTLS(request *generatedRequest, certificate *clientcert.Certificate) (*http.Response, error) {
+	parsed, err := url.Parse(request.rawRequest.FullURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse request url")
+	}
+	address := parsed.Host
+	if parsed.Port() == "" {
+		address = net.JoinHostPort(parsed.Hostname(), "443")
+	}
+
+	timeout := r.options.Options.ProtocolTimeout(types.HTTPProtocol)
+	if request.annotations.Timeout > 0 {
+		timeout = request.annotations.Timeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	conn, err := tlsconfig.DialTLSWithServerName(ctx, protocolstate.Dialer, "tcp", address, request.annotations.SNI, certificate, r.verification)
+	if err != nil {
+		return nil, err
+	}
+
+	client := rawclient.NewClient(conn)
+	if err := client.WriteRequest(&rawclient.Request{RawBytes: request.rawRequest.UnsafeRawBytes}); err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "could not write request")
+	}
+	resp, err := client.ReadResponse()
+	if err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "could not read response")
+	}
+
+	header := make(http.Header)
+	for _, h := range resp.Headers {
+		header.Add(h.Key, strings.TrimSpace(h.Value))
+	}
+	return &http.Response{
+		Status:        resp.Status.String(),
+		StatusCode:    resp.Status.Code,
+		Proto:         resp.Version.String(),
+		ProtoMajor:    resp.Version.Major,
+		ProtoMinor:    resp.Version.Minor,
+		Header:        header,
+		ContentLength: resp.ContentLength(),
+		Body:          &connBody{Reader: resp.Body, conn: conn},
+	}, nil
+}
+
+// connBody is a response body closing its connection
+type connBody struct {
+	io.Reader
+	conn net.Conn
+}
+
+// Close closes the connection of the body
+func (c *connBody) Close() error {
+	return c.conn.Close()
+}
@@ -10,13 +10,20 @@ import (
 // are similar enough to be considered one and can be checked by
 // just adding the matcher/extractors for the request and the correct IDs.
 func (r *Request) CanCluster(other *Request) bool {
-	if len(r.Payloads) > 0 || len(r.Raw) > 0 || len(r.Body) > 0 || r.Unsafe || r.ReqCondition || r.Name != "" {
+	if len(r.Payloads) > 0 || len(r.Raw) > 0 || len(r.Body) > 0 || r.Unsafe || r.ReqCondition || r.Name != "" || r.Auth != nil || other.Auth != nil || r.ClientCertificate != nil || other.ClientCertificate != nil || r.Signature != "" || other.Signature != "" || r.StopAtFirstMatch || r.Baseline || other.Baseline || r.SelfContained || other.SelfContained {
 		return false
 	}
 	if r.Method != other.Method ||
 		r.MaxRedirects != other.MaxRedirects ||
 		r.CookieReuse != other.CookieReuse ||
-		r.Redirects != other.Redirects {
+		r.Redirects != other.Redirects ||
+		r.HTTP2 != other.HTTP2 ||
+		r.TLSSkipVerify != other.TLSSkipVerify ||
+		r.caFile() != other.caFile() ||
+		r.Proxy != other.Proxy ||
+		r.Retries != other.Retries ||
+		r.RetryDelay != other.RetryDelay ||
+		r.StopAtFirstMatch != other.StopAtFirstMatch {
 		return false
 	}
 	if !compare.StringSlice(r.Path, other.Path) {
@@ -25,5 +32,23 @@ func (r *Request) CanCluster(other *Request) bool {
 	if !compare.StringMap(r.Headers, other.Headers) {
 		return false
 	}
+	if !compare.StringSlice(r.DecodeBody, other.DecodeBody) {
+		return false
+	}
 	return true
 }
+
+// caFile returns the CA file resolved at compile time, falling back to
+// the raw value for requests which were not compiled.
+func (r *Request) caFile() string {
+	if r.verification != nil {
+		return r.verification.CAFile()
+	}
+	return r.TLSCAFile
+}
+
+// SetClustered marks the request as shared by a cluster of templates.
+// Stopping at the first match is then handled per template by the cluster.
+func (r *Request) SetClustered() {
+	r.clustered = true
+}
@@ -1,9 +1,16 @@
 package http
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/yaklang/nuclei/v2/internal/testutils"
+	"github.com/yaklang/nuclei/v2/internal/testutils/certificates"
+	"github.com/yaklang/nuclei/v2/pkg/operators"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/clientcert"
 )
 
 func TestCanCluster(t *testing.T) {
@@ -12,4 +19,42 @@ func TestCanCluster(t *testing.T) {
 
 	req = &Request{Path: []string{"{{BaseURL}}"}, Method: "GET"}
 	require.True(t, req.CanCluster(&Request{Path: []string{"{{BaseURL}}"}, Method: "GET"}), "could not cluster GET request")
+	require.False(t, req.CanCluster(&Request{Path: []string{"{{BaseURL}}"}, Method: "GET", HTTP2: true}), "could cluster http2 request with http1 request")
+	require.False(t, req.CanCluster(&Request{Path: []string{"{{BaseURL}}"}, Method: "GET", Redirects: true, MaxRedirects: 2}), "could cluster requests with different redirects")
+	require.False(t, req.CanCluster(&Request{Path: []string{"{{BaseURL}}"}, Method: "GET", Operators: operators.Operators{StopAtFirstMatch: true}}), "could cluster request stopping at first match")
+	require.False(t, req.CanCluster(&Request{Path: []string{"{{BaseURL}}"}, Method: "GET", SelfContained: true}), "could cluster self-contained request")
+	require.False(t, req.CanCluster(&Request{Path: []string{"{{BaseURL}}"}, Method: "GET", TLSSkipVerify: true}), "could cluster requests with different tls settings")
+	require.False(t, req.CanCluster(&Request{Path: []string{"{{BaseURL}}"}, Method: "GET", Auth: &Auth{}}), "could cluster request with auth")
+	require.False(t, req.CanCluster(&Request{Path: []string{"{{BaseURL}}"}, Method: "GET", ClientCertificate: &clientcert.Files{}}), "could cluster request with client certificate")
+	require.False(t, req.CanCluster(&Request{Path: []string{"{{BaseURL}}"}, Method: "GET", Signature: "AWS"}), "could cluster signed request")
+	require.False(t, req.CanCluster(&Request{Path: []string{"{{BaseURL}}"}, Method: "GET", Baseline: true}), "could cluster baseline request")
+}
+
+func TestCanClusterTLSCAFile(t *testing.T) {
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	dir, err := ioutil.TempDir("", "nuclei-cluster-ca-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(dir)
+	otherDir := filepath.Join(dir, "other")
+	require.Nil(t, os.Mkdir(otherDir, 0755), "could not create directory")
+	for _, directory := range []string{dir, otherDir} {
+		_, err := certificates.Generate(directory)
+		require.Nil(t, err, "could not generate certificates")
+	}
+
+	compile := func(templatePath string) *Request {
+		executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+			ID:   "testing-cluster-ca",
+			Info: map[string]interface{}{"severity": "low", "name": "test"},
+			Path: templatePath,
+		})
+		request := &Request{ID: "testing-cluster-ca", Path: []string{"{{BaseURL}}"}, Method: "GET", TLSCAFile: "ca.pem"}
+		require.Nil(t, request.Compile(executerOpts), "could not compile http request")
+		return request
+	}
+	req := compile(filepath.Join(dir, "first.yaml"))
+	require.True(t, req.CanCluster(compile(filepath.Join(dir, "second.yaml"))), "could not cluster requests with the same ca file")
+	require.False(t, req.CanCluster(compile(filepath.Join(otherDir, "second.yaml"))), "could cluster requests with different ca files")
 }
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"sort"
+	"strings"
+)
+
+// curlCommand returns a curl command reproducing a generated request.
+// Unsafe requests can't be reproduced exactly, so their parts are passed
+// as is with the path and the body kept verbatim.
+func curlCommand(request *generatedRequest) string {
+	builder := &strings.Builder{}
+	builder.WriteString("curl -k")
+
+	if request.request != nil {
+		req := request.request
+		if request.original.HTTP2 {
+			builder.WriteString(" --http2")
+		}
+		builder.WriteString(" -X " + shellQuote(req.Method))
+
+		names := make([]string, 0, len(req.Header))
+		for name := range req.Header {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if req.Host != "" && req.Host != req.URL.Host {
+			builder.WriteString(" -H " + shellQuote("Host: "+req.Host))
+		}
+		for _, name := range names {
+			for _, value := range req.Header[name] {
+				builder.WriteString(" -H " + shellQuote(name+": "+value))
+			}
+		}
+		if body, err := req.BodyBytes(); err == nil && len(body) > 0 {
+			builder.WriteString(" --data-binary " + shellQuote(string(body)))
+		}
+		builder.WriteString(" " + shellQuote(req.URL.String()))
+		return builder.String()
+	}
+
+	raw := request.rawRequest
+	builder.WriteString(" --path-as-is -X " + shellQuote(raw.Method))
+	if len(raw.UnsafeRawBytes) > 0 {
+		// unsafe requests are sent verbatim, so the headers and the body
+		// are taken from the bytes on the wire in their original order
+		headers, body := splitUnsafeRequest(raw.UnsafeRawBytes)
+		for _, header := range headers {
+			builder.WriteString(" -H " + shellQuote(header))
+		}
+		if body != "" {
+			builder.WriteString(" --data-binary " + shellQuote(body))
+		}
+		builder.WriteString(" " + shellQuote(raw.FullURL))
+		return builder.String()
+	}
+	names := make([]string, 0, len(raw.Headers))
+	for name := range raw.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		builder.WriteString(" -H " + shellQuote(name+": "+raw.Headers[name]))
+	}
+	if raw.Data != "" {
+		builder.WriteString(" --data-binary " + shellQuote(raw.Data))
+	}
+	builder.WriteString(" " + shellQuote(raw.FullURL))
+	return builder.String()
+}
+
+// splitUnsafeRequest splits the bytes of an unsafe request into its header
+// lines and its body, skipping the request line.
+func splitUnsafeRequest(data []byte) ([]string, string) {
+	lines := strings.SplitAfter(string(data), "\n")
+	var headers []string
+	i := 1
+	for ; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r\n")
+		if line == "" {
+			i++
+			break
+		}
+		headers = append(headers, line)
+	}
+	if i >= len(lines) {
+		return headers, ""
+	}
+	return headers, strings.Join(lines[i:], "")
+}
+
+// shellQuote quotes a value as a single shell argument
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,35 @@
+package http
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/projectdiscovery/retryablehttp-go"
+	"github.com/stretchr/testify/require"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/http/raw"
+)
+
+func TestCurlCommand(t *testing.T) {
+	req, err := retryablehttp.NewRequest("POST", "https://example.com/api?id=1", strings.NewReader(`{"name":"it's"}`))
+	require.Nil(t, err, "could not create request")
+	req.Header.Set("Authorization", "Bearer secret-token")
+	req.Header.Set("Content-Type", "application/json")
+
+	command := curlCommand(&generatedRequest{original: &Request{}, request: req})
+	require.Equal(t, `curl -k -X 'POST' -H 'Authorization: Bearer secret-token' -H 'Content-Type: application/json' --data-binary '{"name":"it'\''s"}' 'https://example.com/api?id=1'`, command, "could not build curl command")
+
+	body, err := req.BodyBytes()
+	require.Nil(t, err, "could not read request body")
+	require.Equal(t, `{"name":"it's"}`, string(body), "consumed request body")
+
+	rawRequest, err := raw.Parse("GET /../etc/passwd HTTP/1.1\r\nHost: example.com\r\n\r\n", "http://example.com", true)
+	require.Nil(t, err, "could not parse unsafe request")
+	command = curlCommand(&generatedRequest{original: &Request{Unsafe: true}, rawRequest: rawRequest})
+	require.Equal(t, `curl -k --path-as-is -X 'GET' -H 'Host: example.com' 'http://example.com/../etc/passwd'`, command, "could not build unsafe curl command")
+
+	rawRequest, err = raw.Parse("POST /%2e%2e/login HTTP/1.1\r\nHost: example.com\r\nContent-Length: 7\r\n\r\nuser=it's", "http://example.com", true)
+	require.Nil(t, err, "could not parse unsafe request")
+	rawRequest.UnsafeRawBytes = append([]byte("POST /%2e%2e/login HTTP/1.1\r\nX-Custom: 1\r\n"), rawRequest.UnsafeRawBytes[len("POST /%2e%2e/login HTTP/1.1\r\n"):]...)
+	command = curlCommand(&generatedRequest{original: &Request{Unsafe: true}, rawRequest: rawRequest})
+	require.Equal(t, `curl -k --path-as-is -X 'POST' -H 'X-Custom: 1' -H 'Host: example.com' -H 'Content-Length: 7' --data-binary 'user=it'\''s' 'http://example.com/%2e%2e/login'`, command, "could not build unsafe curl command from raw bytes")
+}
@@ -0,0 +1,170 @@
+package http
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"mime/quotedprintable"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// Body decoders supported by the decode-body request field
+const (
+	decodeBase64Fields    = "base64-fields"
+	decodeQuotedPrintable = "quoted-printable"
+)
+
+const (
+	// minEncodedSegmentLength is the minimum length of an encoded segment to decode.
+	minEncodedSegmentLength = 16
+	// maxEncodedSegmentLength is the maximum length of an encoded segment to decode.
+	maxEncodedSegmentLength = 512 * 1024
+	// maxDecodedBodySize is the maximum size of the decoded body.
+	maxDecodedBodySize = 4 * 1024 * 1024
+)
+
+var (
+	base64SegmentRegex = regexp.MustCompile(fmt.Sprintf(`[A-Za-z0-9+/_-]{%d,}={0,2}`, minEncodedSegmentLength))
+	qpSegmentRegex     = regexp.MustCompile(`[^\s"'<>]*(?:=[0-9A-F]{2}|=\r?\n)(?:[^\s"'<>]|=\r?\n)*`)
+)
+
+// decodedSegment maps a decoded region of the body to the region of
+// the original body it was decoded from.
+type decodedSegment struct {
+	start, end         int // offsets in decoded body
+	origStart, origEnd int // offsets in original body
+}
+
+// validateDecodeBody validates the decoders of the decode-body field
+func validateDecodeBody(decoders []string) error {
+	for _, decoder := range decoders {
+		if decoder != decodeBase64Fields && decoder != decodeQuotedPrintable {
+			return errors.Errorf("invalid decode-body value %s", decoder)
+		}
+	}
+	return nil
+}
+
+// decodeBody decodes the recognizable encoded segments of the body in
+// place, returning the decoded body along with the decoded segments.
+func decodeBody(body string, decoders []string) (string, []decodedSegment) {
+	type candidate struct {
+		start, end int
+		decoded    string
+	}
+	var candidates []candidate
+
+	for _, decoder := range decoders {
+		var regex *regexp.Regexp
+		var decode func(string) (string, bool)
+		switch decoder {
+		case decodeBase64Fields:
+			regex, decode = base64SegmentRegex, decodeBase64Segment
+		case decodeQuotedPrintable:
+			regex, decode = qpSegmentRegex, decodeQuotedPrintableSegment
+		default:
+			continue
+		}
+		for _, loc := range regex.FindAllStringIndex(body, -1) {
+			length := loc[1] - loc[0]
+			if length < minEncodedSegmentLength || length > maxEncodedSegmentLength {
+				continue
+			}
+			if decoded, ok := decode(body[loc[0]:loc[1]]); ok {
+				candidates = append(candidates, candidate{start: loc[0], end: loc[1], decoded: decoded})
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return body, nil
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].start < candidates[j].start
+	})
+
+	builder := &strings.Builder{}
+	var segments []decodedSegment
+	last := 0
+	for _, c := range candidates {
+		if c.start < last {
+			continue // overlapping segment, already decoded by another decoder
+		}
+		if builder.Len()+(c.start-last)+len(c.decoded) > maxDecodedBodySize {
+			break
+		}
+		builder.WriteString(body[last:c.start])
+		start := builder.Len()
+		builder.WriteString(c.decoded)
+		segments = append(segments, decodedSegment{start: start, end: builder.Len(), origStart: c.start, origEnd: c.end})
+		last = c.end
+	}
+	builder.WriteString(body[last:])
+	return builder.String(), segments
+}
+
+// decodeBase64Segment decodes a base64 segment if it yields printable text
+func decodeBase64Segment(data string) (string, bool) {
+	trimmed := strings.TrimRight(data, "=")
+	var decoded []byte
+	var err error
+	if strings.ContainsAny(trimmed, "-_") {
+		decoded, err = base64.RawURLEncoding.DecodeString(trimmed)
+	} else {
+		decoded, err = base64.RawStdEncoding.DecodeString(trimmed)
+	}
+	if err != nil || !isPrintable(decoded) {
+		return "", false
+	}
+	return string(decoded), true
+}
+
+// decodeQuotedPrintableSegment decodes a quoted-printable segment
+func decodeQuotedPrintableSegment(data string) (string, bool) {
+	decoded, err := ioutil.ReadAll(quotedprintable.NewReader(strings.NewReader(data)))
+	if err != nil || len(decoded) == 0 || string(decoded) == data || !isPrintable(decoded) {
+		return "", false
+	}
+	return string(decoded), true
+}
+
+// isPrintable returns true if the data is mostly printable utf-8 text
+func isPrintable(data []byte) bool {
+	if len(data) == 0 || !utf8.Valid(data) {
+		return false
+	}
+	var total, printable int
+	for _, r := range string(data) {
+		total++
+		if unicode.IsPrint(r) || unicode.IsSpace(r) {
+			printable++
+		}
+	}
+	return printable*10 >= total*9
+}
+
+// originalRegions returns the regions of the original body the value
+// was decoded from, formatted as body[start:end].
+func originalRegions(decoded, value string, segments []decodedSegment) []string {
+	var regions []string
+	for offset := 0; offset < len(decoded); {
+		index := strings.Index(decoded[offset:], value)
+		if index == -1 {
+			break
+		}
+		start := offset + index
+		end := start + len(value)
+		for _, segment := range segments {
+			if start < segment.end && end > segment.start {
+				regions = append(regions, fmt.Sprintf("body[%d:%d]", segment.origStart, segment.origEnd))
+			}
+		}
+		offset = end
+	}
+	return regions
+}
@@ -0,0 +1,183 @@
+package http
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// digestChallenge is a parsed digest WWW-Authenticate challenge
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       string
+}
+
+// digestAuthenticateHeader returns the digest challenge of the WWW-Authenticate
+// header of a response, ok is false if the response does not ask for digest.
+func digestAuthenticateHeader(resp *http.Response) (*digestChallenge, bool) {
+	if resp.StatusCode != http.StatusUnauthorized {
+		return nil, false
+	}
+	for _, value := range resp.Header.Values("WWW-Authenticate") {
+		if len(value) < 7 || !strings.EqualFold(value[:7], "Digest ") {
+			continue
+		}
+		params := parseAuthParams(value[7:])
+		challenge := &digestChallenge{
+			realm:     params["realm"],
+			nonce:     params["nonce"],
+			opaque:    params["opaque"],
+			algorithm: params["algorithm"],
+		}
+		// auth is preferred as auth-int requires hashing the body
+		for _, qop := range strings.Split(params["qop"], ",") {
+			qop = strings.TrimSpace(qop)
+			if qop == "auth" || (qop == "auth-int" && challenge.qop == "") {
+				challenge.qop = qop
+			}
+		}
+		return challenge, true
+	}
+	return nil, false
+}
+
+// parseAuthParams parses the comma separated key=value parameters of an
+// authentication challenge, values can be quoted strings containing commas.
+func parseAuthParams(value string) map[string]string {
+	params := make(map[string]string)
+	for value != "" {
+		value = strings.TrimLeft(value, " \t,")
+		equals := strings.IndexByte(value, '=')
+		if equals == -1 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(value[:equals]))
+		value = strings.TrimLeft(value[equals+1:], " \t")
+
+		var param string
+		if strings.HasPrefix(value, `"`) {
+			end := 1
+			for end < len(value) && value[end] != '"' {
+				if value[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end > len(value) {
+				end = len(value)
+			}
+			param = strings.ReplaceAll(value[1:end], `\`, "")
+			if end < len(value) {
+				end++
+			}
+			value = value[end:]
+		} else {
+			end := strings.IndexByte(value, ',')
+			if end == -1 {
+				end = len(value)
+			}
+			param = strings.TrimSpace(value[:end])
+			value = value[end:]
+		}
+		params[key] = param
+	}
+	return params
+}
+
+// authorization returns the digest Authorization header of a request
+func (c *digestChallenge) authorization(auth *Auth, method, uri string, body []byte) (string, error) {
+	algorithm := strings.ToUpper(c.algorithm)
+	var hasher func() hash.Hash
+	switch strings.TrimSuffix(algorithm, "-SESS") {
+	case "", "MD5":
+		hasher = md5.New
+	case "SHA-256":
+		hasher = sha256.New
+	default:
+		return "", errors.Errorf("unsupported digest algorithm %s", c.algorithm)
+	}
+	hashHex := func(parts ...string) string {
+		h := hasher()
+		_, _ = h.Write([]byte(strings.Join(parts, ":")))
+		return hex.EncodeToString(h.Sum(nil))
+	}
+
+	random := make([]byte, 8)
+	if _, err := rand.Read(random); err != nil {
+		return "", errors.Wrap(err, "could not generate client nonce")
+	}
+	cnonce := hex.EncodeToString(random)
+	nc := "00000001"
+
+	ha1 := hashHex(auth.Username, c.realm, auth.Password)
+	if strings.HasSuffix(algorithm, "-SESS") {
+		ha1 = hashHex(ha1, c.nonce, cnonce)
+	}
+	ha2 := hashHex(method, uri)
+	if c.qop == "auth-int" {
+		h := hasher()
+		_, _ = h.Write(body)
+		ha2 = hashHex(method, uri, hex.EncodeToString(h.Sum(nil)))
+	}
+
+	var response string
+	if c.qop != "" {
+		response = hashHex(ha1, c.nonce, nc, cnonce, c.qop, ha2)
+	} else {
+		response = hashHex(ha1, c.nonce, ha2)
+	}
+
+	builder := &strings.Builder{}
+	builder.WriteString(`Digest username="` + auth.Username + `", realm="` + c.realm + `", nonce="` + c.nonce + `", uri="` + uri + `", response="` + response + `"`)
+	if c.algorithm != "" {
+		builder.WriteString(", algorithm=" + c.algorithm)
+	}
+	if c.opaque != "" {
+		builder.WriteString(`, opaque="` + c.opaque + `"`)
+	}
+	if c.qop != "" {
+		builder.WriteString(", qop=" + c.qop + ", nc=" + nc + `, cnonce="` + cnonce + `"`)
+	}
+	return builder.String(), nil
+}
+
+// doWithDigest sends a request and answers the digest challenge of the
+// server if it asks for authentication. The additional request is counted
+// in the progress and the authenticated response is returned.
+func (r *Request) doWithDigest(req *generatedRequest) (*http.Response, error) {
+	req.request.Header.Del("Authorization")
+	resp, err := req.client.Do(req.request)
+	if err != nil {
+		return resp, err
+	}
+	challenge, ok := digestAuthenticateHeader(resp)
+	if !ok {
+		return resp, nil
+	}
+	drainResponse(resp)
+
+	var body []byte
+	if challenge.qop == "auth-int" {
+		if body, err = req.request.BodyBytes(); err != nil {
+			return nil, errors.Wrap(err, "could not read request body")
+		}
+	}
+	header, err := challenge.authorization(req.auth, req.request.Method, req.request.URL.RequestURI(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.request.Header.Set("Authorization", header)
+
+	r.options.Progress.AddToTotal(1)
+	r.options.Progress.IncrementRequests()
+	return req.client.Do(req.request)
+}
@@ -1,12 +1,18 @@
 package http
 
 import (
+	"net/url"
+	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/yaklang/nuclei/v2/pkg/operators"
 	"github.com/yaklang/nuclei/v2/pkg/protocols"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/clientcert"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/common/generators"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/tlsconfig"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/http/httpclientpool"
 	"github.com/projectdiscovery/rawhttp"
 	"github.com/projectdiscovery/retryablehttp-go"
@@ -24,7 +30,7 @@ type Request struct {
 	// Name is the name of the request
 	Name string `yaml:"Name"`
 	// AttackType is the attack type
-	// Sniper, PitchFork and ClusterBomb. Default is Sniper
+	// Sniper, BatteringRam, PitchFork and ClusterBomb. Default is Sniper
 	AttackType string `yaml:"attack"`
 	// Method is the request method, whether GET, POST, PUT, etc
 	Method string `yaml:"method"`
@@ -37,6 +43,8 @@ type Request struct {
 	// RaceNumberRequests is the number of same request to send in race condition attack
 	RaceNumberRequests int `yaml:"race_count"`
 	// MaxRedirects is the maximum number of redirects that should be followed.
+	// The followed redirects are available in the redirect_chain part, except
+	// for unsafe requests.
 	MaxRedirects int `yaml:"max-redirects"`
 	// PipelineConcurrentConnections is number of connections in pipelining
 	PipelineConcurrentConnections int `yaml:"pipeline-concurrent-connections"`
@@ -46,17 +54,58 @@ type Request struct {
 	Threads int `yaml:"threads"`
 
 	// MaxSize is the maximum size of http response body to read in bytes.
+	// Longer bodies are truncated and set the body_truncated part. Bodies
+	// are read fully if neither max-size nor -response-size-read are set.
 	MaxSize int `yaml:"max-size"`
+	// DecodeBody is the list of decoders (base64-fields, quoted-printable) used
+	// to decode encoded segments of the body into the body_decoded part.
+	DecodeBody []string `yaml:"decode-body"`
+	// Auth contains the authentication to perform for the request.
+	// Credentials can contain payload values.
+	Auth *Auth `yaml:"auth"`
+	// ClientCertificate overrides the client certificate presented for
+	// mutual tls. Relative paths are resolved from the template directory.
+	ClientCertificate *clientcert.Files `yaml:"client-certificate"`
+	// Signature is the signature computed for the request. Only aws
+	// signature v4 is supported for now.
+	Signature string `yaml:"signature"`
+	// AWSCredentials contains the credentials of the aws signature
+	AWSCredentials *AWSCredentials `yaml:"aws-credentials"`
+	// TLSSkipVerify disables the verification of the server certificate,
+	// even if a CA file is provided.
+	TLSSkipVerify bool `yaml:"tls-skip-verify"`
+	// TLSCAFile is the PEM encoded CA file to verify the server certificate
+	// with. Relative paths are resolved from the template directory.
+	TLSCAFile string `yaml:"tls-ca-file"`
+	// Proxy is the http or socks5 proxy to send the requests through,
+	// overriding the proxies of the scan.
+	Proxy string `yaml:"proxy"`
+	// Retries is the number of times the request is retried after connection
+	// errors, or server errors for idempotent methods. The global retries are
+	// used if only the retry delay is set.
+	Retries int `yaml:"retries"`
+	// RetryDelay is the delay before the first retry (1s by default),
+	// doubled for each of the next retries.
+	RetryDelay string `yaml:"retry-delay"`
 
 	CompiledOperators *operators.Operators
 
-	options       *protocols.ExecuterOptions
-	attackType    generators.Type
-	totalRequests int
-	customHeaders map[string]string
-	generator     *generators.Generator // optional, only enabled when using payloads
-	httpClient    *retryablehttp.Client
-	rawhttpClient *rawhttp.Client
+	options        *protocols.ExecuterOptions
+	attackType     generators.Type
+	totalRequests  int
+	customHeaders  map[string]string
+	generator      *generators.Generator // optional, only enabled when using payloads
+	httpClient     *retryablehttp.Client
+	rawhttpClient  *rawhttp.Client
+	ntlmHosts      sync.Map
+	historyMutex   sync.Mutex
+	certificate    *clientcert.Certificate
+	verification   *tlsconfig.Verification
+	proxy          *url.URL
+	awsCredentials *AWSCredentials
+	retries        int
+	retryDelay     time.Duration
+	clustered      bool
 	// CookieReuse is an optional setting that makes cookies shared within requests
 	CookieReuse bool `yaml:"cookie-reuse"`
 	// Redirects specifies whether redirects should be followed.
@@ -66,13 +115,27 @@ type Request struct {
 	Pipeline bool `yaml:"pipeline"`
 	// Specify in order to skip request RFC normalization
 	Unsafe bool `yaml:"unsafe"`
-	// Race determines if all the request have to be attempted at the same time
-	// The minimum number of requests is determined by threads
+	// HTTP2 sends the requests over HTTP/2. Raw requests can set the
+	// :method, :path, :authority and :scheme pseudo-headers.
+	HTTP2 bool `yaml:"http2"`
+	// Race determines if all the request have to be attempted at the same time.
+	// The first request is sent race_count times, with the bodies released together.
 	Race bool `yaml:"race"`
 	// ReqCondition automatically assigns numbers to requests and preserves
 	// their history for being matched at the end.
-	// Currently only works with sequential http requests.
+	// Currently only works with sequential and race http requests.
 	ReqCondition bool `yaml:"req-condition"`
+	// IterateAll sends the next requests once for each value extracted
+	// by the internal extractors instead of only the first one, up to
+	// 1000 combinations of the values.
+	IterateAll bool `yaml:"iterate-all"`
+	// Baseline sends the first request for an input before the others,
+	// even for parallel requests. Its duration is available to the next
+	// requests and their matchers as baseline_duration.
+	Baseline bool `yaml:"baseline"`
+	// SelfContained is set for the requests of self-contained templates
+	// which don't use the input as base url.
+	SelfContained bool `yaml:"-"`
 }
 
 // GetID returns the unique ID of the request if any.
@@ -80,13 +143,83 @@ func (r *Request) GetID() string {
 	return r.ID
 }
 
+// EventType returns the type of the result events of the request.
+func (r *Request) EventType() string {
+	return "http"
+}
+
 // Compile compiles the protocol request for further execution.
 func (r *Request) Compile(options *protocols.ExecuterOptions) error {
+	if r.Auth != nil {
+		if err := r.Auth.validate(r); err != nil {
+			return err
+		}
+	}
+	if r.Signature != "" {
+		if err := r.compileSignature(); err != nil {
+			return err
+		}
+	}
+	if r.Pipeline && r.Race {
+		return errors.New("pipeline is not supported with race requests")
+	}
+	if r.PipelineConcurrentConnections < 0 || r.PipelineRequestsPerConnection < 0 {
+		return errors.New("pipeline connections and requests per connection must be positive")
+	}
+	if r.IterateAll && (r.Pipeline || r.Race || r.Threads > 0) {
+		return errors.New("iterate-all is not supported with pipeline, race or parallel requests")
+	}
+	if r.Baseline && (r.Pipeline || r.Race) {
+		return errors.New("baseline is not supported with pipeline or race requests")
+	}
+	if r.HTTP2 && (r.Unsafe || r.Pipeline || r.Race) {
+		return errors.New("http2 is not supported with unsafe, pipeline or race requests")
+	}
+	if r.ClientCertificate != nil {
+		if r.Pipeline {
+			return errors.New("client certificates are not supported with pipeline requests")
+		}
+		certificate, err := clientcert.Load(r.ClientCertificate.Resolve(filepath.Dir(options.TemplatePath)))
+		if err != nil {
+			return errors.Wrap(err, "could not load client certificate")
+		}
+		r.certificate = certificate
+	}
+	if r.TLSSkipVerify || r.TLSCAFile != "" {
+		if r.Pipeline {
+			return errors.New("tls settings are not supported with pipeline requests")
+		}
+		verification, err := tlsconfig.Load(r.TLSSkipVerify, r.TLSCAFile, filepath.Dir(options.TemplatePath))
+		if err != nil {
+			return errors.Wrap(err, "could not load tls settings")
+		}
+		r.verification = verification
+	}
+	if r.Proxy != "" {
+		if r.Unsafe || r.Pipeline {
+			return errors.New("proxy is not supported with unsafe or pipeline requests")
+		}
+		proxy, err := httpclientpool.ParseProxy(r.Proxy)
+		if err != nil {
+			return err
+		}
+		r.proxy = proxy
+	}
+	if r.Retries > 0 || r.RetryDelay != "" {
+		if err := r.compileRetries(options.Options); err != nil {
+			return err
+		}
+	}
 	client, err := httpclientpool.Get(options.Options, &httpclientpool.Configuration{
-		Threads:         r.Threads,
-		MaxRedirects:    r.MaxRedirects,
-		FollowRedirects: r.Redirects,
-		CookieReuse:     r.CookieReuse,
+		Threads:           r.Threads,
+		MaxRedirects:      r.MaxRedirects,
+		FollowRedirects:   r.Redirects,
+		CookieReuse:       r.CookieReuse,
+		SingleConnection:  r.Auth.isNTLM(),
+		ClientCertificate: r.certificate,
+		HTTP2:             r.HTTP2,
+		Verification:      r.verification,
+		DisableRetries:    r.retries > 0,
 	})
 	if err != nil {
 		return errors.Wrap(err, "could not get dns client")
@@ -99,7 +232,14 @@ func (r *Request) Compile(options *protocols.ExecuterOptions) error {
 		if len(parts) != 2 {
 			continue
 		}
-		r.customHeaders[parts[0]] = strings.TrimSpace(parts[1])
+		r.customHeaders[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	if userAgent := r.options.Options.CustomUserAgent; userAgent != "" && !hasCustomHeader(r.customHeaders, "User-Agent") {
+		r.customHeaders["User-Agent"] = userAgent
+	}
+
+	if err := validateDecodeBody(r.DecodeBody); err != nil {
+		return err
 	}
 
 	if r.Body != "" && !strings.Contains(r.Body, "\r\n") {
@@ -111,7 +251,7 @@ func (r *Request) Compile(options *protocols.ExecuterOptions) error {
 				r.Raw[i] = strings.ReplaceAll(raw, "\n", "\r\n")
 			}
 		}
-		r.rawhttpClient = httpclientpool.GetRawHTTP()
+		r.rawhttpClient = httpclientpool.GetRawHTTP(r.MaxRedirects)
 	}
 	if len(r.Matchers) > 0 || len(r.Extractors) > 0 {
 		compiled := &r.Operators
@@ -126,7 +266,10 @@ func (r *Request) Compile(options *protocols.ExecuterOptions) error {
 		if attackType == "" {
 			attackType = "sniper"
 		}
-		r.attackType = generators.StringToType[attackType]
+		var ok bool
+		if r.attackType, ok = generators.StringToType[attackType]; !ok {
+			return errors.Errorf("invalid attack type %s", attackType)
+		}
 
 		// Resolve payload paths if they are files.
 		for name, payload := range r.Payloads {
@@ -134,14 +277,19 @@ func (r *Request) Compile(options *protocols.ExecuterOptions) error {
 			if ok {
 				final, resolveErr := options.Catalog.ResolvePath(payloadStr, options.TemplatePath)
 				if resolveErr != nil {
-					return errors.Wrap(resolveErr, "could not read payload file")
+					return errors.Wrapf(resolveErr, "[%s] could not read file for payload %s", options.TemplateID, name)
+				}
+				if options.Options.Sandbox {
+					if sandboxErr := generators.SandboxPath(final, options.Options.TemplatesDirectory, filepath.Dir(options.TemplatePath)); sandboxErr != nil {
+						return errors.Wrapf(sandboxErr, "[%s] could not read file for payload %s", options.TemplateID, name)
+					}
 				}
 				r.Payloads[name] = final
 			}
 		}
 		r.generator, err = generators.New(r.Payloads, r.attackType, r.options.TemplatePath)
 		if err != nil {
-			return errors.Wrap(err, "could not parse payloads")
+			return errors.Wrapf(err, "[%s] could not parse payloads", options.TemplateID)
 		}
 	}
 	r.options = options
@@ -151,16 +299,16 @@ func (r *Request) Compile(options *protocols.ExecuterOptions) error {
 
 // Requests returns the total number of requests the YAML rule will perform
 func (r *Request) Requests() int {
+	// Race requests send the first request race_count times
+	if r.Race && r.RaceNumberRequests > 0 {
+		return r.RaceNumberRequests
+	}
 	if r.generator != nil {
 		payloadRequests := r.generator.NewIterator().Total() * len(r.Raw)
 		return payloadRequests
 	}
 	if len(r.Raw) > 0 {
-		requests := len(r.Raw)
-		if requests == 1 && r.RaceNumberRequests != 0 {
-			requests *= r.RaceNumberRequests
-		}
-		return requests
+		return len(r.Raw)
 	}
 	return len(r.Path)
 }
@@ -1,17 +1,21 @@
 package http
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/yaklang/nuclei/v2/internal/testutils"
+	"github.com/yaklang/nuclei/v2/pkg/types"
 	"github.com/stretchr/testify/require"
 )
 
 func TestHTTPCompile(t *testing.T) {
-	options := testutils.DefaultOptions
+	options := *testutils.DefaultOptions
 	options.CustomHeaders = []string{"User-Agent: test", "Hello: World"}
 
-	testutils.Init(options)
+	testutils.Init(&options)
 	templateID := "testing-http"
 	request := &Request{
 		ID:   templateID,
@@ -28,7 +32,7 @@ Connection: close
 Authorization: Basic {{username + ':' + password}}
 Accept-Encoding: gzip`},
 	}
-	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+	executerOpts := testutils.NewMockExecuterOptions(&options, &testutils.TemplateInfo{
 		ID:   templateID,
 		Info: map[string]interface{}{"severity": "low", "name": "test"},
 	})
@@ -37,3 +41,56 @@ Accept-Encoding: gzip`},
 	require.Equal(t, 6, request.Requests(), "could not get correct number of requests")
 	require.Equal(t, map[string]string{"User-Agent": "test", "Hello": "World"}, request.customHeaders, "could not get correct custom headers")
 }
+
+func TestHTTPCompilePayloadFiles(t *testing.T) {
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-http-payload-files"
+
+	templatesDirectory, err := ioutil.TempDir("", "nuclei-templates-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(templatesDirectory)
+	outsideDirectory, err := ioutil.TempDir("", "nuclei-outside-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(outsideDirectory)
+
+	templatePath := filepath.Join(templatesDirectory, "http", "default-logins.yaml")
+	require.Nil(t, os.MkdirAll(filepath.Join(templatesDirectory, "http", "wordlists"), 0755), "could not create wordlists directory")
+	require.Nil(t, ioutil.WriteFile(filepath.Join(templatesDirectory, "http", "wordlists", "common.txt"), []byte("admin\nguest\n\nroot\n"), 0644), "could not write wordlist")
+	outsideFile := filepath.Join(outsideDirectory, "passwords.txt")
+	require.Nil(t, ioutil.WriteFile(outsideFile, []byte("secret\n"), 0644), "could not write wordlist")
+
+	compile := func(options *types.Options, payload string) (*Request, error) {
+		request := &Request{
+			ID:       templateID,
+			Payloads: map[string]interface{}{"passwords": payload},
+			Raw:      []string{"GET /?p={{passwords}} HTTP/1.1\r\nHost: {{Hostname}}\r\n\r\n"},
+		}
+		err := request.Compile(testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{ID: templateID, Path: templatePath}))
+		return request, err
+	}
+
+	request, err := compile(options, "wordlists/common.txt")
+	require.Nil(t, err, "could not compile request with relative payload file")
+	require.Equal(t, 3, request.Requests(), "could not load payloads from file")
+
+	_, err = compile(options, "wordlists/missing.txt")
+	require.NotNil(t, err, "compiled request with missing payload file")
+	require.Contains(t, err.Error(), templateID, "could not get template in error")
+	require.Contains(t, err.Error(), "passwords", "could not get payload key in error")
+
+	sandboxed := *options
+	sandboxed.Sandbox = true
+	sandboxed.TemplatesDirectory = templatesDirectory
+	_, err = compile(&sandboxed, "wordlists/common.txt")
+	require.Nil(t, err, "could not compile sandboxed request with payload file in templates directory")
+	_, err = compile(&sandboxed, outsideFile)
+	require.NotNil(t, err, "compiled sandboxed request with payload file outside templates directory")
+	_, err = compile(&sandboxed, "../../"+filepath.Base(outsideDirectory)+"/passwords.txt")
+	require.NotNil(t, err, "compiled sandboxed request with payload path traversal")
+
+	templatePath = filepath.Join(outsideDirectory, "custom.yaml")
+	_, err = compile(&sandboxed, "passwords.txt")
+	require.Nil(t, err, "could not compile sandboxed request with payload file in template directory")
+}
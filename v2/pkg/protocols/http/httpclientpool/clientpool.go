@@ -2,7 +2,6 @@ package httpclientpool
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
 	"net"
 	"net/http"
@@ -15,7 +14,9 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/projectdiscovery/fastdialer/fastdialer"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/clientcert"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/common/protocolstate"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/tlsconfig"
 	"github.com/yaklang/nuclei/v2/pkg/types"
 	"github.com/projectdiscovery/rawhttp"
 	"github.com/projectdiscovery/retryablehttp-go"
@@ -27,14 +28,18 @@ var (
 	// Dialer is a copy of the fatdialer from protocolstate
 	Dialer *fastdialer.Dialer
 
-	rawhttpClient *rawhttp.Client
-	poolMutex     *sync.RWMutex
-	normalClient  *retryablehttp.Client
-	clientPool    map[string]*retryablehttp.Client
+	rawhttpMutex   sync.Mutex
+	rawhttpClients = make(map[int]*rawhttp.Client)
+	poolMutex      *sync.RWMutex
+	normalClient   *retryablehttp.Client
+	clientPool     map[string]*retryablehttp.Client
 )
 
 // Init initializes the clientpool implementation
 func Init(options *types.Options) error {
+	if err := setProxies(options.InternalProxyList); err != nil {
+		return err
+	}
 	// Don't create clients if already created in past.
 	if normalClient != nil {
 		return nil
@@ -60,6 +65,18 @@ type Configuration struct {
 	CookieReuse bool
 	// FollowRedirects specifies whether to follow redirects
 	FollowRedirects bool
+	// SingleConnection creates a dedicated client keeping a single connection
+	// per host alive, used for connection based authentication.
+	SingleConnection bool
+	// ClientCertificate overrides the client certificate configured by the user
+	ClientCertificate *clientcert.Certificate
+	// HTTP2 sends the requests of the client over HTTP/2
+	HTTP2 bool
+	// Verification overrides the verification of the servers
+	Verification *tlsconfig.Verification
+	// DisableRetries disables the retries of the client for requests
+	// retrying on their own.
+	DisableRetries bool
 }
 
 // Hash returns the hash of the configuration to allow client pooling
@@ -74,21 +91,44 @@ func (c *Configuration) Hash() string {
 	builder.WriteString(strconv.FormatBool(c.FollowRedirects))
 	builder.WriteString("r")
 	builder.WriteString(strconv.FormatBool(c.CookieReuse))
+	builder.WriteString("h")
+	builder.WriteString(strconv.FormatBool(c.HTTP2))
+	if c.ClientCertificate != nil {
+		builder.WriteString("c")
+		builder.WriteString(c.ClientCertificate.Hash())
+	}
+	if c.Verification != nil {
+		builder.WriteString("v")
+		builder.WriteString(c.Verification.Hash())
+	}
+	if c.DisableRetries {
+		builder.WriteString("n")
+	}
 	hash := builder.String()
 	return hash
 }
 
-// GetRawHTTP returns the rawhttp request client
-func GetRawHTTP() *rawhttp.Client {
-	if rawhttpClient == nil {
-		rawhttpClient = rawhttp.NewClient(rawhttp.DefaultOptions)
+// GetRawHTTP returns the rawhttp request client. A client is pooled for
+// each custom maximum number of redirects.
+func GetRawHTTP(maxRedirects int) *rawhttp.Client {
+	rawhttpMutex.Lock()
+	defer rawhttpMutex.Unlock()
+
+	if client, ok := rawhttpClients[maxRedirects]; ok {
+		return client
+	}
+	options := rawhttp.DefaultOptions
+	if maxRedirects > 0 {
+		options.MaxRedirects = maxRedirects
 	}
-	return rawhttpClient
+	client := rawhttp.NewClient(options)
+	rawhttpClients[maxRedirects] = client
+	return client
 }
 
 // Get creates or gets a client for the protocol based on custom configuration
 func Get(options *types.Options, configuration *Configuration) (*retryablehttp.Client, error) {
-	if configuration.Threads == 0 && configuration.MaxRedirects == 0 && !configuration.FollowRedirects && !configuration.CookieReuse {
+	if configuration.Threads == 0 && configuration.MaxRedirects == 0 && !configuration.FollowRedirects && !configuration.CookieReuse && !configuration.SingleConnection && configuration.ClientCertificate == nil && !configuration.HTTP2 && configuration.Verification == nil && !configuration.DisableRetries {
 		return normalClient, nil
 	}
 	return wrappedGet(options, configuration)
@@ -107,12 +147,14 @@ func wrappedGet(options *types.Options, configuration *Configuration) (*retryabl
 	}
 
 	hash := configuration.Hash()
-	poolMutex.RLock()
-	if client, ok := clientPool[hash]; ok {
+	if !configuration.SingleConnection {
+		poolMutex.RLock()
+		if client, ok := clientPool[hash]; ok {
+			poolMutex.RUnlock()
+			return client, nil
+		}
 		poolMutex.RUnlock()
-		return client, nil
 	}
-	poolMutex.RUnlock()
 
 	if options.ProxyURL != "" {
 		proxyURL, err = url.Parse(options.ProxyURL)
@@ -135,9 +177,18 @@ func wrappedGet(options *types.Options, configuration *Configuration) (*retryabl
 		maxIdleConnsPerHost = 500
 		maxConnsPerHost = 500
 	}
+	if configuration.SingleConnection {
+		retryablehttpOptions = retryablehttp.DefaultOptionsSingle
+		disableKeepAlives = false
+		maxIdleConnsPerHost = 1
+		maxConnsPerHost = 1
+	}
 
 	retryablehttpOptions.RetryWaitMax = 10 * time.Second
 	retryablehttpOptions.RetryMax = options.Retries
+	if configuration.DisableRetries {
+		retryablehttpOptions.RetryMax = 0
+	}
 	followRedirects := configuration.FollowRedirects
 	maxRedirects := configuration.MaxRedirects
 
@@ -146,12 +197,13 @@ func wrappedGet(options *types.Options, configuration *Configuration) (*retryabl
 		MaxIdleConns:        maxIdleConns,
 		MaxIdleConnsPerHost: maxIdleConnsPerHost,
 		MaxConnsPerHost:     maxConnsPerHost,
-		TLSClientConfig: &tls.Config{
-			Renegotiation:      tls.RenegotiateOnceAsClient,
-			InsecureSkipVerify: true,
-		},
-		DisableKeepAlives: disableKeepAlives,
+		DisableKeepAlives:   disableKeepAlives,
+	}
+	certificate := configuration.ClientCertificate
+	if certificate == nil {
+		certificate = protocolstate.ClientCertificate
 	}
+	transport.TLSClientConfig = tlsconfig.Config("", certificate, configuration.Verification)
 
 	// Attempts to overwrite the dial function with the socks proxied version
 	if options.ProxySocksURL != "" {
@@ -171,29 +223,35 @@ func wrappedGet(options *types.Options, configuration *Configuration) (*retryabl
 			transport.DialContext = dc.DialContext
 		}
 	}
-	if proxyURL != nil {
-		transport.Proxy = http.ProxyURL(proxyURL)
-	}
+	transport.Proxy = proxyFunc(proxyURL)
 
 	var jar *cookiejar.Jar
 	if configuration.CookieReuse {
-		if jar, err = cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List}); err != nil {
-			return nil, errors.Wrap(err, "could not create cookiejar")
+		if jar, err = newCookieJar(); err != nil {
+			return nil, err
 		}
 	}
 
 	client := retryablehttp.NewWithHTTPClient(&http.Client{
 		Transport:     transport,
-		Timeout:       time.Duration(options.Timeout) * time.Second,
+		Timeout:       options.ProtocolTimeout(types.HTTPProtocol),
 		CheckRedirect: makeCheckRedirectFunc(followRedirects, maxRedirects),
 	}, retryablehttpOptions)
 	if jar != nil {
 		client.HTTPClient.Jar = jar
 	}
+	// The http2 transport is set after the client is created as retryablehttp
+	// derives its http2 fallback client from the http transport.
+	if configuration.HTTP2 {
+		if client.HTTPClient.Transport, err = newHTTP2Transport(transport); err != nil {
+			return nil, err
+		}
+	}
 	client.CheckRetry = retryablehttp.HostSprayRetryPolicy()
 
-	// Only add to client pool if we don't have a cookie jar in place.
-	if jar == nil {
+	// Only add to client pool if we don't have a cookie jar in place
+	// and the client is not dedicated to a single request.
+	if jar == nil && !configuration.SingleConnection {
 		poolMutex.Lock()
 		clientPool[hash] = client
 		poolMutex.Unlock()
@@ -201,6 +259,78 @@ func wrappedGet(options *types.Options, configuration *Configuration) (*retryabl
 	return client, nil
 }
 
+// WithCookieJar returns a copy of a client using a new cookie jar. The copy
+// shares the transport of the client so connections are still reused.
+func WithCookieJar(client *retryablehttp.Client) (*retryablehttp.Client, error) {
+	jar, err := newCookieJar()
+	if err != nil {
+		return nil, err
+	}
+	withJar := *client
+	httpClient := *client.HTTPClient
+	httpClient.Jar = jar
+	withJar.HTTPClient = &httpClient
+	if client.HTTPClient2 != nil {
+		httpClient2 := *client.HTTPClient2
+		httpClient2.Jar = jar
+		withJar.HTTPClient2 = &httpClient2
+	}
+	return &withJar, nil
+}
+
+// serverNameKey is the key of a transport sending a tls server name
+type serverNameKey struct {
+	transport  *http.Transport
+	serverName string
+}
+
+// serverNameTransports are the transports of the clients sending a tls
+// server name, kept so their connections are still reused.
+var serverNameTransports sync.Map
+
+// WithServerName returns a copy of a client sending a tls server name
+// different from the host of the requests.
+func WithServerName(client *retryablehttp.Client, serverName string) (*retryablehttp.Client, error) {
+	transport, ok := client.HTTPClient.Transport.(*http.Transport)
+	if !ok {
+		return nil, errors.New("tls server name is not supported with http2 requests")
+	}
+	key := serverNameKey{transport: transport, serverName: serverName}
+	value, ok := serverNameTransports.Load(key)
+	if !ok {
+		clone := transport.Clone()
+		clone.TLSClientConfig.ServerName = serverName
+		value, _ = serverNameTransports.LoadOrStore(key, clone)
+	}
+	withName := *client
+	httpClient := *client.HTTPClient
+	httpClient.Transport = value.(*http.Transport)
+	withName.HTTPClient = &httpClient
+	return &withName, nil
+}
+
+// WithTimeout returns a copy of a client with a different request timeout
+func WithTimeout(client *retryablehttp.Client, timeout time.Duration) *retryablehttp.Client {
+	withTimeout := *client
+	httpClient := *client.HTTPClient
+	httpClient.Timeout = timeout
+	withTimeout.HTTPClient = &httpClient
+	if client.HTTPClient2 != nil {
+		httpClient2 := *client.HTTPClient2
+		httpClient2.Timeout = timeout
+		withTimeout.HTTPClient2 = &httpClient2
+	}
+	return &withTimeout
+}
+
+func newCookieJar() (*cookiejar.Jar, error) {
+	jar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create cookiejar")
+	}
+	return jar, nil
+}
+
 const defaultMaxRedirects = 10
 
 type checkRedirectFunc func(req *http.Request, via []*http.Request) error
@@ -211,16 +341,15 @@ func makeCheckRedirectFunc(followRedirects bool, maxRedirects int) checkRedirect
 			return http.ErrUseLastResponse
 		}
 
-		if maxRedirects == 0 {
-			if len(via) > defaultMaxRedirects {
-				return http.ErrUseLastResponse
-			}
-			return nil
+		limit := maxRedirects
+		if limit == 0 {
+			limit = defaultMaxRedirects
 		}
-
-		if len(via) > maxRedirects {
+		if len(via) > limit {
 			return http.ErrUseLastResponse
 		}
+		// the body of the followed redirect is kept for the matchers
+		captureRedirectBody(req.Response)
 		return nil
 	}
 }
@@ -0,0 +1,48 @@
+package httpclientpool
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/http2"
+)
+
+// http2Transport sends requests over HTTP/2. TLS connections negotiate
+// HTTP/2 with ALPN while cleartext connections use prior knowledge (h2c).
+type http2Transport struct {
+	tls       *http.Transport
+	cleartext *http2.Transport
+}
+
+// newHTTP2Transport returns a HTTP/2 transport based on a http transport
+func newHTTP2Transport(transport *http.Transport) (http.RoundTripper, error) {
+	if err := http2.ConfigureTransport(transport); err != nil {
+		return nil, errors.Wrap(err, "could not configure http2 transport")
+	}
+	return &http2Transport{
+		tls: transport,
+		cleartext: &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+				return transport.DialContext(context.Background(), network, addr)
+			},
+		},
+	}, nil
+}
+
+// RoundTrip sends a request with the transport of its scheme
+func (t *http2Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme == "http" {
+		return t.cleartext.RoundTrip(req)
+	}
+	return t.tls.RoundTrip(req)
+}
+
+// CloseIdleConnections closes the idle connections of the transports
+func (t *http2Transport) CloseIdleConnections() {
+	t.tls.CloseIdleConnections()
+	t.cleartext.CloseIdleConnections()
+}
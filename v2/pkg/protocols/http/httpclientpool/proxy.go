@@ -0,0 +1,77 @@
+package httpclientpool
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// proxies are the proxies the requests are rotated through
+	proxies    []*url.URL
+	proxyIndex uint32
+)
+
+// proxyContextKey is the context key of the proxy of a request
+type proxyContextKey struct{}
+
+// ParseProxy parses a http or socks5 proxy url
+func ParseProxy(value string) (*url.URL, error) {
+	parsed, err := url.Parse(strings.TrimSpace(value))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid proxy %s", value)
+	}
+	switch parsed.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, errors.Errorf("invalid proxy %s, scheme must be http, https or socks5", value)
+	}
+	if parsed.Host == "" {
+		return nil, errors.Errorf("invalid proxy %s, no host provided", value)
+	}
+	return parsed, nil
+}
+
+// setProxies sets the proxies the requests are rotated through
+func setProxies(list []string) error {
+	parsed := make([]*url.URL, 0, len(list))
+	for _, value := range list {
+		proxy, err := ParseProxy(value)
+		if err != nil {
+			return err
+		}
+		parsed = append(parsed, proxy)
+	}
+	proxies = parsed
+	return nil
+}
+
+// NextProxy returns the next proxy of the proxy list in round-robin
+// order, or nil if no proxy list was provided.
+func NextProxy() *url.URL {
+	if len(proxies) == 0 {
+		return nil
+	}
+	index := atomic.AddUint32(&proxyIndex, 1) - 1
+	return proxies[index%uint32(len(proxies))]
+}
+
+// WithProxy returns a context sending the request through a proxy
+func WithProxy(ctx context.Context, proxy *url.URL) context.Context {
+	return context.WithValue(ctx, proxyContextKey{}, proxy)
+}
+
+// proxyFunc returns the proxy of a request, the proxy of its context
+// if any or the default proxy.
+func proxyFunc(defaultProxy *url.URL) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		if proxy, ok := req.Context().Value(proxyContextKey{}).(*url.URL); ok {
+			return proxy, nil
+		}
+		return defaultProxy, nil
+	}
+}
@@ -0,0 +1,38 @@
+package httpclientpool
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// MaxRedirectBodySize is the maximum size of the redirect bodies kept
+const MaxRedirectBodySize = 10 * 1024
+
+// RedirectBody is the body of a followed redirect response. The client
+// discards the redirect bodies, so they are read when the redirect is
+// followed to keep them in the redirect chain of the final response.
+type RedirectBody struct {
+	io.Reader
+	// Data is the body of the redirect, capped to MaxRedirectBodySize
+	Data []byte
+}
+
+// Close implements io.Closer
+func (b *RedirectBody) Close() error {
+	return nil
+}
+
+// captureRedirectBody reads the body of a redirect response being followed
+func captureRedirectBody(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+	if _, ok := resp.Body.(*RedirectBody); ok {
+		return
+	}
+	data, _ := ioutil.ReadAll(io.LimitReader(resp.Body, MaxRedirectBodySize))
+	resp.Body.Close()
+	resp.Body = &RedirectBody{Reader: bytes.NewReader(data), Data: data}
+}
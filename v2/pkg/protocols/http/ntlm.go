@@ -0,0 +1,252 @@
+package http
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf16"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/md4"
+)
+
+const (
+	ntlmNegotiateUnicode                 = 0x00000001
+	ntlmNegotiateOEM                     = 0x00000002
+	ntlmRequestTarget                    = 0x00000004
+	ntlmNegotiateNTLM                    = 0x00000200
+	ntlmNegotiateAlwaysSign              = 0x00008000
+	ntlmNegotiateExtendedSessionSecurity = 0x00080000
+	ntlmNegotiateTargetInfo              = 0x00800000
+	ntlmNegotiate128                     = 0x20000000
+	ntlmNegotiate56                      = 0x80000000
+
+	ntlmNegotiateFlags = ntlmNegotiateUnicode | ntlmNegotiateOEM | ntlmRequestTarget | ntlmNegotiateNTLM |
+		ntlmNegotiateAlwaysSign | ntlmNegotiateExtendedSessionSecurity | ntlmNegotiateTargetInfo |
+		ntlmNegotiate128 | ntlmNegotiate56
+
+	// ntlmAvTimestamp is the AV_PAIR id of the server timestamp
+	ntlmAvTimestamp = 7
+)
+
+var ntlmSignature = []byte("NTLMSSP\x00")
+
+// ntlmChallenge is a parsed NTLM challenge message
+type ntlmChallenge struct {
+	flags      uint32
+	challenge  []byte
+	targetInfo []byte
+}
+
+// ntlmNegotiateMessage returns the NTLM negotiate message
+func ntlmNegotiateMessage() []byte {
+	message := make([]byte, 32)
+	copy(message, ntlmSignature)
+	binary.LittleEndian.PutUint32(message[8:], 1)
+	binary.LittleEndian.PutUint32(message[12:], ntlmNegotiateFlags)
+	return message
+}
+
+// parseNTLMChallenge parses a NTLM challenge message
+func parseNTLMChallenge(data []byte) (*ntlmChallenge, error) {
+	if len(data) < 48 || !bytes.Equal(data[:8], ntlmSignature) || binary.LittleEndian.Uint32(data[8:]) != 2 {
+		return nil, errors.New("invalid ntlm challenge message")
+	}
+	challenge := &ntlmChallenge{
+		flags:     binary.LittleEndian.Uint32(data[20:]),
+		challenge: data[24:32],
+	}
+	length := int(binary.LittleEndian.Uint16(data[40:]))
+	offset := int(binary.LittleEndian.Uint32(data[44:]))
+	if length > 0 {
+		if offset+length > len(data) {
+			return nil, errors.New("invalid ntlm challenge target info")
+		}
+		challenge.targetInfo = data[offset : offset+length]
+	}
+	return challenge, nil
+}
+
+// ntlmAuthenticateMessage returns the NTLMv2 authenticate message for a challenge
+func ntlmAuthenticateMessage(challenge *ntlmChallenge, auth *Auth) ([]byte, error) {
+	clientChallenge := make([]byte, 8)
+	if _, err := rand.Read(clientChallenge); err != nil {
+		return nil, errors.Wrap(err, "could not generate client challenge")
+	}
+	timestamp, serverTimestamp := ntlmTimestamp(challenge.targetInfo)
+
+	hash := ntlmV2Hash(auth.Username, auth.Password, auth.Domain)
+	temp := &bytes.Buffer{}
+	temp.Write([]byte{1, 1, 0, 0, 0, 0, 0, 0})
+	temp.Write(timestamp)
+	temp.Write(clientChallenge)
+	temp.Write([]byte{0, 0, 0, 0})
+	temp.Write(challenge.targetInfo)
+	temp.Write([]byte{0, 0, 0, 0})
+
+	ntResponse := append(hmacMD5(hash, challenge.challenge, temp.Bytes()), temp.Bytes()...)
+	lmResponse := make([]byte, 24)
+	if !serverTimestamp {
+		lmResponse = append(hmacMD5(hash, challenge.challenge, clientChallenge), clientChallenge...)
+	}
+
+	fields := [][]byte{lmResponse, ntResponse, encodeUTF16(auth.Domain), encodeUTF16(auth.Username), nil, nil}
+	message := make([]byte, 64)
+	copy(message, ntlmSignature)
+	binary.LittleEndian.PutUint32(message[8:], 3)
+	offset := len(message)
+	for i, field := range fields {
+		position := 12 + i*8
+		binary.LittleEndian.PutUint16(message[position:], uint16(len(field)))
+		binary.LittleEndian.PutUint16(message[position+2:], uint16(len(field)))
+		binary.LittleEndian.PutUint32(message[position+4:], uint32(offset))
+		offset += len(field)
+	}
+	binary.LittleEndian.PutUint32(message[60:], challenge.flags&ntlmNegotiateFlags)
+	for _, field := range fields {
+		message = append(message, field...)
+	}
+	return message, nil
+}
+
+// ntlmV2Hash returns the NTLMv2 hash for the credentials
+func ntlmV2Hash(username, password, domain string) []byte {
+	hasher := md4.New()
+	_, _ = hasher.Write(encodeUTF16(password))
+	return hmacMD5(hasher.Sum(nil), encodeUTF16(strings.ToUpper(username)+domain))
+}
+
+// ntlmTimestamp returns the server timestamp from the target info if
+// present, otherwise the current time in windows filetime format.
+func ntlmTimestamp(targetInfo []byte) ([]byte, bool) {
+	for i := 0; i+4 <= len(targetInfo); {
+		id := binary.LittleEndian.Uint16(targetInfo[i:])
+		length := int(binary.LittleEndian.Uint16(targetInfo[i+2:]))
+		if id == 0 || i+4+length > len(targetInfo) {
+			break
+		}
+		if id == ntlmAvTimestamp && length == 8 {
+			return targetInfo[i+4 : i+12], true
+		}
+		i += 4 + length
+	}
+	timestamp := make([]byte, 8)
+	// filetime is the number of 100ns intervals since 1601-01-01
+	binary.LittleEndian.PutUint64(timestamp, uint64(time.Now().UnixNano()/100+116444736000000000))
+	return timestamp, false
+}
+
+func hmacMD5(key []byte, data ...[]byte) []byte {
+	mac := hmac.New(md5.New, key)
+	for _, d := range data {
+		_, _ = mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
+
+func encodeUTF16(value string) []byte {
+	encoded := utf16.Encode([]rune(value))
+	data := make([]byte, len(encoded)*2)
+	for i, c := range encoded {
+		binary.LittleEndian.PutUint16(data[i*2:], c)
+	}
+	return data
+}
+
+// ntlmAuthenticateHeader returns the NTLM token of the WWW-Authenticate
+// header of a response, ok is false if the response does not ask for NTLM.
+func ntlmAuthenticateHeader(resp *http.Response) (string, bool) {
+	if resp.StatusCode != http.StatusUnauthorized {
+		return "", false
+	}
+	for _, value := range resp.Header.Values("WWW-Authenticate") {
+		if strings.EqualFold(value, "NTLM") {
+			return "", true
+		}
+		if len(value) > 5 && strings.EqualFold(value[:5], "NTLM ") {
+			return strings.TrimSpace(value[5:]), true
+		}
+	}
+	return "", false
+}
+
+// ntlmHost is the authentication state of the connection to a host
+type ntlmHost struct {
+	sync.Mutex
+	auth Auth
+}
+
+// ntlmHostState returns the state serializing the requests to a host so
+// the handshake of a request is performed on a single connection.
+func (r *Request) ntlmHostState(host string) *ntlmHost {
+	state, _ := r.ntlmHosts.LoadOrStore(host, &ntlmHost{})
+	return state.(*ntlmHost)
+}
+
+// doWithNTLM sends a request performing the NTLM handshake if the server
+// asks for authentication. Connections are kept alive, so requests reusing
+// an authenticated connection are sent without a handshake.
+func (r *Request) doWithNTLM(req *generatedRequest) (*http.Response, error) {
+	state := r.ntlmHostState(req.request.URL.Host)
+	state.Lock()
+	defer state.Unlock()
+
+	// A connection authenticated with other credentials must not be reused
+	if state.auth != *req.auth {
+		req.client.HTTPClient.CloseIdleConnections()
+		state.auth = *req.auth
+	}
+	req.request.Header.Del("Authorization")
+	resp, err := req.client.Do(req.request)
+	if err != nil {
+		return resp, err
+	}
+	if _, ok := ntlmAuthenticateHeader(resp); !ok {
+		return resp, nil
+	}
+	drainResponse(resp)
+
+	req.request.Header.Set("Authorization", "NTLM "+base64.StdEncoding.EncodeToString(ntlmNegotiateMessage()))
+	resp, err = req.client.Do(req.request)
+	if err != nil {
+		return resp, err
+	}
+	token, ok := ntlmAuthenticateHeader(resp)
+	if !ok || token == "" {
+		return resp, nil
+	}
+	drainResponse(resp)
+
+	data, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode ntlm challenge")
+	}
+	challenge, err := parseNTLMChallenge(data)
+	if err != nil {
+		return nil, err
+	}
+	message, err := ntlmAuthenticateMessage(challenge, req.auth)
+	if err != nil {
+		return nil, err
+	}
+	req.request.Header.Set("Authorization", "NTLM "+base64.StdEncoding.EncodeToString(message))
+	return req.client.Do(req.request)
+}
+
+// drainResponse drains and closes the body of a response so the
+// connection can be reused.
+func drainResponse(resp *http.Response) {
+	if resp.Body != nil {
+		_, _ = io.CopyN(ioutil.Discard, resp.Body, drainReqSize)
+		resp.Body.Close()
+	}
+}
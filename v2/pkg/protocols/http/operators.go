@@ -1,6 +1,7 @@
 package http
 
 import (
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
@@ -12,35 +13,35 @@ import (
 )
 
 // Match matches a generic data response again a given matcher
-func (r *Request) Match(data map[string]interface{}, matcher *matchers.Matcher) bool {
+func (r *Request) Match(data map[string]interface{}, matcher *matchers.Matcher) (bool, []string) {
 	item, ok := getMatchPart(matcher.Part, data)
 	if !ok {
-		return false
+		return false, nil
 	}
 
 	switch matcher.GetType() {
 	case matchers.StatusMatcher:
 		statusCode, ok := data["status_code"]
 		if !ok {
-			return false
+			return false, nil
 		}
 		status, ok := statusCode.(int)
 		if !ok {
-			return false
+			return false, nil
 		}
-		return matcher.Result(matcher.MatchStatusCode(status))
+		return matcher.Result(matcher.MatchStatusCode(status)), nil
 	case matchers.SizeMatcher:
-		return matcher.Result(matcher.MatchSize(len(item)))
+		return matcher.Result(matcher.MatchSize(len(item))), nil
 	case matchers.WordsMatcher:
-		return matcher.Result(matcher.MatchWords(item))
+		return matcher.ResultWithMatchedSnippet(matcher.MatchWords(item))
 	case matchers.RegexMatcher:
-		return matcher.Result(matcher.MatchRegex(item))
+		return matcher.ResultWithMatchedSnippet(matcher.MatchRegex(item))
 	case matchers.BinaryMatcher:
-		return matcher.Result(matcher.MatchBinary(item))
+		return matcher.ResultWithMatchedSnippet(matcher.MatchBinary(item))
 	case matchers.DSLMatcher:
-		return matcher.Result(matcher.MatchDSL(data))
+		return matcher.Result(matcher.MatchDSL(data)), nil
 	}
-	return false
+	return false, nil
 }
 
 // Extract performs extracting operation for a extractor on model and returns true or false.
@@ -54,6 +55,10 @@ func (r *Request) Extract(data map[string]interface{}, extractor *extractors.Ext
 		return extractor.ExtractRegex(item)
 	case extractors.KValExtractor:
 		return extractor.ExtractKval(data)
+	case extractors.XPathExtractor:
+		return extractor.ExtractXPath(item, extractors.IsXMLContentType(types.ToString(data["content_type"])))
+	case extractors.JSONExtractor:
+		return extractor.ExtractJSON(item)
 	}
 	return nil
 }
@@ -93,6 +98,7 @@ func (r *Request) responseToDSLMap(resp *http.Response, host, matched, rawReq, r
 	data["response"] = rawResp
 	data["content_length"] = resp.ContentLength
 	data["status_code"] = resp.StatusCode
+	data["proto"] = fmt.Sprintf("HTTP/%d.%d", resp.ProtoMajor, resp.ProtoMinor)
 	data["body"] = body
 	for _, cookie := range resp.Cookies() {
 		data[strings.ToLower(cookie.Name)] = cookie.Value
@@ -134,9 +140,36 @@ func (r *Request) MakeResultEvent(wrapped *output.InternalWrappedEvent) []*outpu
 		data := r.makeResultEventItem(wrapped)
 		results = append(results, data)
 	}
+	for _, result := range results {
+		addDecodedEvidence(result, wrapped.InternalEvent)
+	}
 	return results
 }
 
+// addDecodedEvidence adds the original body regions of the values extracted
+// from the body_decoded part to the result metadata.
+func addDecodedEvidence(result *output.ResultEvent, event output.InternalEvent) {
+	segments, ok := event["body_decoded_segments"].([]decodedSegment)
+	if !ok || len(segments) == 0 || len(result.ExtractedResults) == 0 {
+		return
+	}
+	decoded := types.ToString(event["body_decoded"])
+
+	var regions []string
+	for _, value := range result.ExtractedResults {
+		regions = append(regions, originalRegions(decoded, value, segments)...)
+	}
+	if len(regions) == 0 {
+		return
+	}
+	metadata := make(map[string]interface{}, len(result.Metadata)+1)
+	for k, v := range result.Metadata {
+		metadata[k] = v
+	}
+	metadata["decoded-from"] = regions
+	result.Metadata = metadata
+}
+
 func (r *Request) makeResultEventItem(wrapped *output.InternalWrappedEvent) *output.ResultEvent {
 	data := &output.ResultEvent{
 		TemplateID:       types.ToString(wrapped.InternalEvent["template-id"]),
@@ -147,12 +180,19 @@ func (r *Request) makeResultEventItem(wrapped *output.InternalWrappedEvent) *out
 		Matched:          types.ToString(wrapped.InternalEvent["matched"]),
 		Metadata:         wrapped.OperatorsResult.PayloadValues,
 		ExtractedResults: wrapped.OperatorsResult.OutputExtracts,
+		MatchedSnippets:  wrapped.OperatorsResult.MatchedSnippets,
+		MatchedWords:     wrapped.OperatorsResult.MatchedWords,
+		MatcherNames:     wrapped.OperatorsResult.AndMatches,
+		ExtractedNamed:   wrapped.OperatorsResult.Extracts,
 		Timestamp:        time.Now(),
 		IP:               types.ToString(wrapped.InternalEvent["ip"]),
+		WAF:              types.ToString(wrapped.InternalEvent["waf"]),
+		CDN:              types.ToString(wrapped.InternalEvent["cdn"]),
+		CurlCommand:      types.ToString(wrapped.InternalEvent["curl-command"]),
 	}
 	if r.options.Options.JSONRequests {
-		data.Request = types.ToString(wrapped.InternalEvent["request"])
-		data.Response = types.ToString(wrapped.InternalEvent["response"])
+		data.Request = output.TruncateRR(types.ToString(wrapped.InternalEvent["request"]), r.options.Options.JSONRequestsMaxSize)
+		data.Response = output.TruncateRR(types.ToString(wrapped.InternalEvent["response"]), r.options.Options.JSONRequestsMaxSize)
 	}
 	return data
 }
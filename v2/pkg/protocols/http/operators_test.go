@@ -38,7 +38,7 @@ func TestResponseToDSLMap(t *testing.T) {
 	matched := "http://example.com/test/?test=1"
 
 	event := request.responseToDSLMap(resp, host, matched, exampleRawRequest, exampleRawResponse, exampleResponseBody, exampleResponseHeader, 1*time.Second, map[string]interface{}{})
-	require.Len(t, event, 13, "could not get correct number of items in dsl map")
+	require.Len(t, event, 14, "could not get correct number of items in dsl map")
 	require.Equal(t, exampleRawResponse, event["response"], "could not get correct resp")
 	require.Equal(t, "Test-Response", event["test"], "could not get correct resp for header")
 }
@@ -68,7 +68,7 @@ func TestHTTPOperatorMatch(t *testing.T) {
 	matched := "http://example.com/test/?test=1"
 
 	event := request.responseToDSLMap(resp, host, matched, exampleRawRequest, exampleRawResponse, exampleResponseBody, exampleResponseHeader, 1*time.Second, map[string]interface{}{})
-	require.Len(t, event, 13, "could not get correct number of items in dsl map")
+	require.Len(t, event, 14, "could not get correct number of items in dsl map")
 	require.Equal(t, exampleRawResponse, event["response"], "could not get correct resp")
 	require.Equal(t, "Test-Response", event["test"], "could not get correct resp for header")
 
@@ -81,7 +81,7 @@ func TestHTTPOperatorMatch(t *testing.T) {
 		err = matcher.CompileMatchers()
 		require.Nil(t, err, "could not compile matcher")
 
-		matched := request.Match(event, matcher)
+		matched, _ := request.Match(event, matcher)
 		require.True(t, matched, "could not match valid response")
 	})
 
@@ -95,7 +95,7 @@ func TestHTTPOperatorMatch(t *testing.T) {
 		err := matcher.CompileMatchers()
 		require.Nil(t, err, "could not compile negative matcher")
 
-		matched := request.Match(event, matcher)
+		matched, _ := request.Match(event, matcher)
 		require.True(t, matched, "could not match valid negative response matcher")
 	})
 
@@ -108,9 +108,41 @@ func TestHTTPOperatorMatch(t *testing.T) {
 		err := matcher.CompileMatchers()
 		require.Nil(t, err, "could not compile matcher")
 
-		matched := request.Match(event, matcher)
+		matched, _ := request.Match(event, matcher)
 		require.False(t, matched, "could match invalid response matcher")
 	})
+
+	t.Run("condition", func(t *testing.T) {
+		tests := []struct {
+			part      string
+			condition string
+			words     []string
+			matched   bool
+			snippets  []string
+		}{
+			{"body", "and", []string{"Example Domain", "1.1.1.1"}, true, []string{"Example Domain", "1.1.1.1"}},
+			{"body", "and", []string{"Example Domain", "random"}, false, nil},
+			{"body", "or", []string{"random", "1.1.1.1"}, true, []string{"1.1.1.1"}},
+			{"header", "and", []string{"ECS (nyb/1D1C)", "X-Cache: HIT"}, true, []string{"ECS (nyb/1D1C)", "X-Cache: HIT"}},
+			{"header", "and", []string{"X-Cache: HIT", "1.1.1.1"}, false, nil},
+			{"all", "and", []string{"X-Cache: HIT", "1.1.1.1"}, true, []string{"X-Cache: HIT", "1.1.1.1"}},
+			{"all", "", []string{"random", "X-Cache: HIT"}, true, []string{"X-Cache: HIT"}},
+		}
+		for _, test := range tests {
+			matcher := &matchers.Matcher{
+				Part:      test.part,
+				Type:      "word",
+				Condition: test.condition,
+				Words:     test.words,
+			}
+			err := matcher.CompileMatchers()
+			require.Nil(t, err, "could not compile matcher")
+
+			matched, snippets := request.Match(event, matcher)
+			require.Equal(t, test.matched, matched, "could not get correct %s match for %s %v", test.condition, test.part, test.words)
+			require.Equal(t, test.snippets, snippets, "could not get correct matched snippets for %s %v", test.part, test.words)
+		}
+	})
 }
 
 func TestHTTPOperatorExtract(t *testing.T) {
@@ -138,7 +170,7 @@ func TestHTTPOperatorExtract(t *testing.T) {
 	matched := "http://example.com/test/?test=1"
 
 	event := request.responseToDSLMap(resp, host, matched, exampleRawRequest, exampleRawResponse, exampleResponseBody, exampleResponseHeader, 1*time.Second, map[string]interface{}{})
-	require.Len(t, event, 13, "could not get correct number of items in dsl map")
+	require.Len(t, event, 14, "could not get correct number of items in dsl map")
 	require.Equal(t, exampleRawResponse, event["response"], "could not get correct resp")
 	require.Equal(t, "Test-Response", event["test_header"], "could not get correct resp for header")
 
@@ -168,6 +200,22 @@ func TestHTTPOperatorExtract(t *testing.T) {
 		require.Greater(t, len(data), 0, "could not extractor kval valid response")
 		require.Equal(t, map[string]struct{}{"Test-Response": {}}, data, "could not extract correct kval data")
 	})
+
+	t.Run("xpath", func(t *testing.T) {
+		extractor := &extractors.Extractor{
+			Type:  "xpath",
+			XPath: []string{"//soap:Envelope/soap:Body/version"},
+		}
+		err = extractor.CompileExtractors()
+		require.Nil(t, err, "could not compile xpath extractor")
+
+		xmlEvent := map[string]interface{}{
+			"body":         `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><version>2.1</version></soap:Body></soap:Envelope>`,
+			"content_type": "text/xml; charset=utf-8",
+		}
+		data := request.Extract(xmlEvent, extractor)
+		require.Equal(t, map[string]struct{}{"2.1": {}}, data, "could not extract correct xml data")
+	})
 }
 
 func TestHTTPMakeResult(t *testing.T) {
@@ -208,7 +256,7 @@ func TestHTTPMakeResult(t *testing.T) {
 	matched := "http://example.com/test/?test=1"
 
 	event := request.responseToDSLMap(resp, host, matched, exampleRawRequest, exampleRawResponse, exampleResponseBody, exampleResponseHeader, 1*time.Second, map[string]interface{}{})
-	require.Len(t, event, 13, "could not get correct number of items in dsl map")
+	require.Len(t, event, 14, "could not get correct number of items in dsl map")
 	require.Equal(t, exampleRawResponse, event["response"], "could not get correct resp")
 	require.Equal(t, "Test-Response", event["test"], "could not get correct resp for header")
 
@@ -226,6 +274,43 @@ func TestHTTPMakeResult(t *testing.T) {
 	require.Equal(t, "1.1.1.1", finalEvent.Results[0].ExtractedResults[0], "could not get correct extracted results")
 }
 
+func TestHTTPMakeResultAndCondition(t *testing.T) {
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-http-and"
+	request := &Request{
+		ID:     templateID,
+		Name:   "testing",
+		Path:   []string{"{{BaseURL}}?test=1"},
+		Method: "GET",
+		Operators: operators.Operators{
+			MatchersCondition: "and",
+			Matchers: []*matchers.Matcher{
+				{Name: "ip", Part: "body", Type: "word", Words: []string{"1.1.1.1"}},
+				{Name: "title", Part: "body", Type: "word", Words: []string{"Example Domain"}},
+			},
+		},
+	}
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	err := request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile http request")
+
+	resp := &http.Response{Header: make(http.Header)}
+	event := request.responseToDSLMap(resp, "http://example.com/test/", "http://example.com/test/?test=1", exampleRawRequest, exampleRawResponse, exampleResponseBody, exampleResponseHeader, 1*time.Second, map[string]interface{}{})
+
+	result, ok := request.CompiledOperators.Execute(event, request.Match, request.Extract)
+	require.True(t, ok, "could not match and condition")
+	finalEvent := &output.InternalWrappedEvent{InternalEvent: event, OperatorsResult: result}
+	results := request.MakeResultEvent(finalEvent)
+	require.Len(t, results, 1, "could not get a single result for and condition")
+	require.Empty(t, results[0].MatcherName, "could not get empty matcher name")
+	require.Equal(t, []string{"ip", "title"}, results[0].MatcherNames, "could not get and matcher names")
+}
+
 const exampleRawRequest = `GET / HTTP/1.1
 Host: example.com
 Upgrade-Insecure-Requests: 1
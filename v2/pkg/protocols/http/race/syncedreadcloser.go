@@ -19,6 +19,13 @@ type SyncedReadCloser struct {
 
 // NewSyncedReadCloser creates a new SyncedReadCloser instance.
 func NewSyncedReadCloser(r io.ReadCloser) *SyncedReadCloser {
+	return NewSyncedReadCloserWithGate(r, make(chan struct{}))
+}
+
+// NewSyncedReadCloserWithGate creates a new SyncedReadCloser instance
+// blocking on a gate shared with other instances. The gate is opened by
+// closing it, releasing all the instances at once.
+func NewSyncedReadCloserWithGate(r io.ReadCloser, gate chan struct{}) *SyncedReadCloser {
 	var (
 		s   SyncedReadCloser
 		err error
@@ -29,7 +36,7 @@ func NewSyncedReadCloser(r io.ReadCloser) *SyncedReadCloser {
 	}
 	r.Close()
 	s.length = int64(len(s.data))
-	s.opengate = make(chan struct{})
+	s.opengate = gate
 	s.enableBlocking = true
 	return &s
 }
@@ -48,14 +55,12 @@ func (s *SyncedReadCloser) SetOpenGate(status bool) {
 
 // OpenGate opens the gate allowing all requests to be completed
 func (s *SyncedReadCloser) OpenGate() {
-	s.opengate <- struct{}{}
+	close(s.opengate)
 }
 
 // OpenGateAfter schedules gate to be opened after a duration
 func (s *SyncedReadCloser) OpenGateAfter(d time.Duration) {
-	time.AfterFunc(d, func() {
-		s.opengate <- struct{}{}
-	})
+	time.AfterFunc(d, s.OpenGate)
 }
 
 // Seek implements seek method for io.ReadSeeker
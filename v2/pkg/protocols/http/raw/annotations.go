@@ -0,0 +1,75 @@
+package raw
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Annotations are the settings of a raw request given with @name: value
+// lines at the top of the request. They are removed from the request sent.
+type Annotations struct {
+	// Host overrides the target the request is sent to, either a host
+	// with an optional port or an url with a scheme.
+	Host string
+	// Timeout overrides the timeout of the request
+	Timeout time.Duration
+	// SNI is the tls server name sent to the target
+	SNI string
+}
+
+// parseAnnotations parses the annotations at the top of a raw request and
+// returns the request without them.
+func parseAnnotations(request string) (Annotations, string, error) {
+	annotations := Annotations{}
+	for strings.HasPrefix(request, "@") {
+		line := request
+		request = ""
+		if end := strings.IndexByte(line, '\n'); end != -1 {
+			line, request = line[:end], line[end+1:]
+		}
+		parts := strings.SplitN(strings.TrimSpace(line[1:]), ":", 2)
+		if len(parts) != 2 {
+			return annotations, "", fmt.Errorf("malformed annotation %s", strings.TrimSpace(line))
+		}
+		name, value := strings.ToLower(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1])
+		switch name {
+		case "host":
+			annotations.Host = value
+		case "timeout":
+			timeout, err := time.ParseDuration(value)
+			if err != nil {
+				return annotations, "", fmt.Errorf("could not parse timeout annotation: %s", err)
+			}
+			annotations.Timeout = timeout
+		case "tls-sni":
+			annotations.SNI = value
+		default:
+			return annotations, "", fmt.Errorf("unknown annotation %s", name)
+		}
+	}
+	return annotations, request, nil
+}
+
+// applyHost sends the request to the host of the annotations, keeping the
+// scheme of the request if the annotation doesn't contain one.
+func (r *Request) applyHost() error {
+	if r.Annotations.Host == "" {
+		return nil
+	}
+	parsed, err := url.Parse(r.FullURL)
+	if err != nil {
+		return fmt.Errorf("could not parse request URL: %s", err)
+	}
+	scheme, host := parsed.Scheme, r.Annotations.Host
+	if strings.Contains(host, "://") {
+		target, err := url.Parse(host)
+		if err != nil {
+			return fmt.Errorf("could not parse host annotation: %s", err)
+		}
+		scheme, host = target.Scheme, target.Host
+	}
+	r.FullURL = fmt.Sprintf("%s://%s%s", scheme, host, r.Path)
+	return nil
+}
@@ -18,20 +18,24 @@ type Request struct {
 	Path           string
 	Data           string
 	Headers        map[string]string
+	PseudoHeaders  map[string]string
 	UnsafeHeaders  client.Headers
 	UnsafeRawBytes []byte
+	Annotations    Annotations
 }
 
 // Parse parses the raw request as supplied by the user
 func Parse(request, baseURL string, unsafe bool) (*Request, error) {
-	rawRequest := &Request{
-		Headers: make(map[string]string),
+	annotations, request, err := parseAnnotations(request)
+	if err != nil {
+		return nil, err
 	}
 	if unsafe {
-		request = strings.ReplaceAll(request, "\\0", "\x00")
-		request = strings.ReplaceAll(request, "\\r", "\r")
-		request = strings.ReplaceAll(request, "\\n", "\n")
-		rawRequest.UnsafeRawBytes = []byte(request)
+		return parseUnsafe(request, baseURL, annotations)
+	}
+	rawRequest := &Request{
+		Headers:     make(map[string]string),
+		Annotations: annotations,
 	}
 	reader := bufio.NewReader(strings.NewReader(request))
 	s, err := reader.ReadString('\n')
@@ -40,7 +44,7 @@ func Parse(request, baseURL string, unsafe bool) (*Request, error) {
 	}
 
 	parts := strings.Split(s, " ")
-	if len(parts) < 3 && !unsafe {
+	if len(parts) < 3 {
 		return nil, fmt.Errorf("malformed request supplied")
 	}
 	// Set the request Method
@@ -59,6 +63,20 @@ func Parse(request, baseURL string, unsafe bool) (*Request, error) {
 			}
 		}
 
+		// HTTP/2 pseudo-headers (:authority etc.) are kept separately
+		if strings.HasPrefix(line, ":") {
+			if p := strings.SplitN(line[1:], ":", 2); len(p) == 2 {
+				if rawRequest.PseudoHeaders == nil {
+					rawRequest.PseudoHeaders = make(map[string]string)
+				}
+				rawRequest.PseudoHeaders[strings.ToLower(p[0])] = strings.TrimSpace(p[1])
+			}
+			if readErr == io.EOF {
+				break
+			}
+			continue
+		}
+
 		p := strings.SplitN(line, ":", 2)
 		key = p[0]
 		if len(p) > 1 {
@@ -68,18 +86,7 @@ func Parse(request, baseURL string, unsafe bool) (*Request, error) {
 			mutlipartRequest = true
 		}
 
-		// in case of unsafe requests multiple headers should be accepted
-		// therefore use the full line as key
-		_, found := rawRequest.Headers[key]
-		if unsafe {
-			rawRequest.UnsafeHeaders = append(rawRequest.UnsafeHeaders, client.Header{Key: line})
-		}
-
-		if unsafe && found {
-			rawRequest.Headers[line] = ""
-		} else {
-			rawRequest.Headers[key] = strings.TrimSpace(value)
-		}
+		rawRequest.Headers[key] = strings.TrimSpace(value)
 		if readErr == io.EOF {
 			break
 		}
@@ -87,7 +94,7 @@ func Parse(request, baseURL string, unsafe bool) (*Request, error) {
 
 	// Handle case with the full http url in path. In that case,
 	// ignore any host header that we encounter and use the path as request URL
-	if !unsafe && strings.HasPrefix(parts[1], "http") {
+	if strings.HasPrefix(parts[1], "http") {
 		parsed, parseErr := url.Parse(parts[1])
 		if parseErr != nil {
 			return nil, fmt.Errorf("could not parse request URL: %s", parseErr)
@@ -95,29 +102,23 @@ func Parse(request, baseURL string, unsafe bool) (*Request, error) {
 
 		rawRequest.Path = parts[1]
 		rawRequest.Headers["Host"] = parsed.Host
-	} else if len(parts) > 1 {
+	} else {
 		rawRequest.Path = parts[1]
 	}
 
-	parsedURL, err := url.Parse(baseURL)
+	hostURL, err := rawRequest.buildURL(baseURL, rawRequest.Headers["Host"])
 	if err != nil {
-		return nil, fmt.Errorf("could not parse request URL: %s", err)
-	}
-	hostURL := parsedURL.Host
-	if strings.HasSuffix(parsedURL.Path, "/") && strings.HasPrefix(rawRequest.Path, "/") {
-		parsedURL.Path = strings.TrimSuffix(parsedURL.Path, "/")
-	}
-	rawRequest.Path = fmt.Sprintf("%s%s", parsedURL.Path, rawRequest.Path)
-	if strings.HasSuffix(rawRequest.Path, "//") {
-		rawRequest.Path = strings.TrimSuffix(rawRequest.Path, "/")
+		return nil, err
 	}
-	rawRequest.FullURL = fmt.Sprintf("%s://%s%s", parsedURL.Scheme, strings.TrimSpace(hostURL), rawRequest.Path)
 
 	// If raw request doesn't have a Host header
 	// this will be generated from the parsed baseURL
 	if rawRequest.Headers["Host"] == "" {
 		rawRequest.Headers["Host"] = hostURL
 	}
+	if err := rawRequest.applyHost(); err != nil {
+		return nil, err
+	}
 
 	// Set the request body
 	b, err := ioutil.ReadAll(reader)
@@ -130,3 +131,77 @@ func Parse(request, baseURL string, unsafe bool) (*Request, error) {
 	}
 	return rawRequest, nil
 }
+
+// parseUnsafe parses an unsafe raw request. Its bytes are sent verbatim, so
+// the request line and the headers are only read to build the request url
+// and nothing is normalized, deduplicated or added to the request.
+func parseUnsafe(request, baseURL string, annotations Annotations) (*Request, error) {
+	request = strings.ReplaceAll(request, "\\0", "\x00")
+	request = strings.ReplaceAll(request, "\\r", "\r")
+	request = strings.ReplaceAll(request, "\\n", "\n")
+	rawRequest := &Request{
+		Headers:        make(map[string]string),
+		UnsafeRawBytes: []byte(request),
+		Annotations:    annotations,
+	}
+
+	lines := strings.SplitAfter(request, "\n")
+	if parts := strings.Fields(lines[0]); len(parts) > 0 {
+		rawRequest.Method = parts[0]
+		if len(parts) > 1 {
+			rawRequest.Path = parts[1]
+		}
+	}
+
+	var host string
+	i := 1
+	for ; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r\n")
+		if line == "" {
+			i++
+			break
+		}
+		rawRequest.UnsafeHeaders = append(rawRequest.UnsafeHeaders, client.Header{Key: line})
+		if p := strings.SplitN(line, ":", 2); len(p) == 2 && host == "" && strings.EqualFold(strings.TrimSpace(p[0]), "Host") {
+			host = strings.TrimSpace(p[1])
+		}
+	}
+	rawRequest.Data = strings.Join(lines[i:], "")
+
+	if _, err := rawRequest.buildURL(baseURL, host); err != nil {
+		return nil, err
+	}
+	if err := rawRequest.applyHost(); err != nil {
+		return nil, err
+	}
+	return rawRequest, nil
+}
+
+// buildURL builds the full url of the request from the base url and returns
+// the host of the url. Requests without a base url target the url of the
+// request line, or the host header if the request line only contains a path.
+func (r *Request) buildURL(baseURL, host string) (string, error) {
+	if baseURL == "" {
+		if parsed, parseErr := url.Parse(r.Path); parseErr == nil && parsed.IsAbs() {
+			baseURL = parsed.Scheme + "://" + parsed.Host
+			r.Path = parsed.RequestURI()
+		} else {
+			baseURL = "http://" + host
+		}
+	}
+
+	parsedURL, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("could not parse request URL: %s", err)
+	}
+	hostURL := parsedURL.Host
+	if strings.HasSuffix(parsedURL.Path, "/") && strings.HasPrefix(r.Path, "/") {
+		parsedURL.Path = strings.TrimSuffix(parsedURL.Path, "/")
+	}
+	r.Path = fmt.Sprintf("%s%s", parsedURL.Path, r.Path)
+	if strings.HasSuffix(r.Path, "//") {
+		r.Path = strings.TrimSuffix(r.Path, "/")
+	}
+	r.FullURL = fmt.Sprintf("%s://%s%s", parsedURL.Scheme, strings.TrimSpace(hostURL), r.Path)
+	return hostURL, nil
+}
@@ -2,6 +2,7 @@ package raw
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -64,3 +65,64 @@ username=admin&password=login`, "https://test.com", false)
 	require.Equal(t, "POST", request.Method, "Could not parse POST method request correctly")
 	require.Equal(t, "username=admin&password=login", request.Data, "Could not parse request data correctly")
 }
+
+func TestParseRawRequestPseudoHeaders(t *testing.T) {
+	request, err := Parse(`GET /api HTTP/2
+:authority: internal.test.com
+:path: /grpc.health.v1.Health/Check
+Content-Type: application/grpc`, "https://test.com", false)
+	require.Nil(t, err, "could not parse http2 request")
+	require.Equal(t, map[string]string{"authority": "internal.test.com", "path": "/grpc.health.v1.Health/Check"}, request.PseudoHeaders, "could not parse pseudo-headers")
+	require.Equal(t, "application/grpc", request.Headers["Content-Type"], "could not parse headers with pseudo-headers")
+	require.NotContains(t, request.Headers, "", "added pseudo-headers as headers")
+}
+
+func TestParseRawRequestWithoutBaseURL(t *testing.T) {
+	request, err := Parse(`GET https://metadata.test.com/latest/meta-data?format=json HTTP/1.1
+Accept: */*`, "", false)
+	require.Nil(t, err, "could not parse request with absolute url")
+	require.Equal(t, "https://metadata.test.com/latest/meta-data?format=json", request.FullURL, "could not get url from request line")
+	require.Equal(t, "metadata.test.com", request.Headers["Host"], "could not get host from request line")
+
+	request, err = Parse(`GET /status HTTP/1.1
+Host: status.test.com`, "", false)
+	require.Nil(t, err, "could not parse request with host header")
+	require.Equal(t, "http://status.test.com/status", request.FullURL, "could not get url from host header")
+}
+
+func TestParseUnsafeRawRequest(t *testing.T) {
+	data := "POST /upload HTTP/1.1\r\n  X-Leading: space\r\nX-Dup: one\r\nX-Dup: one\r\nContent-Length : 4\r\n\r\nbody\r\n"
+	request, err := Parse(data, "http://example.com", true)
+	require.Nil(t, err, "could not parse unsafe request")
+	require.Equal(t, []byte(data), request.UnsafeRawBytes, "could not preserve unsafe request bytes")
+	require.Equal(t, "POST", request.Method, "could not parse unsafe request method")
+	require.Equal(t, "http://example.com/upload", request.FullURL, "could not parse unsafe request url")
+	require.Len(t, request.UnsafeHeaders, 4, "could not keep unsafe header lines")
+	require.Equal(t, "  X-Leading: space", request.UnsafeHeaders[0].Key, "could not keep unsafe header whitespace")
+	require.Empty(t, request.Headers, "added headers to unsafe request")
+	require.Equal(t, "body\r\n", request.Data, "could not keep unsafe request body")
+}
+
+func TestParseRawRequestAnnotations(t *testing.T) {
+	data := "@Host: {{target}}:8443\r\n@timeout: 20s\r\n@tls-sni: {{sni}}\r\nGET /admin?user={{username}} HTTP/1.1\r\nHost: {{Hostname}}\r\n\r\n"
+
+	request, err := Parse(data, "https://example.com", false)
+	require.Nil(t, err, "could not parse annotated request")
+	require.Equal(t, Annotations{Host: "{{target}}:8443", Timeout: 20 * time.Second, SNI: "{{sni}}"}, request.Annotations, "could not parse annotations")
+	require.Equal(t, "GET", request.Method, "could not parse method after annotations")
+	require.Equal(t, "https://{{target}}:8443/admin?user={{username}}", request.FullURL, "could not send request to annotation host")
+	require.Equal(t, "{{Hostname}}", request.Headers["Host"], "could not keep host header")
+
+	request, err = Parse(data, "https://example.com", true)
+	require.Nil(t, err, "could not parse annotated unsafe request")
+	require.Equal(t, "GET /admin?user={{username}} HTTP/1.1\r\nHost: {{Hostname}}\r\n\r\n", string(request.UnsafeRawBytes), "could not strip annotations")
+
+	request, err = Parse("@Host: http://internal.test:8080\nGET / HTTP/1.1\nHost: example.com\n\n", "https://example.com", false)
+	require.Nil(t, err, "could not parse request with url host annotation")
+	require.Equal(t, "http://internal.test:8080/", request.FullURL, "could not use scheme of host annotation")
+
+	_, err = Parse("@timeout: soon\r\nGET / HTTP/1.1\r\n\r\n", "https://example.com", false)
+	require.NotNil(t, err, "parsed invalid timeout annotation")
+	_, err = Parse("@retries: 3\r\nGET / HTTP/1.1\r\n\r\n", "https://example.com", false)
+	require.NotNil(t, err, "parsed unknown annotation")
+}
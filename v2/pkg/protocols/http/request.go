@@ -2,12 +2,15 @@ package http
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -18,27 +21,34 @@ import (
 	"github.com/yaklang/nuclei/v2/pkg/protocols"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/common/generators"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/common/interactsh"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/tlsconfig"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/common/tostring"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/http/httpclientpool"
+	"github.com/yaklang/nuclei/v2/pkg/types"
 	"github.com/projectdiscovery/rawhttp"
+	"github.com/projectdiscovery/retryablehttp-go"
 	"github.com/remeh/sizedwaitgroup"
 	"go.uber.org/multierr"
 )
 
-const defaultMaxWorkers = 150
+// raceGateDelay is the delay for the race requests to be sent before their
+// bodies are released.
+const raceGateDelay = 2 * time.Second
 
 // executeRaceRequest executes race condition request for a URL
-func (r *Request) executeRaceRequest(reqURL string, previous output.InternalEvent, callback protocols.OutputEventCallback) error {
+func (r *Request) executeRaceRequest(reqURL string, client *retryablehttp.Client, previous output.InternalEvent, callback protocols.OutputEventCallback) error {
 	var requests []*generatedRequest
 
 	// Requests within race condition should be dumped once and the output prefilled to allow DSL language to work
 	// This will introduce a delay and will populate in hacky way the field "request" of outputEvent
 	generator := r.newGenerator()
+	// The request for dump is never sent so its body gate is already open
+	generator.raceGate = make(chan struct{})
+	close(generator.raceGate)
 	requestForDump, err := generator.Make(reqURL, nil, "")
 	if err != nil {
 		return err
 	}
-	r.setCustomHeaders(requestForDump)
 	dumpedRequest, err := dump(requestForDump, reqURL)
 	if err != nil {
 		return err
@@ -49,62 +59,101 @@ func (r *Request) executeRaceRequest(reqURL string, previous output.InternalEven
 	}
 	previous["request"] = string(dumpedRequest)
 
-	// Pre-Generate requests
+	// Pre-Generate requests, their bodies are all gated by the same gate
+	gate := make(chan struct{})
 	for i := 0; i < r.RaceNumberRequests; i++ {
 		generator := r.newGenerator()
+		generator.client = client
+		generator.raceGate = gate
 		request, err := generator.Make(reqURL, nil, "")
 		if err != nil {
+			close(gate)
 			return err
 		}
 		requests = append(requests, request)
 	}
 
+	// The requests are not rate limited so they are sent at the same time
 	wg := sync.WaitGroup{}
 	var requestErr error
 	mutex := &sync.Mutex{}
 	for i := 0; i < r.RaceNumberRequests; i++ {
 		wg.Add(1)
-		go func(httpRequest *generatedRequest) {
+		go func(httpRequest *generatedRequest, requestNumber int) {
 			defer wg.Done()
-			err := r.executeRequest(reqURL, httpRequest, previous, callback, 0)
+			err := r.executeRequest(reqURL, httpRequest, previous, callback, requestNumber)
 			mutex.Lock()
 			if err != nil {
 				requestErr = multierr.Append(requestErr, err)
 			}
 			mutex.Unlock()
-		}(requests[i])
+		}(requests[i], i+1)
 		r.options.Progress.IncrementRequests()
 	}
+	time.AfterFunc(raceGateDelay, func() { close(gate) })
 	wg.Wait()
 
 	return requestErr
 }
 
 // executeRaceRequest executes parallel requests for a template
-func (r *Request) executeParallelHTTP(reqURL string, dynamicValues, previous output.InternalEvent, callback protocols.OutputEventCallback) error {
+func (r *Request) executeParallelHTTP(reqURL string, client *retryablehttp.Client, dynamicValues, previous output.InternalEvent, callback protocols.OutputEventCallback) error {
 	generator := r.newGenerator()
+	generator.client = client
 
 	// Workers that keeps enqueuing new requests
 	maxWorkers := r.Threads
 	swg := sizedwaitgroup.New(maxWorkers)
 
 	var requestErr error
+	var gotMatches, baselineSent bool
 	mutex := &sync.Mutex{}
 	for {
+		mutex.Lock()
+		stop := gotMatches && r.stopAtFirstMatch()
+		mutex.Unlock()
+		if stop {
+			r.options.Progress.AddToTotal(-int64(generator.Remaining()))
+			break
+		}
 		request, err := generator.Make(reqURL, dynamicValues, "")
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			r.options.Progress.IncrementFailedRequestsBy(int64(generator.Total()))
+			r.options.Progress.IncrementFailedRequestsBy(int64(generator.Remaining()))
 			return err
 		}
+		// The baseline is sent alone before the other requests
+		if r.Baseline && !baselineSent {
+			baselineSent = true
+			r.options.RateLimiter.Take()
+			err := r.executeRequest(reqURL, request, previous, func(event *output.InternalWrappedEvent) {
+				if event.OperatorsResult != nil && event.OperatorsResult.Matched {
+					gotMatches = true
+				}
+				dynamicValues = r.setBaselineDuration(event, dynamicValues, previous)
+				callback(event)
+			}, 0)
+			if err != nil {
+				requestErr = multierr.Append(requestErr, err)
+			}
+			r.options.Progress.IncrementRequests()
+			continue
+		}
 		swg.Add()
 		go func(httpRequest *generatedRequest) {
 			defer swg.Done()
 
 			r.options.RateLimiter.Take()
-			err := r.executeRequest(reqURL, httpRequest, previous, callback, 0)
+			err := r.executeRequest(reqURL, httpRequest, previous, func(event *output.InternalWrappedEvent) {
+				if event.OperatorsResult != nil && event.OperatorsResult.Matched {
+					mutex.Lock()
+					gotMatches = true
+					mutex.Unlock()
+				}
+				callback(event)
+			}, 0)
 			mutex.Lock()
 			if err != nil {
 				requestErr = multierr.Append(requestErr, err)
@@ -121,40 +170,33 @@ func (r *Request) executeParallelHTTP(reqURL string, dynamicValues, previous out
 func (r *Request) executeTurboHTTP(reqURL string, dynamicValues, previous output.InternalEvent, callback protocols.OutputEventCallback) error {
 	generator := r.newGenerator()
 
-	// need to extract the target from the url
-	URL, err := url.Parse(reqURL)
+	pipeclient, pipeOptions, err := r.newPipelineClient(reqURL)
 	if err != nil {
 		return err
 	}
 
-	pipeOptions := rawhttp.DefaultPipelineOptions
-	pipeOptions.Host = URL.Host
-	pipeOptions.MaxConnections = 1
-	if r.PipelineConcurrentConnections > 0 {
-		pipeOptions.MaxConnections = r.PipelineConcurrentConnections
-	}
-	if r.PipelineRequestsPerConnection > 0 {
-		pipeOptions.MaxPendingRequests = r.PipelineRequestsPerConnection
-	}
-	pipeclient := rawhttp.NewPipelineClient(pipeOptions)
-
-	// defaultMaxWorkers should be a sufficient value to keep queues always full
-	maxWorkers := defaultMaxWorkers
-	// in case the queue is bigger increase the workers
-	if pipeOptions.MaxPendingRequests > maxWorkers {
-		maxWorkers = pipeOptions.MaxPendingRequests
-	}
+	// Enough workers are used to keep the queues of the connections full
+	// without overflowing them.
+	maxWorkers := pipeOptions.MaxConnections * pipeOptions.MaxPendingRequests
 	swg := sizedwaitgroup.New(maxWorkers)
 
 	var requestErr error
+	var gotMatches bool
 	mutex := &sync.Mutex{}
 	for {
+		mutex.Lock()
+		stop := gotMatches && r.stopAtFirstMatch()
+		mutex.Unlock()
+		if stop {
+			r.options.Progress.AddToTotal(-int64(generator.Remaining()))
+			break
+		}
 		request, err := generator.Make(reqURL, dynamicValues, "")
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			r.options.Progress.IncrementFailedRequestsBy(int64(generator.Total()))
+			r.options.Progress.IncrementFailedRequestsBy(int64(generator.Remaining()))
 			return err
 		}
 		request.pipelinedClient = pipeclient
@@ -163,7 +205,14 @@ func (r *Request) executeTurboHTTP(reqURL string, dynamicValues, previous output
 		go func(httpRequest *generatedRequest) {
 			defer swg.Done()
 
-			err := r.executeRequest(reqURL, httpRequest, previous, callback, 0)
+			err := r.executeRequest(reqURL, httpRequest, previous, func(event *output.InternalWrappedEvent) {
+				if event.OperatorsResult != nil && event.OperatorsResult.Matched {
+					mutex.Lock()
+					gotMatches = true
+					mutex.Unlock()
+				}
+				callback(event)
+			}, 0)
 			mutex.Lock()
 			if err != nil {
 				requestErr = multierr.Append(requestErr, err)
@@ -176,6 +225,45 @@ func (r *Request) executeTurboHTTP(reqURL string, dynamicValues, previous output
 	return requestErr
 }
 
+// newPipelineClient creates the pipelined client for a URL. Connections are
+// dialed with the dialer of nuclei, performing the tls handshake for https.
+func (r *Request) newPipelineClient(reqURL string) (*rawhttp.PipelineClient, rawhttp.PipelineOptions, error) {
+	parsed, err := url.Parse(reqURL)
+	if err != nil {
+		return nil, rawhttp.PipelineOptions{}, errors.Wrap(err, "could not parse url")
+	}
+	isTLS := strings.EqualFold(parsed.Scheme, "https")
+	address := parsed.Host
+	if parsed.Port() == "" {
+		port := "80"
+		if isTLS {
+			port = "443"
+		}
+		address = net.JoinHostPort(parsed.Hostname(), port)
+	}
+
+	timeout := r.options.Options.ProtocolTimeout(types.HTTPProtocol)
+	pipeOptions := rawhttp.DefaultPipelineOptions
+	pipeOptions.Host = address
+	pipeOptions.Timeout = timeout
+	pipeOptions.MaxConnections = 1
+	if r.PipelineConcurrentConnections > 0 {
+		pipeOptions.MaxConnections = r.PipelineConcurrentConnections
+	}
+	if r.PipelineRequestsPerConnection > 0 {
+		pipeOptions.MaxPendingRequests = r.PipelineRequestsPerConnection
+	}
+	pipeOptions.Dialer = func(addr string) (net.Conn, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if isTLS {
+			return tlsconfig.DialTLS(ctx, httpclientpool.Dialer, "tcp", addr, r.clientCertificate(), nil)
+		}
+		return httpclientpool.Dialer.Dial(ctx, "tcp", addr)
+	}
+	return rawhttp.NewPipelineClient(pipeOptions), pipeOptions, nil
+}
+
 // ExecuteWithResults executes the final request on a URL
 func (r *Request) ExecuteWithResults(reqURL string, dynamicValues, previous output.InternalEvent, callback protocols.OutputEventCallback) error {
 	// verify if pipeline was requested
@@ -183,80 +271,206 @@ func (r *Request) ExecuteWithResults(reqURL string, dynamicValues, previous outp
 		return r.executeTurboHTTP(reqURL, dynamicValues, previous, callback)
 	}
 
+	// Each input gets its own cookie jar so cookies set by a target are
+	// never sent to the other targets executed in parallel.
+	client := r.httpClient
+	if r.CookieReuse {
+		var err error
+		if client, err = httpclientpool.WithCookieJar(r.httpClient); err != nil {
+			return err
+		}
+	}
+
 	// verify if a basic race condition was requested
 	if r.Race && r.RaceNumberRequests > 0 {
-		return r.executeRaceRequest(reqURL, previous, callback)
+		return r.executeRaceRequest(reqURL, client, previous, callback)
 	}
 
 	// verify if parallel elaboration was requested
 	if r.Threads > 0 {
-		return r.executeParallelHTTP(reqURL, dynamicValues, previous, callback)
+		return r.executeParallelHTTP(reqURL, client, dynamicValues, previous, callback)
 	}
 
 	generator := r.newGenerator()
+	generator.client = client
 
+	// iterations are the dynamic values each request is sent with, there
+	// is a single one unless iterate-all extracted multiple values.
+	iterations := []output.InternalEvent{dynamicValues}
 	requestCount := 1
 	var requestErr error
+mainLoop:
 	for {
-		hasInteractMarkers := interactsh.HasMatchers(r.CompiledOperators)
-
-		var interactURL string
-		if r.options.Interactsh != nil && hasInteractMarkers {
-			interactURL = r.options.Interactsh.URL()
-		}
-		request, err := generator.Make(reqURL, dynamicValues, interactURL)
-		if err == io.EOF {
+		data, payloads, ok := generator.nextValue()
+		if !ok {
 			break
 		}
-		if err != nil {
-			r.options.Progress.IncrementFailedRequestsBy(int64(generator.Total()))
-			return err
-		}
+		// The progress only counts each request once
+		r.options.Progress.AddToTotal(int64(len(iterations) - 1))
+
+		var nextIterations []output.InternalEvent
+		for i, values := range iterations {
+			hasInteractMarkers := interactsh.HasMatchers(r.CompiledOperators)
+
+			var interactURL string
+			if r.options.Interactsh != nil && hasInteractMarkers {
+				interactURL = r.options.Interactsh.URL()
+			}
+			request, err := generator.makeRequest(reqURL, data, payloads, values, interactURL)
+			if err != nil {
+				r.options.Progress.IncrementFailedRequestsBy(int64(generator.Remaining() + len(iterations) - i))
+				return err
+			}
 
-		var gotOutput bool
-		r.options.RateLimiter.Take()
-		err = r.executeRequest(reqURL, request, previous, func(event *output.InternalWrappedEvent) {
-			// Add the extracts to the dynamic values if any.
-			if event.OperatorsResult != nil {
-				gotOutput = true
-				dynamicValues = generators.MergeMaps(dynamicValues, event.OperatorsResult.DynamicValues)
+			var gotMatches bool
+			var extracted map[string][]string
+			// The random values sent are published for the next requests
+			nextValues := generators.MergeMaps(values, request.randomValues)
+			r.options.RateLimiter.Take()
+			err = r.executeRequest(reqURL, request, previous, func(event *output.InternalWrappedEvent) {
+				// Add the extracts to the dynamic values if any.
+				if event.OperatorsResult != nil {
+					gotMatches = event.OperatorsResult.Matched
+					nextValues = generators.MergeMaps(nextValues, event.OperatorsResult.DynamicValues)
+					extracted = event.OperatorsResult.AllDynamicValues
+				}
+				if r.Baseline && requestCount == 1 {
+					nextValues = r.setBaselineDuration(event, nextValues, previous)
+				}
+				if hasInteractMarkers && r.options.Interactsh != nil {
+					r.options.Interactsh.RequestEvent(interactURL, &interactsh.RequestData{
+						MakeResultFunc: r.MakeResultEvent,
+						Event:          event,
+						Operators:      r.CompiledOperators,
+						MatchFunc:      r.Match,
+						ExtractFunc:    r.Extract,
+					})
+				} else {
+					callback(event)
+				}
+			}, requestCount)
+			if err != nil {
+				requestErr = multierr.Append(requestErr, err)
 			}
-			if hasInteractMarkers && r.options.Interactsh != nil {
-				r.options.Interactsh.RequestEvent(interactURL, &interactsh.RequestData{
-					MakeResultFunc: r.MakeResultEvent,
-					Event:          event,
-					Operators:      r.CompiledOperators,
-					MatchFunc:      r.Match,
-					ExtractFunc:    r.Extract,
-				})
+			requestCount++
+			r.options.Progress.IncrementRequests()
+
+			if r.IterateAll {
+				nextIterations = append(nextIterations, iterateValues(nextValues, extracted)...)
+				if len(nextIterations) > maxIterations {
+					nextIterations = nextIterations[:maxIterations]
+				}
 			} else {
-				callback(event)
+				nextIterations = append(nextIterations, nextValues)
+			}
+			if gotMatches && r.stopAtFirstMatch() {
+				r.options.Progress.AddToTotal(-int64(generator.Remaining() + len(iterations) - i - 1))
+				break mainLoop
 			}
-		}, requestCount)
-		if err != nil {
-			requestErr = multierr.Append(requestErr, err)
 		}
-		requestCount++
-		r.options.Progress.IncrementRequests()
+		iterations = nextIterations
+	}
+	return requestErr
+}
 
-		if request.original.options.Options.StopAtFirstMatch && gotOutput {
-			r.options.Progress.IncrementErrorsBy(int64(generator.Total()))
-			break
+// setBaselineDuration adds the duration of the baseline request of an
+// event to the dynamic values and the history of the next requests.
+func (r *Request) setBaselineDuration(event *output.InternalWrappedEvent, dynamicValues, previous output.InternalEvent) output.InternalEvent {
+	duration, ok := event.InternalEvent["duration"]
+	if !ok {
+		return dynamicValues
+	}
+	if previous != nil {
+		r.historyMutex.Lock()
+		previous["baseline_duration"] = duration
+		r.historyMutex.Unlock()
+	}
+	return generators.MergeMaps(dynamicValues, map[string]interface{}{"baseline_duration": duration})
+}
+
+// maxIterations is the maximum number of times a request is sent for an
+// input with the combinations of the values extracted with iterate-all.
+const maxIterations = 1000
+
+// iterateValues returns the dynamic values of the next requests with each
+// combination of the values extracted by the internal extractors, up to
+// maxIterations combinations.
+func iterateValues(values output.InternalEvent, extracted map[string][]string) []output.InternalEvent {
+	iterations := []output.InternalEvent{values}
+	names := make([]string, 0, len(extracted))
+	for name := range extracted {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		next := make([]output.InternalEvent, 0, len(iterations)*len(extracted[name]))
+		for _, iteration := range iterations {
+			for _, value := range extracted[name] {
+				if len(next) == maxIterations {
+					break
+				}
+				next = append(next, generators.MergeMaps(iteration, map[string]interface{}{name: value}))
+			}
 		}
+		iterations = next
 	}
-	return requestErr
+	return iterations
+}
+
+// stopAtFirstMatch returns true if the requests for an input must stop after
+// the first match. Clustered requests are stopped per template by the cluster.
+func (r *Request) stopAtFirstMatch() bool {
+	if r.clustered {
+		return false
+	}
+	return r.StopAtFirstMatch || r.options.Options.StopAtFirstMatch
+}
+
+// requestProxy returns the proxy a request was sent through for the
+// trace log, without the credentials of the proxy.
+func (r *Request) requestProxy(request *generatedRequest) string {
+	if request.request == nil {
+		return ""
+	}
+	proxy := request.proxy
+	if proxy == nil && r.options.Options.ProxyURL != "" {
+		proxy, _ = url.Parse(r.options.Options.ProxyURL)
+	}
+	if proxy == nil {
+		return ""
+	}
+	return proxy.Redacted()
+}
+
+// maxResponseSize returns the maximum size of the response bodies to read,
+// 0 if they must be read fully.
+func (r *Request) maxResponseSize() int64 {
+	if r.MaxSize > 0 {
+		return int64(r.MaxSize)
+	}
+	return int64(r.options.Options.ResponseReadSize)
 }
 
 const drainReqSize = int64(8 * 1024)
 
 // executeRequest executes the actual generated request and returns error if occurred
 func (r *Request) executeRequest(reqURL string, request *generatedRequest, previous output.InternalEvent, callback protocols.OutputEventCallback, requestCount int) error {
-	r.setCustomHeaders(request)
+	// Self-contained requests have no input and are sent to their own url
+	if r.SelfContained {
+		reqURL = request.targetURL()
+	}
+	if err := r.setSessionHeader(request); err != nil {
+		r.options.Output.Request(r.options.TemplateID, reqURL, "http", err)
+		r.options.Progress.IncrementErrorsBy(1)
+		return err
+	}
 
 	var (
 		resp          *http.Response
 		fromcache     bool
 		dumpedRequest []byte
+		curl          string
 		err           error
 	)
 
@@ -266,6 +480,7 @@ func (r *Request) executeRequest(reqURL string, request *generatedRequest, previ
 		if err != nil {
 			return err
 		}
+		curl = curlCommand(request)
 
 		if r.options.Options.Debug || r.options.Options.DebugRequests {
 			gologger.Info().Msgf("[%s] Dumped HTTP request for %s\n\n", r.options.TemplateID, reqURL)
@@ -286,6 +501,10 @@ func (r *Request) executeRequest(reqURL string, request *generatedRequest, previ
 		} else if request.request != nil {
 			resp, err = request.pipelinedClient.Dor(request.request)
 		}
+		// pipelined responses are returned without a body on errors
+		if err != nil {
+			resp = nil
+		}
 	} else if request.original.Unsafe && request.rawRequest != nil {
 		formedURL = request.rawRequest.FullURL
 		if parsed, parseErr := url.Parse(formedURL); parseErr == nil {
@@ -294,7 +513,22 @@ func (r *Request) executeRequest(reqURL string, request *generatedRequest, previ
 		options := request.original.rawhttpClient.Options
 		options.FollowRedirects = r.Redirects
 		options.CustomRawBytes = request.rawRequest.UnsafeRawBytes
-		resp, err = request.original.rawhttpClient.DoRawWithOptions(request.rawRequest.Method, reqURL, request.rawRequest.Path, generators.ExpandMapValues(request.rawRequest.Headers), ioutil.NopCloser(strings.NewReader(request.rawRequest.Data)), options)
+		if request.annotations.Timeout > 0 {
+			options.Timeout = request.annotations.Timeout
+		}
+		certificate := r.clientCertificate()
+		doUnsafe := func() (*http.Response, error) {
+			if (certificate != nil || r.verification != nil || request.annotations.SNI != "") && strings.HasPrefix(strings.ToLower(formedURL), "https://") {
+				return r.doUnsafeWithTLS(request, certificate)
+			}
+			return request.original.rawhttpClient.DoRawWithOptions(request.rawRequest.Method, formedURL, request.rawRequest.Path, generators.ExpandMapValues(request.rawRequest.Headers), ioutil.NopCloser(strings.NewReader(request.rawRequest.Data)), options)
+		}
+		resp, err = doUnsafe()
+		if retry, retryErr := r.retryWithSession(resp, err, request); retryErr != nil {
+			resp, err = nil, retryErr
+		} else if retry {
+			resp, err = doUnsafe()
+		}
 	} else {
 		hostname = request.request.URL.Host
 		formedURL = request.request.URL.String()
@@ -307,11 +541,27 @@ func (r *Request) executeRequest(reqURL string, request *generatedRequest, previ
 				fromcache = false
 			}
 		}
-		if resp == nil {
-			resp, err = r.httpClient.Do(request.request)
+		do := func() (*http.Response, error) {
+			// only the last attempt of retried requests is timed
+			timeStart = time.Now()
+			if request.auth != nil {
+				return r.doWithAuth(request)
+			}
+			resp, err := request.client.Do(request.request)
+			if retry, retryErr := r.retryWithSession(resp, err, request); retryErr != nil {
+				return nil, retryErr
+			} else if retry {
+				return request.client.Do(request.request)
+			}
+			return resp, err
+		}
+		if resp == nil && r.retries > 0 {
+			resp, err = r.doWithRetries(request, do)
+		} else if resp == nil {
+			resp, err = do()
 		}
 	}
-	if resp == nil {
+	if resp == nil && err == nil {
 		err = errors.New("no response got for request")
 	}
 	if err != nil {
@@ -320,7 +570,7 @@ func (r *Request) executeRequest(reqURL string, request *generatedRequest, previ
 			_, _ = io.CopyN(ioutil.Discard, resp.Body, drainReqSize)
 			resp.Body.Close()
 		}
-		r.options.Output.Request(r.options.TemplateID, formedURL, "http", err)
+		r.options.Output.RequestWithProxy(r.options.TemplateID, formedURL, "http", r.requestProxy(request), err)
 		r.options.Progress.IncrementErrorsBy(1)
 		return err
 	}
@@ -330,18 +580,21 @@ func (r *Request) executeRequest(reqURL string, request *generatedRequest, previ
 	}()
 
 	gologger.Verbose().Msgf("[%s] Sent HTTP request to %s", r.options.TemplateID, formedURL)
-	r.options.Output.Request(r.options.TemplateID, formedURL, "http", err)
+	r.options.Output.RequestWithProxy(r.options.TemplateID, formedURL, "http", r.requestProxy(request), err)
 
 	duration := time.Since(timeStart)
+	r.options.Progress.RecordLatency(hostname, duration)
 
 	dumpedResponseHeaders, err := httputil.DumpResponse(resp, false)
 	if err != nil {
 		return errors.Wrap(err, "could not dump http response")
 	}
 
+	// One more byte than the maximum size is read to detect truncation
+	maxSize := r.maxResponseSize()
 	var bodyReader io.Reader
-	if r.MaxSize != 0 {
-		bodyReader = io.LimitReader(resp.Body, int64(r.MaxSize))
+	if maxSize > 0 {
+		bodyReader = io.LimitReader(resp.Body, maxSize+1)
 	} else {
 		bodyReader = resp.Body
 	}
@@ -352,6 +605,10 @@ func (r *Request) executeRequest(reqURL string, request *generatedRequest, previ
 		}
 	}
 	resp.Body.Close()
+	bodyTruncated := maxSize > 0 && int64(len(data)) > maxSize
+	if bodyTruncated {
+		data = data[:maxSize]
+	}
 
 	redirectedResponse, err := dumpResponseWithRedirectChain(resp, data)
 	if err != nil {
@@ -362,7 +619,13 @@ func (r *Request) executeRequest(reqURL string, request *generatedRequest, previ
 	// encoding has been specified by the user in the request so in case we have to
 	// manually do it.
 	dataOrig := data
-	data, _ = handleDecompression(resp, data)
+	maxDecompressedSize := int64(defaultMaxDecompressedSize)
+	if maxSize > 0 {
+		maxDecompressedSize = maxSize
+	}
+	var decompressedTruncated bool
+	data, decompressedTruncated, _ = handleDecompression(resp, data, maxDecompressedSize)
+	bodyTruncated = bodyTruncated || decompressedTruncated
 
 	// Dump response - step 2 - replace gzip body with deflated one or with itself (NOP operation)
 	dumpedResponseBuilder := &bytes.Buffer{}
@@ -398,8 +661,32 @@ func (r *Request) executeRequest(reqURL string, request *generatedRequest, previ
 	if i := strings.LastIndex(hostname, ":"); i != -1 {
 		hostname = hostname[:i]
 	}
+	outputEvent["raw_body"] = tostring.UnsafeToString(dataOrig)
+	outputEvent["curl-command"] = curl
+	outputEvent["body_truncated"] = bodyTruncated
+	if requestCount > 0 {
+		outputEvent["request_number"] = requestCount
+	}
 	outputEvent["ip"] = httpclientpool.Dialer.GetDialedIP(hostname)
-	outputEvent["redirect-chain"] = tostring.UnsafeToString(redirectedResponse)
+	if outputEvent["ip"] == "" && net.ParseIP(strings.Trim(hostname, "[]")) != nil {
+		outputEvent["ip"] = strings.Trim(hostname, "[]")
+	}
+	if parsed, parseErr := url.Parse(matchedURL); parseErr == nil {
+		outputEvent["scheme"] = parsed.Scheme
+		outputEvent["port"] = urlPort(parsed)
+	}
+	r.options.Fingerprinter.Observe(hostname, resp).Annotate(outputEvent)
+	outputEvent["redirect_chain"] = redirectChain(resp)
+	for k, v := range redirectHistory(resp) {
+		outputEvent[k] = v
+	}
+	if len(r.DecodeBody) > 0 {
+		decoded, segments := decodeBody(tostring.UnsafeToString(data), r.DecodeBody)
+		outputEvent["body_decoded"] = decoded
+		outputEvent["body_decoded_segments"] = segments
+	}
+	// Race requests share the history of the previous requests
+	r.historyMutex.Lock()
 	for k, v := range previous {
 		finalEvent[k] = v
 	}
@@ -414,6 +701,7 @@ func (r *Request) executeRequest(reqURL string, request *generatedRequest, previ
 			finalEvent[key] = v
 		}
 	}
+	r.historyMutex.Unlock()
 
 	event := &output.InternalWrappedEvent{InternalEvent: outputEvent}
 	if !interactsh.HasMatchers(r.CompiledOperators) {
@@ -431,17 +719,56 @@ func (r *Request) executeRequest(reqURL string, request *generatedRequest, previ
 	return nil
 }
 
-// setCustomHeaders sets the custom headers for generated request
-func (r *Request) setCustomHeaders(req *generatedRequest) {
-	for k, v := range r.customHeaders {
-		if req.rawRequest != nil {
-			req.rawRequest.Headers[k] = v
-		} else {
-			kk, vv := strings.TrimSpace(k), strings.TrimSpace(v)
-			req.request.Header.Set(kk, vv)
-			if kk == "Host" {
-				req.request.Host = vv
-			}
+// setSessionHeader sets the authentication session header for the
+// generated request if the target host is in scope of the session.
+func (r *Request) setSessionHeader(req *generatedRequest) error {
+	if r.options.Session == nil || req.original.Pipeline {
+		return nil
+	}
+	var host string
+	if req.request != nil {
+		host = req.request.URL.Host
+	} else if req.rawRequest != nil {
+		if parsed, err := url.Parse(req.rawRequest.FullURL); err == nil {
+			host = parsed.Host
 		}
 	}
+	if !r.options.Session.InScope(host) {
+		return nil
+	}
+	value, err := r.options.Session.HeaderValue()
+	if err != nil {
+		return errors.Wrapf(err, "skipping request to %s", host)
+	}
+	if req.rawRequest != nil && req.request == nil {
+		req.rawRequest.Headers[r.options.Session.Header()] = value
+	} else {
+		req.request.Header.Set(r.options.Session.Header(), value)
+	}
+	return nil
+}
+
+// retryWithSession refreshes the session token when an in-scope request
+// was rejected as unauthorized and returns true if it should be sent again.
+func (r *Request) retryWithSession(resp *http.Response, err error, req *generatedRequest) (bool, error) {
+	if r.options.Session == nil || err != nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		return false, nil
+	}
+	var host string
+	if req.request != nil {
+		host = req.request.URL.Host
+	} else if parsed, parseErr := url.Parse(req.rawRequest.FullURL); parseErr == nil {
+		host = parsed.Host
+	}
+	if !r.options.Session.InScope(host) {
+		return false, nil
+	}
+	if resp.Body != nil {
+		_, _ = io.CopyN(ioutil.Discard, resp.Body, drainReqSize)
+		resp.Body.Close()
+	}
+	if refreshErr := r.options.Session.Refresh(); refreshErr != nil {
+		return false, errors.Wrapf(refreshErr, "could not refresh session for %s", host)
+	}
+	return true, r.setSessionHeader(req)
 }
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"github.com/projectdiscovery/retryablehttp-go"
 	"github.com/yaklang/nuclei/v2/pkg/protocols"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/common/generators"
 )
@@ -16,6 +17,11 @@ type requestGenerator struct {
 	request         *Request
 	options         *protocols.ExecuterOptions
 	payloadIterator *generators.Iterator
+	// client is the http client of the generated requests, the client
+	// of the request is used if not set.
+	client *retryablehttp.Client
+	// raceGate releases the bodies of the race requests together
+	raceGate chan struct{}
 }
 
 // newGenerator creates a new request generator instance
@@ -60,6 +60,7 @@ func TestRequestGeneratorClusterBombMultipleRaw(t *testing.T) {
 	generator := req.newGenerator()
 	var payloads []map[string]interface{}
 	for {
+		require.Equal(t, 18-len(payloads), generator.Remaining(), "Could not get correct number of remaining requests")
 		_, data, ok := generator.nextValue()
 		if !ok {
 			break
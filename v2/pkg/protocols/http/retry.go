@@ -0,0 +1,95 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/retryablehttp-go"
+	"github.com/yaklang/nuclei/v2/pkg/types"
+)
+
+const (
+	// defaultRetryDelay is the delay before the first retry of a request
+	defaultRetryDelay = time.Second
+	// maxRetryDelay is the maximum delay between the retries of a request
+	maxRetryDelay = 30 * time.Second
+)
+
+// compileRetries validates the retries of the request, falling back to
+// the global retries if the request only sets the retry delay.
+func (r *Request) compileRetries(options *types.Options) error {
+	if r.Unsafe || r.Pipeline || r.Race {
+		return errors.New("retries are not supported with unsafe, pipeline or race requests")
+	}
+	if r.Retries < 0 {
+		return errors.New("retries must be positive")
+	}
+	r.retries = r.Retries
+	if r.retries == 0 {
+		r.retries = options.Retries
+	}
+	r.retryDelay = defaultRetryDelay
+	if r.RetryDelay != "" {
+		delay, err := time.ParseDuration(r.RetryDelay)
+		if err != nil {
+			return errors.Wrap(err, "could not parse retry delay")
+		}
+		r.retryDelay = delay
+	}
+	return nil
+}
+
+// doWithRetries sends a request and retries it with backoff after
+// connection errors or server errors for idempotent methods. Only the
+// response of the last attempt is returned, the retries are stopped when
+// the context of the request is done.
+func (r *Request) doWithRetries(req *generatedRequest, do func() (*http.Response, error)) (*http.Response, error) {
+	delay := r.retryDelay
+	for attempt := 0; ; attempt++ {
+		resp, err := do()
+		if attempt >= r.retries || !shouldRetry(req.request.Context(), req.request.Method, resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			drainResponse(resp)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.request.Context().Done():
+			timer.Stop()
+			return nil, req.request.Context().Err()
+		case <-timer.C:
+		}
+		if delay *= 2; delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
+
+// retryPolicy decides whether the errors of the requests are recoverable
+var retryPolicy = retryablehttp.HostSprayRetryPolicy()
+
+// shouldRetry returns true if a request failed with a recoverable error or
+// got a server error response for an idempotent method.
+func shouldRetry(ctx context.Context, method string, resp *http.Response, err error) bool {
+	if err != nil {
+		// the policy only recognizes the url errors of the requests
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr
+		}
+		retry, _ := retryPolicy(ctx, nil, err)
+		return retry
+	}
+	if resp.StatusCode < http.StatusInternalServerError || resp.StatusCode == http.StatusNotImplemented {
+		return false
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+package http
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// AWSSignature is the signature type for aws signature v4 signing
+const AWSSignature = "aws"
+
+// AWSCredentials contains the credentials for aws signature v4 signing.
+// Missing values are read from the standard aws environment variables.
+type AWSCredentials struct {
+	// AccessKey is the access key id
+	AccessKey string `yaml:"access-key"`
+	// SecretKey is the secret access key
+	SecretKey string `yaml:"secret-key"`
+	// SessionToken is the optional session token of temporary credentials
+	SessionToken string `yaml:"session-token"`
+	// Region is the region of the service, us-east-1 by default
+	Region string `yaml:"region"`
+	// Service is the name of the service, for example s3 or execute-api
+	Service string `yaml:"service"`
+}
+
+// compileSignature validates the signature of the request and resolves
+// its credentials with the environment.
+func (r *Request) compileSignature() error {
+	if !strings.EqualFold(r.Signature, AWSSignature) {
+		return errors.Errorf("invalid signature type %s", r.Signature)
+	}
+	if r.Unsafe || r.Pipeline || r.Race {
+		return errors.New("aws signature is not supported with unsafe, pipeline or race requests")
+	}
+
+	credentials := AWSCredentials{}
+	if r.AWSCredentials != nil {
+		credentials = *r.AWSCredentials
+	}
+	fromEnv := func(value *string, names ...string) {
+		for _, name := range names {
+			if *value != "" {
+				return
+			}
+			*value = os.Getenv(name)
+		}
+	}
+	fromEnv(&credentials.AccessKey, "AWS_ACCESS_KEY_ID")
+	fromEnv(&credentials.SecretKey, "AWS_SECRET_ACCESS_KEY")
+	fromEnv(&credentials.SessionToken, "AWS_SESSION_TOKEN")
+	fromEnv(&credentials.Region, "AWS_REGION", "AWS_DEFAULT_REGION")
+	if credentials.Region == "" {
+		credentials.Region = "us-east-1"
+	}
+	if credentials.AccessKey == "" || credentials.SecretKey == "" {
+		return errors.New("no aws credentials provided for signature")
+	}
+	if credentials.Service == "" {
+		return errors.New("no aws service provided for signature")
+	}
+	r.awsCredentials = &credentials
+	return nil
+}
+
+// signAWS signs a request with aws signature v4. Only the host and the
+// x-amz headers are signed so headers added later keep the signature valid.
+func signAWS(req *http.Request, credentials *AWSCredentials, now time.Time) error {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return errors.Wrap(err, "could not read request body")
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	payloadHash := sha256Hex(body)
+
+	amzDate := now.UTC().Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if credentials.Service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+	if credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", credentials.SessionToken)
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		if name = strings.ToLower(name); strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	canonicalHeaders := &strings.Builder{}
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	date := amzDate[:8]
+	scope := date + "/" + credentials.Region + "/" + credentials.Service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+credentials.SecretKey), date)
+	for _, part := range []string{credentials.Region, credentials.Service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+credentials.AccessKey+"/"+scope+", SignedHeaders="+signedHeaders+", Signature="+signature)
+	return nil
+}
+
+// canonicalQuery returns the sorted and escaped query of an url
+func canonicalQuery(u *url.URL) string {
+	query := u.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, awsEscape(key)+"="+awsEscape(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsEscape escapes a value with the rfc 3986 encoding used by aws
+func awsEscape(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+func sha256Hex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
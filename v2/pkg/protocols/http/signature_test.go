@@ -0,0 +1,86 @@
+package http
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaklang/nuclei/v2/internal/testutils"
+	"github.com/yaklang/nuclei/v2/pkg/operators"
+	"github.com/yaklang/nuclei/v2/pkg/operators/matchers"
+	"github.com/yaklang/nuclei/v2/pkg/output"
+)
+
+func TestSignAWS(t *testing.T) {
+	credentials := &AWSCredentials{
+		AccessKey: "AKIDEXAMPLE",
+		SecretKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		Region:    "us-east-1",
+		Service:   "service",
+	}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	// test vectors of the aws signature v4 test suite
+	vectors := map[string]string{
+		"/":                             "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		"/?Param2=value2&Param1=value1": "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+	}
+	for path, signature := range vectors {
+		req, err := http.NewRequest("GET", "https://example.amazonaws.com"+path, nil)
+		require.Nil(t, err, "could not create request")
+		err = signAWS(req, credentials, now)
+		require.Nil(t, err, "could not sign request")
+		require.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"), "could not set date header")
+		require.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature="+signature, req.Header.Get("Authorization"), "could not sign %s", path)
+	}
+}
+
+func TestHTTPAWSSignature(t *testing.T) {
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-http-aws-signature"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") && r.Header.Get("X-Amz-Content-Sha256") == sha256Hex(body) {
+			_, _ = w.Write([]byte("signed"))
+		}
+	}))
+	defer ts.Close()
+
+	compile := func(request *Request) error {
+		request.ID = templateID
+		return request.Compile(testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+			ID:   templateID,
+			Info: map[string]interface{}{"severity": "low", "name": "test"},
+		}))
+	}
+	credentials := &AWSCredentials{AccessKey: "AKIDEXAMPLE", SecretKey: "secret", Service: "s3"}
+
+	request := &Request{
+		Raw:            []string{"PUT /bucket/{{name}} HTTP/1.1\r\nHost: {{Hostname}}\r\n\r\nobject {{name}}"},
+		Payloads:       map[string]interface{}{"name": []interface{}{"a.txt"}},
+		Signature:      "aws",
+		AWSCredentials: credentials,
+		Operators: operators.Operators{
+			Matchers: []*matchers.Matcher{{Type: "word", Part: "body", Words: []string{"signed"}}},
+		},
+	}
+	require.Nil(t, compile(request), "could not compile signed request")
+	var finalEvent *output.InternalWrappedEvent
+	err := request.ExecuteWithResults(ts.URL, make(output.InternalEvent), make(output.InternalEvent), func(event *output.InternalWrappedEvent) {
+		finalEvent = event
+	})
+	require.Nil(t, err, "could not execute signed request")
+	require.Len(t, finalEvent.Results, 1, "could not sign request")
+
+	err = compile(&Request{Raw: []string{"GET / HTTP/1.1\r\n\r\n"}, Unsafe: true, Signature: "aws", AWSCredentials: credentials})
+	require.NotNil(t, err, "compiled aws signature for unsafe request")
+	err = compile(&Request{Path: []string{"{{BaseURL}}"}, Signature: "aws", AWSCredentials: &AWSCredentials{AccessKey: "AKIDEXAMPLE", SecretKey: "secret"}})
+	require.NotNil(t, err, "compiled aws signature without service")
+}
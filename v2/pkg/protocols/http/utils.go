@@ -2,16 +2,21 @@ package http
 
 import (
 	"bytes"
+	"compress/flate"
 	"compress/gzip"
 	"compress/zlib"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httputil"
+	"net/url"
+	"strconv"
 	"strings"
 
+	"github.com/andybalholm/brotli"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/common/generators"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/common/tostring"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/http/httpclientpool"
 	"github.com/projectdiscovery/rawhttp"
 )
 
@@ -40,15 +45,11 @@ func dumpResponseWithRedirectChain(resp *http.Response, body []byte) ([]byte, er
 		redirectResp = resp.Request.Response
 	}
 	for redirectResp != nil {
-		var body []byte
-
 		respData, err := httputil.DumpResponse(redirectResp, false)
 		if err != nil {
 			break
 		}
-		if redirectResp.Body != nil {
-			body, _ = ioutil.ReadAll(redirectResp.Body)
-		}
+		body := redirectBody(redirectResp)
 		redirectChain.WriteString(tostring.UnsafeToString(respData))
 		if len(body) > 0 {
 			redirectChain.WriteString(tostring.UnsafeToString(body))
@@ -63,6 +64,72 @@ func dumpResponseWithRedirectChain(resp *http.Response, body []byte) ([]byte, er
 	return redirectChain.Bytes(), nil
 }
 
+// redirectChain returns the status codes and locations of the redirects
+// followed for a response, one redirect per line starting from the first.
+// It is empty for unsafe requests, the raw client following their redirects
+// doesn't keep the intermediate responses.
+func redirectChain(resp *http.Response) string {
+	builder := &strings.Builder{}
+	for _, redirect := range redirectResponses(resp) {
+		builder.WriteString(strconv.Itoa(redirect.StatusCode) + " " + redirect.Header.Get("Location"))
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// redirectResponses returns the redirect responses followed for a
+// response starting from the first one.
+func redirectResponses(resp *http.Response) []*http.Response {
+	var responses []*http.Response
+	for resp != nil && resp.Request != nil && resp.Request.Response != nil {
+		resp = resp.Request.Response
+		responses = append([]*http.Response{resp}, responses...)
+	}
+	return responses
+}
+
+// redirectBody returns the body kept for a followed redirect response
+func redirectBody(resp *http.Response) []byte {
+	if body, ok := resp.Body.(*httpclientpool.RedirectBody); ok {
+		return body.Data
+	}
+	return nil
+}
+
+// redirectHistory returns the parts of the followed redirects for the
+// matchers. Each redirect is dumped to redirect_history and its status
+// code, location, headers and body are numbered from the first redirect.
+func redirectHistory(resp *http.Response) map[string]interface{} {
+	data := make(map[string]interface{})
+	history := &strings.Builder{}
+	for i, redirect := range redirectResponses(resp) {
+		body := tostring.UnsafeToString(redirectBody(redirect))
+		if dumped, err := httputil.DumpResponse(redirect, false); err == nil {
+			history.Write(dumped)
+			history.WriteString(body)
+			history.WriteString("\n")
+		}
+		n := strconv.Itoa(i + 1)
+		data["status_code_"+n] = redirect.StatusCode
+		data["location_"+n] = redirect.Header.Get("Location")
+		data["all_headers_"+n] = headersToString(redirect.Header)
+		data["body_"+n] = body
+	}
+	data["redirect_history"] = history.String()
+	return data
+}
+
+// urlPort returns the port of an url, defaulting to the port of its scheme
+func urlPort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	if u.Scheme == "https" {
+		return "443"
+	}
+	return "80"
+}
+
 // headersToString converts http headers to string
 func headersToString(headers http.Header) string {
 	builder := &strings.Builder{}
@@ -96,29 +163,45 @@ func dump(req *generatedRequest, reqURL string) ([]byte, error) {
 	return rawhttp.DumpRequestRaw(req.rawRequest.Method, reqURL, req.rawRequest.Path, generators.ExpandMapValues(req.rawRequest.Headers), ioutil.NopCloser(strings.NewReader(req.rawRequest.Data)), rawhttp.Options{CustomHeaders: req.rawRequest.UnsafeHeaders, CustomRawBytes: req.rawRequest.UnsafeRawBytes})
 }
 
-// handleDecompression if the user specified a custom encoding (as golang transport doesn't do this automatically)
-func handleDecompression(resp *http.Response, bodyOrig []byte) (bodyDec []byte, err error) {
+// defaultMaxDecompressedSize is the maximum size of a decompressed body
+// if the request doesn't set a maximum size.
+const defaultMaxDecompressedSize = 10 * 1024 * 1024
+
+// handleDecompression decodes gzip, deflate and brotli bodies as the golang
+// transport only does it for the encodings it requested itself and unsafe
+// requests are never decoded. At most maxSize bytes are decoded so
+// decompression bombs can't exhaust the memory, truncated is true if the
+// decoded body was longer.
+func handleDecompression(resp *http.Response, bodyOrig []byte, maxSize int64) (bodyDec []byte, truncated bool, err error) {
 	if resp == nil {
-		return bodyOrig, nil
+		return bodyOrig, false, nil
 	}
 
-	var reader io.ReadCloser
-	switch resp.Header.Get("Content-Encoding") {
-	case "gzip":
+	var reader io.Reader
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
 		reader, err = gzip.NewReader(bytes.NewReader(bodyOrig))
 	case "deflate":
-		reader, err = zlib.NewReader(bytes.NewReader(bodyOrig))
+		// deflate should be zlib wrapped but some servers send raw deflate data
+		if reader, err = zlib.NewReader(bytes.NewReader(bodyOrig)); err != nil {
+			reader, err = flate.NewReader(bytes.NewReader(bodyOrig)), nil
+		}
+	case "br":
+		reader = brotli.NewReader(bytes.NewReader(bodyOrig))
 	default:
-		return bodyOrig, nil
+		return bodyOrig, false, nil
 	}
 	if err != nil {
-		return nil, err
+		return bodyOrig, false, err
 	}
-	defer reader.Close()
 
-	bodyDec, err = ioutil.ReadAll(reader)
-	if err != nil {
-		return bodyOrig, err
+	// truncated bodies are still decoded up to the truncation
+	bodyDec, err = ioutil.ReadAll(io.LimitReader(reader, maxSize+1))
+	if err != nil && len(bodyDec) == 0 {
+		return bodyOrig, false, err
+	}
+	if int64(len(bodyDec)) > maxSize {
+		return bodyDec[:maxSize], true, nil
 	}
-	return bodyDec, nil
+	return bodyDec, false, nil
 }
@@ -0,0 +1,71 @@
+package network
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+// variableRegex matches the {{name}} references to extracted variables
+var variableRegex = regexp.MustCompile(`\{\{([A-Za-z0-9_-]+)\}\}`)
+
+// compileExtract validates an extract input and adds its variable to the
+// variables available to the next inputs.
+func (i *Input) compileExtract(variables map[string]struct{}, hasRead bool) error {
+	if i.Name == "" {
+		return errors.New("extract input without name")
+	}
+	if !hasRead {
+		return errors.New("extract input without a previous read")
+	}
+	if i.Data != "" || i.File != "" || len(i.Segments) > 0 {
+		return errors.New("extract input cannot send data")
+	}
+	if i.Regex != "" {
+		if i.Offset != 0 || i.Length != 0 {
+			return errors.New("regex cannot be used with offset and length")
+		}
+		compiled, err := regexp.Compile(i.Regex)
+		if err != nil {
+			return errors.Wrap(err, "could not compile regex")
+		}
+		i.regex = compiled
+	} else if i.Offset < 0 || i.Length <= 0 {
+		return errors.New("extract input requires a regex or a positive length")
+	}
+	variables[i.Name] = struct{}{}
+	return nil
+}
+
+// extract captures the variable of an extract input from the data read
+func (i *Input) extract(data []byte) ([]byte, bool) {
+	if i.regex != nil {
+		match := i.regex.FindSubmatch(data)
+		if match == nil {
+			return nil, false
+		}
+		if len(match) > 1 {
+			return match[1], true
+		}
+		return match[0], true
+	}
+	if i.Offset+i.Length > len(data) {
+		return nil, false
+	}
+	return data[i.Offset : i.Offset+i.Length], true
+}
+
+// replaceVariables replaces the references to the variables in data with
+// their encoded values. Unknown references are left as is.
+func replaceVariables(data string, variables map[string][]byte, encode func([]byte) string) string {
+	if len(variables) == 0 {
+		return data
+	}
+	return variableRegex.ReplaceAllStringFunc(data, func(match string) string {
+		value, ok := variables[match[2:len(match)-2]]
+		if !ok {
+			return match
+		}
+		return encode(value)
+	})
+}
@@ -0,0 +1,405 @@
+package network
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/yaklang/nuclei/v2/pkg/protocols"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/expressions"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/interactsh"
+)
+
+// Segment is a part of an input concatenated with the other segments
+type Segment struct {
+	// Data is the data of the segment
+	Data string `yaml:"data"`
+	// Type is the encoding of the data - hex, base64 or text.
+	Type string `yaml:"type"`
+	// File is a file to load the binary data of the segment from
+	File string `yaml:"file"`
+	// Name is the optional name of the segment for length placeholders
+	Name string `yaml:"name"`
+}
+
+// compiledSegment is a segment with static data decoded at compile time
+type compiledSegment struct {
+	name   string
+	data   []byte // decoded data for hex, base64 and file segments
+	text   string // text data substituted for each request
+	static bool
+	hex    bool // hex data decoded for each request after substitution
+	dump   string
+}
+
+const (
+	// maxInputFileSize is the maximum size of a file loaded as input
+	maxInputFileSize = 10 * 1024 * 1024
+	// maxInvalidCharacters is the number of offending characters reported
+	maxInvalidCharacters = 5
+)
+
+// lengthPlaceholderRegex matches {{len:segment}} and {{len:segment:format}}
+var lengthPlaceholderRegex = regexp.MustCompile(`\{\{len:([A-Za-z0-9_-]+)(?::(u8|u16be|u16le|u32be|u32le))?\}\}`)
+
+// compileInputs validates and pre-decodes the inputs of the request
+func (r *Request) compileInputs(options *protocols.ExecuterOptions) error {
+	variables := make(map[string]struct{})
+	var hasRead bool
+	for i, input := range r.Inputs {
+		if input.Type == "extract" {
+			if err := input.compileExtract(variables, hasRead); err != nil {
+				return errors.Wrapf(err, "[%s] input %d", options.TemplateID, i)
+			}
+			continue
+		}
+		if input.Type == "starttls" {
+			if err := r.compileStartTLS(input); err != nil {
+				return errors.Wrapf(err, "[%s] input %d", options.TemplateID, i)
+			}
+			continue
+		}
+		segments := input.Segments
+		if len(segments) == 0 {
+			segments = []*Segment{{Data: input.Data, Type: input.Type, File: input.File}}
+		} else if input.Data != "" || input.File != "" {
+			return errors.Errorf("[%s] input %d: data and file cannot be used with segments", options.TemplateID, i)
+		}
+
+		names := make(map[string]struct{})
+		input.compiled = make([]*compiledSegment, 0, len(segments))
+		for j, segment := range segments {
+			compiled, err := compileSegment(segment, options)
+			if err != nil {
+				if len(input.Segments) > 0 {
+					return errors.Wrapf(err, "[%s] input %d segment %d", options.TemplateID, i, j)
+				}
+				return errors.Wrapf(err, "[%s] input %d", options.TemplateID, i)
+			}
+			if segment.Name != "" {
+				if _, ok := names[segment.Name]; ok {
+					return errors.Errorf("[%s] input %d: duplicate segment name %s", options.TemplateID, i, segment.Name)
+				}
+				names[segment.Name] = struct{}{}
+			}
+			if compiled.hex {
+				for _, match := range variableRegex.FindAllStringSubmatch(compiled.text, -1) {
+					if _, ok := variables[match[1]]; !ok {
+						return errors.Errorf("[%s] input %d: unknown variable %s", options.TemplateID, i, match[1])
+					}
+				}
+			}
+			input.compiled = append(input.compiled, compiled)
+		}
+		if err := validateLengthPlaceholders(input.compiled); err != nil {
+			return errors.Wrapf(err, "[%s] input %d", options.TemplateID, i)
+		}
+		if err := input.compileRead(); err != nil {
+			return errors.Wrapf(err, "[%s] input %d", options.TemplateID, i)
+		}
+		if input.Read > 0 || len(input.delimiter) > 0 {
+			hasRead = true
+		}
+	}
+	return nil
+}
+
+// compileRead decodes the read delimiter and timeout of an input
+func (i *Input) compileRead() error {
+	switch i.ReadUntilType {
+	case "hex":
+		delimiter, err := decodeHexInput(i.ReadUntil)
+		if err != nil {
+			return errors.Wrap(err, "could not decode read-until")
+		}
+		i.delimiter = delimiter
+	case "", "text":
+		i.delimiter = []byte(i.ReadUntil)
+	default:
+		return errors.Errorf("invalid read-until type %s", i.ReadUntilType)
+	}
+	if i.Timeout != "" {
+		timeout, err := time.ParseDuration(i.Timeout)
+		if err != nil || timeout <= 0 {
+			return errors.Errorf("invalid timeout %s", i.Timeout)
+		}
+		i.timeout = timeout
+	}
+	return nil
+}
+
+// compileSegment decodes a segment returning the compiled segment
+func compileSegment(segment *Segment, options *protocols.ExecuterOptions) (*compiledSegment, error) {
+	compiled := &compiledSegment{name: segment.Name, static: true, dump: segment.Data}
+
+	if segment.File != "" {
+		if segment.Data != "" {
+			return nil, errors.New("data and file cannot be used together")
+		}
+		data, err := loadInputFile(segment.File, options)
+		if err != nil {
+			return nil, err
+		}
+		compiled.data = data
+		compiled.dump = fmt.Sprintf("<file:%s>", segment.File)
+		return compiled, nil
+	}
+
+	switch segment.Type {
+	case "hex":
+		if variableRegex.MatchString(segment.Data) {
+			// Validate the data around the variables extracted later
+			if _, err := decodeHexInput(variableRegex.ReplaceAllString(segment.Data, "")); err != nil {
+				return nil, err
+			}
+			compiled.text = segment.Data
+			compiled.static = false
+			compiled.hex = true
+			break
+		}
+		data, err := decodeHexInput(segment.Data)
+		if err != nil {
+			return nil, err
+		}
+		compiled.data = data
+	case "base64":
+		data, err := decodeBase64Input(segment.Data)
+		if err != nil {
+			return nil, err
+		}
+		compiled.data = data
+	case "", "text":
+		// Pre-compile any input dsl functions before executing the request.
+		compiled.text = segment.Data
+		if evaluated, err := expressions.Evaluate(segment.Data, map[string]interface{}{}); err == nil {
+			compiled.text = evaluated
+		}
+		compiled.static = false
+	default:
+		return nil, errors.Errorf("invalid input type %s", segment.Type)
+	}
+	return compiled, nil
+}
+
+// decodeHexInput decodes hex input data ignoring whitespace
+func decodeHexInput(data string) ([]byte, error) {
+	var invalid []string
+	builder := &strings.Builder{}
+	for i, c := range data {
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			continue
+		case (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F'):
+			builder.WriteRune(c)
+		default:
+			invalid = append(invalid, fmt.Sprintf("%q at %d", c, i))
+		}
+	}
+	if len(invalid) > 0 {
+		return nil, invalidCharactersError("hex", invalid)
+	}
+	if builder.Len()%2 != 0 {
+		return nil, errors.New("invalid hex data: odd number of digits")
+	}
+	return hex.DecodeString(builder.String())
+}
+
+// decodeBase64Input decodes standard or url-safe base64 input data
+func decodeBase64Input(data string) ([]byte, error) {
+	var invalid []string
+	builder := &strings.Builder{}
+	for i, c := range data {
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			continue
+		case (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || strings.ContainsRune("+/-_=", c):
+			builder.WriteRune(c)
+		default:
+			invalid = append(invalid, fmt.Sprintf("%q at %d", c, i))
+		}
+	}
+	if len(invalid) > 0 {
+		return nil, invalidCharactersError("base64", invalid)
+	}
+	value := strings.TrimRight(builder.String(), "=")
+	encoding := base64.RawStdEncoding
+	if strings.ContainsAny(value, "-_") {
+		encoding = base64.RawURLEncoding
+	}
+	decoded, err := encoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid base64 data")
+	}
+	return decoded, nil
+}
+
+func invalidCharactersError(encoding string, invalid []string) error {
+	count := len(invalid)
+	if count > maxInvalidCharacters {
+		invalid = append(invalid[:maxInvalidCharacters], fmt.Sprintf("%d more", count-maxInvalidCharacters))
+	}
+	return errors.Errorf("invalid %s data: offending characters %s", encoding, strings.Join(invalid, ", "))
+}
+
+// loadInputFile loads a binary input file. Relative paths are resolved
+// from the template directory and the file must be inside the template
+// directory or the templates directory.
+func loadInputFile(file string, options *protocols.ExecuterOptions) ([]byte, error) {
+	var roots []string
+	if options.TemplatePath != "" {
+		roots = append(roots, filepath.Dir(options.TemplatePath))
+	}
+	if options.Options != nil && options.Options.TemplatesDirectory != "" {
+		roots = append(roots, options.Options.TemplatesDirectory)
+	}
+	if len(roots) == 0 {
+		return nil, errors.New("no directory to load input file from")
+	}
+
+	path := file
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(roots[0], path)
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not resolve input file")
+	}
+	if !insideDirectories(resolved, roots) {
+		return nil, errors.Errorf("input file %s is outside of the template directories", file)
+	}
+
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not stat input file")
+	}
+	if info.IsDir() || info.Size() > maxInputFileSize {
+		return nil, errors.Errorf("input file %s is not a regular file below %d bytes", file, maxInputFileSize)
+	}
+	data, err := ioutil.ReadFile(resolved)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read input file")
+	}
+	return data, nil
+}
+
+// insideDirectories returns true if path is inside any of the directories
+func insideDirectories(path string, directories []string) bool {
+	for _, directory := range directories {
+		resolved, err := filepath.EvalSymlinks(directory)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(resolved, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateLengthPlaceholders checks the length placeholders reference
+// named segments which do not contain length placeholders themselves.
+func validateLengthPlaceholders(segments []*compiledSegment) error {
+	named := make(map[string]*compiledSegment)
+	for _, segment := range segments {
+		if segment.name != "" {
+			named[segment.name] = segment
+		}
+	}
+	for _, segment := range segments {
+		if segment.static {
+			continue
+		}
+		for _, match := range lengthPlaceholderRegex.FindAllStringSubmatch(segment.text, -1) {
+			referenced, ok := named[match[1]]
+			if !ok {
+				return errors.Errorf("length placeholder %s references unknown segment", match[0])
+			}
+			if !referenced.static && lengthPlaceholderRegex.MatchString(referenced.text) {
+				return errors.Errorf("length placeholder %s references segment with length placeholders", match[0])
+			}
+		}
+	}
+	return nil
+}
+
+// build builds the data for an input substituting the extracted variables
+// and interactsh markers, computing the length placeholders after substitution.
+func (i *Input) build(interactshClient *interactsh.Client, interactURL string, variables map[string][]byte) ([]byte, string) {
+	values := make([][]byte, len(i.compiled))
+	dumps := make([]string, len(i.compiled))
+	named := make(map[string][]byte)
+	for j, segment := range i.compiled {
+		if segment.static {
+			values[j] = segment.data
+		} else if segment.hex {
+			// The data around the variables was validated at compile time
+			dumps[j] = replaceVariables(segment.text, variables, hex.EncodeToString)
+			values[j], _ = decodeHexInput(dumps[j])
+		} else {
+			text := replaceVariables(segment.text, variables, func(value []byte) string { return string(value) })
+			if interactURL != "" {
+				text = interactshClient.ReplaceMarkers(text, interactURL)
+			}
+			values[j] = []byte(text)
+		}
+		if segment.name != "" {
+			named[segment.name] = values[j]
+		}
+	}
+
+	data := make([]byte, 0, 64)
+	dump := &strings.Builder{}
+	for j, segment := range i.compiled {
+		value := values[j]
+		if !segment.static && strings.Contains(segment.text, "{{len:") {
+			value = lengthPlaceholderRegex.ReplaceAllFunc(value, func(match []byte) []byte {
+				parts := lengthPlaceholderRegex.FindSubmatch(match)
+				return encodeLength(len(named[string(parts[1])]), string(parts[2]))
+			})
+		}
+		data = append(data, value...)
+		if segment.static {
+			dump.WriteString(segment.dump)
+		} else if segment.hex {
+			dump.WriteString(dumps[j])
+		} else {
+			dump.Write(value)
+		}
+	}
+	return data, dump.String()
+}
+
+// encodeLength encodes a length in the format of a length placeholder
+func encodeLength(length int, format string) []byte {
+	switch format {
+	case "u8":
+		return []byte{byte(length)}
+	case "u16be":
+		data := make([]byte, 2)
+		binary.BigEndian.PutUint16(data, uint16(length))
+		return data
+	case "u16le":
+		data := make([]byte, 2)
+		binary.LittleEndian.PutUint16(data, uint16(length))
+		return data
+	case "u32be":
+		data := make([]byte, 4)
+		binary.BigEndian.PutUint32(data, uint32(length))
+		return data
+	case "u32le":
+		data := make([]byte, 4)
+		binary.LittleEndian.PutUint32(data, uint32(length))
+		return data
+	}
+	return []byte(strconv.Itoa(length))
+}
@@ -2,13 +2,18 @@ package network
 
 import (
 	"net"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/projectdiscovery/fastdialer/fastdialer"
 	"github.com/yaklang/nuclei/v2/pkg/operators"
 	"github.com/yaklang/nuclei/v2/pkg/protocols"
-	"github.com/yaklang/nuclei/v2/pkg/protocols/common/expressions"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/clientcert"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/tlsconfig"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/network/networkclientpool"
 )
 
@@ -16,40 +21,87 @@ import (
 type Request struct {
 	ID string `yaml:"id"`
 
-	// Address is the address to send requests to (host:port:tls combos generally)
+	// Address is the address to send requests to (host:port:tls combos generally).
+	// The inputs are sent as datagrams to the udp://host:port addresses.
+	// The port can be a comma separated list of ports, the port of the input
+	// is used for addresses without a port.
 	Address   []string `yaml:"host"`
 	addresses []addressKV
+	// Ports are the ports to send the requests to for addresses without a port
+	Ports []int `yaml:"ports"`
 
 	// Payload is the payload to send for the network request
 	Inputs []*Input `yaml:"inputs"`
 	// ReadSize is the size of response to read (1024 if not provided by default)
 	ReadSize int `yaml:"read-size"`
+	// ClientCertificate overrides the client certificate presented on tls
+	// connections. Relative paths are resolved from the template directory.
+	ClientCertificate *clientcert.Files `yaml:"client-certificate"`
+	// TLSSkipVerify disables the verification of the server certificate,
+	// even if a CA file is provided.
+	TLSSkipVerify bool `yaml:"tls-skip-verify"`
+	// TLSCAFile is the PEM encoded CA file to verify the server certificate
+	// with. Relative paths are resolved from the template directory.
+	TLSCAFile string `yaml:"tls-ca-file"`
+	// SNI is the server name sent on tls connections instead of the host.
+	SNI string `yaml:"sni"`
 
 	// Operators for the current request go here.
 	operators.Operators `yaml:",inline,omitempty"`
 	CompiledOperators   *operators.Operators
 
 	// cache any variables that may be needed for operation.
-	dialer  *fastdialer.Dialer
-	options *protocols.ExecuterOptions
+	dialer       *fastdialer.Dialer
+	certificate  *clientcert.Certificate
+	verification *tlsconfig.Verification
+	options      *protocols.ExecuterOptions
 }
 
 type addressKV struct {
 	ip   string
 	port string
 	tls  bool
+	udp  bool
 }
 
 // Input is the input to send on the network
 type Input struct {
 	// Data is the data to send as the input
 	Data string `yaml:"data"`
-	// Type is the type of input - hex, text.
+	// Type is the type of input - hex, base64, text, extract or starttls.
+	// Extract inputs capture bytes of the previous read into the variable
+	// of their name, which later inputs can reference as {{name}}.
+	// Starttls inputs upgrade the connection to tls for the next inputs.
 	Type string `yaml:"type"`
+	// File is a file to load the binary data to send from
+	File string `yaml:"file"`
+	// Segments are concatenated to build the input instead of data
+	Segments []*Segment `yaml:"segments"`
 	// Read is the number of bytes to read from socket
 	Read int `yaml:"read"`
+	// ReadUntil stops the read after the input at this delimiter, reading
+	// at most read bytes or the read-size of the request.
+	ReadUntil string `yaml:"read-until"`
+	// ReadUntilType is the encoding of the delimiter - hex or text.
+	ReadUntilType string `yaml:"read-until-type"`
+	// Timeout is the maximum duration of the read after the input, like 500ms
+	Timeout string `yaml:"timeout"`
 	// Name is the optional name of the input to provide matching on
 	Name string `yaml:"name"`
+	// Regex captures the first group, or the match, of extract inputs
+	Regex string `yaml:"regex"`
+	// Offset is the offset of the bytes captured by extract inputs
+	Offset int `yaml:"offset"`
+	// Length is the number of bytes captured by extract inputs at the offset
+	Length int `yaml:"length"`
+	// Protocol is the protocol of starttls inputs - smtp, imap, pop3 or ldap,
+	// whose starttls command is sent before the handshake if provided.
+	Protocol string `yaml:"protocol"`
+
+	compiled  []*compiledSegment
+	delimiter []byte
+	timeout   time.Duration
+	regex     *regexp.Regexp
 }
 
 // GetID returns the unique ID of the request if any.
@@ -57,43 +109,48 @@ func (r *Request) GetID() string {
 	return r.ID
 }
 
+// EventType returns the type of the result events of the request.
+func (r *Request) EventType() string {
+	return "network"
+}
+
 // Compile compiles the protocol request for further execution.
 func (r *Request) Compile(options *protocols.ExecuterOptions) error {
-	var shouldUseTLS bool
 	var err error
 
 	for _, address := range r.Address {
-		// check if the connection should be encrypted
-		if strings.HasPrefix(address, "tls://") {
-			shouldUseTLS = true
-			address = strings.TrimPrefix(address, "tls://")
-		}
-		if strings.Contains(address, ":") {
-			addressHost, addressPort, portErr := net.SplitHostPort(address)
-			if portErr != nil {
-				return errors.Wrap(portErr, "could not parse address")
-			}
-			r.addresses = append(r.addresses, addressKV{ip: addressHost, port: addressPort, tls: shouldUseTLS})
-		} else {
-			r.addresses = append(r.addresses, addressKV{ip: address, tls: shouldUseTLS})
+		addresses, err := r.compileAddress(address)
+		if err != nil {
+			return err
 		}
+		r.addresses = append(r.addresses, addresses...)
 	}
-	// Pre-compile any input dsl functions before executing the request.
-	for _, input := range r.Inputs {
-		if input.Type != "" {
-			continue
-		}
-		if compiled, evalErr := expressions.Evaluate(input.Data, map[string]interface{}{}); evalErr == nil {
-			input.Data = compiled
+	if err := r.compileInputs(options); err != nil {
+		return err
+	}
+
+	configuration := &networkclientpool.Configuration{}
+	if r.ClientCertificate != nil {
+		certificate, err := clientcert.Load(r.ClientCertificate.Resolve(filepath.Dir(options.TemplatePath)))
+		if err != nil {
+			return errors.Wrap(err, "could not load client certificate")
 		}
+		configuration.ClientCertificate = certificate
+	}
+	verification, err := tlsconfig.Load(r.TLSSkipVerify, r.TLSCAFile, filepath.Dir(options.TemplatePath))
+	if err != nil {
+		return errors.Wrap(err, "could not load tls settings")
 	}
+	configuration.Verification = verification
 
 	// Create a client for the class
-	client, err := networkclientpool.Get(options.Options, &networkclientpool.Configuration{})
+	client, err := networkclientpool.Get(options.Options, configuration)
 	if err != nil {
 		return errors.Wrap(err, "could not get network client")
 	}
 	r.dialer = client
+	r.certificate = configuration.Certificate()
+	r.verification = configuration.Verification
 
 	if len(r.Matchers) > 0 || len(r.Extractors) > 0 {
 		compiled := &r.Operators
@@ -106,7 +163,56 @@ func (r *Request) Compile(options *protocols.ExecuterOptions) error {
 	return nil
 }
 
+// compileAddress parses an address expanding it for each of its ports
+func (r *Request) compileAddress(address string) ([]addressKV, error) {
+	// check if the connection should be encrypted
+	var shouldUseTLS bool
+	if strings.HasPrefix(address, "tls://") {
+		shouldUseTLS = true
+		address = strings.TrimPrefix(address, "tls://")
+	}
+	// check if the inputs should be sent as datagrams
+	var shouldUseUDP bool
+	if strings.HasPrefix(address, "udp://") {
+		shouldUseUDP = true
+		address = strings.TrimPrefix(address, "udp://")
+	}
+	kv := addressKV{ip: address, tls: shouldUseTLS && !shouldUseUDP, udp: shouldUseUDP}
+
+	var ports []string
+	if strings.Contains(address, ":") {
+		addressHost, addressPort, portErr := net.SplitHostPort(address)
+		if portErr != nil {
+			return nil, errors.Wrap(portErr, "could not parse address")
+		}
+		kv.ip = addressHost
+		ports = strings.Split(addressPort, ",")
+	} else {
+		for _, port := range r.Ports {
+			ports = append(ports, strconv.Itoa(port))
+		}
+	}
+	if len(ports) == 0 {
+		return []addressKV{kv}, nil
+	}
+
+	addresses := make([]addressKV, 0, len(ports))
+	for _, port := range ports {
+		port = strings.TrimSpace(port)
+		if value, err := strconv.Atoi(port); (err != nil || value <= 0 || value > 65535) && !strings.Contains(port, "{{") {
+			return nil, errors.Errorf("invalid port %s in address %s", port, address)
+		}
+		portKV := kv
+		portKV.port = port
+		addresses = append(addresses, portKV)
+	}
+	return addresses, nil
+}
+
 // Requests returns the total number of requests the YAML rule will perform
 func (r *Request) Requests() int {
+	if len(r.addresses) > 0 {
+		return len(r.addresses)
+	}
 	return len(r.Address)
 }
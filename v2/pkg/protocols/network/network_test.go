@@ -1,6 +1,9 @@
 package network
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/yaklang/nuclei/v2/internal/testutils"
@@ -38,4 +41,127 @@ func TestNetworkCompileMake(t *testing.T) {
 		require.Equal(t, "443", request.addresses[2].port, "could not get correct port for host")
 		require.True(t, request.addresses[2].tls, "could not get correct port for host")
 	})
+
+	t.Run("ports", func(t *testing.T) {
+		request := &Request{
+			ID:      templateID,
+			Address: []string{"{{Hostname}}:6379,6380, 7000", "tls://{{Hostname}}", "udp://{{Hostname}}:53"},
+			Ports:   []int{443, 8443},
+			Inputs:  []*Input{{Data: "test-data"}},
+		}
+		err := request.Compile(executerOpts)
+		require.Nil(t, err, "could not compile network request with ports")
+		require.Equal(t, 6, request.Requests(), "could not expand ports")
+
+		var ports []string
+		for _, address := range request.addresses {
+			ports = append(ports, address.port)
+		}
+		require.Equal(t, []string{"6379", "6380", "7000", "443", "8443", "53"}, ports, "could not get expanded ports")
+		require.True(t, request.addresses[4].tls, "could not get tls for expanded ports")
+		require.False(t, request.addresses[5].tls, "could get tls for address after tls address")
+
+		request = &Request{ID: templateID, Address: []string{"{{Hostname}}:80,http"}}
+		err = request.Compile(executerOpts)
+		require.NotNil(t, err, "could compile address with invalid port")
+	})
+}
+
+func TestNetworkCompileInputs(t *testing.T) {
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-network-inputs"
+
+	dir, err := ioutil.TempDir("", "nuclei-network-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(dir)
+	err = ioutil.WriteFile(filepath.Join(dir, "payload.bin"), []byte{0xde, 0xad, 0xbe, 0xef}, 0644)
+	require.Nil(t, err, "could not write payload file")
+
+	compile := func(inputs ...*Input) (*Request, error) {
+		request := &Request{ID: templateID, Address: []string{"{{Hostname}}"}, Inputs: inputs}
+		executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+			ID:   templateID,
+			Info: map[string]interface{}{"severity": "low", "name": "test"},
+			Path: filepath.Join(dir, "template.yaml"),
+		})
+		return request, request.Compile(executerOpts)
+	}
+
+	t.Run("decode", func(t *testing.T) {
+		request, err := compile(&Input{Data: "de ad\nbe ef", Type: "hex"}, &Input{Data: "aGVsbG8=", Type: "base64"}, &Input{File: "payload.bin"})
+		require.Nil(t, err, "could not compile inputs")
+
+		data, _ := request.Inputs[0].build(nil, "", nil)
+		require.Equal(t, []byte{0xde, 0xad, 0xbe, 0xef}, data, "could not decode hex input")
+		data, _ = request.Inputs[1].build(nil, "", nil)
+		require.Equal(t, []byte("hello"), data, "could not decode base64 input")
+		data, _ = request.Inputs[2].build(nil, "", nil)
+		require.Equal(t, []byte{0xde, 0xad, 0xbe, 0xef}, data, "could not load file input")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := compile(&Input{Data: "0001"}, &Input{Data: "00zz01", Type: "hex"})
+		require.NotNil(t, err, "invalid hex input was compiled")
+		require.Contains(t, err.Error(), "[testing-network-inputs] input 1", "could not name template and input")
+		require.Contains(t, err.Error(), `'z' at 2, 'z' at 3`, "could not name offending characters")
+
+		_, err = compile(&Input{Data: "aGVs!G8=", Type: "base64"})
+		require.Contains(t, err.Error(), `'!' at 4`, "could not name offending base64 characters")
+
+		_, err = compile(&Input{File: "../outside.bin"})
+		require.NotNil(t, err, "file outside of template directory was loaded")
+	})
+
+	t.Run("length", func(t *testing.T) {
+		request, err := compile(&Input{Segments: []*Segment{
+			{Data: "0102", Type: "hex"},
+			{Data: "{{len:body:u16be}}"},
+			{Data: "size={{len:body}};"},
+			{Data: "ping {{interactsh-url}}", Name: "body"},
+		}})
+		require.Nil(t, err, "could not compile segmented input")
+
+		data, dump := request.Inputs[0].build(nil, "", nil)
+		require.Equal(t, append([]byte{0x01, 0x02, 0x00, 0x17}, []byte("size=23;ping {{interactsh-url}}")...), data, "could not compute length placeholders")
+		require.Contains(t, dump, "0102\x00\x17size=23;", "could not dump segmented input")
+
+		_, err = compile(&Input{Segments: []*Segment{{Data: "{{len:missing}}"}}})
+		require.NotNil(t, err, "unknown length placeholder segment was compiled")
+	})
+
+	t.Run("extract", func(t *testing.T) {
+		request, err := compile(
+			&Input{Data: "", Read: 16},
+			&Input{Type: "extract", Name: "nonce", Offset: 2, Length: 2},
+			&Input{Type: "extract", Name: "token", Regex: "token=([a-z]+)"},
+			&Input{Data: "ff {{nonce}}", Type: "hex"},
+			&Input{Segments: []*Segment{{Data: "{{token}}:"}, {Data: "{{len:token}}"}, {Data: "{{nonce}}", Name: "token"}}},
+		)
+		require.Nil(t, err, "could not compile extract inputs")
+
+		nonce, ok := request.Inputs[1].extract([]byte("\x01\x02\xbe\xeftoken=abc"))
+		require.True(t, ok, "could not extract offset")
+		require.Equal(t, []byte{0xbe, 0xef}, nonce, "could not extract offset bytes")
+		token, ok := request.Inputs[2].extract([]byte("\x01\x02\xbe\xeftoken=abc"))
+		require.True(t, ok, "could not extract regex")
+		require.Equal(t, []byte("abc"), token, "could not extract regex group")
+		_, ok = request.Inputs[1].extract([]byte{0x01, 0x02})
+		require.False(t, ok, "could extract bytes out of range")
+
+		variables := map[string][]byte{"nonce": nonce, "token": token}
+		data, dump := request.Inputs[3].build(nil, "", variables)
+		require.Equal(t, []byte{0xff, 0xbe, 0xef}, data, "could not concatenate hex variable")
+		require.Equal(t, "ff beef", dump, "could not dump hex variable")
+		data, _ = request.Inputs[4].build(nil, "", variables)
+		require.Equal(t, []byte("abc:2\xbe\xef"), data, "could not replace text variables")
+
+		_, err = compile(&Input{Type: "extract", Name: "nonce", Length: 2})
+		require.NotNil(t, err, "extract input without read was compiled")
+		_, err = compile(&Input{Data: "", Read: 16}, &Input{Type: "extract", Name: "nonce"})
+		require.NotNil(t, err, "extract input without regex or length was compiled")
+		_, err = compile(&Input{Data: "{{nonce}}", Type: "hex"})
+		require.NotNil(t, err, "hex input with unknown variable was compiled")
+	})
 }
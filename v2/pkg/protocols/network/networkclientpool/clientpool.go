@@ -2,7 +2,9 @@ package networkclientpool
 
 import (
 	"github.com/projectdiscovery/fastdialer/fastdialer"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/clientcert"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/common/protocolstate"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/tlsconfig"
 	"github.com/yaklang/nuclei/v2/pkg/types"
 )
 
@@ -21,10 +23,18 @@ func Init(options *types.Options) error {
 }
 
 // Configuration contains the custom configuration options for a client
-type Configuration struct{}
+type Configuration struct {
+	// ClientCertificate overrides the client certificate configured by the user
+	ClientCertificate *clientcert.Certificate
+	// Verification overrides the verification of the servers
+	Verification *tlsconfig.Verification
+}
 
 // Hash returns the hash of the configuration to allow client pooling
 func (c *Configuration) Hash() string {
+	if c.Verification != nil {
+		return c.Verification.Hash()
+	}
 	return ""
 }
 
@@ -32,3 +42,12 @@ func (c *Configuration) Hash() string {
 func Get(options *types.Options, configuration *Configuration) (*fastdialer.Dialer, error) {
 	return normalClient, nil
 }
+
+// Certificate returns the client certificate to present on tls connections
+// for the configuration if any.
+func (c *Configuration) Certificate() *clientcert.Certificate {
+	if c.ClientCertificate != nil {
+		return c.ClientCertificate
+	}
+	return protocolstate.ClientCertificate
+}
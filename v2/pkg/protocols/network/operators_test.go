@@ -67,7 +67,7 @@ func TestNetworkOperatorMatch(t *testing.T) {
 		err = matcher.CompileMatchers()
 		require.Nil(t, err, "could not compile matcher")
 
-		matched := request.Match(event, matcher)
+		matched, _ := request.Match(event, matcher)
 		require.True(t, matched, "could not match valid response")
 	})
 
@@ -81,7 +81,7 @@ func TestNetworkOperatorMatch(t *testing.T) {
 		err := matcher.CompileMatchers()
 		require.Nil(t, err, "could not compile negative matcher")
 
-		matched := request.Match(event, matcher)
+		matched, _ := request.Match(event, matcher)
 		require.True(t, matched, "could not match valid negative response matcher")
 	})
 
@@ -94,9 +94,27 @@ func TestNetworkOperatorMatch(t *testing.T) {
 		err := matcher.CompileMatchers()
 		require.Nil(t, err, "could not compile matcher")
 
-		matched := request.Match(event, matcher)
+		matched, _ := request.Match(event, matcher)
 		require.False(t, matched, "could match invalid response matcher")
 	})
+
+	t.Run("binary", func(t *testing.T) {
+		matcher := &matchers.Matcher{
+			Part:   "data",
+			Type:   "binary",
+			Binary: []string{"4d5a9000ff"},
+		}
+		err := matcher.CompileMatchers()
+		require.Nil(t, err, "could not compile binary matcher")
+
+		binaryEvent := request.responseToDSLMap(req, "\x00\xfeMZ\x90\x00\xff\xfe", "one.one.one.one", "one.one.one.one", "test")
+		matched, snippets := request.Match(binaryEvent, matcher)
+		require.True(t, matched, "could not match valid binary response")
+		require.Equal(t, []string{"4d5a9000ff"}, snippets, "could not get matched binary")
+
+		matched, _ = request.Match(event, matcher)
+		require.False(t, matched, "could match invalid binary response")
+	})
 }
 
 func TestNetworkOperatorExtract(t *testing.T) {
@@ -196,3 +214,38 @@ func TestNetworkMakeResult(t *testing.T) {
 	require.Equal(t, "test", finalEvent.Results[0].MatcherName, "could not get correct matcher name of results")
 	require.Equal(t, "1.1.1.1", finalEvent.Results[0].ExtractedResults[0], "could not get correct extracted results")
 }
+
+func TestNetworkMakeResultRequests(t *testing.T) {
+	options := *testutils.DefaultOptions
+	options.JSONRequests = true
+	options.JSONRequestsMaxSize = 4
+
+	testutils.Init(&options)
+	templateID := "testing-network-requests"
+	request := &Request{
+		ID:       templateID,
+		Address:  []string{"{{Hostname}}"},
+		ReadSize: 1024,
+		Inputs:   []*Input{{Data: "test-data\r\n"}},
+		Operators: operators.Operators{
+			Matchers: []*matchers.Matcher{{Part: "data", Type: "word", Words: []string{"STAT "}}},
+		},
+	}
+	executerOpts := testutils.NewMockExecuterOptions(&options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	err := request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile network request")
+
+	event := request.responseToDSLMap("\x00\x01data", "\xffSTAT \r\n", "one.one.one.one", "one.one.one.one", "test")
+	finalEvent := &output.InternalWrappedEvent{InternalEvent: event}
+	result, ok := request.CompiledOperators.Execute(event, request.Match, request.Extract)
+	require.True(t, ok, "could not match network response")
+	finalEvent.OperatorsResult = result
+	finalEvent.Results = request.MakeResultEvent(finalEvent)
+
+	require.Equal(t, 1, len(finalEvent.Results), "could not get correct number of results")
+	require.Equal(t, "00016461", finalEvent.Results[0].Request, "could not get hex encoded request")
+	require.Equal(t, "ff535441", finalEvent.Results[0].Response, "could not get hex encoded response")
+}
@@ -1,6 +1,8 @@
 package network
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/hex"
 	"io"
@@ -15,6 +17,8 @@ import (
 	"github.com/yaklang/nuclei/v2/pkg/protocols"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/common/interactsh"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/common/replacer"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/tlsconfig"
+	"github.com/yaklang/nuclei/v2/pkg/types"
 )
 
 var _ protocols.Request = &Request{}
@@ -37,7 +41,7 @@ func (r *Request) ExecuteWithResults(input string, metadata, previous output.Int
 			actualAddress = net.JoinHostPort(actualAddress, kv.port)
 		}
 
-		err = r.executeAddress(actualAddress, address, input, kv.tls, previous, callback)
+		err = r.executeAddress(actualAddress, address, input, kv, previous, callback)
 		if err != nil {
 			gologger.Verbose().Label("ERR").Msgf("Could not make network request for %s: %s\n", actualAddress, err)
 			continue
@@ -47,7 +51,7 @@ func (r *Request) ExecuteWithResults(input string, metadata, previous output.Int
 }
 
 // executeAddress executes the request for an address
-func (r *Request) executeAddress(actualAddress, address, input string, shouldUseTLS bool, previous output.InternalEvent, callback protocols.OutputEventCallback) error {
+func (r *Request) executeAddress(actualAddress, address, input string, kv addressKV, previous output.InternalEvent, callback protocols.OutputEventCallback) error {
 	if !strings.Contains(actualAddress, ":") {
 		err := errors.New("no port provided in network protocol request")
 		r.options.Output.Request(r.options.TemplateID, address, "network", err)
@@ -65,10 +69,21 @@ func (r *Request) executeAddress(actualAddress, address, input string, shouldUse
 		hostname = host
 	}
 
-	if shouldUseTLS {
-		conn, err = r.dialer.DialTLS(context.Background(), "tcp", actualAddress)
+	timeout := r.options.Options.ProtocolTimeout(types.NetworkProtocol)
+	timeStart := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	serverName := replacer.Replace(r.SNI, map[string]interface{}{"Hostname": hostname})
+	if serverName == "" {
+		serverName = hostname
+	}
+	if kv.udp {
+		conn, err = r.dialer.Dial(ctx, "udp", actualAddress)
+	} else if kv.tls {
+		conn, err = tlsconfig.DialTLSWithServerName(ctx, r.dialer, "tcp", actualAddress, serverName, r.certificate, r.verification)
 	} else {
-		conn, err = r.dialer.Dial(context.Background(), "tcp", actualAddress)
+		conn, err = r.dialer.Dial(ctx, "tcp", actualAddress)
 	}
 	if err != nil {
 		r.options.Output.Request(r.options.TemplateID, address, "network", err)
@@ -76,7 +91,19 @@ func (r *Request) executeAddress(actualAddress, address, input string, shouldUse
 		return errors.Wrap(err, "could not connect to server request")
 	}
 	defer conn.Close()
-	_ = conn.SetReadDeadline(time.Now().Add(time.Duration(r.options.Options.Timeout) * time.Second))
+	// The certificate is available to the matchers even without inputs
+	tlsEvents := connectionStateEvents(conn)
+
+	deadline := time.Now().Add(timeout)
+	_ = conn.SetReadDeadline(deadline)
+
+	bufferSize := 1024
+	if r.ReadSize != 0 {
+		bufferSize = r.ReadSize
+	}
+	// The reads are buffered to stop at the delimiters of the inputs,
+	// the size fits the largest udp datagrams.
+	reader := bufio.NewReaderSize(conn, 65536)
 
 	hasInteractMarkers := interactsh.HasMatchers(r.CompiledOperators)
 	var interactURL string
@@ -86,27 +113,40 @@ func (r *Request) executeAddress(actualAddress, address, input string, shouldUse
 
 	responseBuilder := &strings.Builder{}
 	reqBuilder := &strings.Builder{}
+	sent := &bytes.Buffer{}
 
 	inputEvents := make(map[string]interface{})
+	variables := make(map[string][]byte)
+	var lastRead []byte
 	for _, input := range r.Inputs {
-		var data []byte
-
-		switch input.Type {
-		case "hex":
-			data, err = hex.DecodeString(input.Data)
-		default:
-			if interactURL != "" {
-				input.Data = r.options.Interactsh.ReplaceMarkers(input.Data, interactURL)
+		if input.Type == "extract" {
+			value, ok := input.extract(lastRead)
+			if !ok {
+				err = errors.Errorf("could not extract %s from the response", input.Name)
+				r.options.Output.Request(r.options.TemplateID, address, "network", err)
+				r.options.Progress.IncrementFailedRequestsBy(1)
+				return err
 			}
-			data = []byte(input.Data)
+			variables[input.Name] = value
+			inputEvents[input.Name] = string(value)
+			continue
 		}
-		if err != nil {
-			r.options.Output.Request(r.options.TemplateID, address, "network", err)
-			r.options.Progress.IncrementFailedRequestsBy(1)
-			return errors.Wrap(err, "could not write request to server")
+		if input.Type == "starttls" {
+			tlsConn, err := r.startTLS(conn, reader, input, serverName)
+			if err != nil {
+				r.options.Output.Request(r.options.TemplateID, address, "network", err)
+				r.options.Progress.IncrementFailedRequestsBy(1)
+				return errors.Wrap(err, "could not upgrade connection to tls")
+			}
+			// The underlying connection is closed with the deferred close
+			conn = tlsConn
+			reader = bufio.NewReaderSize(conn, 65536)
+			tlsEvents = connectionStateEvents(conn)
+			continue
 		}
-		reqBuilder.Grow(len(input.Data))
-		reqBuilder.WriteString(input.Data)
+		data, dump := input.build(r.options.Interactsh, interactURL, variables)
+		reqBuilder.Grow(len(dump))
+		reqBuilder.WriteString(dump)
 
 		_, err = conn.Write(data)
 		if err != nil {
@@ -114,13 +154,20 @@ func (r *Request) executeAddress(actualAddress, address, input string, shouldUse
 			r.options.Progress.IncrementFailedRequestsBy(1)
 			return errors.Wrap(err, "could not write request to server")
 		}
+		sent.Write(data)
+		if r.options.Options.Debug {
+			r.debugHexDump("Sent", actualAddress, data)
+		}
 
-		if input.Read > 0 {
-			buffer := make([]byte, input.Read)
-			n, _ := conn.Read(buffer)
-			responseBuilder.Write(buffer[:n])
+		if input.Read > 0 || len(input.delimiter) > 0 {
+			data := readInput(conn, reader, input, bufferSize, deadline)
+			if r.options.Options.Debug {
+				r.debugHexDump("Received", actualAddress, data)
+			}
+			responseBuilder.Write(data)
+			lastRead = data
 			if input.Name != "" {
-				inputEvents[input.Name] = string(buffer[:n])
+				inputEvents[input.Name] = string(data)
 			}
 		}
 	}
@@ -131,33 +178,57 @@ func (r *Request) executeAddress(actualAddress, address, input string, shouldUse
 		gologger.Print().Msgf("%s", reqBuilder.String())
 	}
 
-	r.options.Output.Request(r.options.TemplateID, actualAddress, "network", err)
-	gologger.Verbose().Msgf("Sent TCP request to %s", actualAddress)
-
-	bufferSize := 1024
-	if r.ReadSize != 0 {
-		bufferSize = r.ReadSize
+	if kv.udp {
+		gologger.Verbose().Msgf("Sent UDP request to %s", actualAddress)
+	} else {
+		gologger.Verbose().Msgf("Sent TCP request to %s", actualAddress)
 	}
+
 	final := make([]byte, bufferSize)
-	n, err := conn.Read(final)
-	if err != nil && err != io.EOF {
-		r.options.Output.Request(r.options.TemplateID, address, "network", err)
+	n, err := reader.Read(final)
+	duration := time.Since(timeStart)
+	r.options.Progress.RecordLatency(actualAddress, duration)
+	// A tls handshake or a udp reply already read by the inputs is enough of
+	// a response, the servers may not send anything more before the timeout.
+	if err != nil && err != io.EOF && !(isTimeout(err) && (len(tlsEvents) > 0 || (kv.udp && responseBuilder.Len() > 0))) {
+		r.options.Output.RequestWithData(r.options.TemplateID, address, "network", sent.Bytes(), []byte(responseBuilder.String()), err)
 		return errors.Wrap(err, "could not read from server")
 	}
+	if r.options.Options.Debug {
+		r.debugHexDump("Received", actualAddress, final[:n])
+	}
 	responseBuilder.Write(final[:n])
+	r.options.Output.RequestWithData(r.options.TemplateID, actualAddress, "network", sent.Bytes(), []byte(responseBuilder.String()), nil)
 
 	if r.options.Options.Debug || r.options.Options.DebugResponse {
 		gologger.Debug().Msgf("[%s] Dumped Network response for %s", r.options.TemplateID, actualAddress)
 		gologger.Print().Msgf("%s", responseBuilder.String())
 	}
 	outputEvent := r.responseToDSLMap(reqBuilder.String(), string(final[:n]), responseBuilder.String(), input, actualAddress)
+	outputEvent["duration"] = duration.Seconds()
+	outputEvent["bytes_read"] = responseBuilder.Len()
 	outputEvent["ip"] = r.dialer.GetDialedIP(hostname)
+	if remoteIP, remotePort, splitErr := net.SplitHostPort(conn.RemoteAddr().String()); splitErr == nil {
+		outputEvent["ip"] = remoteIP
+		outputEvent["port"] = remotePort
+	}
+	switch {
+	case kv.udp:
+		outputEvent["scheme"] = "udp"
+	case kv.tls:
+		outputEvent["scheme"] = "tls"
+	default:
+		outputEvent["scheme"] = "tcp"
+	}
 	for k, v := range previous {
 		outputEvent[k] = v
 	}
 	for k, v := range inputEvents {
 		outputEvent[k] = v
 	}
+	for k, v := range tlsEvents {
+		outputEvent[k] = v
+	}
 
 	event := &output.InternalWrappedEvent{InternalEvent: outputEvent}
 	if !hasInteractMarkers {
@@ -181,6 +252,43 @@ func (r *Request) executeAddress(actualAddress, address, input string, shouldUse
 	return nil
 }
 
+// readInput reads the response to an input until its delimiter, its size
+// or the default size is read or its timeout is reached.
+func readInput(conn net.Conn, reader *bufio.Reader, input *Input, defaultSize int, deadline time.Time) []byte {
+	size := input.Read
+	if size <= 0 {
+		size = defaultSize
+	}
+	if input.timeout > 0 {
+		_ = conn.SetReadDeadline(time.Now().Add(input.timeout))
+		defer func() { _ = conn.SetReadDeadline(deadline) }()
+	}
+
+	if len(input.delimiter) == 0 {
+		buffer := make([]byte, size)
+		n, _ := reader.Read(buffer)
+		return buffer[:n]
+	}
+	data := make([]byte, 0, size)
+	for len(data) < size {
+		b, err := reader.ReadByte()
+		if err != nil {
+			break
+		}
+		data = append(data, b)
+		if bytes.HasSuffix(data, input.delimiter) {
+			break
+		}
+	}
+	return data
+}
+
+// debugHexDump prints a hexdump of the data sent to or received from an address
+func (r *Request) debugHexDump(direction, address string, data []byte) {
+	gologger.Debug().Msgf("[%s] %s %d bytes for %s", r.options.TemplateID, direction, len(data), address)
+	gologger.Print().Msgf("%s", hex.Dump(data))
+}
+
 // getAddress returns the address of the host to make request to
 func getAddress(toTest string) (string, error) {
 	if strings.Contains(toTest, "://") {
@@ -1,18 +1,31 @@
 package network
 
 import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
 	"encoding/hex"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/yaklang/nuclei/v2/internal/testutils"
+	"github.com/yaklang/nuclei/v2/internal/testutils/certificates"
 	"github.com/yaklang/nuclei/v2/pkg/operators"
 	"github.com/yaklang/nuclei/v2/pkg/operators/extractors"
 	"github.com/yaklang/nuclei/v2/pkg/operators/matchers"
 	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/clientcert"
+	"github.com/yaklang/nuclei/v2/pkg/types"
 	"github.com/stretchr/testify/require"
 )
 
@@ -71,6 +84,11 @@ func TestNetworkExecuteWithResults(t *testing.T) {
 	require.Equal(t, "test", finalEvent.Results[0].MatcherName, "could not get correct matcher name of results")
 	require.Equal(t, 1, len(finalEvent.Results[0].ExtractedResults), "could not get correct number of extracted results")
 	require.Equal(t, "<h1>Example Domain</h1>", finalEvent.Results[0].ExtractedResults[0], "could not get correct extracted results")
+	require.Contains(t, finalEvent.InternalEvent, "duration", "could not get duration of request")
+	require.Equal(t, "127.0.0.1", finalEvent.InternalEvent["ip"], "could not get ip of request")
+	require.Equal(t, parsed.Port(), finalEvent.InternalEvent["port"], "could not get port of request")
+	require.Equal(t, "tcp", finalEvent.InternalEvent["scheme"], "could not get scheme of request")
+	require.Equal(t, "127.0.0.1", finalEvent.Results[0].IP, "could not get ip of result")
 	finalEvent = nil
 
 	t.Run("invalid-port-override", func(t *testing.T) {
@@ -106,6 +124,59 @@ func TestNetworkExecuteWithResults(t *testing.T) {
 	require.Equal(t, "<h1>Example Domain</h1>", finalEvent.Results[0].ExtractedResults[0], "could not get correct extracted results")
 }
 
+func TestNetworkClientCertificate(t *testing.T) {
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-network-client-certificate"
+
+	dir, err := ioutil.TempDir("", "nuclei-client-certificate-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(dir)
+	generated, err := certificates.Generate(dir)
+	require.Nil(t, err, "could not generate certificates")
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(exampleBody))
+	}))
+	ts.TLS = &tls.Config{
+		Certificates: []tls.Certificate{generated.Server},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    generated.Pool,
+	}
+	ts.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	ts.StartTLS()
+	defer ts.Close()
+
+	parsed, err := url.Parse(ts.URL)
+	require.Nil(t, err, "could not parse url")
+
+	request := &Request{
+		ID:                templateID,
+		Address:           []string{"tls://{{Hostname}}:" + parsed.Port()},
+		Inputs:            []*Input{{Data: fmt.Sprintf("GET / HTTP/1.1\r\nHost: %s\r\n\r\n", parsed.Host)}},
+		ReadSize:          2048,
+		ClientCertificate: &clientcert.Files{Cert: generated.ClientCertFile, Key: generated.ClientKeyFile, CA: generated.CAFile},
+		Operators: operators.Operators{
+			Matchers: []*matchers.Matcher{{Part: "data", Type: "word", Words: []string{"200 OK"}}},
+		},
+	}
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	err = request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile network request")
+
+	var finalEvent *output.InternalWrappedEvent
+	err = request.ExecuteWithResults(parsed.Host, make(output.InternalEvent), make(output.InternalEvent), func(event *output.InternalWrappedEvent) {
+		finalEvent = event
+	})
+	require.Nil(t, err, "could not execute network request")
+	require.NotNil(t, finalEvent, "could not get event output from request")
+	require.Equal(t, 1, len(finalEvent.Results), "could not authenticate with client certificate")
+}
+
 var exampleBody = `<!doctype html>
 <html>
 <head>
@@ -153,3 +224,497 @@ var exampleBody = `<!doctype html>
 </body>
 </html>
 `
+
+func TestNetworkExecuteUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err, "could not listen for udp server")
+	defer conn.Close()
+
+	// The server answers each datagram with its reversed bytes
+	go func() {
+		buffer := make([]byte, 1024)
+		for {
+			n, addr, err := conn.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+			reply := make([]byte, n)
+			for i := 0; i < n; i++ {
+				reply[i] = buffer[n-1-i]
+			}
+			_, _ = conn.WriteTo(reply, addr)
+		}
+	}()
+	_, port, err := net.SplitHostPort(conn.LocalAddr().String())
+	require.Nil(t, err, "could not get udp server port")
+
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-network"
+	request := &Request{
+		ID:      templateID,
+		Address: []string{"udp://{{Hostname}}:" + port},
+		Inputs:  []*Input{{Data: "00ff30a1c3", Type: "hex"}},
+		Operators: operators.Operators{
+			Matchers: []*matchers.Matcher{{
+				Name:   "reversed",
+				Part:   "data",
+				Type:   "binary",
+				Binary: []string{"c3a130ff00"},
+			}},
+		},
+	}
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	err = request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile network request")
+	require.True(t, request.addresses[0].udp, "could not get udp address")
+	require.False(t, request.addresses[0].tls, "could get tls udp address")
+
+	var finalEvent *output.InternalWrappedEvent
+	err = request.ExecuteWithResults("127.0.0.1", make(output.InternalEvent), make(output.InternalEvent), func(event *output.InternalWrappedEvent) {
+		finalEvent = event
+	})
+	require.Nil(t, err, "could not execute network request")
+	require.NotNil(t, finalEvent, "could not get event output from request")
+	require.Equal(t, "udp", finalEvent.InternalEvent["scheme"], "could not get udp scheme")
+	require.Equal(t, string([]byte{0xc3, 0xa1, 0x30, 0xff, 0x00}), finalEvent.InternalEvent["data"], "could not get udp response")
+	require.Len(t, finalEvent.Results, 1, "could not match udp response")
+}
+
+func TestNetworkExecuteUDPRead(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err, "could not listen for udp server")
+	defer conn.Close()
+
+	// The server echoes each datagram back once
+	go func() {
+		buffer := make([]byte, 1024)
+		for {
+			n, addr, err := conn.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+			_, _ = conn.WriteTo(buffer[:n], addr)
+		}
+	}()
+	_, port, err := net.SplitHostPort(conn.LocalAddr().String())
+	require.Nil(t, err, "could not get udp server port")
+
+	options := *testutils.DefaultOptions
+	options.TimeoutNetwork = 1
+
+	testutils.Init(&options)
+	templateID := "testing-network"
+	request := &Request{
+		ID:      templateID,
+		Address: []string{"udp://{{Hostname}}:" + port},
+		Inputs:  []*Input{{Data: "ping", Read: 4, Name: "reply"}},
+		Operators: operators.Operators{
+			Matchers: []*matchers.Matcher{{
+				Part:  "reply",
+				Type:  "word",
+				Words: []string{"ping"},
+			}},
+		},
+	}
+	executerOpts := testutils.NewMockExecuterOptions(&options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	err = request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile network request")
+
+	var finalEvent *output.InternalWrappedEvent
+	err = request.ExecuteWithResults("127.0.0.1", make(output.InternalEvent), make(output.InternalEvent), func(event *output.InternalWrappedEvent) {
+		finalEvent = event
+	})
+	require.Nil(t, err, "could not execute network request")
+	require.NotNil(t, finalEvent, "could not get event output from request")
+	require.Equal(t, "ping", finalEvent.InternalEvent["reply"], "could not get udp reply")
+	require.Len(t, finalEvent.Results, 1, "could not match udp reply")
+}
+
+func TestNetworkExecuteProtocolTimeout(t *testing.T) {
+	// The server reads the requests without ever answering them
+	server := testutils.NewTCPServer(func(conn net.Conn) {
+		defer conn.Close()
+		_, _ = io.Copy(ioutil.Discard, conn)
+	})
+	defer server.Close()
+
+	options := *testutils.DefaultOptions
+	options.Timeout = 30
+	options.TimeoutNetwork = 1
+
+	testutils.Init(&options)
+	templateID := "testing-network"
+	request := &Request{
+		ID:      templateID,
+		Address: []string{"{{Hostname}}"},
+		Inputs:  []*Input{{Data: "PING\r\n"}},
+	}
+	executerOpts := testutils.NewMockExecuterOptions(&options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	err := request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile network request")
+
+	var events int
+	started := time.Now()
+	_ = request.ExecuteWithResults(server.URL, make(output.InternalEvent), make(output.InternalEvent), func(event *output.InternalWrappedEvent) {
+		events++
+	})
+	require.Zero(t, events, "could read response from silent server")
+	require.Less(t, time.Since(started), 5*time.Second, "could not use network protocol timeout")
+}
+
+func TestNetworkExecuteReadUntil(t *testing.T) {
+	server := testutils.NewTCPServer(func(conn net.Conn) {
+		defer conn.Close()
+
+		_, _ = conn.Write([]byte("220 ready\r\n"))
+		buffer := make([]byte, 1024)
+		if _, err := conn.Read(buffer); err != nil {
+			return
+		}
+		// The second reply is dribbled slowly without a delimiter
+		for _, b := range []byte("250 slow reply") {
+			_, _ = conn.Write([]byte{b})
+			time.Sleep(100 * time.Millisecond)
+		}
+	})
+	defer server.Close()
+
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-network"
+	request := &Request{
+		ID:       templateID,
+		Address:  []string{"{{Hostname}}"},
+		ReadSize: 1,
+		Inputs: []*Input{
+			{Data: "", Name: "banner", ReadUntil: "0d0a", ReadUntilType: "hex", Read: 64},
+			{Data: "EHLO test\r\n", Name: "reply", ReadUntil: "\r\n", Timeout: "350ms", Read: 64},
+		},
+	}
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	err := request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile network request")
+
+	var finalEvent *output.InternalWrappedEvent
+	start := time.Now()
+	err = request.ExecuteWithResults(server.URL, make(output.InternalEvent), make(output.InternalEvent), func(event *output.InternalWrappedEvent) {
+		finalEvent = event
+	})
+	require.Nil(t, err, "could not execute network request")
+	require.Less(t, int64(time.Since(start)), int64(time.Second), "could not stop read at timeout")
+	require.NotNil(t, finalEvent, "could not get event output from request")
+	require.Equal(t, "220 ready\r\n", finalEvent.InternalEvent["banner"], "could not read until delimiter")
+
+	reply := types.ToString(finalEvent.InternalEvent["reply"])
+	require.True(t, strings.HasPrefix(reply, "250"), "could not read slow reply")
+	require.Less(t, len(reply), len("250 slow reply"), "could not stop slow reply at timeout")
+	require.Equal(t, len("220 ready\r\n")+len(reply)+1, finalEvent.InternalEvent["bytes_read"], "could not get bytes read")
+
+	t.Run("invalid", func(t *testing.T) {
+		request := &Request{ID: templateID, Address: []string{"{{Hostname}}"}, Inputs: []*Input{{Data: "test", Timeout: "soon"}}}
+		err := request.Compile(executerOpts)
+		require.NotNil(t, err, "could compile input with invalid timeout")
+	})
+}
+
+func TestNetworkExecuteTLSCertificate(t *testing.T) {
+	// Copied to wait for the server without inputs for one second only
+	options := *testutils.DefaultOptions
+	options.TimeoutNetwork = 1
+
+	testutils.Init(&options)
+	templateID := "testing-network-tls-certificate"
+
+	dir, err := ioutil.TempDir("", "nuclei-tls-certificate-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(dir)
+	generated, err := certificates.Generate(dir)
+	require.Nil(t, err, "could not generate certificates")
+
+	serverNames := make(chan string, 1)
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			serverNames <- hello.ServerName
+			return &generated.Server, nil
+		},
+	})
+	require.Nil(t, err, "could not listen")
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// The server doesn't send anything after the handshake
+		_, _ = conn.Read(make([]byte, 1))
+	}()
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	require.Nil(t, err, "could not get listener port")
+
+	request := &Request{
+		ID:      templateID,
+		Address: []string{"tls://{{Hostname}}:" + port},
+		SNI:     "nuclei.{{Hostname}}",
+		Operators: operators.Operators{
+			Matchers: []*matchers.Matcher{{Type: "dsl", DSL: []string{`!cert_expired(tls_not_after) && contains(tls_subject, "127.0.0.1")`}}},
+		},
+	}
+	executerOpts := testutils.NewMockExecuterOptions(&options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	err = request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile network request")
+
+	var finalEvent *output.InternalWrappedEvent
+	err = request.ExecuteWithResults("127.0.0.1", make(output.InternalEvent), make(output.InternalEvent), func(event *output.InternalWrappedEvent) {
+		finalEvent = event
+	})
+	require.Nil(t, err, "could not execute network request")
+	require.NotNil(t, finalEvent, "could not get event output from request")
+	require.Equal(t, "nuclei.127.0.0.1", <-serverNames, "could not send sni")
+	require.Equal(t, []string{"127.0.0.1"}, finalEvent.InternalEvent["tls_san"], "could not get certificate names")
+	require.NotEmpty(t, finalEvent.InternalEvent["tls_version"], "could not get tls version")
+	require.Equal(t, 1, len(finalEvent.Results), "could not match certificate details")
+}
+
+func TestNetworkExecuteExtractInputs(t *testing.T) {
+	nonce := []byte{0x13, 0x37, 0xca, 0xfe}
+	server := testutils.NewTCPServer(func(conn net.Conn) {
+		defer conn.Close()
+
+		_, _ = conn.Write(append([]byte{0xaa, 0x01}, nonce...))
+		// The negotiation must echo the nonce after its header
+		buffer := make([]byte, 6)
+		if _, err := io.ReadFull(conn, buffer); err != nil {
+			return
+		}
+		if bytes.Equal(buffer, append([]byte{0xbb, 0x02}, nonce...)) {
+			_, _ = conn.Write([]byte("negotiated"))
+		} else {
+			_, _ = conn.Write([]byte("invalid nonce"))
+		}
+	})
+	defer server.Close()
+
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-network-extract"
+	request := &Request{
+		ID:      templateID,
+		Address: []string{"{{Hostname}}"},
+		Inputs: []*Input{
+			{Data: "", Read: 6},
+			{Type: "extract", Name: "nonce", Offset: 2, Length: 4},
+			{Data: "bb02{{nonce}}", Type: "hex"},
+		},
+		ReadSize: 64,
+		Operators: operators.Operators{
+			Matchers: []*matchers.Matcher{{Part: "data", Type: "word", Words: []string{"negotiated"}}},
+		},
+	}
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	err := request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile network request")
+
+	var finalEvent *output.InternalWrappedEvent
+	err = request.ExecuteWithResults(server.URL, make(output.InternalEvent), make(output.InternalEvent), func(event *output.InternalWrappedEvent) {
+		finalEvent = event
+	})
+	require.Nil(t, err, "could not execute network request")
+	require.NotNil(t, finalEvent, "could not get event output from request")
+	require.Equal(t, string(nonce), finalEvent.InternalEvent["nonce"], "could not get extracted nonce")
+	require.Equal(t, 1, len(finalEvent.Results), "could not echo extracted nonce")
+}
+
+func TestNetworkExecuteStartTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nuclei-starttls-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(dir)
+	generated, err := certificates.Generate(dir)
+	require.Nil(t, err, "could not generate certificates")
+
+	server := testutils.NewTCPServer(func(conn net.Conn) {
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		_, _ = conn.Write([]byte("220 test ESMTP\r\n"))
+		if line, _ := reader.ReadString('\n'); line != "EHLO nuclei\r\n" {
+			return
+		}
+		_, _ = conn.Write([]byte("250-test\r\n250 STARTTLS\r\n"))
+		if line, _ := reader.ReadString('\n'); line != "STARTTLS\r\n" {
+			return
+		}
+		_, _ = conn.Write([]byte("220 ready to start tls\r\n"))
+
+		tlsConn := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{generated.Server}})
+		if err := tlsConn.Handshake(); err != nil {
+			return
+		}
+		if line, _ := bufio.NewReader(tlsConn).ReadString('\n'); line == "EHLO nuclei\r\n" {
+			_, _ = tlsConn.Write([]byte("250 secure\r\n"))
+		}
+	})
+	defer server.Close()
+
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-network-starttls"
+	request := &Request{
+		ID:      templateID,
+		Address: []string{"{{Hostname}}"},
+		Inputs: []*Input{
+			{Data: "", Read: 64, ReadUntil: "\r\n"},
+			{Data: "EHLO nuclei\r\n", Read: 64, ReadUntil: "250 STARTTLS\r\n"},
+			{Type: "starttls", Protocol: "smtp"},
+			{Data: "EHLO nuclei\r\n"},
+		},
+		ReadSize: 64,
+		Operators: operators.Operators{
+			Matchers: []*matchers.Matcher{{Part: "data", Type: "word", Words: []string{"250 secure"}}},
+		},
+	}
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	err = request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile network request")
+
+	var finalEvent *output.InternalWrappedEvent
+	err = request.ExecuteWithResults(server.URL, make(output.InternalEvent), make(output.InternalEvent), func(event *output.InternalWrappedEvent) {
+		finalEvent = event
+	})
+	require.Nil(t, err, "could not execute network request")
+	require.NotNil(t, finalEvent, "could not get event output from request")
+	require.Equal(t, 1, len(finalEvent.Results), "could not continue over tls")
+	require.Equal(t, []string{"127.0.0.1"}, finalEvent.InternalEvent["tls_san"], "could not get certificate names")
+
+	t.Run("invalid", func(t *testing.T) {
+		request := &Request{ID: templateID, Address: []string{"tls://{{Hostname}}"}, Inputs: []*Input{{Type: "starttls"}}}
+		err := request.Compile(executerOpts)
+		require.NotNil(t, err, "could compile starttls input with tls address")
+
+		request = &Request{ID: templateID, Address: []string{"{{Hostname}}"}, Inputs: []*Input{{Type: "starttls", Protocol: "ftp"}}}
+		err = request.Compile(executerOpts)
+		require.NotNil(t, err, "could compile starttls input with unknown protocol")
+	})
+}
+
+func TestNetworkExecutePorts(t *testing.T) {
+	handler := func(conn net.Conn) {
+		defer conn.Close()
+
+		_, _ = conn.Write([]byte("PONG"))
+	}
+	first := testutils.NewTCPServer(handler)
+	defer first.Close()
+	second := testutils.NewTCPServer(handler)
+	defer second.Close()
+
+	var ports []string
+	for _, server := range []*testutils.TCPServer{first, second} {
+		_, port, err := net.SplitHostPort(server.URL)
+		require.Nil(t, err, "could not get server port")
+		ports = append(ports, port)
+	}
+
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-network-ports"
+	request := &Request{
+		ID:       templateID,
+		Address:  []string{"{{Hostname}}:" + strings.Join(ports, ",")},
+		Inputs:   []*Input{{Data: "PING"}},
+		ReadSize: 4,
+		Operators: operators.Operators{
+			Matchers: []*matchers.Matcher{{Part: "data", Type: "word", Words: []string{"PONG"}}},
+		},
+	}
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	err := request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile network request")
+	require.Equal(t, 2, request.Requests(), "could not get requests for ports")
+
+	var matched []string
+	err = request.ExecuteWithResults("127.0.0.1", make(output.InternalEvent), make(output.InternalEvent), func(event *output.InternalWrappedEvent) {
+		for _, result := range event.Results {
+			matched = append(matched, result.Matched)
+		}
+	})
+	require.Nil(t, err, "could not execute network request")
+	require.Equal(t, []string{"127.0.0.1:" + ports[0], "127.0.0.1:" + ports[1]}, matched, "could not match each port")
+}
+
+func TestNetworkExecuteTraceData(t *testing.T) {
+	server := testutils.NewTCPServer(func(conn net.Conn) {
+		defer conn.Close()
+
+		data := make([]byte, 4)
+		if _, err := conn.Read(data); err == nil && string(data) == "PING" {
+			_, _ = conn.Write([]byte("PONG"))
+		}
+	})
+	defer server.Close()
+
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-network-trace"
+	request := &Request{
+		ID:       templateID,
+		Address:  []string{"{{Hostname}}"},
+		Inputs:   []*Input{{Data: "50494e47", Type: "hex"}},
+		ReadSize: 4,
+		Operators: operators.Operators{
+			Matchers: []*matchers.Matcher{{Part: "data", Type: "word", Words: []string{"PONG"}}},
+		},
+	}
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	var sent, received []byte
+	executerOpts.Output.(*testutils.MockOutputWriter).DataCallback = func(templateID, url string, sentData, receivedData []byte) {
+		sent, received = sentData, receivedData
+	}
+	err := request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile network request")
+
+	var finalEvent *output.InternalWrappedEvent
+	err = request.ExecuteWithResults(server.URL, make(output.InternalEvent), make(output.InternalEvent), func(event *output.InternalWrappedEvent) {
+		finalEvent = event
+	})
+	require.Nil(t, err, "could not execute network request")
+	require.Equal(t, []byte("PING"), sent, "could not trace sent bytes")
+	require.Equal(t, []byte("PONG"), received, "could not trace received bytes")
+	require.NotNil(t, finalEvent, "could not get event output from request")
+	require.Equal(t, 1, len(finalEvent.Results), "could not get correct number of results")
+	require.Equal(t, "504f4e47", finalEvent.Results[0].ResponseHex, "could not get hex encoded response")
+}
@@ -0,0 +1,146 @@
+package network
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/tlsconfig"
+)
+
+// tlsVersions are the names of the tls versions
+var tlsVersions = map[uint16]string{
+	tls.VersionTLS10: "tls10",
+	tls.VersionTLS11: "tls11",
+	tls.VersionTLS12: "tls12",
+	tls.VersionTLS13: "tls13",
+}
+
+// connectionStateEvents returns the version, cipher and peer certificate
+// details of a tls connection for the matchers.
+func connectionStateEvents(conn net.Conn) map[string]interface{} {
+	tlsConn, ok := conn.(*tls.Conn)
+	if !ok {
+		return nil
+	}
+	state := tlsConn.ConnectionState()
+	events := map[string]interface{}{
+		"tls_version": tlsVersions[state.Version],
+		"tls_cipher":  tls.CipherSuiteName(state.CipherSuite),
+	}
+	if len(state.PeerCertificates) == 0 {
+		return events
+	}
+	certificate := state.PeerCertificates[0]
+	san := make([]string, 0, len(certificate.DNSNames)+len(certificate.IPAddresses))
+	san = append(san, certificate.DNSNames...)
+	for _, ip := range certificate.IPAddresses {
+		san = append(san, ip.String())
+	}
+	events["tls_subject"] = certificate.Subject.String()
+	events["tls_issuer"] = certificate.Issuer.String()
+	events["tls_san"] = san
+	events["tls_not_before"] = certificate.NotBefore.UTC().Format(time.RFC3339)
+	events["tls_not_after"] = certificate.NotAfter.UTC().Format(time.RFC3339)
+	return events
+}
+
+// isTimeout returns true if an error is a network timeout
+func isTimeout(err error) bool {
+	netErr, ok := err.(net.Error)
+	return ok && netErr.Timeout()
+}
+
+// startTLSCommand is the command upgrading a connection of a protocol to tls
+type startTLSCommand struct {
+	command []byte
+	// reply reads the reply to the command returning an error if refused
+	reply func(reader *bufio.Reader) error
+}
+
+// ldapStartTLS is the ldap extended request with the starttls oid
+var ldapStartTLS = append([]byte{0x30, 0x1d, 0x02, 0x01, 0x01, 0x77, 0x18, 0x80, 0x16}, "1.3.6.1.4.1.1466.20037"...)
+
+// startTLSCommands are the starttls commands of the supported protocols
+var startTLSCommands = map[string]startTLSCommand{
+	"smtp": {command: []byte("STARTTLS\r\n"), reply: lineReply("220", "")},
+	"imap": {command: []byte("a001 STARTTLS\r\n"), reply: lineReply("a001 OK", "* ")},
+	"pop3": {command: []byte("STLS\r\n"), reply: lineReply("+OK", "")},
+	"ldap": {command: ldapStartTLS, reply: ldapReply},
+}
+
+// lineReply reads the reply lines until one starts with the prefix,
+// skipping the untagged lines.
+func lineReply(prefix, untagged string) func(reader *bufio.Reader) error {
+	return func(reader *bufio.Reader) error {
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return errors.Wrap(err, "could not read starttls reply")
+			}
+			if strings.HasPrefix(line, prefix) {
+				return nil
+			}
+			if untagged == "" || !strings.HasPrefix(line, untagged) {
+				return errors.Errorf("starttls refused: %s", strings.TrimSpace(line))
+			}
+		}
+	}
+}
+
+// ldapReply reads the extended response checking the result code is success
+func ldapReply(reader *bufio.Reader) error {
+	buffer := make([]byte, 256)
+	n, err := reader.Read(buffer)
+	if err != nil {
+		return errors.Wrap(err, "could not read starttls reply")
+	}
+	// The result code is the first enumerated of the extended response
+	if index := bytes.Index(buffer[:n], []byte{0x0a, 0x01}); index == -1 || index+2 >= n || buffer[index+2] != 0 {
+		return errors.New("starttls refused")
+	}
+	return nil
+}
+
+// compileStartTLS validates a starttls input
+func (r *Request) compileStartTLS(input *Input) error {
+	if input.Protocol != "" {
+		if _, ok := startTLSCommands[input.Protocol]; !ok {
+			return errors.Errorf("invalid starttls protocol %s", input.Protocol)
+		}
+	}
+	if input.Data != "" || input.File != "" || len(input.Segments) > 0 {
+		return errors.New("starttls input cannot send data")
+	}
+	for _, address := range r.addresses {
+		if address.tls || address.udp {
+			return errors.New("starttls input cannot be used with tls or udp addresses")
+		}
+	}
+	return nil
+}
+
+// startTLS sends the starttls command of the input protocol if any and
+// performs the tls handshake on the connection.
+func (r *Request) startTLS(conn net.Conn, reader *bufio.Reader, input *Input, serverName string) (net.Conn, error) {
+	if command, ok := startTLSCommands[input.Protocol]; ok {
+		if _, err := conn.Write(command.command); err != nil {
+			return nil, errors.Wrap(err, "could not write starttls command")
+		}
+		if err := command.reply(reader); err != nil {
+			return nil, err
+		}
+	}
+	if reader.Buffered() > 0 {
+		return nil, errors.New("unexpected data before starttls handshake")
+	}
+	tlsConn, err := tlsconfig.Client(conn, serverName, r.certificate, r.verification)
+	if err != nil {
+		return nil, err
+	}
+	return tlsConn, nil
+}
@@ -17,6 +17,11 @@ func (r *Request) GetID() string {
 	return ""
 }
 
+// EventType returns the type of the result events of the request.
+func (r *Request) EventType() string {
+	return "http"
+}
+
 // Compile compiles the protocol request for further execution.
 func (r *Request) Compile(options *protocols.ExecuterOptions) error {
 	for _, operator := range options.Operators {
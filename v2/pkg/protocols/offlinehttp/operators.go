@@ -12,31 +12,31 @@ import (
 )
 
 // Match matches a generic data response again a given matcher
-func (r *Request) Match(data map[string]interface{}, matcher *matchers.Matcher) bool {
+func (r *Request) Match(data map[string]interface{}, matcher *matchers.Matcher) (bool, []string) {
 	item, ok := getMatchPart(matcher.Part, data)
 	if !ok {
-		return false
+		return false, nil
 	}
 
 	switch matcher.GetType() {
 	case matchers.StatusMatcher:
 		statusCode, ok := data["status_code"]
 		if !ok {
-			return false
+			return false, nil
 		}
-		return matcher.Result(matcher.MatchStatusCode(statusCode.(int)))
+		return matcher.Result(matcher.MatchStatusCode(statusCode.(int))), nil
 	case matchers.SizeMatcher:
-		return matcher.Result(matcher.MatchSize(len(item)))
+		return matcher.Result(matcher.MatchSize(len(item))), nil
 	case matchers.WordsMatcher:
-		return matcher.Result(matcher.MatchWords(item))
+		return matcher.ResultWithMatchedSnippet(matcher.MatchWords(item))
 	case matchers.RegexMatcher:
-		return matcher.Result(matcher.MatchRegex(item))
+		return matcher.ResultWithMatchedSnippet(matcher.MatchRegex(item))
 	case matchers.BinaryMatcher:
-		return matcher.Result(matcher.MatchBinary(item))
+		return matcher.ResultWithMatchedSnippet(matcher.MatchBinary(item))
 	case matchers.DSLMatcher:
-		return matcher.Result(matcher.MatchDSL(data))
+		return matcher.Result(matcher.MatchDSL(data)), nil
 	}
-	return false
+	return false, nil
 }
 
 // Extract performs extracting operation for a extractor on model and returns true or false.
@@ -50,6 +50,10 @@ func (r *Request) Extract(data map[string]interface{}, extractor *extractors.Ext
 		return extractor.ExtractRegex(item)
 	case extractors.KValExtractor:
 		return extractor.ExtractKval(data)
+	case extractors.XPathExtractor:
+		return extractor.ExtractXPath(item, extractors.IsXMLContentType(types.ToString(data["content_type"])))
+	case extractors.JSONExtractor:
+		return extractor.ExtractJSON(item)
 	}
 	return nil
 }
@@ -139,15 +143,20 @@ func (r *Request) makeResultEventItem(wrapped *output.InternalWrappedEvent) *out
 		TemplatePath:     types.ToString(wrapped.InternalEvent["template-path"]),
 		Info:             wrapped.InternalEvent["template-info"].(map[string]interface{}),
 		Type:             "http",
+		Host:             types.ToString(wrapped.InternalEvent["host"]),
 		Path:             types.ToString(wrapped.InternalEvent["path"]),
 		Matched:          types.ToString(wrapped.InternalEvent["matched"]),
 		Metadata:         wrapped.OperatorsResult.PayloadValues,
 		ExtractedResults: wrapped.OperatorsResult.OutputExtracts,
+		MatchedSnippets:  wrapped.OperatorsResult.MatchedSnippets,
+		MatchedWords:     wrapped.OperatorsResult.MatchedWords,
+		MatcherNames:     wrapped.OperatorsResult.AndMatches,
+		ExtractedNamed:   wrapped.OperatorsResult.Extracts,
 		IP:               types.ToString(wrapped.InternalEvent["ip"]),
 	}
 	if r.options.Options.JSONRequests {
-		data.Request = types.ToString(wrapped.InternalEvent["request"])
-		data.Response = types.ToString(wrapped.InternalEvent["raw"])
+		data.Request = output.TruncateRR(types.ToString(wrapped.InternalEvent["request"]), r.options.Options.JSONRequestsMaxSize)
+		data.Response = output.TruncateRR(types.ToString(wrapped.InternalEvent["raw"]), r.options.Options.JSONRequestsMaxSize)
 	}
 	return data
 }
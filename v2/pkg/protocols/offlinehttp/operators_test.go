@@ -73,7 +73,7 @@ func TestHTTPOperatorMatch(t *testing.T) {
 		err = matcher.CompileMatchers()
 		require.Nil(t, err, "could not compile matcher")
 
-		matched := request.Match(event, matcher)
+		matched, _ := request.Match(event, matcher)
 		require.True(t, matched, "could not match valid response")
 	})
 
@@ -87,7 +87,7 @@ func TestHTTPOperatorMatch(t *testing.T) {
 		err := matcher.CompileMatchers()
 		require.Nil(t, err, "could not compile negative matcher")
 
-		matched := request.Match(event, matcher)
+		matched, _ := request.Match(event, matcher)
 		require.True(t, matched, "could not match valid negative response matcher")
 	})
 
@@ -100,7 +100,7 @@ func TestHTTPOperatorMatch(t *testing.T) {
 		err := matcher.CompileMatchers()
 		require.Nil(t, err, "could not compile matcher")
 
-		matched := request.Match(event, matcher)
+		matched, _ := request.Match(event, matcher)
 		require.False(t, matched, "could match invalid response matcher")
 	})
 }
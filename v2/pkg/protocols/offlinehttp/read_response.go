@@ -2,22 +2,24 @@ package offlinehttp
 
 import (
 	"bufio"
-	"errors"
 	"net/http"
+	"regexp"
 	"strings"
 )
 
+// statusLineRegex matches the status line of a raw http response
+var statusLineRegex = regexp.MustCompile(`(?m)^HTTP/[0-9.]+ [0-9]{3}`)
+
 // readResponseFromString reads a raw http response from a string.
+//
+// Data without a status line is a plain body dump, and is read
+// as the body of a successful response.
 func readResponseFromString(data string) (*http.Response, error) {
 	var final string
-	if strings.HasPrefix(data, "HTTP/") {
-		final = data
+	if matches := statusLineRegex.FindAllStringIndex(data, -1); len(matches) > 0 {
+		final = data[matches[len(matches)-1][0]:] // choose last http/ in case of it being later.
 	} else {
-		lastIndex := strings.LastIndex(data, "HTTP/")
-		if lastIndex == -1 {
-			return nil, errors.New("malformed raw http response")
-		}
-		final = data[lastIndex:] // choose last http/ in case of it being later.
+		final = "HTTP/1.1 200 OK\r\n\r\n" + data
 	}
 	return http.ReadResponse(bufio.NewReader(strings.NewReader(final)), nil)
 }
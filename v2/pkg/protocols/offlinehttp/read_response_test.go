@@ -82,4 +82,13 @@ Server: Google Frontend
 		require.Equal(t, expectedBody, string(respData), "could not get correct parsed body")
 		require.Equal(t, "Google Frontend", resp.Header.Get("Server"), "could not get correct headers")
 	})
+	t.Run("body-only", func(t *testing.T) {
+		resp, err := readResponseFromString(expectedBody)
+		require.Nil(t, err, "could not read body dump from string")
+
+		respData, err := ioutil.ReadAll(resp.Body)
+		require.Nil(t, err, "could not read response body")
+		require.Equal(t, 200, resp.StatusCode, "could not synthesize status line")
+		require.Equal(t, expectedBody, string(respData), "could not get correct parsed body")
+	})
 }
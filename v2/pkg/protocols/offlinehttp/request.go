@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -23,7 +24,17 @@ const maxSize = 5 * 1024 * 1024
 func (r *Request) ExecuteWithResults(input string, metadata, previous output.InternalEvent, callback protocols.OutputEventCallback) error {
 	wg := sizedwaitgroup.New(r.options.Options.BulkSize)
 
-	err := r.getInputPaths(input, func(data string) {
+	urls, err := loadURLIndex(input)
+	if err != nil {
+		r.options.Output.Request(r.options.TemplateID, input, "file", err)
+		r.options.Progress.IncrementFailedRequestsBy(1)
+		return errors.Wrap(err, "could not load url index")
+	}
+
+	err = r.getInputPaths(input, func(data string) {
+		if filepath.Base(data) == urlIndexFile {
+			return
+		}
 		wg.Add()
 
 		go func(data string) {
@@ -53,6 +64,14 @@ func (r *Request) ExecuteWithResults(input string, metadata, previous output.Int
 			}
 			dataStr := tostring.UnsafeToString(buffer)
 
+			targetURL, dataStr := parseURLComment(dataStr)
+			if targetURL == "" {
+				targetURL = urls[filepath.Clean(data)]
+			}
+			if targetURL == "" {
+				targetURL = data
+			}
+
 			resp, err := readResponseFromString(dataStr)
 			if err != nil {
 				gologger.Error().Msgf("Could not read raw response %s: %s\n", data, err)
@@ -78,6 +97,7 @@ func (r *Request) ExecuteWithResults(input string, metadata, previous output.Int
 			}
 
 			outputEvent := r.responseToDSLMap(resp, data, data, data, tostring.UnsafeToString(dumpedResponse), tostring.UnsafeToString(body), headersToString(resp.Header), 0, nil)
+			outputEvent["host"] = targetURL
 			outputEvent["ip"] = ""
 			for k, v := range previous {
 				outputEvent[k] = v
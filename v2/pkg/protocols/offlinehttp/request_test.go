@@ -0,0 +1,64 @@
+package offlinehttp
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/yaklang/nuclei/v2/internal/testutils"
+	"github.com/yaklang/nuclei/v2/pkg/operators"
+	"github.com/yaklang/nuclei/v2/pkg/operators/matchers"
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOfflineHTTPDirectory(t *testing.T) {
+	options := testutils.DefaultOptions
+
+	testutils.Init(options)
+	templateID := "testing-offline-directory"
+	request := &Request{}
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{
+		ID:   templateID,
+		Info: map[string]interface{}{"severity": "low", "name": "test"},
+	})
+	executerOpts.Operators = []*operators.Operators{{
+		Matchers: []*matchers.Matcher{{Type: "word", Part: "body", Words: []string{"secret"}}},
+	}}
+	err := request.Compile(executerOpts)
+	require.Nil(t, err, "could not compile offline request")
+
+	tempDir, err := ioutil.TempDir("", "test-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(tempDir)
+
+	files := map[string]string{
+		"indexed.txt": "HTTP/1.1 200 OK\r\nContent-Type: text/plain\r\n\r\nsecret",
+		"comment.txt": "# X-Nuclei-URL: https://comment.example.com/admin\nHTTP/1.1 200 OK\r\n\r\nsecret",
+		"body.txt":    "a plain secret body dump",
+		"missing.txt": "HTTP/1.1 404 Not Found\r\n\r\nnothing here",
+		urlIndexFile:  "indexed.txt https://indexed.example.com/\n",
+	}
+	for k, v := range files {
+		err = ioutil.WriteFile(filepath.Join(tempDir, k), []byte(v), 0644)
+		require.Nil(t, err, "could not write temporary file")
+	}
+
+	mutex := &sync.Mutex{}
+	matched := make(map[string]string)
+	err = request.ExecuteWithResults(tempDir, make(output.InternalEvent), make(output.InternalEvent), func(event *output.InternalWrappedEvent) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		for _, result := range event.Results {
+			matched[filepath.Base(result.Matched)] = result.Host
+		}
+	})
+	require.Nil(t, err, "could not execute offline request")
+	require.Equal(t, map[string]string{
+		"indexed.txt": "https://indexed.example.com/",
+		"comment.txt": "https://comment.example.com/admin",
+		"body.txt":    filepath.Join(tempDir, "body.txt"),
+	}, matched, "could not get correct matched responses")
+}
@@ -0,0 +1,74 @@
+package offlinehttp
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// urlIndexFile is the file of a responses directory mapping
+// the responses to their urls, one "<file> <url>" per line.
+const urlIndexFile = "urls.txt"
+
+// urlComment is the comment line of a response giving its url
+const urlComment = "X-Nuclei-URL:"
+
+// loadURLIndex loads the url index of the directory of an input, which
+// is the input itself or the directory of a file or glob input.
+func loadURLIndex(input string) (map[string]string, error) {
+	directory := input
+	if info, err := os.Stat(input); err != nil || !info.IsDir() {
+		directory = filepath.Dir(input)
+	}
+
+	file, err := os.Open(filepath.Join(directory, urlIndexFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open url index")
+	}
+	defer file.Close()
+
+	index := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		parts := strings.Fields(text)
+		if len(parts) < 2 {
+			continue
+		}
+		path := parts[0]
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(directory, path)
+		}
+		index[filepath.Clean(path)] = parts[1]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "could not read url index")
+	}
+	return index, nil
+}
+
+// parseURLComment returns the url of a "# X-Nuclei-URL: <url>" comment
+// on the first line of a response and the response without it.
+func parseURLComment(data string) (string, string) {
+	if !strings.HasPrefix(data, "#") {
+		return "", data
+	}
+	line, rest := data, ""
+	if i := strings.Index(data, "\n"); i != -1 {
+		line, rest = data[:i], data[i+1:]
+	}
+	line = strings.TrimSpace(strings.TrimPrefix(line, "#"))
+	if len(line) < len(urlComment) || !strings.EqualFold(line[:len(urlComment)], urlComment) {
+		return "", data
+	}
+	return strings.TrimSpace(line[len(urlComment):]), rest
+}
@@ -1,6 +1,8 @@
 package protocols
 
 import (
+	"context"
+
 	"github.com/yaklang/nuclei/v2/pkg/catalog"
 	"github.com/yaklang/nuclei/v2/pkg/operators"
 	"github.com/yaklang/nuclei/v2/pkg/operators/extractors"
@@ -9,6 +11,10 @@ import (
 	"github.com/yaklang/nuclei/v2/pkg/progress"
 	"github.com/yaklang/nuclei/v2/pkg/projectfile"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/common/interactsh"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/fingerprint"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/resultcap"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/session"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/variables"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/headless/engine"
 	"github.com/yaklang/nuclei/v2/pkg/reporting"
 	"github.com/yaklang/nuclei/v2/pkg/types"
@@ -27,6 +33,16 @@ type Executer interface {
 	ExecuteWithResults(input string, callback OutputEventCallback) error
 }
 
+// ValuesExecuter is an executer whose requests can start from dynamic values,
+// used by workflows to pass the values extracted by a template to the next.
+type ValuesExecuter interface {
+	// ExecuteWithValues executes the protocol group with initial dynamic values.
+	ExecuteWithValues(input string, values map[string]interface{}) (bool, error)
+	// ExecuteWithValuesAndResults executes the protocol requests with initial
+	// dynamic values and returns results instead of writing them.
+	ExecuteWithValuesAndResults(input string, values map[string]interface{}, callback OutputEventCallback) error
+}
+
 // ExecuterOptions contains the configuration options for executer clients
 type ExecuterOptions struct {
 	// TemplateID is the ID of the template for the request
@@ -53,6 +69,16 @@ type ExecuterOptions struct {
 	Browser *engine.Browser
 	// Interactsh is a client for interactsh oob polling server
 	Interactsh *interactsh.Client
+	// Session is the scan-level authentication session if any
+	Session *session.Manager
+	// ResultCap limits the number of results written per template
+	ResultCap *resultcap.Limiter
+	// Fingerprinter detects the WAF and CDN of the hosts being scanned
+	Fingerprinter *fingerprint.Fingerprinter
+	// Variables are the template-level variables evaluated for each input
+	Variables *variables.Variable
+	// Context is cancelled when the scan is interrupted, nil if it can't be.
+	Context context.Context
 
 	Operators []*operators.Operators // only used by offlinehttp module
 }
@@ -67,8 +93,10 @@ type Request interface {
 	// condition matching. So, two requests can be sent and their match can
 	// be evaluated from the third request by using the IDs for both requests.
 	GetID() string
+	// EventType returns the type of the result events of the request.
+	EventType() string
 	// Match performs matching operation for a matcher on model and returns true or false.
-	Match(data map[string]interface{}, matcher *matchers.Matcher) bool
+	Match(data map[string]interface{}, matcher *matchers.Matcher) (bool, []string)
 	// Extract performs extracting operation for a extractor on model and returns true or false.
 	Extract(data map[string]interface{}, matcher *extractors.Extractor) map[string]struct{}
 	// ExecuteWithResults executes the protocol requests and returns results instead of writing them.
@@ -6,8 +6,11 @@ package dedupe
 
 import (
 	"crypto/sha1"
+	"encoding/binary"
 	"io/ioutil"
 	"os"
+	"sort"
+	"time"
 	"unsafe"
 
 	"github.com/yaklang/nuclei/v2/pkg/output"
@@ -18,15 +21,37 @@ import (
 
 // Storage is a duplicate detecting storage for nuclei scan events.
 type Storage struct {
-	temporary string
-	storage   *leveldb.DB
+	temporary   string
+	storage     *leveldb.DB
+	keyTemplate string
+}
+
+// Options contains the configuration options for the duplicate storage
+type Options struct {
+	// Path is the directory of the storage, a temporary one if empty
+	Path string
+	// KeyTemplate is the template of the keys identifying the duplicates,
+	// like {{template_id}}:{{host}}. All the fields are used if empty.
+	KeyTemplate string
+	// TTL is the time after which the entries not seen again are purged
+	// when the storage is opened, 0 to keep them forever.
+	TTL time.Duration
 }
 
 // New creates a new duplicate detecting storage for nuclei scan events.
 func New(dbPath string) (*Storage, error) {
-	storage := &Storage{}
+	return NewWithOptions(&Options{Path: dbPath})
+}
+
+// NewWithOptions creates a new duplicate detecting storage with options.
+func NewWithOptions(options *Options) (*Storage, error) {
+	if err := validateKeyTemplate(options.KeyTemplate); err != nil {
+		return nil, err
+	}
+	storage := &Storage{keyTemplate: options.KeyTemplate}
 
 	var err error
+	dbPath := options.Path
 	if dbPath == "" {
 		dbPath, err = ioutil.TempDir("", "nuclei-report-*")
 		storage.temporary = dbPath
@@ -47,9 +72,39 @@ func New(dbPath string) (*Storage, error) {
 			return nil, err
 		}
 	}
+	if options.TTL > 0 {
+		if err := storage.purge(time.Now().Add(-options.TTL)); err != nil {
+			storage.Close()
+			return nil, err
+		}
+	}
 	return storage, nil
 }
 
+// purge deletes the entries last seen before a time. The entries written
+// without a time by the previous versions are stamped with the current
+// time instead, so they are not reported again.
+func (s *Storage) purge(before time.Time) error {
+	now := make([]byte, 8)
+	binary.BigEndian.PutUint64(now, uint64(time.Now().Unix()))
+
+	batch := new(leveldb.Batch)
+	iterator := s.storage.NewIterator(nil, nil)
+	for iterator.Next() {
+		value := iterator.Value()
+		if len(value) != 8 {
+			batch.Put(append([]byte{}, iterator.Key()...), now)
+		} else if int64(binary.BigEndian.Uint64(value)) < before.Unix() {
+			batch.Delete(append([]byte{}, iterator.Key()...))
+		}
+	}
+	iterator.Release()
+	if err := iterator.Error(); err != nil {
+		return err
+	}
+	return s.storage.Write(batch, nil)
+}
+
 // Close closes the storage for further operations
 func (s *Storage) Close() {
 	s.storage.Close()
@@ -58,9 +113,8 @@ func (s *Storage) Close() {
 	}
 }
 
-// Index indexes an item in storage and returns true if the item
-// was unique.
-func (s *Storage) Index(result *output.ResultEvent) (bool, error) {
+// Hash returns the hash identifying the duplicates of a result event
+func Hash(result *output.ResultEvent) []byte {
 	hasher := sha1.New()
 	if result.TemplateID != "" {
 		_, _ = hasher.Write(unsafeToBytes(result.TemplateID))
@@ -83,11 +137,23 @@ func (s *Storage) Index(result *output.ResultEvent) (bool, error) {
 	for _, v := range result.ExtractedResults {
 		_, _ = hasher.Write(unsafeToBytes(v))
 	}
-	for k, v := range result.Metadata {
+	// Sort the metadata keys to get the same hash for each duplicate
+	keys := make([]string, 0, len(result.Metadata))
+	for k := range result.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
 		_, _ = hasher.Write(unsafeToBytes(k))
-		_, _ = hasher.Write(unsafeToBytes(types.ToString(v)))
+		_, _ = hasher.Write(unsafeToBytes(types.ToString(result.Metadata[k])))
 	}
-	hash := hasher.Sum(nil)
+	return hasher.Sum(nil)
+}
+
+// Index indexes an item in storage and returns true if the item
+// was unique. The time the item was last seen is updated either way.
+func (s *Storage) Index(result *output.ResultEvent) (bool, error) {
+	hash := KeyHash(s.keyTemplate, result)
 
 	exists, err := s.storage.Has(hash, nil)
 	if err != nil {
@@ -95,10 +161,9 @@ func (s *Storage) Index(result *output.ResultEvent) (bool, error) {
 		// since we don't want to loose an issue considering it a dupe.
 		return true, err
 	}
-	if !exists {
-		return true, s.storage.Put(hash, nil, nil)
-	}
-	return false, err
+	seen := make([]byte, 8)
+	binary.BigEndian.PutUint64(seen, uint64(time.Now().Unix()))
+	return !exists, s.storage.Put(hash, seen, nil)
 }
 
 // unsafeToBytes converts a string to byte slice and does it with
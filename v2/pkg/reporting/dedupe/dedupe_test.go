@@ -1,12 +1,16 @@
 package dedupe
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/yaklang/nuclei/v2/pkg/output"
 	"github.com/stretchr/testify/require"
+	"github.com/syndtr/goleveldb/leveldb"
 )
 
 func TestDedupeDuplicates(t *testing.T) {
@@ -29,3 +33,80 @@ func TestDedupeDuplicates(t *testing.T) {
 	require.Nil(t, err, "could not index item")
 	require.False(t, second, "could index duplicate item")
 }
+
+func TestDedupeKeyTemplate(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "nuclei")
+	require.Nil(t, err, "could not create temporary storage")
+	defer os.RemoveAll(tempDir)
+
+	storage, err := NewWithOptions(&Options{Path: tempDir, KeyTemplate: "{{template_id}}:{{host}}"})
+	require.Nil(t, err, "could not create duplicate storage")
+	defer storage.Close()
+
+	first, err := storage.Index(&output.ResultEvent{TemplateID: "test", Host: "https://example.com", MatcherName: "first"})
+	require.Nil(t, err, "could not index item")
+	require.True(t, first, "could not index valid item")
+
+	second, err := storage.Index(&output.ResultEvent{TemplateID: "test", Host: "https://example.com", MatcherName: "second"})
+	require.Nil(t, err, "could not index item")
+	require.False(t, second, "could index item with same key")
+
+	_, err = NewWithOptions(&Options{KeyTemplate: "{{template_id}}:{{unknown}}"})
+	require.NotNil(t, err, "could create storage with unknown placeholder")
+}
+
+func TestDedupeTTL(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "nuclei")
+	require.Nil(t, err, "could not create temporary storage")
+	defer os.RemoveAll(tempDir)
+
+	storage, err := NewWithOptions(&Options{Path: tempDir})
+	require.Nil(t, err, "could not create duplicate storage")
+	event := &output.ResultEvent{TemplateID: "test", Host: "https://example.com"}
+	_, err = storage.Index(event)
+	require.Nil(t, err, "could not index item")
+	storage.Close()
+
+	storage, err = NewWithOptions(&Options{Path: tempDir, TTL: time.Hour})
+	require.Nil(t, err, "could not reopen duplicate storage")
+	unique, err := storage.Index(event)
+	require.Nil(t, err, "could not index item")
+	require.False(t, unique, "could purge recent item")
+	defer storage.Close()
+
+	require.Nil(t, storage.purge(time.Now().Add(time.Minute)), "could not purge storage")
+	unique, err = storage.Index(event)
+	require.Nil(t, err, "could not index item")
+	require.True(t, unique, "could not purge stale item")
+}
+
+func TestDedupeTTLPreviousEntries(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "nuclei")
+	require.Nil(t, err, "could not create temporary storage")
+	defer os.RemoveAll(tempDir)
+
+	// The previous versions stored the hashes without a value
+	event := &output.ResultEvent{TemplateID: "test", Host: "https://example.com"}
+	db, err := leveldb.OpenFile(tempDir, nil)
+	require.Nil(t, err, "could not open storage")
+	require.Nil(t, db.Put(Hash(event), nil, nil), "could not write previous entry")
+	db.Close()
+
+	storage, err := NewWithOptions(&Options{Path: tempDir, TTL: time.Hour})
+	require.Nil(t, err, "could not open duplicate storage")
+	defer storage.Close()
+
+	unique, err := storage.Index(event)
+	require.Nil(t, err, "could not index item")
+	require.False(t, unique, "could purge previous entry")
+}
+
+func TestFingerprint(t *testing.T) {
+	event := &output.ResultEvent{TemplateID: "test", Host: "https://example.com", Matched: "https://example.com/a", ExtractedResults: []string{"token-1"}}
+	changed := &output.ResultEvent{TemplateID: "test", Host: "https://example.com", Matched: "https://example.com/b", ExtractedResults: []string{"token-2"}}
+
+	require.Equal(t, Fingerprint("", event), Fingerprint("", changed), "could not get same fingerprint for changed values")
+	hash := sha1.Sum([]byte("test|https://example.com"))
+	require.Equal(t, "nuclei-"+hex.EncodeToString(hash[:]), Fingerprint("", event), "could not get template and host fingerprint")
+	require.NotEqual(t, Fingerprint("{{matched}}", event), Fingerprint("{{matched}}", changed), "could not use dedupe key for fingerprint")
+}
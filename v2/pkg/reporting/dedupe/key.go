@@ -0,0 +1,94 @@
+package dedupe
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/yaklang/nuclei/v2/pkg/types"
+)
+
+// keyPlaceholderRegex matches the placeholders of a key template
+var keyPlaceholderRegex = regexp.MustCompile(`{{\s*([a-z_]+)\s*}}`)
+
+// keyFields are the values of the placeholders of a key template
+var keyFields = map[string]func(result *output.ResultEvent) string{
+	"template_id": func(result *output.ResultEvent) string {
+		return result.TemplateID
+	},
+	"host": func(result *output.ResultEvent) string {
+		return result.Host
+	},
+	"matched": func(result *output.ResultEvent) string {
+		return result.Matched
+	},
+	"matcher_name": func(result *output.ResultEvent) string {
+		return result.MatcherName
+	},
+	"extractor_name": func(result *output.ResultEvent) string {
+		return result.ExtractorName
+	},
+	"type": func(result *output.ResultEvent) string {
+		return result.Type
+	},
+	"extracted": func(result *output.ResultEvent) string {
+		return strings.Join(result.ExtractedResults, ",")
+	},
+	"metadata": func(result *output.ResultEvent) string {
+		keys := make([]string, 0, len(result.Metadata))
+		for k := range result.Metadata {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, 0, len(keys))
+		for _, k := range keys {
+			parts = append(parts, k+"="+types.ToString(result.Metadata[k]))
+		}
+		return strings.Join(parts, ",")
+	},
+}
+
+// validateKeyTemplate returns an error if a key template has an unknown placeholder
+func validateKeyTemplate(template string) error {
+	for _, match := range keyPlaceholderRegex.FindAllStringSubmatch(template, -1) {
+		if _, ok := keyFields[match[1]]; !ok {
+			return fmt.Errorf("unknown dedupe key placeholder %s", match[0])
+		}
+	}
+	return nil
+}
+
+// KeyHash returns the hash of the key of a result built from a template
+// like {{template_id}}:{{host}}, or the hash of all its fields if empty.
+func KeyHash(template string, result *output.ResultEvent) []byte {
+	if template == "" {
+		return Hash(result)
+	}
+	key := keyPlaceholderRegex.ReplaceAllStringFunc(template, func(placeholder string) string {
+		name := keyPlaceholderRegex.FindStringSubmatch(placeholder)[1]
+		field, ok := keyFields[name]
+		if !ok {
+			return placeholder
+		}
+		return field(result)
+	})
+	hash := sha1.Sum([]byte(key))
+	return hash[:]
+}
+
+// defaultFingerprintKey is the key template of the issue fingerprints
+// when no dedupe key is configured.
+const defaultFingerprintKey = "{{template_id}}|{{host}}"
+
+// Fingerprint returns the fingerprint identifying the tracker issues of a
+// result, built from the dedupe key template or its template ID and host.
+func Fingerprint(template string, result *output.ResultEvent) string {
+	if template == "" {
+		template = defaultFingerprintKey
+	}
+	return "nuclei-" + hex.EncodeToString(KeyHash(template, result))
+}
@@ -0,0 +1,125 @@
+package csv
+
+import (
+	"encoding/csv"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/filter"
+	"github.com/yaklang/nuclei/v2/pkg/types"
+)
+
+// columns are the functions returning the value of each csv column
+var columns = map[string]func(event *output.ResultEvent) string{
+	"template-id": func(event *output.ResultEvent) string {
+		return event.TemplateID
+	},
+	"name": func(event *output.ResultEvent) string {
+		return types.ToString(event.Info["name"])
+	},
+	"severity": func(event *output.ResultEvent) string {
+		return types.ToString(event.Info["severity"])
+	},
+	"host": func(event *output.ResultEvent) string {
+		return event.Host
+	},
+	"matched-at": func(event *output.ResultEvent) string {
+		if event.Matched != "" {
+			return event.Matched
+		}
+		return event.Host
+	},
+	"extracted-results": func(event *output.ResultEvent) string {
+		return strings.Join(event.ExtractedResults, ",")
+	},
+	"timestamp": func(event *output.ResultEvent) string {
+		return event.Timestamp.Format(time.RFC3339)
+	},
+}
+
+// DefaultColumns are the columns written when none are configured
+var DefaultColumns = []string{"template-id", "name", "severity", "host", "matched-at", "extracted-results", "timestamp"}
+
+// Exporter is an exporter for nuclei csv output format.
+type Exporter struct {
+	file    *os.File
+	writer  *csv.Writer
+	columns []string
+	mutex   *sync.Mutex
+	options *Options
+}
+
+// Options contains the configuration options for csv exporter client
+type Options struct {
+	// File is the file to export found results to
+	File string `yaml:"file"`
+	// Columns are the columns to write, all of them if empty
+	Columns []string `yaml:"columns"`
+	// AllowList contains a list of allowed events for this exporter
+	AllowList *filter.Filter `yaml:"allow-list"`
+	// DenyList contains a list of denied events for this exporter
+	DenyList *filter.Filter `yaml:"deny-list"`
+}
+
+// New creates a new csv exporter integration client based on options,
+// writing the header of the columns to the file.
+func New(options *Options) (*Exporter, error) {
+	selected := options.Columns
+	if len(selected) == 0 {
+		selected = DefaultColumns
+	}
+	for _, column := range selected {
+		if _, ok := columns[column]; !ok {
+			return nil, errors.Errorf("invalid csv column %s", column)
+		}
+	}
+
+	file, err := os.Create(options.File)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create csv output file")
+	}
+	writer := csv.NewWriter(file)
+	if err := writer.Write(selected); err != nil {
+		file.Close()
+		return nil, errors.Wrap(err, "could not write csv header")
+	}
+	return &Exporter{file: file, writer: writer, columns: selected, mutex: &sync.Mutex{}, options: options}, nil
+}
+
+// Export exports a passed result event as a csv row
+func (i *Exporter) Export(event *output.ResultEvent) error {
+	row := make([]string, 0, len(i.columns))
+	for _, column := range i.columns {
+		row = append(row, escapeFormula(columns[column](event)))
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	return i.writer.Write(row)
+}
+
+// escapeFormula prefixes the values starting like a formula with a quote,
+// so spreadsheets opening the file don't evaluate the scanned content.
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsAny(value[:1], "=+-@\t\r") {
+		return "'" + value
+	}
+	return value
+}
+
+// Close closes the exporter after operation
+func (i *Exporter) Close() error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.writer.Flush()
+	if err := i.writer.Error(); err != nil {
+		i.file.Close()
+		return errors.Wrap(err, "could not write csv output file")
+	}
+	return i.file.Close()
+}
@@ -0,0 +1,50 @@
+package csv
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVExporter(t *testing.T) {
+	directory, err := ioutil.TempDir("", "csv-exporter-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(directory)
+
+	file := filepath.Join(directory, "results.csv")
+	exporter, err := New(&Options{File: file, Columns: []string{"template-id", "severity", "matched-at", "extracted-results"}})
+	require.Nil(t, err, "could not create csv exporter")
+
+	err = exporter.Export(&output.ResultEvent{
+		TemplateID:       "test-template",
+		Info:             map[string]interface{}{"name": "Test", "severity": "high"},
+		Host:             "https://example.com",
+		Matched:          "https://example.com/login",
+		ExtractedResults: []string{"admin", `say "hi"`},
+		Timestamp:        time.Now(),
+	})
+	require.Nil(t, err, "could not export event")
+	err = exporter.Export(&output.ResultEvent{TemplateID: "dns-template", Host: "example.com"})
+	require.Nil(t, err, "could not export event")
+	err = exporter.Export(&output.ResultEvent{TemplateID: "formula-template", Host: "example.com", ExtractedResults: []string{"=HYPERLINK(\"http://evil\")", "x"}})
+	require.Nil(t, err, "could not export event")
+	err = exporter.Export(&output.ResultEvent{TemplateID: "@template", Matched: "+cmd", ExtractedResults: []string{"-1"}})
+	require.Nil(t, err, "could not export event")
+	require.Nil(t, exporter.Close(), "could not close csv exporter")
+
+	data, err := ioutil.ReadFile(file)
+	require.Nil(t, err, "could not read csv file")
+	require.Equal(t, "template-id,severity,matched-at,extracted-results\n"+
+		"test-template,high,https://example.com/login,\"admin,say \"\"hi\"\"\"\n"+
+		"dns-template,,example.com,\n"+
+		"formula-template,,example.com,\"'=HYPERLINK(\"\"http://evil\"\"),x\"\n"+
+		"'@template,,'+cmd,'-1\n", string(data), "could not get correct csv output")
+
+	_, err = New(&Options{File: file, Columns: []string{"unknown"}})
+	require.NotNil(t, err, "invalid column was accepted")
+}
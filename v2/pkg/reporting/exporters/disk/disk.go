@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/filter"
 	"github.com/yaklang/nuclei/v2/pkg/reporting/format"
 )
 
@@ -20,6 +21,10 @@ type Exporter struct {
 type Options struct {
 	// Directory is the directory to export found results to
 	Directory string `yaml:"directory"`
+	// AllowList contains a list of allowed events for this exporter
+	AllowList *filter.Filter `yaml:"allow-list"`
+	// DenyList contains a list of denied events for this exporter
+	DenyList *filter.Filter `yaml:"deny-list"`
 }
 
 // New creates a new disk exporter integration client based on options.
@@ -0,0 +1,259 @@
+package es
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/dedupe"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/filter"
+)
+
+const (
+	defaultBatchSize     = 100
+	defaultFlushInterval = 5
+	defaultRetries       = 3
+)
+
+// Exporter is an exporter bulk indexing the result events in elasticsearch.
+type Exporter struct {
+	client  *http.Client
+	options *Options
+
+	mutex  *sync.Mutex
+	buffer *bytes.Buffer
+	events int
+
+	sendMutex *sync.Mutex
+	indexed   int
+	failed    int
+	lastErr   error
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Options contains the configuration options for elasticsearch exporter client
+type Options struct {
+	// Host is the URL of the elasticsearch server
+	Host string `yaml:"host"`
+	// Index is the name of the index to write the events to
+	Index string `yaml:"index"`
+	// Username is the username for basic authentication
+	Username string `yaml:"username"`
+	// Password is the password for basic authentication
+	Password string `yaml:"password"`
+	// APIKey is the base64 encoded API key, used instead of basic authentication
+	APIKey string `yaml:"api-key"`
+	// SkipVerify disables the verification of the server certificate
+	SkipVerify bool `yaml:"skip-verify"`
+	// BatchSize is the number of events indexed per bulk request
+	BatchSize int `yaml:"batch-size"`
+	// FlushInterval is the interval in seconds the buffered events are indexed at
+	FlushInterval int `yaml:"flush-interval"`
+	// Retries is the number of times a failed bulk request is retried
+	Retries int `yaml:"retries"`
+	// AllowList contains a list of allowed events for this exporter
+	AllowList *filter.Filter `yaml:"allow-list"`
+	// DenyList contains a list of denied events for this exporter
+	DenyList *filter.Filter `yaml:"deny-list"`
+	// DedupeKey is the dedupe key template of the reporting options,
+	// used to build the document IDs.
+	DedupeKey string `yaml:"-"`
+}
+
+// New creates a new elasticsearch exporter integration client based on options.
+func New(options *Options) (*Exporter, error) {
+	if options.Host == "" || options.Index == "" {
+		return nil, errors.New("elasticsearch host and index are required")
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultBatchSize
+	}
+	if options.FlushInterval <= 0 {
+		options.FlushInterval = defaultFlushInterval
+	}
+	if options.Retries <= 0 {
+		options.Retries = defaultRetries
+	}
+
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: options.SkipVerify},
+		},
+	}
+	exporter := &Exporter{
+		client:    client,
+		options:   options,
+		mutex:     &sync.Mutex{},
+		buffer:    &bytes.Buffer{},
+		sendMutex: &sync.Mutex{},
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go exporter.flushLoop(time.Duration(options.FlushInterval) * time.Second)
+	return exporter, nil
+}
+
+// Export buffers a passed result event, indexing the buffer once full.
+// The document ID is the dedupe key hash of the event so rescans update
+// the existing documents instead of duplicating them.
+func (i *Exporter) Export(event *output.ResultEvent) error {
+	document, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal event")
+	}
+	action, err := json.Marshal(map[string]map[string]string{
+		"index": {"_index": i.options.Index, "_id": hex.EncodeToString(dedupe.KeyHash(i.options.DedupeKey, event))},
+	})
+	if err != nil {
+		return errors.Wrap(err, "could not marshal bulk action")
+	}
+
+	i.mutex.Lock()
+	i.buffer.Write(action)
+	i.buffer.WriteByte('\n')
+	i.buffer.Write(document)
+	i.buffer.WriteByte('\n')
+	i.events++
+	full := i.events >= i.options.BatchSize
+	i.mutex.Unlock()
+
+	if full {
+		i.flush()
+	}
+	return nil
+}
+
+// Close indexes the buffered events, returning a summary of the failures
+func (i *Exporter) Close() error {
+	close(i.stop)
+	<-i.done
+	i.flush()
+
+	i.sendMutex.Lock()
+	defer i.sendMutex.Unlock()
+	if i.failed > 0 {
+		return errors.Errorf("could not index %d of %d events in elasticsearch: %s", i.failed, i.failed+i.indexed, i.lastErr)
+	}
+	return nil
+}
+
+// flushLoop indexes the buffered events on an interval until stopped
+func (i *Exporter) flushLoop(interval time.Duration) {
+	defer close(i.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			i.flush()
+		case <-i.stop:
+			return
+		}
+	}
+}
+
+// flush indexes the buffered events in a bulk request
+func (i *Exporter) flush() {
+	i.mutex.Lock()
+	if i.events == 0 {
+		i.mutex.Unlock()
+		return
+	}
+	body, events := i.buffer.Bytes(), i.events
+	i.buffer = &bytes.Buffer{}
+	i.events = 0
+	i.mutex.Unlock()
+
+	i.sendMutex.Lock()
+	defer i.sendMutex.Unlock()
+
+	var failed int
+	var err error
+	for attempt := 0; attempt <= i.options.Retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * time.Second)
+		}
+		var retry bool
+		if failed, retry, err = i.bulk(body, events); err == nil || !retry {
+			break
+		}
+	}
+	if err != nil {
+		gologger.Warning().Msgf("Could not index events in elasticsearch: %s\n", err)
+		i.lastErr = err
+	}
+	i.failed += failed
+	i.indexed += events - failed
+}
+
+// bulkResponse is the part of the bulk API response reporting failures
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  struct {
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// bulk sends a bulk request, returning the number of events not indexed
+// and whether the request can be retried.
+func (i *Exporter) bulk(body []byte, events int) (int, bool, error) {
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(i.options.Host, "/")+"/_bulk", bytes.NewReader(body))
+	if err != nil {
+		return events, false, errors.Wrap(err, "could not create bulk request")
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if i.options.APIKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+i.options.APIKey)
+	} else if i.options.Username != "" {
+		req.SetBasicAuth(i.options.Username, i.options.Password)
+	}
+
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return events, true, errors.Wrap(err, "could not send bulk request")
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return events, true, errors.Wrap(err, "could not read bulk response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return events, retry, errors.Errorf("unexpected status code %d: %s", resp.StatusCode, bytes.TrimSpace(data))
+	}
+
+	response := &bulkResponse{}
+	if err := json.Unmarshal(data, response); err != nil {
+		return events, false, errors.Wrap(err, "could not parse bulk response")
+	}
+	if !response.Errors {
+		return 0, false, nil
+	}
+	var failed int
+	var reason string
+	for _, item := range response.Items {
+		for _, result := range item {
+			if result.Status >= 300 {
+				failed++
+				reason = result.Error.Reason
+			}
+		}
+	}
+	return failed, false, errors.Errorf("%d events were rejected: %s", failed, reason)
+}
@@ -0,0 +1,117 @@
+package es
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/dedupe"
+	"github.com/stretchr/testify/require"
+)
+
+func TestElasticsearchExporter(t *testing.T) {
+	var mutex sync.Mutex
+	var requests int
+	ids := make(map[string]int)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		require.Equal(t, "/_bulk", r.URL.Path, "could not get bulk path")
+		require.Equal(t, "ApiKey secret", r.Header.Get("Authorization"), "could not get api key")
+
+		body, _ := ioutil.ReadAll(r.Body)
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		for scanner.Scan() {
+			action := map[string]map[string]string{}
+			require.Nil(t, json.Unmarshal(scanner.Bytes(), &action), "could not parse bulk action")
+			require.Equal(t, "nuclei", action["index"]["_index"], "could not get index")
+			ids[action["index"]["_id"]]++
+			require.True(t, scanner.Scan(), "could not get bulk document")
+		}
+		_, _ = w.Write([]byte(`{"errors":false,"items":[]}`))
+	}))
+	defer ts.Close()
+
+	exporter, err := New(&Options{Host: ts.URL, Index: "nuclei", APIKey: "secret", BatchSize: 2})
+	require.Nil(t, err, "could not create elasticsearch exporter")
+
+	events := []*output.ResultEvent{
+		{TemplateID: "first", Host: "https://example.com"},
+		{TemplateID: "second", Host: "https://example.com"},
+		{TemplateID: "first", Host: "https://example.com"},
+	}
+	for _, event := range events {
+		require.Nil(t, exporter.Export(event), "could not export event")
+	}
+	require.Nil(t, exporter.Close(), "could not close elasticsearch exporter")
+
+	require.Equal(t, 3, requests, "could not retry and flush bulk requests")
+	require.Len(t, ids, 2, "could not derive document ids from dedupe hash")
+	for _, count := range ids {
+		require.Contains(t, []int{1, 2}, count, "could not get document id count")
+	}
+}
+
+func TestElasticsearchExporterDedupeKey(t *testing.T) {
+	var mutex sync.Mutex
+	ids := make(map[string]int)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		body, _ := ioutil.ReadAll(r.Body)
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		for scanner.Scan() {
+			action := map[string]map[string]string{}
+			require.Nil(t, json.Unmarshal(scanner.Bytes(), &action), "could not parse bulk action")
+			ids[action["index"]["_id"]]++
+			require.True(t, scanner.Scan(), "could not get bulk document")
+		}
+		_, _ = w.Write([]byte(`{"errors":false,"items":[]}`))
+	}))
+	defer ts.Close()
+
+	exporter, err := New(&Options{Host: ts.URL, Index: "nuclei", DedupeKey: "{{template_id}}"})
+	require.Nil(t, err, "could not create elasticsearch exporter")
+
+	events := []*output.ResultEvent{
+		{TemplateID: "first", Host: "https://example.com", Matched: "https://example.com/a"},
+		{TemplateID: "first", Host: "https://example.com", Matched: "https://example.com/b"},
+	}
+	for _, event := range events {
+		require.Nil(t, exporter.Export(event), "could not export event")
+	}
+	require.Nil(t, exporter.Close(), "could not close elasticsearch exporter")
+
+	expected := hex.EncodeToString(dedupe.KeyHash("{{template_id}}", events[0]))
+	require.Equal(t, map[string]int{expected: 2}, ids, "could not derive document ids from dedupe key")
+}
+
+func TestElasticsearchExporterRejected(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errors":true,"items":[{"index":{"status":400,"error":{"reason":"mapping error"}}}]}`))
+	}))
+	defer ts.Close()
+
+	exporter, err := New(&Options{Host: ts.URL, Index: "nuclei"})
+	require.Nil(t, err, "could not create elasticsearch exporter")
+	require.Nil(t, exporter.Export(&output.ResultEvent{TemplateID: "test"}), "could not export event")
+
+	err = exporter.Close()
+	require.NotNil(t, err, "could not get rejected events error")
+	require.Contains(t, err.Error(), "could not index 1 of 1 events", "could not get error summary")
+	require.Contains(t, err.Error(), "mapping error", "could not get rejection reason")
+}
@@ -0,0 +1,245 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/filter"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/format"
+	"github.com/yaklang/nuclei/v2/pkg/types"
+)
+
+// indexFile is the name of the file linking all the findings
+const indexFile = "index.md"
+
+// unsafeFilenameRegex matches the characters replaced in the file names
+var unsafeFilenameRegex = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// severityColors are the badge colors of each severity
+var severityColors = map[string]string{
+	"info":     "blue",
+	"low":      "green",
+	"medium":   "yellow",
+	"high":     "orange",
+	"critical": "red",
+}
+
+// Exporter is an exporter writing a markdown report per host or per finding
+type Exporter struct {
+	directory string
+	options   *Options
+
+	mutex    *sync.Mutex
+	files    map[string]string
+	findings []*finding
+}
+
+// finding is a finding written to a report, linked from the index
+type finding struct {
+	host     string
+	file     string
+	summary  string
+	severity string
+}
+
+// Options contains the configuration options for markdown exporter client
+type Options struct {
+	// Directory is the directory to export the reports to
+	Directory string `yaml:"directory"`
+	// PerFinding writes a report per finding instead of per host
+	PerFinding bool `yaml:"per-finding"`
+	// AllowList contains a list of allowed events for this exporter
+	AllowList *filter.Filter `yaml:"allow-list"`
+	// DenyList contains a list of denied events for this exporter
+	DenyList *filter.Filter `yaml:"deny-list"`
+}
+
+// New creates a new markdown exporter integration client based on options.
+func New(options *Options) (*Exporter, error) {
+	directory := options.Directory
+	if options.Directory == "" {
+		dir, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		directory = dir
+	}
+	if err := os.MkdirAll(directory, os.ModePerm); err != nil {
+		return nil, errors.Wrap(err, "could not create markdown directory")
+	}
+	return &Exporter{options: options, directory: directory, mutex: &sync.Mutex{}, files: make(map[string]string)}, nil
+}
+
+// Export exports a passed result event to the report of its host or finding
+func (i *Exporter) Export(event *output.ResultEvent) error {
+	host := event.Host
+	if host == "" {
+		host = event.Matched
+	}
+	section := findingSection(event)
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	var filename, header string
+	if i.options.PerFinding {
+		filename = fmt.Sprintf("%s-%s-%d.md", sanitizeFilename(event.TemplateID), sanitizeFilename(host), len(i.findings)+1)
+		header = "# " + host + "\n\n"
+	} else if existing, ok := i.files[host]; ok {
+		filename = existing
+	} else {
+		filename = sanitizeFilename(host) + ".md"
+		i.files[host] = filename
+		header = "# " + host + "\n\n"
+	}
+
+	file, err := os.OpenFile(path.Join(i.directory, filename), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return errors.Wrap(err, "could not open markdown report")
+	}
+	defer file.Close()
+	if header != "" {
+		if err := file.Truncate(0); err != nil {
+			return errors.Wrap(err, "could not truncate markdown report")
+		}
+	}
+	if _, err := file.WriteString(header + section); err != nil {
+		return errors.Wrap(err, "could not write markdown report")
+	}
+
+	i.findings = append(i.findings, &finding{
+		host:     host,
+		file:     filename,
+		summary:  format.GetMatchedTemplate(event) + " - " + types.ToString(event.Info["name"]),
+		severity: types.ToString(event.Info["severity"]),
+	})
+	return nil
+}
+
+// Close writes the index linking all the findings grouped by host
+func (i *Exporter) Close() error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	if len(i.findings) == 0 {
+		return nil // do not write when no results
+	}
+	findings := make([]*finding, len(i.findings))
+	copy(findings, i.findings)
+	sort.SliceStable(findings, func(a, b int) bool {
+		return findings[a].host < findings[b].host
+	})
+
+	builder := &bytes.Buffer{}
+	builder.WriteString("# Nuclei Report\n\n")
+	var host string
+	for _, finding := range findings {
+		if finding.host != host {
+			host = finding.host
+			builder.WriteString("\n## ")
+			builder.WriteString(host)
+			builder.WriteString("\n\n")
+		}
+		builder.WriteString(fmt.Sprintf("- [%s] [%s](%s)\n", finding.severity, finding.summary, finding.file))
+	}
+	if err := ioutil.WriteFile(path.Join(i.directory, indexFile), builder.Bytes(), 0644); err != nil {
+		return errors.Wrap(err, "could not write markdown index")
+	}
+	return nil
+}
+
+// findingSection returns the markdown section describing a finding
+func findingSection(event *output.ResultEvent) string {
+	severity := types.ToString(event.Info["severity"])
+
+	builder := &bytes.Buffer{}
+	builder.WriteString("## ")
+	builder.WriteString(types.ToString(event.Info["name"]))
+	builder.WriteString("\n\n")
+	builder.WriteString(severityBadge(severity))
+	builder.WriteString("\n\n**Template**: ")
+	builder.WriteString(format.GetMatchedTemplate(event))
+	builder.WriteString("\n\n**Matched At**: ")
+	builder.WriteString(event.Matched)
+	builder.WriteString("\n\n**Timestamp**: ")
+	builder.WriteString(event.Timestamp.Format("Mon Jan 2 15:04:05 -0700 MST 2006"))
+	builder.WriteString("\n")
+
+	if description := types.ToString(event.Info["description"]); description != "" {
+		builder.WriteString("\n**Description**: ")
+		builder.WriteString(strings.TrimSpace(description))
+		builder.WriteString("\n")
+	}
+	if len(event.ExtractedResults) > 0 {
+		builder.WriteString("\n**Extracted Values**:\n\n")
+		for _, value := range event.ExtractedResults {
+			builder.WriteString("- `")
+			builder.WriteString(value)
+			builder.WriteString("`\n")
+		}
+	}
+	if references := referenceList(event.Info["reference"]); len(references) > 0 {
+		builder.WriteString("\n**References**:\n\n")
+		for _, reference := range references {
+			builder.WriteString("- ")
+			builder.WriteString(reference)
+			builder.WriteString("\n")
+		}
+	}
+	if event.Request != "" {
+		builder.WriteString("\n**Request**\n\n```\n")
+		builder.WriteString(event.Request)
+		builder.WriteString("\n```\n")
+	}
+	if event.Response != "" {
+		builder.WriteString("\n**Response**\n\n```\n")
+		builder.WriteString(event.Response)
+		builder.WriteString("\n```\n")
+	}
+	builder.WriteString("\n---\n\n")
+	return builder.String()
+}
+
+// severityBadge returns a shields.io badge for a severity
+func severityBadge(severity string) string {
+	if severity == "" {
+		severity = "unknown"
+	}
+	color, ok := severityColors[strings.ToLower(severity)]
+	if !ok {
+		color = "lightgrey"
+	}
+	return fmt.Sprintf("![%s](https://img.shields.io/badge/severity-%s-%s)", severity, strings.ReplaceAll(severity, "-", "--"), color)
+}
+
+// referenceList returns the references of the template info block
+func referenceList(reference interface{}) []string {
+	var references []string
+	switch v := reference.(type) {
+	case string:
+		for _, line := range strings.Split(v, "\n") {
+			if line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "-")); line != "" {
+				references = append(references, line)
+			}
+		}
+	case []interface{}:
+		references = types.ToStringSlice(v)
+	case []string:
+		references = v
+	}
+	return references
+}
+
+// sanitizeFilename replaces the characters not allowed in file names
+func sanitizeFilename(name string) string {
+	return strings.Trim(unsafeFilenameRegex.ReplaceAllString(name, "_"), "_")
+}
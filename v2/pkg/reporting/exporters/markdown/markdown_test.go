@@ -0,0 +1,94 @@
+package markdown
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+func testEvents() []*output.ResultEvent {
+	return []*output.ResultEvent{
+		{
+			TemplateID:       "exposed-panel",
+			Info:             map[string]interface{}{"name": "Exposed Panel", "severity": "high", "description": "An admin panel is exposed.", "reference": []interface{}{"https://example.com/advisory"}},
+			Host:             "https://example.com",
+			Matched:          "https://example.com/admin",
+			ExtractedResults: []string{"v1.2.3"},
+			Request:          "GET /admin HTTP/1.1",
+			Timestamp:        time.Now(),
+		},
+		{
+			TemplateID: "tech-detect",
+			Info:       map[string]interface{}{"name": "Tech Detect", "severity": "info"},
+			Host:       "https://example.com",
+			Matched:    "https://example.com",
+			Timestamp:  time.Now(),
+		},
+		{
+			TemplateID: "dns-takeover",
+			Info:       map[string]interface{}{"name": "DNS Takeover", "severity": "critical"},
+			Host:       "sub.example.org",
+			Matched:    "sub.example.org",
+			Timestamp:  time.Now(),
+		},
+	}
+}
+
+func TestMarkdownExporterPerHost(t *testing.T) {
+	directory, err := ioutil.TempDir("", "markdown-exporter-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(directory)
+
+	exporter, err := New(&Options{Directory: directory})
+	require.Nil(t, err, "could not create markdown exporter")
+	for _, event := range testEvents() {
+		require.Nil(t, exporter.Export(event), "could not export event")
+	}
+	require.Nil(t, exporter.Close(), "could not close markdown exporter")
+
+	files, err := filepath.Glob(filepath.Join(directory, "*.md"))
+	require.Nil(t, err, "could not list reports")
+	require.Len(t, files, 3, "could not write a report per host and the index")
+
+	data, err := ioutil.ReadFile(filepath.Join(directory, "https_example.com.md"))
+	require.Nil(t, err, "could not read host report")
+	report := string(data)
+	require.Contains(t, report, "# https://example.com\n", "could not write host title")
+	require.Contains(t, report, "## Exposed Panel", "could not write first finding")
+	require.Contains(t, report, "## Tech Detect", "could not write second finding")
+	require.Contains(t, report, "severity-high-orange", "could not write severity badge")
+	require.Contains(t, report, "An admin panel is exposed.", "could not write description")
+	require.Contains(t, report, "- https://example.com/advisory", "could not write reference")
+	require.Contains(t, report, "- `v1.2.3`", "could not write extracted values")
+	require.Contains(t, report, "GET /admin HTTP/1.1", "could not write request evidence")
+
+	data, err = ioutil.ReadFile(filepath.Join(directory, indexFile))
+	require.Nil(t, err, "could not read index")
+	require.Contains(t, string(data), "- [critical] [dns-takeover - DNS Takeover](sub.example.org.md)", "could not link finding in index")
+}
+
+func TestMarkdownExporterPerFinding(t *testing.T) {
+	directory, err := ioutil.TempDir("", "markdown-exporter-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(directory)
+
+	exporter, err := New(&Options{Directory: directory, PerFinding: true})
+	require.Nil(t, err, "could not create markdown exporter")
+	for _, event := range testEvents() {
+		require.Nil(t, exporter.Export(event), "could not export event")
+	}
+	require.Nil(t, exporter.Close(), "could not close markdown exporter")
+
+	files, err := filepath.Glob(filepath.Join(directory, "*.md"))
+	require.Nil(t, err, "could not list reports")
+	require.Len(t, files, 4, "could not write a report per finding and the index")
+
+	data, err := ioutil.ReadFile(filepath.Join(directory, indexFile))
+	require.Nil(t, err, "could not read index")
+	require.Contains(t, string(data), "(tech-detect-https_example.com-2.md)", "could not link finding in index")
+}
@@ -1,24 +1,38 @@
 package sarif
 
 import (
-	"crypto/sha1"
-	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"os"
 	"path"
+	"regexp"
+	"sort"
 	"strings"
 	"sync"
 
-	"github.com/owenrumney/go-sarif/sarif"
 	"github.com/pkg/errors"
 	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/filter"
 	"github.com/yaklang/nuclei/v2/pkg/reporting/format"
+	"github.com/yaklang/nuclei/v2/pkg/types"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+var (
+	cveRegex = regexp.MustCompile(`(?i)\bCVE-\d{4}-\d{4,}\b`)
+	cweRegex = regexp.MustCompile(`(?i)\bCWE-\d+\b`)
 )
 
 // Exporter is an exporter for nuclei sarif output format.
 type Exporter struct {
-	sarif *sarif.Report
-	run   *sarif.Run
-	mutex *sync.Mutex
+	mutex   *sync.Mutex
+	rules   []*rule
+	indexes map[string]int
+	results []*result
 
 	home    string
 	options *Options
@@ -28,89 +42,233 @@ type Exporter struct {
 type Options struct {
 	// File is the file to export found sarif result to
 	File string `yaml:"file"`
+	// AllowList contains a list of allowed events for this exporter
+	AllowList *filter.Filter `yaml:"allow-list"`
+	// DenyList contains a list of denied events for this exporter
+	DenyList *filter.Filter `yaml:"deny-list"`
+}
+
+// report is a sarif log with a single run of nuclei
+type report struct {
+	Version string `json:"version"`
+	Schema  string `json:"$schema"`
+	Runs    []*run `json:"runs"`
+}
+
+type run struct {
+	Tool    tool      `json:"tool"`
+	Results []*result `json:"results"`
+}
+
+type tool struct {
+	Driver driver `json:"driver"`
+}
+
+type driver struct {
+	Name           string  `json:"name"`
+	InformationURI string  `json:"informationUri"`
+	Rules          []*rule `json:"rules"`
+}
+
+// rule describes a template of the results
+type rule struct {
+	ID                   string            `json:"id"`
+	Name                 string            `json:"name,omitempty"`
+	ShortDescription     message           `json:"shortDescription"`
+	FullDescription      *message          `json:"fullDescription,omitempty"`
+	Help                 *message          `json:"help,omitempty"`
+	HelpURI              string            `json:"helpUri,omitempty"`
+	DefaultConfiguration ruleConfiguration `json:"defaultConfiguration"`
+	Properties           ruleProperties    `json:"properties"`
+}
+
+type ruleConfiguration struct {
+	Level string `json:"level"`
+}
+
+type ruleProperties struct {
+	Tags             []string `json:"tags,omitempty"`
+	SecuritySeverity string   `json:"security-severity,omitempty"`
+	CVE              []string `json:"cve,omitempty"`
+	CWE              []string `json:"cwe,omitempty"`
+}
+
+// result is a result of a template on a location
+type result struct {
+	RuleID    string     `json:"ruleId"`
+	RuleIndex int        `json:"ruleIndex"`
+	Level     string     `json:"level"`
+	Message   message    `json:"message"`
+	Locations []location `json:"locations"`
+}
+
+type message struct {
+	Text     string `json:"text"`
+	Markdown string `json:"markdown,omitempty"`
+}
+
+type location struct {
+	PhysicalLocation physicalLocation `json:"physicalLocation"`
+}
+
+type physicalLocation struct {
+	ArtifactLocation artifactLocation `json:"artifactLocation"`
+	Region           *region          `json:"region,omitempty"`
+}
+
+type artifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type region struct {
+	StartLine int `json:"startLine"`
 }
 
 // New creates a new disk exporter integration client based on options.
 func New(options *Options) (*Exporter, error) {
-	report, err := sarif.New(sarif.Version210)
-	if err != nil {
-		return nil, errors.Wrap(err, "could not create sarif exporter")
-	}
-
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return nil, errors.Wrap(err, "could not get home dir")
 	}
 	templatePath := path.Join(home, "nuclei-templates")
 
-	run := sarif.NewRun("nuclei", "https://github.com/projectdiscovery/nuclei")
-	return &Exporter{options: options, home: templatePath, sarif: report, run: run, mutex: &sync.Mutex{}}, nil
+	return &Exporter{options: options, home: templatePath, indexes: make(map[string]int), mutex: &sync.Mutex{}}, nil
 }
 
 // Export exports a passed result event to sarif structure
 func (i *Exporter) Export(event *output.ResultEvent) error {
-	templatePath := strings.TrimPrefix(event.TemplatePath, i.home)
+	severity := types.ToString(event.Info["severity"])
+	level := getSarifSeverity(event)
 
-	h := sha1.New()
-	h.Write([]byte(event.Host))
-	templateID := event.TemplateID + "-" + hex.EncodeToString(h.Sum(nil))
+	text := types.ToString(event.Info["name"]) + " matched at " + event.Matched
+	if len(event.ExtractedResults) > 0 {
+		text += " with extracted values " + strings.Join(event.ExtractedResults, ", ")
+	}
+	result := &result{
+		RuleID:    event.TemplateID,
+		Level:     level,
+		Message:   message{Text: text},
+		Locations: resultLocations(event),
+	}
 
-	fullDescription := format.MarkdownDescription(event)
-	sarifSeverity := getSarifSeverity(event)
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
 
-	var ruleName string
-	if s, ok := event.Info["name"]; ok {
-		ruleName = s.(string)
+	index, ok := i.indexes[event.TemplateID]
+	if !ok {
+		index = len(i.rules)
+		i.indexes[event.TemplateID] = index
+		i.rules = append(i.rules, i.templateRule(event, severity, level))
 	}
+	result.RuleIndex = index
+	i.results = append(i.results, result)
+	return nil
+}
 
+// templateRule returns the rule describing the template of an event
+func (i *Exporter) templateRule(event *output.ResultEvent, severity, level string) *rule {
 	var templateURL string
 	if strings.HasPrefix(event.TemplatePath, i.home) {
-		templateURL = "https://github.com/projectdiscovery/nuclei-templates/blob/master" + templatePath
+		templateURL = "https://github.com/projectdiscovery/nuclei-templates/blob/master" + strings.TrimPrefix(event.TemplatePath, i.home)
 	} else {
 		templateURL = "https://github.com/projectdiscovery/nuclei-templates"
 	}
 
-	var ruleDescription string
-	if d, ok := event.Info["description"]; ok {
-		ruleDescription = d.(string)
+	name := types.ToString(event.Info["name"])
+	rule := &rule{
+		ID:                   event.TemplateID,
+		Name:                 name,
+		ShortDescription:     message{Text: name},
+		Help:                 &message{Text: format.MarkdownDescription(event), Markdown: format.MarkdownDescription(event)},
+		HelpURI:              templateURL,
+		DefaultConfiguration: ruleConfiguration{Level: level},
+	}
+	if description := types.ToString(event.Info["description"]); description != "" {
+		rule.FullDescription = &message{Text: strings.TrimSpace(description)}
 	}
 
-	i.mutex.Lock()
-	defer i.mutex.Unlock()
+	tags := []string{"security"}
+	for _, tag := range strings.Split(types.ToString(event.Info["tags"]), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	cves, cwes := infoIdentifiers(event.Info)
+	for _, cwe := range cwes {
+		tags = append(tags, "external/cwe/"+strings.ToLower(cwe))
+	}
+	rule.Properties = ruleProperties{
+		Tags:             tags,
+		SecuritySeverity: securitySeverity(severity),
+		CVE:              cves,
+		CWE:              cwes,
+	}
+	return rule
+}
 
-	_ = i.run.AddRule(templateID).
-		WithDescription(ruleName).
-		WithHelp(fullDescription).
-		WithHelpURI(templateURL).
-		WithFullDescription(sarif.NewMultiformatMessageString(ruleDescription))
-	result := i.run.AddResult(templateID).
-		WithMessage(sarif.NewMessage().WithText(event.Host)).
-		WithLevel(sarifSeverity)
-
-		// Also write file match metadata to file
-	if event.Type == "file" && (event.FileToIndexPosition != nil && len(event.FileToIndexPosition) > 0) {
-		for file, line := range event.FileToIndexPosition {
-			result.WithLocation(sarif.NewLocation().WithMessage(sarif.NewMessage().WithText(ruleName)).WithPhysicalLocation(
-				sarif.NewPhysicalLocation().
-					WithArtifactLocation(sarif.NewArtifactLocation().WithUri(file)).
-					WithRegion(sarif.NewRegion().WithStartColumn(1).WithStartLine(line).WithEndLine(line).WithEndColumn(32)),
-			))
+// resultLocations returns the locations of a result, the lines of the
+// files for file results and the matched URL otherwise.
+func resultLocations(event *output.ResultEvent) []location {
+	if event.Type == "file" && len(event.FileToIndexPosition) > 0 {
+		files := make([]string, 0, len(event.FileToIndexPosition))
+		for file := range event.FileToIndexPosition {
+			files = append(files, file)
 		}
-	} else {
-		result.WithLocation(sarif.NewLocation().WithMessage(sarif.NewMessage().WithText(event.Host)).WithPhysicalLocation(
-			sarif.NewPhysicalLocation().
-				WithArtifactLocation(sarif.NewArtifactLocation().WithUri("README.md")).
-				WithRegion(sarif.NewRegion().WithStartColumn(1).WithStartLine(1).WithEndLine(1).WithEndColumn(1)),
-		))
+		sort.Strings(files)
+
+		locations := make([]location, 0, len(files))
+		for _, file := range files {
+			locations = append(locations, location{PhysicalLocation: physicalLocation{
+				ArtifactLocation: artifactLocation{URI: file},
+				Region:           &region{StartLine: event.FileToIndexPosition[file]},
+			}})
+		}
+		return locations
 	}
-	return nil
+	uri := event.Matched
+	if uri == "" {
+		uri = event.Host
+	}
+	return []location{{PhysicalLocation: physicalLocation{ArtifactLocation: artifactLocation{URI: uri}}}}
+}
+
+// infoIdentifiers returns the CVE and CWE identifiers of a template info block
+func infoIdentifiers(info map[string]interface{}) ([]string, []string) {
+	keys := make([]string, 0, len(info))
+	for key := range info {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	builder := &strings.Builder{}
+	for _, key := range keys {
+		builder.WriteString(fmt.Sprint(info[key]))
+		builder.WriteString("\n")
+	}
+	data := builder.String()
+	return uniqueUpper(cveRegex.FindAllString(data, -1)), uniqueUpper(cweRegex.FindAllString(data, -1))
+}
+
+// uniqueUpper returns the unique upper cased items of a slice
+func uniqueUpper(items []string) []string {
+	var unique []string
+	seen := make(map[string]struct{})
+	for _, item := range items {
+		item = strings.ToUpper(item)
+		if _, ok := seen[item]; ok {
+			continue
+		}
+		seen[item] = struct{}{}
+		unique = append(unique, item)
+	}
+	return unique
 }
 
 // getSarifSeverity returns the sarif severity
 func getSarifSeverity(event *output.ResultEvent) string {
 	var ruleSeverity string
 	if s, ok := event.Info["severity"]; ok {
-		ruleSeverity = s.(string)
+		ruleSeverity = types.ToString(s)
 	}
 
 	switch ruleSeverity {
@@ -125,13 +283,28 @@ func getSarifSeverity(event *output.ResultEvent) string {
 	}
 }
 
-// Close closes the exporter after operation
+// securitySeverity returns the numeric security severity of a severity
+func securitySeverity(severity string) string {
+	switch severity {
+	case "critical":
+		return "9.5"
+	case "high":
+		return "8.0"
+	case "medium":
+		return "5.5"
+	case "low":
+		return "2.0"
+	}
+	return ""
+}
+
+// Close closes the exporter after operation, writing all the results in
+// a single run.
 func (i *Exporter) Close() error {
 	i.mutex.Lock()
 	defer i.mutex.Unlock()
 
-	i.sarif.AddRun(i.run)
-	if len(i.run.Results) == 0 {
+	if len(i.results) == 0 {
 		return nil // do not write when no results
 	}
 	file, err := os.Create(i.options.File)
@@ -139,5 +312,19 @@ func (i *Exporter) Close() error {
 		return errors.Wrap(err, "could not create sarif output file")
 	}
 	defer file.Close()
-	return i.sarif.Write(file)
+
+	log := &report{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []*run{{
+			Tool:    tool{Driver: driver{Name: "nuclei", InformationURI: "https://github.com/projectdiscovery/nuclei", Rules: i.rules}},
+			Results: i.results,
+		}},
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(log); err != nil {
+		return errors.Wrap(err, "could not write sarif output file")
+	}
+	return nil
 }
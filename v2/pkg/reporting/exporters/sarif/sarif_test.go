@@ -0,0 +1,63 @@
+package sarif
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSarifExporter(t *testing.T) {
+	directory, err := ioutil.TempDir("", "sarif-exporter-*")
+	require.Nil(t, err, "could not create temporary directory")
+	defer os.RemoveAll(directory)
+
+	file := filepath.Join(directory, "results.sarif")
+	exporter, err := New(&Options{File: file})
+	require.Nil(t, err, "could not create sarif exporter")
+
+	info := map[string]interface{}{
+		"name":        "Apache RCE",
+		"severity":    "critical",
+		"description": "Remote code execution (CWE-78) tracked as CVE-2021-41773.",
+		"tags":        "cve,apache,rce",
+	}
+	hosts := 2000
+	for i := 0; i < hosts; i++ {
+		err = exporter.Export(&output.ResultEvent{
+			TemplateID:       "CVE-2021-41773",
+			Info:             info,
+			Host:             fmt.Sprintf("https://host%d.example.com", i),
+			Matched:          fmt.Sprintf("https://host%d.example.com/cgi-bin/", i),
+			ExtractedResults: []string{"uid=1"},
+		})
+		require.Nil(t, err, "could not export event")
+	}
+	require.Nil(t, exporter.Close(), "could not close sarif exporter")
+
+	data, err := ioutil.ReadFile(file)
+	require.Nil(t, err, "could not read sarif file")
+	log := &report{}
+	require.Nil(t, json.Unmarshal(data, log), "could not parse sarif file")
+
+	require.Len(t, log.Runs, 1, "could not aggregate results in a single run")
+	require.Len(t, log.Runs[0].Tool.Driver.Rules, 1, "could not create a rule per template")
+	require.Len(t, log.Runs[0].Results, hosts, "could not write all results")
+
+	rule := log.Runs[0].Tool.Driver.Rules[0]
+	require.Equal(t, "CVE-2021-41773", rule.ID, "could not get rule id")
+	require.Equal(t, "error", rule.DefaultConfiguration.Level, "could not map severity to level")
+	require.Equal(t, "9.5", rule.Properties.SecuritySeverity, "could not get security severity")
+	require.Equal(t, []string{"CVE-2021-41773"}, rule.Properties.CVE, "could not parse cve")
+	require.Equal(t, []string{"CWE-78"}, rule.Properties.CWE, "could not parse cwe")
+	require.Equal(t, []string{"security", "cve", "apache", "rce", "external/cwe/cwe-78"}, rule.Properties.Tags, "could not get tags")
+
+	result := log.Runs[0].Results[1]
+	require.Equal(t, "https://host1.example.com/cgi-bin/", result.Locations[0].PhysicalLocation.ArtifactLocation.URI, "could not get matched location")
+	require.Contains(t, result.Message.Text, "uid=1", "could not include extracted values")
+}
@@ -0,0 +1,131 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/filter"
+)
+
+const (
+	defaultSignatureHeader = "X-Nuclei-Signature"
+	defaultTimeout         = 10
+	defaultRetries         = 3
+)
+
+// retryBackoff is the delay before the first retry, doubled on each retry
+var retryBackoff = time.Second
+
+// Exporter is an exporter posting the result events to a webhook.
+type Exporter struct {
+	client  *http.Client
+	options *Options
+}
+
+// Options contains the configuration options for webhook exporter client
+type Options struct {
+	// URL is the URL of the webhook to post the events to
+	URL string `yaml:"url"`
+	// Headers are the custom headers sent with each event
+	Headers map[string]string `yaml:"headers"`
+	// Secret is the secret signing the body with HMAC-SHA256 if any
+	Secret string `yaml:"secret"`
+	// SignatureHeader is the header containing the signature of the body
+	SignatureHeader string `yaml:"signature-header"`
+	// Timeout is the timeout in seconds of each request
+	Timeout int `yaml:"timeout"`
+	// Retries is the number of times a failed request is retried
+	Retries int `yaml:"retries"`
+	// AllowList contains a list of allowed events for this exporter
+	AllowList *filter.Filter `yaml:"allow-list"`
+	// DenyList contains a list of denied events for this exporter
+	DenyList *filter.Filter `yaml:"deny-list"`
+}
+
+// New creates a new webhook exporter integration client based on options.
+func New(options *Options) (*Exporter, error) {
+	if options.URL == "" {
+		return nil, errors.New("webhook url is required")
+	}
+	if options.SignatureHeader == "" {
+		options.SignatureHeader = defaultSignatureHeader
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = defaultTimeout
+	}
+	if options.Retries <= 0 {
+		options.Retries = defaultRetries
+	}
+	client := &http.Client{Timeout: time.Duration(options.Timeout) * time.Second}
+	return &Exporter{client: client, options: options}, nil
+}
+
+// Export posts a passed result event as JSON to the webhook, retrying with
+// an exponential backoff on failures.
+func (i *Exporter) Export(event *output.ResultEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal event")
+	}
+
+	backoff := retryBackoff
+	for attempt := 0; attempt <= i.options.Retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		if err = i.post(body); err == nil {
+			return nil
+		}
+	}
+	gologger.Warning().Msgf("Could not post %s result for %s to webhook: %s\n", event.TemplateID, event.Host, err)
+	return nil
+}
+
+// post posts a body to the webhook
+func (i *Exporter) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, i.options.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "could not create request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range i.options.Headers {
+		req.Header.Set(key, value)
+	}
+	if i.options.Secret != "" {
+		req.Header.Set(i.options.SignatureHeader, "sha256="+Sign(body, i.options.Secret))
+	}
+
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "could not send request")
+	}
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 signature of a body
+func Sign(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Close closes the exporter after operation
+func (i *Exporter) Close() error {
+	return nil
+}
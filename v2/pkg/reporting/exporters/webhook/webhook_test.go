@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookExporterSignature(t *testing.T) {
+	var received *output.ResultEvent
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		require.Equal(t, "sha256="+Sign(body, "secret"), r.Header.Get("X-Signature"), "could not get correct signature")
+		require.Equal(t, "nuclei", r.Header.Get("X-Source"), "could not get custom header")
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"), "could not get content type")
+
+		received = &output.ResultEvent{}
+		require.Nil(t, json.Unmarshal(body, received), "could not unmarshal event")
+	}))
+	defer ts.Close()
+
+	exporter, err := New(&Options{URL: ts.URL, Secret: "secret", SignatureHeader: "X-Signature", Headers: map[string]string{"X-Source": "nuclei"}})
+	require.Nil(t, err, "could not create webhook exporter")
+
+	err = exporter.Export(&output.ResultEvent{TemplateID: "test-template", Host: "https://example.com"})
+	require.Nil(t, err, "could not export event")
+	require.NotNil(t, received, "could not post event")
+	require.Equal(t, "test-template", received.TemplateID, "could not get posted template")
+	require.Equal(t, "https://example.com", received.Host, "could not get posted host")
+}
+
+func TestWebhookExporterRetries(t *testing.T) {
+	defaultBackoff := retryBackoff
+	retryBackoff = time.Millisecond
+	defer func() { retryBackoff = defaultBackoff }()
+
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer ts.Close()
+
+	exporter, err := New(&Options{URL: ts.URL, Retries: 2})
+	require.Nil(t, err, "could not create webhook exporter")
+	require.Nil(t, exporter.Export(&output.ResultEvent{TemplateID: "test"}), "could not export event")
+	require.Equal(t, 3, requests, "could not retry failed requests")
+
+	requests = 0
+	exporter, err = New(&Options{URL: ts.URL, Retries: 1})
+	require.Nil(t, err, "could not create webhook exporter")
+	require.Nil(t, exporter.Export(&output.ResultEvent{TemplateID: "test"}), "could not export event")
+	require.Equal(t, 2, requests, "could not stop at retry limit")
+}
@@ -0,0 +1,119 @@
+// Package filter implements the allow and deny lists deciding which
+// events are reported by the reporting module and by each destination.
+package filter
+
+import (
+	"path"
+	"strings"
+
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/yaklang/nuclei/v2/pkg/types"
+)
+
+// Filter filters the received event and decides whether to perform
+// reporting for it or not. As in the previous versions, the severity
+// decides the match by itself when configured, otherwise the tags do.
+// The template IDs must match as well and excluded severities never match.
+type Filter struct {
+	// Severity is a comma separated list of severities to match
+	Severity string `yaml:"severity"`
+	severity []string
+	// ExcludeSeverity is a comma separated list of severities never matched
+	ExcludeSeverity string `yaml:"exclude-severity"`
+	excludeSeverity []string
+	// Tags is a comma separated list of tags to match
+	Tags string `yaml:"tags"`
+	tags []string
+	// TemplateIDs is a comma separated list of template IDs or glob patterns to match
+	TemplateIDs string `yaml:"template-ids"`
+	templateIDs []string
+}
+
+// Compile compiles the filter creating match structures.
+func (f *Filter) Compile() {
+	f.severity = splitList(f.Severity)
+	f.excludeSeverity = splitList(f.ExcludeSeverity)
+	f.tags = splitList(f.Tags)
+	f.templateIDs = splitList(f.TemplateIDs)
+}
+
+// GetMatch returns true if a filter matches result event
+func (f *Filter) GetMatch(event *output.ResultEvent) bool {
+	severity := types.ToString(event.Info["severity"])
+	if stringSliceContains(f.excludeSeverity, severity) {
+		return false
+	}
+	if len(f.severity) > 0 {
+		if !stringSliceContains(f.severity, severity) {
+			return false
+		}
+	} else if len(f.tags) > 0 && !f.matchTags(event) {
+		return false
+	}
+	if len(f.templateIDs) > 0 && !f.matchTemplateID(event.TemplateID) {
+		return false
+	}
+	return !f.empty()
+}
+
+// empty returns true if the filter has no criteria
+func (f *Filter) empty() bool {
+	return len(f.severity)+len(f.excludeSeverity)+len(f.tags)+len(f.templateIDs) == 0
+}
+
+// matchTags returns true if the event has one of the tags of the filter
+func (f *Filter) matchTags(event *output.ResultEvent) bool {
+	tagParts := splitList(types.ToString(event.Info["tags"]))
+	for _, tag := range f.tags {
+		if stringSliceContains(tagParts, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchTemplateID returns true if a template ID matches one of the filter
+func (f *Filter) matchTemplateID(templateID string) bool {
+	for _, pattern := range f.templateIDs {
+		if strings.EqualFold(pattern, templateID) {
+			return true
+		}
+		if matched, err := path.Match(strings.ToLower(pattern), strings.ToLower(templateID)); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Allowed returns true if an event matches the allow list and doesn't
+// match the deny list, each of them being optional. An allow list without
+// criteria allows all the events.
+func Allowed(allowList, denyList *Filter, event *output.ResultEvent) bool {
+	if allowList != nil && !allowList.empty() && !allowList.GetMatch(event) {
+		return false
+	}
+	if denyList != nil && denyList.GetMatch(event) {
+		return false
+	}
+	return true
+}
+
+// splitList splits a comma separated list ignoring the empty items
+func splitList(value string) []string {
+	var items []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			items = append(items, part)
+		}
+	}
+	return items
+}
+
+func stringSliceContains(slice []string, item string) bool {
+	for _, i := range slice {
+		if strings.EqualFold(i, item) {
+			return true
+		}
+	}
+	return false
+}
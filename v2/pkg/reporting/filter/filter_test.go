@@ -0,0 +1,68 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestFilterGetMatch(t *testing.T) {
+	critical := &output.ResultEvent{TemplateID: "CVE-2021-1234", Info: map[string]interface{}{"severity": "critical", "tags": "cve, rce"}}
+	info := &output.ResultEvent{TemplateID: "tech-detect", Info: map[string]interface{}{"severity": "info", "tags": "tech"}}
+
+	tests := []struct {
+		filter   *Filter
+		critical bool
+		info     bool
+	}{
+		{filter: &Filter{Severity: "critical,high"}, critical: true, info: false},
+		{filter: &Filter{ExcludeSeverity: "info"}, critical: true, info: false},
+		{filter: &Filter{Tags: "tech"}, critical: false, info: true},
+		{filter: &Filter{TemplateIDs: "cve-2021-*"}, critical: true, info: false},
+		{filter: &Filter{Severity: "critical", Tags: "tech"}, critical: true, info: false},
+		{filter: &Filter{Severity: "critical", TemplateIDs: "tech-*"}, critical: false, info: false},
+		{filter: &Filter{}, critical: false, info: false},
+	}
+	for _, test := range tests {
+		test.filter.Compile()
+		require.Equal(t, test.critical, test.filter.GetMatch(critical), "could not match critical event with %+v", test.filter)
+		require.Equal(t, test.info, test.filter.GetMatch(info), "could not match info event with %+v", test.filter)
+	}
+}
+
+func TestFilterBaselineConfig(t *testing.T) {
+	config := &struct {
+		AllowList *Filter `yaml:"allow-list"`
+		DenyList  *Filter `yaml:"deny-list"`
+	}{}
+	err := yaml.Unmarshal([]byte("allow-list:\n  tags: cve\ndeny-list:\n  severity: info\n  tags: dos\n"), config)
+	require.Nil(t, err, "could not unmarshal baseline config")
+	config.AllowList.Compile()
+	config.DenyList.Compile()
+
+	info := &output.ResultEvent{TemplateID: "CVE-2021-1234", Info: map[string]interface{}{"severity": "info", "tags": "cve"}}
+	high := &output.ResultEvent{TemplateID: "CVE-2021-5678", Info: map[string]interface{}{"severity": "high", "tags": "cve,dos"}}
+	tech := &output.ResultEvent{TemplateID: "tech-detect", Info: map[string]interface{}{"severity": "low", "tags": "tech"}}
+
+	require.False(t, Allowed(config.AllowList, config.DenyList, info), "could allow info event of the deny list severity")
+	require.True(t, Allowed(config.AllowList, config.DenyList, high), "could not allow event with the deny list tags only")
+	require.False(t, Allowed(config.AllowList, config.DenyList, tech), "could allow event not in allow list")
+}
+
+func TestFilterAllowed(t *testing.T) {
+	event := &output.ResultEvent{TemplateID: "tech-detect", Info: map[string]interface{}{"severity": "info"}}
+
+	allowList := &Filter{Severity: "critical"}
+	allowList.Compile()
+	denyList := &Filter{TemplateIDs: "tech-*"}
+	denyList.Compile()
+	empty := &Filter{}
+	empty.Compile()
+
+	require.True(t, Allowed(nil, nil, event), "could not allow event without filters")
+	require.False(t, Allowed(allowList, nil, event), "could allow event not in allow list")
+	require.False(t, Allowed(nil, denyList, event), "could allow event in deny list")
+	require.True(t, Allowed(empty, empty, event), "could not allow event with empty filters")
+}
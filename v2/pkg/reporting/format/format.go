@@ -54,6 +54,11 @@ func MarkdownDescription(event *output.ResultEvent) string {
 		builder.WriteString(event.Request)
 		builder.WriteString("\n```\n")
 	}
+	if event.CurlCommand != "" {
+		builder.WriteString("\n**CURL Command**\n\n```\n")
+		builder.WriteString(event.CurlCommand)
+		builder.WriteString("\n```\n")
+	}
 	if event.Response != "" {
 		builder.WriteString("\n**Response**\n\n```http\n")
 		// If the response is larger than 5 kb, truncate it before writing.
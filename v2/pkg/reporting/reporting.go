@@ -1,17 +1,25 @@
 package reporting
 
 import (
-	"strings"
+	"time"
 
 	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
 	"github.com/yaklang/nuclei/v2/pkg/output"
 	"github.com/yaklang/nuclei/v2/pkg/reporting/dedupe"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/exporters/csv"
 	"github.com/yaklang/nuclei/v2/pkg/reporting/exporters/disk"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/exporters/es"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/exporters/markdown"
 	"github.com/yaklang/nuclei/v2/pkg/reporting/exporters/sarif"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/exporters/webhook"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/filter"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/trackers/discord"
 	"github.com/yaklang/nuclei/v2/pkg/reporting/trackers/github"
 	"github.com/yaklang/nuclei/v2/pkg/reporting/trackers/gitlab"
 	"github.com/yaklang/nuclei/v2/pkg/reporting/trackers/jira"
-	"github.com/yaklang/nuclei/v2/pkg/types"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/trackers/slack"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/trackers/teams"
 	"go.uber.org/multierr"
 )
 
@@ -27,52 +35,35 @@ type Options struct {
 	Gitlab *gitlab.Options `yaml:"gitlab"`
 	// Jira contains configuration options for Jira Issue Tracker
 	Jira *jira.Options `yaml:"jira"`
+	// Slack contains configuration options for Slack notifications
+	Slack *slack.Options `yaml:"slack"`
+	// Discord contains configuration options for Discord notifications
+	Discord *discord.Options `yaml:"discord"`
+	// Teams contains configuration options for Microsoft Teams notifications
+	Teams *teams.Options `yaml:"teams"`
 	// DiskExporter contains configuration options for Disk Exporter Module
 	DiskExporter *disk.Options `yaml:"disk"`
 	// SarifExporter contains configuration options for Sarif Exporter Module
 	SarifExporter *sarif.Options `yaml:"sarif"`
+	// CSVExporter contains configuration options for CSV Exporter Module
+	CSVExporter *csv.Options `yaml:"csv"`
+	// MarkdownExporter contains configuration options for Markdown Exporter Module
+	MarkdownExporter *markdown.Options `yaml:"markdown"`
+	// ElasticsearchExporter contains configuration options for Elasticsearch Exporter Module
+	ElasticsearchExporter *es.Options `yaml:"elasticsearch"`
+	// WebhookExporter contains configuration options for Webhook Exporter Module
+	WebhookExporter *webhook.Options `yaml:"webhook"`
+	// DedupeKey is the template of the keys identifying the duplicate
+	// results, like {{template_id}}:{{host}}. All the fields are used if empty.
+	DedupeKey string `yaml:"dedupe-key"`
+	// DedupeTTL is the time after which the duplicates not seen again are
+	// forgotten, 0 to remember them forever.
+	DedupeTTL time.Duration `yaml:"dedupe-ttl"`
 }
 
 // Filter filters the received event and decides whether to perform
 // reporting for it or not.
-type Filter struct {
-	Severity string `yaml:"severity"`
-	severity []string
-	Tags     string `yaml:"tags"`
-	tags     []string
-}
-
-// Compile compiles the filter creating match structures.
-func (f *Filter) Compile() {
-	parts := strings.Split(f.Severity, ",")
-	for _, part := range parts {
-		f.severity = append(f.severity, strings.TrimSpace(part))
-	}
-	parts = strings.Split(f.Tags, ",")
-	for _, part := range parts {
-		f.tags = append(f.tags, strings.TrimSpace(part))
-	}
-}
-
-// GetMatch returns true if a filter matches result event
-func (f *Filter) GetMatch(event *output.ResultEvent) bool {
-	severity := types.ToString(event.Info["severity"])
-	if len(f.severity) > 0 {
-		return stringSliceContains(f.severity, severity)
-	}
-
-	tags := event.Info["tags"]
-	tagParts := strings.Split(types.ToString(tags), ",")
-	for i, tag := range tagParts {
-		tagParts[i] = strings.TrimSpace(tag)
-	}
-	for _, tag := range f.tags {
-		if stringSliceContains(tagParts, tag) {
-			return true
-		}
-	}
-	return false
-}
+type Filter = filter.Filter
 
 // Tracker is an interface implemented by an issue tracker
 type Tracker interface {
@@ -90,58 +81,119 @@ type Exporter interface {
 
 // Client is a client for nuclei issue tracking module
 type Client struct {
-	trackers  []Tracker
-	exporters []Exporter
+	trackers  []*filteredTracker
+	exporters []*filteredExporter
 	options   *Options
 	dedupe    *dedupe.Storage
 }
 
+// filteredTracker is a tracker with its own allow and deny lists
+type filteredTracker struct {
+	tracker   Tracker
+	allowList *Filter
+	denyList  *Filter
+}
+
+// filteredExporter is an exporter with its own allow and deny lists
+type filteredExporter struct {
+	exporter  Exporter
+	allowList *Filter
+	denyList  *Filter
+}
+
 // New creates a new nuclei issue tracker reporting client
 func New(options *Options, db string) (*Client, error) {
-	if options.AllowList != nil {
-		options.AllowList.Compile()
-	}
-	if options.DenyList != nil {
-		options.DenyList.Compile()
-	}
+	compileFilters(options.AllowList, options.DenyList)
 
 	client := &Client{options: options}
 	if options.Github != nil {
+		options.Github.DedupeKey = options.DedupeKey
 		tracker, err := github.New(options.Github)
 		if err != nil {
 			return nil, errors.Wrap(err, "could not create reporting client")
 		}
-		client.trackers = append(client.trackers, tracker)
+		client.addTracker(tracker, options.Github.AllowList, options.Github.DenyList)
 	}
 	if options.Gitlab != nil {
 		tracker, err := gitlab.New(options.Gitlab)
 		if err != nil {
 			return nil, errors.Wrap(err, "could not create reporting client")
 		}
-		client.trackers = append(client.trackers, tracker)
+		client.addTracker(tracker, options.Gitlab.AllowList, options.Gitlab.DenyList)
 	}
 	if options.Jira != nil {
+		options.Jira.DedupeKey = options.DedupeKey
 		tracker, err := jira.New(options.Jira)
 		if err != nil {
 			return nil, errors.Wrap(err, "could not create reporting client")
 		}
-		client.trackers = append(client.trackers, tracker)
+		client.addTracker(tracker, options.Jira.AllowList, options.Jira.DenyList)
+	}
+	if options.Slack != nil {
+		tracker, err := slack.New(options.Slack)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create reporting client")
+		}
+		client.addTracker(tracker, options.Slack.AllowList, options.Slack.DenyList)
+	}
+	if options.Discord != nil {
+		tracker, err := discord.New(options.Discord)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create reporting client")
+		}
+		client.addTracker(tracker, options.Discord.AllowList, options.Discord.DenyList)
+	}
+	if options.Teams != nil {
+		tracker, err := teams.New(options.Teams)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create reporting client")
+		}
+		client.addTracker(tracker, options.Teams.AllowList, options.Teams.DenyList)
 	}
 	if options.DiskExporter != nil {
 		exporter, err := disk.New(options.DiskExporter)
 		if err != nil {
 			return nil, errors.Wrap(err, "could not create exporting client")
 		}
-		client.exporters = append(client.exporters, exporter)
+		client.addExporter(exporter, options.DiskExporter.AllowList, options.DiskExporter.DenyList)
 	}
 	if options.SarifExporter != nil {
 		exporter, err := sarif.New(options.SarifExporter)
 		if err != nil {
 			return nil, errors.Wrap(err, "could not create exporting client")
 		}
-		client.exporters = append(client.exporters, exporter)
+		client.addExporter(exporter, options.SarifExporter.AllowList, options.SarifExporter.DenyList)
 	}
-	storage, err := dedupe.New(db)
+	if options.CSVExporter != nil {
+		exporter, err := csv.New(options.CSVExporter)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create exporting client")
+		}
+		client.addExporter(exporter, options.CSVExporter.AllowList, options.CSVExporter.DenyList)
+	}
+	if options.MarkdownExporter != nil {
+		exporter, err := markdown.New(options.MarkdownExporter)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create exporting client")
+		}
+		client.addExporter(exporter, options.MarkdownExporter.AllowList, options.MarkdownExporter.DenyList)
+	}
+	if options.ElasticsearchExporter != nil {
+		options.ElasticsearchExporter.DedupeKey = options.DedupeKey
+		exporter, err := es.New(options.ElasticsearchExporter)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create exporting client")
+		}
+		client.addExporter(exporter, options.ElasticsearchExporter.AllowList, options.ElasticsearchExporter.DenyList)
+	}
+	if options.WebhookExporter != nil {
+		exporter, err := webhook.New(options.WebhookExporter)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create exporting client")
+		}
+		client.addExporter(exporter, options.WebhookExporter.AllowList, options.WebhookExporter.DenyList)
+	}
+	storage, err := dedupe.NewWithOptions(&dedupe.Options{Path: db, KeyTemplate: options.DedupeKey, TTL: options.DedupeTTL})
 	if err != nil {
 		return nil, err
 	}
@@ -149,44 +201,62 @@ func New(options *Options, db string) (*Client, error) {
 	return client, nil
 }
 
+// addTracker adds a tracker to the client, compiling its filters
+func (c *Client) addTracker(tracker Tracker, allowList, denyList *Filter) {
+	compileFilters(allowList, denyList)
+	c.trackers = append(c.trackers, &filteredTracker{tracker: tracker, allowList: allowList, denyList: denyList})
+}
+
+// addExporter adds an exporter to the client, compiling its filters
+func (c *Client) addExporter(exporter Exporter, allowList, denyList *Filter) {
+	compileFilters(allowList, denyList)
+	c.exporters = append(c.exporters, &filteredExporter{exporter: exporter, allowList: allowList, denyList: denyList})
+}
+
+// compileFilters compiles the filters which are configured
+func compileFilters(filters ...*Filter) {
+	for _, f := range filters {
+		if f != nil {
+			f.Compile()
+		}
+	}
+}
+
 // Close closes the issue tracker reporting client
 func (c *Client) Close() {
 	c.dedupe.Close()
 	for _, exporter := range c.exporters {
-		exporter.Close()
+		if err := exporter.exporter.Close(); err != nil {
+			gologger.Warning().Msgf("Could not close exporter: %s\n", err)
+		}
 	}
 }
 
-// CreateIssue creates an issue in the tracker
+// CreateIssue creates an issue in the trackers and exports it with the
+// exporters allowing it, after the global allow and deny lists.
 func (c *Client) CreateIssue(event *output.ResultEvent) error {
-	if c.options.AllowList != nil && !c.options.AllowList.GetMatch(event) {
-		return nil
-	}
-	if c.options.DenyList != nil && c.options.DenyList.GetMatch(event) {
+	if !filter.Allowed(c.options.AllowList, c.options.DenyList, event) {
 		return nil
 	}
 
 	unique, err := c.dedupe.Index(event)
 	if unique {
 		for _, tracker := range c.trackers {
-			if trackerErr := tracker.CreateIssue(event); trackerErr != nil {
+			if !filter.Allowed(tracker.allowList, tracker.denyList, event) {
+				continue
+			}
+			if trackerErr := tracker.tracker.CreateIssue(event); trackerErr != nil {
 				err = multierr.Append(err, trackerErr)
 			}
 		}
 		for _, exporter := range c.exporters {
-			if exportErr := exporter.Export(event); exportErr != nil {
+			if !filter.Allowed(exporter.allowList, exporter.denyList, event) {
+				continue
+			}
+			if exportErr := exporter.exporter.Export(event); exportErr != nil {
 				err = multierr.Append(err, exportErr)
 			}
 		}
 	}
 	return err
 }
-
-func stringSliceContains(slice []string, item string) bool {
-	for _, i := range slice {
-		if strings.EqualFold(i, item) {
-			return true
-		}
-	}
-	return false
-}
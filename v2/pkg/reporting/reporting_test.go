@@ -0,0 +1,51 @@
+package reporting
+
+import (
+	"testing"
+
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/dedupe"
+	"github.com/stretchr/testify/require"
+)
+
+type mockDestination struct {
+	events []string
+}
+
+func (m *mockDestination) CreateIssue(event *output.ResultEvent) error {
+	m.events = append(m.events, event.TemplateID)
+	return nil
+}
+
+func (m *mockDestination) Export(event *output.ResultEvent) error {
+	return m.CreateIssue(event)
+}
+
+func (m *mockDestination) Close() error {
+	return nil
+}
+
+func TestClientDestinationFilters(t *testing.T) {
+	storage, err := dedupe.New("")
+	require.Nil(t, err, "could not create dedupe storage")
+
+	client := &Client{options: &Options{DenyList: &Filter{TemplateIDs: "denied-*"}}, dedupe: storage}
+	compileFilters(client.options.AllowList, client.options.DenyList)
+	defer client.Close()
+
+	jira := &mockDestination{}
+	client.addTracker(jira, &Filter{Severity: "critical"}, nil)
+	disk := &mockDestination{}
+	client.addExporter(disk, nil, &Filter{Severity: "critical"})
+
+	events := []*output.ResultEvent{
+		{TemplateID: "critical-finding", Info: map[string]interface{}{"severity": "critical"}},
+		{TemplateID: "info-finding", Info: map[string]interface{}{"severity": "info"}},
+		{TemplateID: "denied-finding", Info: map[string]interface{}{"severity": "critical"}},
+	}
+	for _, event := range events {
+		require.Nil(t, client.CreateIssue(event), "could not create issue")
+	}
+	require.Equal(t, []string{"critical-finding"}, jira.events, "could not filter tracker events")
+	require.Equal(t, []string{"info-finding"}, disk.events, "could not filter exporter events")
+}
@@ -2,16 +2,33 @@ package github
 
 import (
 	"context"
+	"fmt"
 	"net/url"
+	"time"
 
 	"golang.org/x/oauth2"
 
 	"github.com/google/go-github/github"
 	"github.com/pkg/errors"
 	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/dedupe"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/filter"
 	"github.com/yaklang/nuclei/v2/pkg/reporting/format"
+	"github.com/yaklang/nuclei/v2/pkg/types"
 )
 
+const (
+	// maxRateLimitRetries is the number of times a rate limited call is retried
+	maxRateLimitRetries = 3
+	// maxRateLimitWait is the maximum time waited for a rate limit reset
+	maxRateLimitWait = 15 * time.Minute
+	// defaultAbuseWait is the time waited on abuse rate limits without Retry-After
+	defaultAbuseWait = time.Minute
+)
+
+// sleep waits for the rate limits to reset, replaced in tests
+var sleep = time.Sleep
+
 // Integration is a client for a issue tracker integration
 type Integration struct {
 	client  *github.Client
@@ -32,6 +49,22 @@ type Options struct {
 	ProjectName string `yaml:"project-name"`
 	// IssueLabel is the label of the created issue type
 	IssueLabel string `yaml:"issue-label"`
+	// Labels are the additional labels of the created issues
+	Labels []string `yaml:"labels"`
+	// Assignees are the users assigned to the issues, the user if empty
+	Assignees []string `yaml:"assignees"`
+	// SeverityLabels maps the severities to the labels of the issues
+	SeverityLabels map[string]string `yaml:"severity-labels"`
+	// DuplicateIssueCheck comments on the open issue of the same template
+	// and host instead of creating a new one.
+	DuplicateIssueCheck bool `yaml:"duplicate-issue-check"`
+	// DedupeKey is the dedupe key template of the reporting options,
+	// identifying the duplicate issues.
+	DedupeKey string `yaml:"-"`
+	// AllowList contains a list of allowed events for this tracker
+	AllowList *filter.Filter `yaml:"allow-list"`
+	// DenyList contains a list of denied events for this tracker
+	DenyList *filter.Filter `yaml:"deny-list"`
 }
 
 // New creates a new issue tracker integration client based on options.
@@ -53,17 +86,102 @@ func New(options *Options) (*Integration, error) {
 	return &Integration{client: client, options: options}, nil
 }
 
-// CreateIssue creates an issue in the tracker
+// CreateIssue creates an issue in the tracker, or comments on the open
+// issue with the same fingerprint if duplicate issues are checked.
 func (i *Integration) CreateIssue(event *output.ResultEvent) error {
+	fingerprint := dedupe.Fingerprint(i.options.DedupeKey, event)
+	if i.options.DuplicateIssueCheck {
+		number, err := i.findIssue(fingerprint)
+		if err != nil {
+			return errors.Wrap(err, "could not search existing issues")
+		}
+		if number != 0 {
+			return i.commentIssue(number, event)
+		}
+	}
+
 	summary := format.Summary(event)
-	description := format.MarkdownDescription(event)
+	description := format.MarkdownDescription(event) + "\n\nFingerprint: `" + fingerprint + "`"
+	labels := i.issueLabels(event)
+	assignees := i.options.Assignees
+	if len(assignees) == 0 && i.options.Username != "" {
+		assignees = []string{i.options.Username}
+	}
 
 	req := &github.IssueRequest{
 		Title:     &summary,
 		Body:      &description,
-		Labels:    &[]string{i.options.IssueLabel},
-		Assignees: &[]string{i.options.Username},
+		Labels:    &labels,
+		Assignees: &assignees,
+	}
+	return i.withRateLimit(func() (*github.Response, error) {
+		_, resp, err := i.client.Issues.Create(context.Background(), i.options.Owner, i.options.ProjectName, req)
+		return resp, err
+	})
+}
+
+// issueLabels returns the labels of the issue of an event
+func (i *Integration) issueLabels(event *output.ResultEvent) []string {
+	labels := []string{}
+	if i.options.IssueLabel != "" {
+		labels = append(labels, i.options.IssueLabel)
+	}
+	labels = append(labels, i.options.Labels...)
+	if label, ok := i.options.SeverityLabels[types.ToString(event.Info["severity"])]; ok {
+		labels = append(labels, label)
+	}
+	return labels
+}
+
+// findIssue returns the number of the open issue with a fingerprint, 0 if none
+func (i *Integration) findIssue(fingerprint string) (int, error) {
+	query := fmt.Sprintf("repo:%s/%s is:issue is:open in:body %s", i.options.Owner, i.options.ProjectName, fingerprint)
+
+	var result *github.IssuesSearchResult
+	err := i.withRateLimit(func() (*github.Response, error) {
+		var resp *github.Response
+		var err error
+		result, resp, err = i.client.Search.Issues(context.Background(), query, &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 1}})
+		return resp, err
+	})
+	if err != nil || len(result.Issues) == 0 {
+		return 0, err
+	}
+	return result.Issues[0].GetNumber(), nil
+}
+
+// commentIssue comments on an existing issue that an event was found again
+func (i *Integration) commentIssue(number int, event *output.ResultEvent) error {
+	body := fmt.Sprintf("Found again at %s on %s", event.Matched, event.Timestamp.Format("Mon Jan 2 15:04:05 -0700 MST 2006"))
+	return i.withRateLimit(func() (*github.Response, error) {
+		_, resp, err := i.client.Issues.CreateComment(context.Background(), i.options.Owner, i.options.ProjectName, number, &github.IssueComment{Body: &body})
+		return resp, err
+	})
+}
+
+// withRateLimit calls the github API, waiting for the rate limits to reset
+// and retrying instead of dropping the event.
+func (i *Integration) withRateLimit(call func() (*github.Response, error)) error {
+	var err error
+	for attempt := 0; attempt <= maxRateLimitRetries; attempt++ {
+		_, err = call()
+
+		var wait time.Duration
+		switch v := err.(type) {
+		case *github.RateLimitError:
+			wait = time.Until(v.Rate.Reset.Time) + time.Second
+		case *github.AbuseRateLimitError:
+			wait = defaultAbuseWait
+			if v.RetryAfter != nil {
+				wait = *v.RetryAfter
+			}
+		default:
+			return err
+		}
+		if wait > maxRateLimitWait {
+			wait = maxRateLimitWait
+		}
+		sleep(wait)
 	}
-	_, _, err := i.client.Issues.Create(context.Background(), i.options.Owner, i.options.ProjectName, req)
 	return err
 }
@@ -0,0 +1,90 @@
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/dedupe"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGithubCreateIssue(t *testing.T) {
+	var waits []time.Duration
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { sleep = time.Sleep }()
+
+	var searches, rateLimited int
+	var existing bool
+	var created map[string]interface{}
+	var comments []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search/issues":
+			searches++
+			require.Contains(t, r.URL.Query().Get("q"), "repo:owner/repo is:issue is:open", "could not get search query")
+			if !existing {
+				_, _ = w.Write([]byte(`{"total_count":0,"items":[]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"total_count":1,"items":[{"number":7}]}`))
+		case "/repos/owner/repo/issues":
+			if rateLimited == 0 {
+				rateLimited++
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Unix(), 10))
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"message":"API rate limit exceeded for user"}`))
+				return
+			}
+			created = map[string]interface{}{}
+			require.Nil(t, json.NewDecoder(r.Body).Decode(&created), "could not decode issue")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"number":7}`))
+		case "/repos/owner/repo/issues/7/comments":
+			comment := map[string]interface{}{}
+			require.Nil(t, json.NewDecoder(r.Body).Decode(&comment), "could not decode comment")
+			comments = append(comments, comment["body"].(string))
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	integration, err := New(&Options{
+		BaseURL:             ts.URL + "/",
+		Owner:               "owner",
+		ProjectName:         "repo",
+		Labels:              []string{"nuclei"},
+		Assignees:           []string{"alice", "bob"},
+		SeverityLabels:      map[string]string{"critical": "priority: critical"},
+		DuplicateIssueCheck: true,
+	})
+	require.Nil(t, err, "could not create github integration")
+
+	event := &output.ResultEvent{
+		TemplateID: "exposed-panel",
+		Info:       map[string]interface{}{"name": "Exposed Panel", "severity": "critical"},
+		Host:       "https://example.com",
+		Matched:    "https://example.com/admin",
+	}
+	require.Nil(t, integration.CreateIssue(event), "could not create issue")
+	require.Len(t, waits, 1, "could not back off on rate limit")
+	require.NotNil(t, created, "could not create issue after rate limit")
+	require.Equal(t, []interface{}{"nuclei", "priority: critical"}, created["labels"], "could not set labels")
+	require.Equal(t, []interface{}{"alice", "bob"}, created["assignees"], "could not set assignees")
+	require.Contains(t, created["body"], dedupe.Fingerprint("", event), "could not embed fingerprint")
+
+	created = nil
+	existing = true
+	require.Nil(t, integration.CreateIssue(event), "could not comment on issue")
+	require.Nil(t, created, "could create duplicate issue")
+	require.Len(t, comments, 1, "could not comment on existing issue")
+	require.Equal(t, 2, searches, "could not search existing issues")
+}
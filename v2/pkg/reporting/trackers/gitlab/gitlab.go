@@ -2,6 +2,7 @@ package gitlab
 
 import (
 	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/filter"
 	"github.com/yaklang/nuclei/v2/pkg/reporting/format"
 	"github.com/xanzy/go-gitlab"
 )
@@ -25,6 +26,10 @@ type Options struct {
 	ProjectName string `yaml:"project-name"`
 	// IssueLabel is the label of the created issue type
 	IssueLabel string `yaml:"issue-label"`
+	// AllowList contains a list of allowed events for this tracker
+	AllowList *filter.Filter `yaml:"allow-list"`
+	// DenyList contains a list of denied events for this tracker
+	DenyList *filter.Filter `yaml:"deny-list"`
 }
 
 // New creates a new issue tracker integration client based on options.
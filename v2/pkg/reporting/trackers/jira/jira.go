@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"io/ioutil"
 	"strings"
+	"text/template"
 
 	jira "github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
 	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/dedupe"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/filter"
 	"github.com/yaklang/nuclei/v2/pkg/reporting/format"
 	"github.com/yaklang/nuclei/v2/pkg/types"
 )
@@ -34,6 +38,20 @@ type Options struct {
 	ProjectName string `yaml:"project-name"`
 	// IssueType is the name of the created issue type
 	IssueType string `yaml:"issue-type"`
+	// CustomFields are the custom fields of the issues by field ID, their
+	// string values being Go templates evaluated with the result event.
+	CustomFields map[string]interface{} `yaml:"custom-fields"`
+	// Components are the names of the project components of the issues
+	Components []string `yaml:"components"`
+	// SeverityPriorityMap maps the severities to the priorities of the issues
+	SeverityPriorityMap map[string]string `yaml:"severity-priority-map"`
+	// DedupeKey is the dedupe key template of the reporting options,
+	// identifying the duplicate issues.
+	DedupeKey string `yaml:"-"`
+	// AllowList contains a list of allowed events for this tracker
+	AllowList *filter.Filter `yaml:"allow-list"`
+	// DenyList contains a list of denied events for this tracker
+	DenyList *filter.Filter `yaml:"deny-list"`
 }
 
 // New creates a new issue tracker integration client based on options.
@@ -50,11 +68,20 @@ func New(options *Options) (*Integration, error) {
 	if err != nil {
 		return nil, err
 	}
+	if _, err := evaluateField(options.CustomFields, &output.ResultEvent{}); err != nil {
+		return nil, errors.Wrap(err, "could not parse custom fields")
+	}
 	return &Integration{jira: jiraClient, options: options}, nil
 }
 
-// CreateIssue creates an issue in the tracker
+// CreateIssue creates an issue in the tracker, or comments on the existing
+// issue labelled with the fingerprint of the event if there's one.
 func (i *Integration) CreateIssue(event *output.ResultEvent) error {
+	label := dedupe.Fingerprint(i.options.DedupeKey, event)
+	if existing, err := i.findIssue(label); err == nil && existing != "" {
+		return i.commentIssue(existing, event)
+	}
+
 	summary := format.Summary(event)
 
 	fields := &jira.IssueFields{
@@ -76,6 +103,11 @@ func (i *Integration) CreateIssue(event *output.ResultEvent) error {
 		}
 	}
 
+	if err := i.setExtraFields(fields, event); err != nil {
+		return err
+	}
+	fields.Labels = append(fields.Labels, label)
+
 	issueData := &jira.Issue{
 		Fields: fields,
 	}
@@ -91,6 +123,97 @@ func (i *Integration) CreateIssue(event *output.ResultEvent) error {
 	return nil
 }
 
+// setExtraFields sets the components, the priority and the custom fields
+// configured for the issues of an event.
+func (i *Integration) setExtraFields(fields *jira.IssueFields, event *output.ResultEvent) error {
+	for _, component := range i.options.Components {
+		fields.Components = append(fields.Components, &jira.Component{Name: component})
+	}
+	severity := types.ToString(event.Info["severity"])
+	if priority, ok := i.options.SeverityPriorityMap[severity]; ok {
+		fields.Priority = &jira.Priority{Name: priority}
+	}
+	if len(i.options.CustomFields) == 0 {
+		return nil
+	}
+	customFields, err := evaluateField(i.options.CustomFields, event)
+	if err != nil {
+		return errors.Wrap(err, "could not evaluate custom fields")
+	}
+	fields.Unknowns = customFields.(map[string]interface{})
+	return nil
+}
+
+// findIssue returns the key of the unresolved issue with a label if any
+func (i *Integration) findIssue(label string) (string, error) {
+	jql := fmt.Sprintf("project = \"%s\" AND labels = \"%s\" AND statusCategory != Done", i.options.ProjectName, label)
+	issues, _, err := i.jira.Issue.Search(jql, &jira.SearchOptions{MaxResults: 1, Fields: []string{"key"}})
+	if err != nil {
+		return "", err
+	}
+	if len(issues) == 0 {
+		return "", nil
+	}
+	return issues[0].Key, nil
+}
+
+// commentIssue comments on an existing issue that an event was found again
+func (i *Integration) commentIssue(key string, event *output.ResultEvent) error {
+	body := fmt.Sprintf("Found again at %s on %s", event.Matched, event.Timestamp.Format("Mon Jan 2 15:04:05 -0700 MST 2006"))
+	if _, _, err := i.jira.Issue.AddComment(key, &jira.Comment{Body: body}); err != nil {
+		return errors.Wrapf(err, "could not comment on issue %s", key)
+	}
+	return nil
+}
+
+// evaluateField evaluates the Go templates of the strings of a custom
+// field value, converting the maps decoded from yaml to string keys.
+func evaluateField(value interface{}, event *output.ResultEvent) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		tpl, err := template.New("field").Option("missingkey=zero").Parse(v)
+		if err != nil {
+			return nil, err
+		}
+		builder := &strings.Builder{}
+		if err := tpl.Execute(builder, event); err != nil {
+			return nil, err
+		}
+		return builder.String(), nil
+	case map[string]interface{}:
+		fields := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			evaluated, err := evaluateField(item, event)
+			if err != nil {
+				return nil, err
+			}
+			fields[key] = evaluated
+		}
+		return fields, nil
+	case map[interface{}]interface{}:
+		fields := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			evaluated, err := evaluateField(item, event)
+			if err != nil {
+				return nil, err
+			}
+			fields[types.ToString(key)] = evaluated
+		}
+		return fields, nil
+	case []interface{}:
+		items := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			evaluated, err := evaluateField(item, event)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, evaluated)
+		}
+		return items, nil
+	}
+	return value, nil
+}
+
 // jiraFormatDescription formats a short description of the generated
 // event by the nuclei scanner in Jira format.
 func jiraFormatDescription(event *output.ResultEvent) string {
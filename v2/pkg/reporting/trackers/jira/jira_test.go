@@ -0,0 +1,86 @@
+package jira
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/dedupe"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestJiraCreateIssueFields(t *testing.T) {
+	var existing string
+	var created map[string]interface{}
+	var comments []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/rest/api/2/search":
+			if existing == "" {
+				_, _ = w.Write([]byte(`{"issues":[]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"issues":[{"key":"` + existing + `"}]}`))
+		case r.URL.Path == "/rest/api/2/issue" && r.Method == http.MethodPost:
+			issue := map[string]interface{}{}
+			require.Nil(t, json.NewDecoder(r.Body).Decode(&issue), "could not decode issue")
+			created = issue["fields"].(map[string]interface{})
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"key":"SEC-1"}`))
+		case r.URL.Path == "/rest/api/2/issue/SEC-1/comment":
+			comment := map[string]interface{}{}
+			require.Nil(t, json.NewDecoder(r.Body).Decode(&comment), "could not decode comment")
+			comments = append(comments, comment["body"].(string))
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	options := &Options{}
+	err := yaml.Unmarshal([]byte(`
+url: `+ts.URL+`
+project-name: SEC
+issue-type: Bug
+components: [web]
+severity-priority-map:
+  critical: Highest
+custom-fields:
+  customfield_10010: "{{.Host}}"
+  customfield_10020:
+    value: "{{.Info.name}}"
+`), options)
+	require.Nil(t, err, "could not unmarshal options")
+
+	integration, err := New(options)
+	require.Nil(t, err, "could not create jira integration")
+
+	event := &output.ResultEvent{
+		TemplateID: "exposed-panel",
+		Info:       map[string]interface{}{"name": "Exposed Panel", "severity": "critical"},
+		Host:       "https://example.com",
+		Matched:    "https://example.com/admin",
+	}
+	require.Nil(t, integration.CreateIssue(event), "could not create issue")
+	require.NotNil(t, created, "could not create issue")
+	require.Equal(t, []interface{}{map[string]interface{}{"name": "web"}}, created["components"], "could not set components")
+	require.Equal(t, "Highest", created["priority"].(map[string]interface{})["name"], "could not map priority")
+	require.Equal(t, "https://example.com", created["customfield_10010"], "could not set templated custom field")
+	require.Equal(t, map[string]interface{}{"value": "Exposed Panel"}, created["customfield_10020"], "could not set nested custom field")
+	require.Equal(t, []interface{}{dedupe.Fingerprint("", event)}, created["labels"], "could not set fingerprint label")
+
+	created = nil
+	existing = "SEC-1"
+	require.Nil(t, integration.CreateIssue(event), "could not comment on issue")
+	require.Nil(t, created, "could create duplicate issue")
+	require.Len(t, comments, 1, "could not comment on existing issue")
+	require.Contains(t, comments[0], "https://example.com/admin", "could not get comment body")
+
+	_, err = New(&Options{URL: ts.URL, CustomFields: map[string]interface{}{"customfield_1": "{{.Host"}})
+	require.NotNil(t, err, "invalid custom field template was accepted")
+}
@@ -0,0 +1,133 @@
+// Package notify implements the webhook posting shared by the chat
+// notification trackers.
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/yaklang/nuclei/v2/pkg/types"
+	"go.uber.org/ratelimit"
+)
+
+// DefaultRateLimit is the default number of messages posted per second
+const DefaultRateLimit = 1
+
+// severities are the severities in increasing order of importance
+var severities = []string{"info", "low", "medium", "high", "critical"}
+
+// emojis are the emojis prefixing the messages of each severity
+var emojis = map[string]string{
+	"info":     "🔵",
+	"low":      "🟢",
+	"medium":   "🟡",
+	"high":     "🟠",
+	"critical": "🔴",
+}
+
+// Notifier posts the messages of a chat tracker to its webhook
+type Notifier struct {
+	url         string
+	client      *http.Client
+	limiter     ratelimit.Limiter
+	minSeverity int
+}
+
+// New creates a new notifier posting to a webhook url at most rateLimit
+// messages per second, for the events of at least minSeverity.
+func New(url, minSeverity string, rateLimit int) (*Notifier, error) {
+	if url == "" {
+		return nil, errors.New("webhook url is required")
+	}
+	minimum := -1
+	if minSeverity != "" {
+		if minimum = severityRank(minSeverity); minimum < 0 {
+			return nil, errors.Errorf("invalid minimum severity %s", minSeverity)
+		}
+	}
+	if rateLimit <= 0 {
+		rateLimit = DefaultRateLimit
+	}
+	return &Notifier{
+		url:         url,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		limiter:     ratelimit.New(rateLimit),
+		minSeverity: minimum,
+	}, nil
+}
+
+// Allowed returns true if the severity of an event reaches the minimum.
+// All the events are allowed when no minimum is configured.
+func (n *Notifier) Allowed(event *output.ResultEvent) bool {
+	if n.minSeverity < 0 {
+		return true
+	}
+	return severityRank(types.ToString(event.Info["severity"])) >= n.minSeverity
+}
+
+// Post posts a JSON payload to the webhook
+func (n *Notifier) Post(payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal message")
+	}
+	n.limiter.Take()
+
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "could not post message")
+	}
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Message returns the compact message describing an event, one field per
+// line separated by separator.
+func Message(event *output.ResultEvent, separator string) string {
+	severity := types.ToString(event.Info["severity"])
+	matched := event.Matched
+	if matched == "" {
+		matched = event.Host
+	}
+
+	builder := &strings.Builder{}
+	if emoji, ok := emojis[strings.ToLower(severity)]; ok {
+		builder.WriteString(emoji)
+		builder.WriteString(" ")
+	}
+	builder.WriteString("[")
+	builder.WriteString(severity)
+	builder.WriteString("] ")
+	builder.WriteString(types.ToString(event.Info["name"]))
+	builder.WriteString(" (")
+	builder.WriteString(event.TemplateID)
+	builder.WriteString(")")
+	builder.WriteString(separator)
+	builder.WriteString("Host: ")
+	builder.WriteString(event.Host)
+	builder.WriteString(separator)
+	builder.WriteString("Matched at: ")
+	builder.WriteString(matched)
+	return builder.String()
+}
+
+// severityRank returns the rank of a severity, -1 if it's unknown
+func severityRank(severity string) int {
+	for i, item := range severities {
+		if strings.EqualFold(item, severity) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifierAllowed(t *testing.T) {
+	notifier, err := New("https://example.com/hook", "high", 0)
+	require.Nil(t, err, "could not create notifier")
+
+	require.False(t, notifier.Allowed(&output.ResultEvent{Info: map[string]interface{}{"severity": "medium"}}), "lower severity was allowed")
+	require.True(t, notifier.Allowed(&output.ResultEvent{Info: map[string]interface{}{"severity": "high"}}), "minimum severity was not allowed")
+	require.True(t, notifier.Allowed(&output.ResultEvent{Info: map[string]interface{}{"severity": "Critical"}}), "higher severity was not allowed")
+
+	notifier, err = New("https://example.com/hook", "", 0)
+	require.Nil(t, err, "could not create notifier")
+	require.True(t, notifier.Allowed(&output.ResultEvent{Info: map[string]interface{}{"severity": "info"}}), "severity was filtered without minimum")
+	require.True(t, notifier.Allowed(&output.ResultEvent{Info: map[string]interface{}{"severity": "unknown"}}), "unknown severity was filtered without minimum")
+	require.True(t, notifier.Allowed(&output.ResultEvent{Info: map[string]interface{}{"name": "test"}}), "event without severity was filtered without minimum")
+
+	_, err = New("https://example.com/hook", "urgent", 0)
+	require.NotNil(t, err, "invalid minimum severity was accepted")
+}
+
+func TestNotifierMessage(t *testing.T) {
+	message := Message(&output.ResultEvent{
+		TemplateID: "exposed-panel",
+		Info:       map[string]interface{}{"name": "Exposed Panel", "severity": "critical"},
+		Host:       "https://example.com",
+		Matched:    "https://example.com/admin",
+	}, "\n")
+	require.Equal(t, "🔴 [critical] Exposed Panel (exposed-panel)\nHost: https://example.com\nMatched at: https://example.com/admin", message, "could not get message")
+}
+
+func TestNotifierPostRateLimit(t *testing.T) {
+	var payloads []map[string]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := map[string]string{}
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&payload), "could not decode payload")
+		payloads = append(payloads, payload)
+	}))
+	defer ts.Close()
+
+	notifier, err := New(ts.URL, "", 10)
+	require.Nil(t, err, "could not create notifier")
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		require.Nil(t, notifier.Post(map[string]string{"text": "message"}), "could not post message")
+	}
+	require.GreaterOrEqual(t, int64(time.Since(start)), int64(250*time.Millisecond), "could not rate limit messages")
+	require.Len(t, payloads, 4, "could not post messages")
+	require.Equal(t, "message", payloads[0]["text"], "could not get posted payload")
+}
@@ -0,0 +1,44 @@
+package slack
+
+import (
+	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/filter"
+	"github.com/yaklang/nuclei/v2/pkg/reporting/trackers/notify"
+)
+
+// Integration is a client for a Slack notification integration
+type Integration struct {
+	notifier *notify.Notifier
+	options  *Options
+}
+
+// Options contains the configuration options for slack notification client
+type Options struct {
+	// WebhookURL is the url of the incoming webhook of the channel
+	WebhookURL string `yaml:"webhook-url"`
+	// MinSeverity is the minimum severity of the results to notify
+	MinSeverity string `yaml:"min-severity"`
+	// RateLimit is the maximum number of messages posted per second
+	RateLimit int `yaml:"rate-limit"`
+	// AllowList contains a list of allowed events for this tracker
+	AllowList *filter.Filter `yaml:"allow-list"`
+	// DenyList contains a list of denied events for this tracker
+	DenyList *filter.Filter `yaml:"deny-list"`
+}
+
+// New creates a new slack notification integration client based on options.
+func New(options *Options) (*Integration, error) {
+	notifier, err := notify.New(options.WebhookURL, options.MinSeverity, options.RateLimit)
+	if err != nil {
+		return nil, err
+	}
+	return &Integration{notifier: notifier, options: options}, nil
+}
+
+// CreateIssue posts a message for a result to the channel
+func (i *Integration) CreateIssue(event *output.ResultEvent) error {
+	if !i.notifier.Allowed(event) {
+		return nil
+	}
+	return i.notifier.Post(map[string]string{"text": notify.Message(event, "\n")})
+}
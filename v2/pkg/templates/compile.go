@@ -62,16 +62,37 @@ func Parse(filePath string, options protocols.ExecuterOptions) (*Template, error
 		}
 	}
 
+	if err := template.Requirements.checkRequirements(&options); err != nil {
+		return nil, err
+	}
+
+	if template.MaxResults > 0 && options.ResultCap != nil {
+		options.ResultCap.SetMax(template.ID, template.MaxResults)
+	}
+
 	// Setting up variables regarding template metadata
 	options.TemplateID = template.ID
 	options.TemplateInfo = template.Info
 	options.TemplatePath = filePath
+	options.Variables = &template.Variables
+	if err := template.Variables.Validate(); err != nil {
+		return nil, errors.Wrap(err, "could not compile variables")
+	}
 
 	// If no requests, and it is also not a workflow, return error.
 	if len(template.RequestsDNS)+len(template.RequestsHTTP)+len(template.RequestsFile)+len(template.RequestsNetwork)+len(template.RequestsHeadless)+len(template.Workflows) == 0 {
 		return nil, fmt.Errorf("no requests defined for %s", template.ID)
 	}
 
+	if template.SelfContained {
+		if len(template.RequestsHTTP) == 0 || len(template.RequestsDNS)+len(template.RequestsFile)+len(template.RequestsNetwork)+len(template.RequestsHeadless)+len(template.Workflows) > 0 {
+			return nil, fmt.Errorf("self-contained template %s can only have http requests", template.ID)
+		}
+		for _, req := range template.RequestsHTTP {
+			req.SelfContained = true
+		}
+	}
+
 	// Compile the workflow request
 	if len(template.Workflows) > 0 {
 		compiled := &template.Workflow
@@ -184,13 +205,19 @@ func (t *Template) parseWorkflowTemplate(workflow *workflows.WorkflowTemplate, o
 	}
 	for _, path := range paths {
 		opts := protocols.ExecuterOptions{
-			Output:       options.Output,
-			Options:      options.Options,
-			Progress:     options.Progress,
-			Catalog:      options.Catalog,
-			RateLimiter:  options.RateLimiter,
-			IssuesClient: options.IssuesClient,
-			ProjectFile:  options.ProjectFile,
+			Output:        options.Output,
+			Options:       options.Options,
+			Progress:      options.Progress,
+			Catalog:       options.Catalog,
+			RateLimiter:   options.RateLimiter,
+			IssuesClient:  options.IssuesClient,
+			ProjectFile:   options.ProjectFile,
+			Interactsh:    options.Interactsh,
+			Browser:       options.Browser,
+			Session:       options.Session,
+			ResultCap:     options.ResultCap,
+			Fingerprinter: options.Fingerprinter,
+			Context:       options.Context,
 		}
 		template, err := Parse(path, opts)
 		if err != nil {
@@ -0,0 +1,74 @@
+package templates
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/yaklang/nuclei/v2/pkg/protocols"
+	"github.com/yaklang/nuclei/v2/pkg/types"
+)
+
+// Requirements contains the requirements of the scanner host for a template
+type Requirements struct {
+	// Headless requires headless browser support to be available
+	Headless bool `yaml:"headless"`
+	// Code requires code protocol (starlight) support to be available
+	Code bool `yaml:"code"`
+	// MinNucleiVersion is the minimum version of nuclei engine required
+	MinNucleiVersion string `yaml:"min-nuclei-version"`
+	// OS is the list of operating systems the template can run on
+	OS []string `yaml:"os"`
+}
+
+// RequirementsError is returned when the requirements of a template are
+// not satisfied by the scanner host.
+type RequirementsError struct {
+	// Reasons are the unmet requirements of the template
+	Reasons []string
+}
+
+// Error returns the unmet requirements as an error string
+func (e *RequirementsError) Error() string {
+	return "unmet requirements: " + strings.Join(e.Reasons, ", ")
+}
+
+// checkRequirements checks the template requirements returning a
+// RequirementsError if any of them are not satisfied.
+func (r *Requirements) checkRequirements(options *protocols.ExecuterOptions) error {
+	if r == nil {
+		return nil
+	}
+	var reasons []string
+	if r.Headless && (!options.Options.Headless || options.Browser == nil) {
+		reasons = append(reasons, "headless browser not available (use -headless)")
+	}
+	if r.Code {
+		reasons = append(reasons, "code protocol not supported by this engine")
+	}
+	if r.MinNucleiVersion != "" {
+		required, err := semver.ParseTolerant(r.MinNucleiVersion)
+		if err != nil {
+			reasons = append(reasons, fmt.Sprintf("invalid min-nuclei-version %s", r.MinNucleiVersion))
+		} else if current, _ := semver.Make(types.Version); current.LT(required) {
+			reasons = append(reasons, fmt.Sprintf("requires nuclei v%s (running v%s)", required, types.Version))
+		}
+	}
+	if len(r.OS) > 0 && !stringSliceContains(r.OS, runtime.GOOS) {
+		reasons = append(reasons, fmt.Sprintf("os %s not in [%s]", runtime.GOOS, strings.Join(r.OS, ", ")))
+	}
+	if len(reasons) > 0 {
+		return &RequirementsError{Reasons: reasons}
+	}
+	return nil
+}
+
+func stringSliceContains(slice []string, item string) bool {
+	for _, i := range slice {
+		if strings.EqualFold(strings.TrimSpace(i), item) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package templates
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaklang/nuclei/v2/internal/testutils"
+	"github.com/yaklang/nuclei/v2/pkg/types"
+)
+
+const requirementsTemplate = `id: requirements-test
+info:
+  name: Requirements Test
+  author: pdteam
+  severity: info
+requirements:
+  min-nuclei-version: 99.0.0
+  os:
+    - plan9
+requests:
+  - method: GET
+    path:
+      - "{{BaseURL}}"
+    matchers:
+      - type: status
+        status:
+          - 200
+`
+
+func TestParseUnmetRequirements(t *testing.T) {
+	options := testutils.DefaultOptions
+	testutils.Init(options)
+
+	directory, err := ioutil.TempDir("", "requirements-*")
+	require.Nil(t, err, "could not create temp directory")
+	defer os.RemoveAll(directory)
+
+	templatePath := path.Join(directory, "requirements.yaml")
+	err = ioutil.WriteFile(templatePath, []byte(requirementsTemplate), 0644)
+	require.Nil(t, err, "could not write template")
+
+	executerOpts := testutils.NewMockExecuterOptions(options, &testutils.TemplateInfo{})
+	_, err = Parse(templatePath, *executerOpts)
+
+	var requirementsErr *RequirementsError
+	require.True(t, errors.As(err, &requirementsErr), "could not get requirements error")
+	require.Equal(t, []string{"requires nuclei v99.0.0 (running v" + types.Version + ")", "os " + runtime.GOOS + " not in [plan9]"}, requirementsErr.Reasons, "could not get correct skip reasons")
+}
+
+func TestRequirementsSatisfied(t *testing.T) {
+	requirements := &Requirements{MinNucleiVersion: "2.0"}
+	err := requirements.checkRequirements(testutils.NewMockExecuterOptions(testutils.DefaultOptions, &testutils.TemplateInfo{}))
+	require.Nil(t, err, "could not satisfy requirements")
+
+	requirements = &Requirements{Headless: true}
+	err = requirements.checkRequirements(testutils.NewMockExecuterOptions(testutils.DefaultOptions, &testutils.TemplateInfo{}))
+	require.NotNil(t, err, "could satisfy headless requirement without browser")
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/yaklang/nuclei/v2/pkg/protocols/dns"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/file"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/headless"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/variables"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/http"
 	"github.com/yaklang/nuclei/v2/pkg/protocols/network"
 	"github.com/yaklang/nuclei/v2/pkg/workflows"
@@ -16,6 +17,15 @@ type Template struct {
 	ID string `yaml:"id"`
 	// Info contains information about the template
 	Info map[string]interface{} `yaml:"info"`
+	// Requirements contains the scanner host requirements for the template
+	Requirements *Requirements `yaml:"requirements,omitempty"`
+	// MaxResults is the maximum number of results written for the template
+	MaxResults int `yaml:"max-results,omitempty"`
+	// SelfContained marks a template whose requests target absolute urls,
+	// it is executed once instead of for each input.
+	SelfContained bool `yaml:"self-contained,omitempty"`
+	// Variables are evaluated for each input and can be used by all requests
+	Variables variables.Variable `yaml:"variables,omitempty"`
 	// RequestsHTTP contains the http request to make in the template
 	RequestsHTTP []*http.Request `yaml:"requests,omitempty" json:"requests"`
 	// RequestsDNS contains the dns request to make in the template
@@ -0,0 +1,31 @@
+package types
+
+import "time"
+
+// Protocols with their own configurable timeouts
+const (
+	DNSProtocol      = "dns"
+	HTTPProtocol     = "http"
+	HeadlessProtocol = "headless"
+	NetworkProtocol  = "network"
+)
+
+// ProtocolTimeout returns the timeout for a protocol falling back
+// to the global timeout if no timeout was set for it.
+func (options *Options) ProtocolTimeout(protocol string) time.Duration {
+	var timeout int
+	switch protocol {
+	case DNSProtocol:
+		timeout = options.TimeoutDNS
+	case HTTPProtocol:
+		timeout = options.TimeoutHTTP
+	case HeadlessProtocol:
+		timeout = options.TimeoutHeadless
+	case NetworkProtocol:
+		timeout = options.TimeoutNetwork
+	}
+	if timeout <= 0 {
+		timeout = options.Timeout
+	}
+	return time.Duration(timeout) * time.Second
+}
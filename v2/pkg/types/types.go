@@ -18,9 +18,12 @@ type Options struct {
 	ExcludedTemplates goflags.StringSlice
 	// CustomHeaders is the list of custom global headers to send with each request.
 	CustomHeaders goflags.StringSlice
+	// Vars is the list of key=value variables usable in all the templates
+	Vars goflags.StringSlice
 	// Severity filters templates based on their severity and only run the matching ones.
 	Severity              goflags.StringSlice
 	InternalResolversList []string // normalized from resolvers flag as well as file provided.
+	InternalProxyList     []string // read from the proxy file provided.
 	// ProjectPath allows nuclei to use a user defined project folder
 	ProjectPath string
 	// InteractshURL is the URL for the interactsh server.
@@ -29,42 +32,100 @@ type Options struct {
 	Target string
 	// Targets specifies the targets to scan using templates.
 	Targets string
+	// Shard is the index/count of the slice of targets to scan
+	Shard string
 	// Output is the file to write found results to.
 	Output string
+	// Manifest is the file to write the scan manifest to
+	Manifest string
+	// RepairOutput is a json output file to repair after an unclean exit
+	RepairOutput string
 	// ProxyURL is the URL for the proxy server
 	ProxyURL string
 	// ProxySocksURL is the URL for the proxy socks server
 	ProxySocksURL string
+	// ProxyFile is a file of http and socks5 proxies the requests are rotated through
+	ProxyFile string
+	// CustomUserAgent is the user agent sent with http requests instead of a
+	// random one. It can contain {{Hostname}} and helper expressions.
+	CustomUserAgent string
+	// ClientCertFile is the client certificate file for mutual tls authentication
+	ClientCertFile string
+	// ClientKeyFile is the private key file of the client certificate
+	ClientKeyFile string
+	// ClientCAFile is the CA file to verify servers with when using a client certificate
+	ClientCAFile string
 	// TemplatesDirectory is the directory to use for storing templates
 	TemplatesDirectory string
 	// TraceLogFile specifies a file to write with the trace of all requests
 	TraceLogFile string
 	// ReportingDB is the db for report storage as well as deduplication
 	ReportingDB string
+	// ReportingDBKey is the template of the keys identifying duplicate results
+	ReportingDBKey string
+	// ReportingDBTTL is the duration after which duplicate results are forgotten
+	ReportingDBTTL string
 	// ReportingConfig is the config file for nuclei reporting module
 	ReportingConfig string
 	// DiskExportDirectory is the directory to export reports in markdown on disk to
 	DiskExportDirectory string
 	// SarifExport is the file to export sarif output format to
 	SarifExport string
+	// CSVExport is the file to export csv output format to
+	CSVExport string
+	// AuthConfig is the config file for scan-level authentication sessions
+	AuthConfig string
 	// ResolversFile is a file containing resolvers for nuclei.
 	ResolversFile string
+	// MaxResultsPerTemplate is the maximum number of results written per template
+	MaxResultsPerTemplate int
+	// OutputSyncInterval is the number of seconds between syncs of the output file to disk
+	OutputSyncInterval int
 	// StatsInterval is the number of seconds to display stats after
 	StatsInterval int
 	// MetricsPort is the port to show metrics on
 	MetricsPort int
 	// BulkSize is the of targets analyzed in parallel for each template
 	BulkSize int
+	// FileConcurrency is the number of files analyzed in parallel for each
+	// file template, bulk-size if not set.
+	FileConcurrency int
 	// TemplateThreads is the number of templates executed in parallel
 	TemplateThreads int
 	// Timeout is the seconds to wait for a response from the server.
 	Timeout int
+	// TimeoutDNS is the seconds to wait for a dns response
+	TimeoutDNS int
+	// TimeoutHTTP is the seconds to wait for a http response
+	TimeoutHTTP int
+	// TimeoutHeadless is the seconds to wait for a headless browser request
+	TimeoutHeadless int
+	// TimeoutNetwork is the seconds to wait for a network response
+	TimeoutNetwork int
 	// Retries is the number of times to retry the request
 	Retries int
 	// Rate-Limit is the maximum number of requests per specified target
 	RateLimit int
 	// PageTimeout is the maximum time to wait for a page in seconds
 	PageTimeout int
+	// NavigationTimeout is the maximum time to wait for a navigation of a
+	// headless page in seconds, the page timeout if not set.
+	NavigationTimeout int
+	// HeadlessConcurrency is the number of headless pages open in parallel,
+	// reused across template executions. Pages are not pooled if not set.
+	HeadlessConcurrency int
+	// HeadlessPageUses is the number of executions a pooled headless page is
+	// used for before being recycled.
+	HeadlessPageUses int
+	// AllowLocalFileAccess allows headless pages to access file:// URLs and
+	// to upload files from outside the folder of their template.
+	AllowLocalFileAccess bool
+	// AllowLocalNetworkAccess allows headless pages to access the private,
+	// loopback and link-local networks other than the scanned input host.
+	AllowLocalNetworkAccess bool
+	// ResponseReadSize is the maximum size of http response bodies to read
+	// in bytes for the requests not setting a max-size. 0 reads them fully.
+	ResponseReadSize int
 	// InteractionsCacheSize is the number of interaction-url->req to keep in cache at a time.
 	InteractionsCacheSize int
 	// InteractionsPollDuration is the number of seconds to wait before each interaction poll
@@ -79,10 +140,18 @@ type Options struct {
 	// using same matchers/extractors from http protocol without the need
 	// to send a new request, reading responses from a file.
 	OfflineHTTP bool
+	// TargetDir is a directory of saved http responses to process
+	// in passive mode.
+	TargetDir string
 	// Headless specifies whether to allow headless mode templates
 	Headless bool
 	// ShowBrowser specifies whether the show the browser in headless mode
 	ShowBrowser bool
+	// NoScreenshots disables the screenshot actions of headless templates
+	NoScreenshots bool
+	// ScreenshotDir is the directory headless screenshots are written to,
+	// the store response directory if not set.
+	ScreenshotDir string
 	// SytemResolvers enables override of nuclei's DNS client opting to use system resolver stack.
 	SystemResolvers bool
 	// Metrics enables display of metrics via an http endpoint
@@ -107,6 +176,22 @@ type Options struct {
 	JSON bool
 	// JSONRequests writes requests/responses for matches in JSON output
 	JSONRequests bool
+	// JSONRequestsMaxSize is the maximum size of the requests/responses
+	// written in JSON output, 0 for no limit.
+	JSONRequestsMaxSize int
+	// MatcherStatus writes an event for each template and input with no match
+	MatcherStatus bool
+	// FailOnSeverity exits with a failure code when results of at least this severity are found
+	FailOnSeverity string
+	// FailOnFindings exits with a failure code when any result is found
+	FailOnFindings bool
+	// StoreResponse writes the requests/responses of matches to files
+	StoreResponse bool
+	// ExtractedFilesDir is the directory the values of the to-file extractors
+	// are written to. The values are not written to files if empty.
+	ExtractedFilesDir string
+	// StoreResponseDir is the directory the requests/responses are written to
+	StoreResponseDir string
 	// EnableProgressBar enables progress bar
 	EnableProgressBar bool
 	// TemplatesVersion shows the templates installed version
@@ -125,4 +210,9 @@ type Options struct {
 	NewTemplates bool
 	// NoInteractsh disables use of interactsh server for interaction polling
 	NoInteractsh bool
+	// NoRandomAgent disables the random user agent sent with http requests
+	NoRandomAgent bool
+	// Sandbox restricts payload files loaded by templates to the templates directory
+	// and the directory of the template loading them
+	Sandbox bool
 }
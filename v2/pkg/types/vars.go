@@ -0,0 +1,17 @@
+package types
+
+import "strings"
+
+// VarsMap returns the key=value variables of the options as a map of
+// dynamic values, ignoring the items without a key.
+func (options *Options) VarsMap() map[string]interface{} {
+	values := make(map[string]interface{}, len(options.Vars))
+	for _, item := range options.Vars {
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			continue
+		}
+		values[strings.TrimSpace(parts[0])] = parts[1]
+	}
+	return values
+}
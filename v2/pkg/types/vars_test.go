@@ -0,0 +1,16 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVarsMap(t *testing.T) {
+	options := &Options{Vars: []string{"token=abc", "env = staging", "query=a=b,c", "invalid", "=empty"}}
+	require.Equal(t, map[string]interface{}{
+		"token": "abc",
+		"env":   " staging",
+		"query": "a=b,c",
+	}, options.VarsMap(), "could not get variables")
+}
@@ -0,0 +1,4 @@
+package types
+
+// Version is the current version of the nuclei engine
+const Version = `2.3.8`
@@ -1,21 +1,30 @@
 package workflows
 
 import (
+	"sync"
+
 	"github.com/projectdiscovery/gologger"
+	"github.com/yaklang/nuclei/v2/pkg/operators"
 	"github.com/yaklang/nuclei/v2/pkg/output"
+	"github.com/yaklang/nuclei/v2/pkg/protocols"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/generators"
 	"github.com/remeh/sizedwaitgroup"
 	"go.uber.org/atomic"
 )
 
 // RunWorkflow runs a workflow on an input and returns true or false
+//
+// The variables of the workflow are passed to its templates, overriding
+// the variables of the command line.
 func (w *Workflow) RunWorkflow(input string) bool {
 	results := &atomic.Bool{}
+	values := w.Options.Variables.Evaluate(input)
 
 	swg := sizedwaitgroup.New(w.Options.Options.TemplateThreads)
 	for _, template := range w.Workflows {
 		swg.Add()
 		func(template *WorkflowTemplate) {
-			err := w.runWorkflowStep(template, input, results, &swg)
+			err := w.runWorkflowStep(template, input, values, results, &swg)
 			if err != nil {
 				gologger.Warning().Msgf("[%s] Could not execute workflow step: %s\n", template.Template, err)
 			}
@@ -27,28 +36,40 @@ func (w *Workflow) RunWorkflow(input string) bool {
 }
 
 // runWorkflowStep runs a workflow step for the workflow. It executes the workflow
-// in a recursive manner running all subtemplates and matchers.
-func (w *Workflow) runWorkflowStep(template *WorkflowTemplate, input string, results *atomic.Bool, swg *sizedwaitgroup.SizedWaitGroup) error {
+// in a recursive manner running all subtemplates and matchers, passing them the
+// values extracted by the template merged with the values of its parents.
+func (w *Workflow) runWorkflowStep(template *WorkflowTemplate, input string, values map[string]interface{}, results *atomic.Bool, swg *sizedwaitgroup.SizedWaitGroup) error {
 	var firstMatched bool
 	var err error
 	var mainErr error
 
-	if len(template.Matchers) == 0 {
+	// Names of the matchers and extractors of the template which matched,
+	// and the values it extracted.
+	names := make(map[string]struct{})
+	extracted := values
+	namesMutex := &sync.Mutex{}
+
+	matchers := template.subtemplateMatchers()
+	if len(matchers) == 0 {
 		for _, executer := range template.Executers {
 			executer.Options.Progress.AddToTotal(int64(executer.Executer.Requests()))
 
 			// Don't print results with subtemplates, only print results on template.
 			if len(template.Subtemplates) > 0 {
-				err = executer.Executer.ExecuteWithResults(input, func(result *output.InternalWrappedEvent) {
+				err = executeWithResults(executer.Executer, input, values, func(result *output.InternalWrappedEvent) {
 					if result.OperatorsResult == nil {
 						return
 					}
+					namesMutex.Lock()
+					addMatchedNames(result.OperatorsResult, names)
+					extracted = generators.MergeMaps(extracted, extractedValues(result.OperatorsResult))
 					if len(result.Results) > 0 {
 						firstMatched = true
 					}
+					namesMutex.Unlock()
 				})
 			} else {
-				firstMatched, err = executer.Executer.Execute(input)
+				firstMatched, err = execute(executer.Executer, input, values)
 			}
 			if err != nil {
 				if len(template.Executers) == 1 {
@@ -63,27 +84,31 @@ func (w *Workflow) runWorkflowStep(template *WorkflowTemplate, input string, res
 	if len(template.Subtemplates) == 0 {
 		results.CAS(false, firstMatched)
 	}
-	if len(template.Matchers) > 0 {
+	if len(matchers) > 0 {
 		for _, executer := range template.Executers {
 			executer.Options.Progress.AddToTotal(int64(executer.Executer.Requests()))
 
-			err := executer.Executer.ExecuteWithResults(input, func(event *output.InternalWrappedEvent) {
+			err := executeWithResults(executer.Executer, input, values, func(event *output.InternalWrappedEvent) {
 				if event.OperatorsResult == nil {
 					return
 				}
 
-				for _, matcher := range template.Matchers {
-					_, matchOK := event.OperatorsResult.Matches[matcher.Name]
-					_, extractOK := event.OperatorsResult.Extracts[matcher.Name]
-					if !matchOK && !extractOK {
+				eventNames := make(map[string]struct{})
+				addMatchedNames(event.OperatorsResult, eventNames)
+				eventValues := generators.MergeMaps(values, extractedValues(event.OperatorsResult))
+				for _, matcher := range matchers {
+					if _, ok := eventNames[matcher.Name]; !ok {
 						continue
 					}
 
 					for _, subtemplate := range matcher.Subtemplates {
+						if !subtemplate.matchesParent(eventNames) {
+							continue
+						}
 						swg.Add()
 
 						go func(subtemplate *WorkflowTemplate) {
-							if err := w.runWorkflowStep(subtemplate, input, results, swg); err != nil {
+							if err := w.runWorkflowStep(subtemplate, input, eventValues, results, swg); err != nil {
 								gologger.Warning().Msgf("[%s] Could not execute workflow step: %s\n", subtemplate.Template, err)
 							}
 							swg.Done()
@@ -104,10 +129,13 @@ func (w *Workflow) runWorkflowStep(template *WorkflowTemplate, input string, res
 	}
 	if len(template.Subtemplates) > 0 && firstMatched {
 		for _, subtemplate := range template.Subtemplates {
+			if !subtemplate.matchesParent(names) {
+				continue
+			}
 			swg.Add()
 
 			go func(template *WorkflowTemplate) {
-				err := w.runWorkflowStep(template, input, results, swg)
+				err := w.runWorkflowStep(template, input, extracted, results, swg)
 				if err != nil {
 					gologger.Warning().Msgf("[%s] Could not execute workflow step: %s\n", template.Template, err)
 				}
@@ -117,3 +145,48 @@ func (w *Workflow) runWorkflowStep(template *WorkflowTemplate, input string, res
 	}
 	return mainErr
 }
+
+// addMatchedNames adds the names of the matchers and extractors of a result
+func addMatchedNames(result *operators.Result, names map[string]struct{}) {
+	for name := range result.Matches {
+		names[name] = struct{}{}
+	}
+	for _, name := range result.AndMatches {
+		names[name] = struct{}{}
+	}
+	for name := range result.Extracts {
+		names[name] = struct{}{}
+	}
+}
+
+// extractedValues returns the values extracted by a result, the first value
+// of the named extractors overridden by the dynamic values.
+func extractedValues(result *operators.Result) map[string]interface{} {
+	values := make(map[string]interface{}, len(result.Extracts)+len(result.DynamicValues))
+	for name, extracts := range result.Extracts {
+		if len(extracts) > 0 {
+			values[name] = extracts[0]
+		}
+	}
+	for name, value := range result.DynamicValues {
+		values[name] = value
+	}
+	return values
+}
+
+// execute executes a template starting from the values of its parents
+func execute(executer protocols.Executer, input string, values map[string]interface{}) (bool, error) {
+	if valuesExecuter, ok := executer.(protocols.ValuesExecuter); ok && len(values) > 0 {
+		return valuesExecuter.ExecuteWithValues(input, values)
+	}
+	return executer.Execute(input)
+}
+
+// executeWithResults executes a template starting from the values of its
+// parents and returns results instead of writing them.
+func executeWithResults(executer protocols.Executer, input string, values map[string]interface{}, callback protocols.OutputEventCallback) error {
+	if valuesExecuter, ok := executer.(protocols.ValuesExecuter); ok && len(values) > 0 {
+		return valuesExecuter.ExecuteWithValuesAndResults(input, values, callback)
+	}
+	return executer.ExecuteWithResults(input, callback)
+}
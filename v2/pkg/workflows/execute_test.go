@@ -8,7 +8,9 @@ import (
 	"github.com/yaklang/nuclei/v2/pkg/output"
 	"github.com/yaklang/nuclei/v2/pkg/progress"
 	"github.com/yaklang/nuclei/v2/pkg/protocols"
+	"github.com/yaklang/nuclei/v2/pkg/protocols/common/variables"
 	"github.com/yaklang/nuclei/v2/pkg/types"
+	"gopkg.in/yaml.v2"
 )
 
 func TestWorkflowsSimple(t *testing.T) {
@@ -152,10 +154,122 @@ func TestWorkflowsSubtemplatesWithMatcherNoMatch(t *testing.T) {
 	require.Equal(t, "", secondInput, "could not get correct second input")
 }
 
+func TestWorkflowsSubtemplatesWithParentMatchers(t *testing.T) {
+	progressBar, _ := progress.NewStatsTicker(0, false, false, 0)
+
+	var firstInput, v1Input, v2Input string
+	workflow := &Workflow{Options: &protocols.ExecuterOptions{Options: &types.Options{TemplateThreads: 10}}, Workflows: []*WorkflowTemplate{
+		{Executers: []*ProtocolExecuterPair{{
+			Executer: &mockExecuter{result: true, executeHook: func(input string) {
+				firstInput = input
+			}, outputs: []*output.InternalWrappedEvent{
+				{OperatorsResult: &operators.Result{
+					Matches:  map[string]struct{}{"v2.x": {}},
+					Extracts: map[string][]string{},
+				}, Results: []*output.ResultEvent{{}}},
+			}}, Options: &protocols.ExecuterOptions{Progress: progressBar}},
+		}, Subtemplates: []*WorkflowTemplate{
+			{Matchers: []*Matcher{{Name: "v1.x"}}, Executers: []*ProtocolExecuterPair{{
+				Executer: &mockExecuter{result: true, executeHook: func(input string) {
+					v1Input = input
+				}}, Options: &protocols.ExecuterOptions{Progress: progressBar}},
+			}},
+			{Matchers: []*Matcher{{Name: "v2.x"}}, Executers: []*ProtocolExecuterPair{{
+				Executer: &mockExecuter{result: true, executeHook: func(input string) {
+					v2Input = input
+				}}, Options: &protocols.ExecuterOptions{Progress: progressBar}},
+			}},
+		}},
+	}}
+
+	matched := workflow.RunWorkflow("https://test.com")
+	require.True(t, matched, "could not get correct match value")
+
+	require.Equal(t, "https://test.com", firstInput, "could not get correct first input")
+	require.Equal(t, "", v1Input, "could run subtemplate of unmatched matcher")
+	require.Equal(t, "https://test.com", v2Input, "could not run subtemplate of matched matcher")
+}
+
+func TestWorkflowParentMatchersYAML(t *testing.T) {
+	workflow := &Workflow{}
+	err := yaml.Unmarshal([]byte(`workflows:
+  - template: detect.yaml
+    subtemplates:
+      - template: exploit-v2.yaml
+        matchers: [v2.x]
+  - template: tech-detect.yaml
+    matchers:
+      - name: tomcat
+        subtemplates:
+          - template: tomcat.yaml
+`), workflow)
+	require.Nil(t, err, "could not unmarshal workflow")
+
+	subtemplate := workflow.Workflows[0].Subtemplates[0]
+	require.Equal(t, "v2.x", subtemplate.Matchers[0].Name, "could not unmarshal matcher name")
+	require.Empty(t, subtemplate.subtemplateMatchers(), "could get subtemplate matchers of parent matchers")
+	require.True(t, subtemplate.matchesParent(map[string]struct{}{"v2.x": {}}), "could not match parent matchers")
+	require.False(t, subtemplate.matchesParent(map[string]struct{}{"v1.x": {}}), "could match unmatched parent matchers")
+
+	matchers := workflow.Workflows[1].subtemplateMatchers()
+	require.Len(t, matchers, 1, "could not unmarshal matcher")
+	require.Equal(t, "tomcat.yaml", matchers[0].Subtemplates[0].Template, "could not unmarshal matcher subtemplates")
+}
+
+func TestWorkflowsSubtemplatesValues(t *testing.T) {
+	progressBar, _ := progress.NewStatsTicker(0, false, false, 0)
+
+	parent := &mockExecuter{result: true, outputs: []*output.InternalWrappedEvent{
+		{OperatorsResult: &operators.Result{
+			Extracts:      map[string][]string{"path": {"/admin", "/login"}, "version": {"1.0"}},
+			DynamicValues: map[string]interface{}{"token": "parent-token"},
+		}, Results: []*output.ResultEvent{{}}},
+	}}
+	child := &mockExecuter{result: true, outputs: []*output.InternalWrappedEvent{
+		{OperatorsResult: &operators.Result{
+			Extracts: map[string][]string{"version": {"2.0"}},
+		}, Results: []*output.ResultEvent{{}}},
+	}}
+	grandchild := &mockExecuter{result: true}
+
+	workflow := &Workflow{Options: &protocols.ExecuterOptions{Options: &types.Options{TemplateThreads: 10}}, Workflows: []*WorkflowTemplate{
+		{Executers: []*ProtocolExecuterPair{{Executer: parent, Options: &protocols.ExecuterOptions{Progress: progressBar}}},
+			Subtemplates: []*WorkflowTemplate{{Executers: []*ProtocolExecuterPair{{Executer: child, Options: &protocols.ExecuterOptions{Progress: progressBar}}},
+				Subtemplates: []*WorkflowTemplate{{Executers: []*ProtocolExecuterPair{{Executer: grandchild, Options: &protocols.ExecuterOptions{Progress: progressBar}}}}},
+			}},
+		},
+	}}
+
+	matched := workflow.RunWorkflow("https://test.com")
+	require.True(t, matched, "could not get correct match value")
+
+	require.Nil(t, parent.values, "could pass values to first template")
+	require.Equal(t, map[string]interface{}{"path": "/admin", "version": "1.0", "token": "parent-token"}, child.values, "could not pass values to subtemplate")
+	require.Equal(t, map[string]interface{}{"path": "/admin", "version": "2.0", "token": "parent-token"}, grandchild.values, "could not prefer values of most recent template")
+}
+
+func TestWorkflowsVariables(t *testing.T) {
+	progressBar, _ := progress.NewStatsTicker(0, false, false, 0)
+
+	workflowVariables := &variables.Variable{}
+	err := yaml.Unmarshal([]byte("env: staging\n"), workflowVariables)
+	require.Nil(t, err, "could not unmarshal variables")
+
+	first := &mockExecuter{result: true}
+	workflow := &Workflow{Options: &protocols.ExecuterOptions{Options: &types.Options{TemplateThreads: 10}, Variables: workflowVariables}, Workflows: []*WorkflowTemplate{
+		{Executers: []*ProtocolExecuterPair{{Executer: first, Options: &protocols.ExecuterOptions{Progress: progressBar}}}},
+	}}
+
+	matched := workflow.RunWorkflow("https://test.com")
+	require.True(t, matched, "could not get correct match value")
+	require.Equal(t, map[string]interface{}{"env": "staging"}, first.values, "could not pass workflow variables")
+}
+
 type mockExecuter struct {
 	result      bool
 	executeHook func(input string)
 	outputs     []*output.InternalWrappedEvent
+	values      map[string]interface{}
 }
 
 // Compile compiles the execution generators preparing any requests possible.
@@ -186,3 +300,15 @@ func (m *mockExecuter) ExecuteWithResults(input string, callback protocols.Outpu
 	}
 	return nil
 }
+
+// ExecuteWithValues executes the protocol group with initial dynamic values.
+func (m *mockExecuter) ExecuteWithValues(input string, values map[string]interface{}) (bool, error) {
+	m.values = values
+	return m.Execute(input)
+}
+
+// ExecuteWithValuesAndResults executes the protocol requests with initial dynamic values and returns results instead of writing them.
+func (m *mockExecuter) ExecuteWithValuesAndResults(input string, values map[string]interface{}, callback protocols.OutputEventCallback) error {
+	m.values = values
+	return m.ExecuteWithResults(input, callback)
+}
@@ -15,6 +15,9 @@ type WorkflowTemplate struct {
 	// Template is the template to run
 	Template string `yaml:"template"`
 	// Matchers perform name based matching to run subtemplates for a workflow.
+	//
+	// Matchers without subtemplates on a subtemplate are the names of the
+	// matchers of its parent it runs for, for example `matchers: [v2.x]`.
 	Matchers []*Matcher `yaml:"matchers"`
 	// Subtemplates are ran if the template matches.
 	Subtemplates []*WorkflowTemplate `yaml:"subtemplates"`
@@ -35,3 +38,42 @@ type Matcher struct {
 	// Subtemplates are ran if the name of matcher matches.
 	Subtemplates []*WorkflowTemplate `yaml:"subtemplates"`
 }
+
+// UnmarshalYAML unmarshals a matcher, allowing the name alone
+func (m *Matcher) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var name string
+	if err := unmarshal(&name); err == nil {
+		m.Name = name
+		return nil
+	}
+	type plain Matcher
+	return unmarshal((*plain)(m))
+}
+
+// subtemplateMatchers returns the matchers of the template running subtemplates
+func (t *WorkflowTemplate) subtemplateMatchers() []*Matcher {
+	var matchers []*Matcher
+	for _, matcher := range t.Matchers {
+		if len(matcher.Subtemplates) > 0 {
+			matchers = append(matchers, matcher)
+		}
+	}
+	return matchers
+}
+
+// matchesParent returns true if the names matched by the parent of a
+// subtemplate include one of its matchers without subtemplates, or if it
+// has none.
+func (t *WorkflowTemplate) matchesParent(names map[string]struct{}) bool {
+	var conditions int
+	for _, matcher := range t.Matchers {
+		if len(matcher.Subtemplates) > 0 {
+			continue
+		}
+		conditions++
+		if _, ok := names[matcher.Name]; ok {
+			return true
+		}
+	}
+	return conditions == 0
+}